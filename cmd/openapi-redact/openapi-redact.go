@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// openapi-redact removes paths and definitions from the OpenAPI v2 spec read
+// on stdin, and writes the result on stdout.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/redact"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// stringList is a flag which can be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	var pathPrefixes, gvks, extensions stringList
+	flag.Var(&pathPrefixes, "path-prefix", "remove the paths with this prefix (repeatable)")
+	flag.Var(&gvks, "gvk", "remove the definitions and paths of group/version/kind, where empty parts match anything (repeatable)")
+	flag.Var(&extensions, "extension", "remove the definitions and paths with the vendor extension key=value (repeatable)")
+	scrubDescriptions := flag.Bool("scrub-descriptions", false, "clear descriptions")
+	scrubExamples := flag.Bool("scrub-examples", false, "clear examples")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		log.Fatal("this program takes input on stdin and writes output to stdout.")
+	}
+
+	opts := redact.Options{
+		PathPrefixes:      pathPrefixes,
+		Extensions:        map[string][]string{},
+		ScrubDescriptions: *scrubDescriptions,
+		ScrubExamples:     *scrubExamples,
+	}
+	for _, gvk := range gvks {
+		parts := strings.Split(gvk, "/")
+		if len(parts) != 3 {
+			log.Fatalf("invalid -gvk %q, expected group/version/kind", gvk)
+		}
+		opts.GroupVersionKinds = append(opts.GroupVersionKinds, redact.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]})
+	}
+	for _, ext := range extensions {
+		i := strings.Index(ext, "=")
+		if i < 0 {
+			log.Fatalf("invalid -extension %q, expected key=value", ext)
+		}
+		opts.Extensions[ext[:i]] = append(opts.Extensions[ext[:i]], ext[i+1:])
+	}
+
+	input, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatalf("error reading stdin: %v", err)
+	}
+	sp := &spec.Swagger{}
+	if err := json.Unmarshal(input, sp); err != nil {
+		log.Fatalf("error interpreting stdin: %v", err)
+	}
+
+	output, err := json.MarshalIndent(redact.Redact(sp, opts), "", "  ")
+	if err != nil {
+		log.Fatalf("error serializing spec: %v", err)
+	}
+	fmt.Println(string(output))
+}
@@ -21,9 +21,11 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"k8s.io/kube-openapi/pkg/validation/spec"
 
+	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/schemamutation"
 	"k8s.io/kube-openapi/pkg/util"
 )
@@ -91,74 +93,180 @@ func FilterSpecByPathsWithoutSideEffects(sp *spec.Swagger, keepPathPrefixes []st
 	return &ret
 }
 
-type rename struct {
-	from, to string
+// PruneOptions selects the roots of PruneSpec.
+type PruneOptions struct {
+	// PathPrefixes are the prefixes of the paths kept. Use "/" to keep all paths.
+	PathPrefixes []string
+	// Definitions are the names of definitions kept in addition to those
+	// referenced by the kept paths, e.g. the kinds of a group.
+	Definitions []string
 }
 
-// renameDefinition renames references, without mutating the input.
-// The output might share data structures with the input.
-func renameDefinition(s *spec.Swagger, renames map[string]string) *spec.Swagger {
-	refRenames := make(map[string]string, len(renames))
-	foundOne := false
-	for k, v := range renames {
-		refRenames[definitionPrefix+k] = definitionPrefix + v
-		if _, ok := s.Definitions[k]; ok {
-			foundOne = true
+// PruneSpec returns sp with the paths selected by opts, and only the
+// definitions, shared parameters and shared responses transitively
+// referenced by them or by the definitions of opts. Other definitions are
+// dropped, even if they were not used by any path of sp.
+// It does not modify the input, but the output shares data structures with the input.
+func PruneSpec(sp *spec.Swagger, opts PruneOptions) *spec.Swagger {
+	ret := *sp
+	if sp.Paths != nil {
+		prefixes := util.NewTrie(opts.PathPrefixes)
+		ret.Paths = &spec.Paths{
+			VendorExtensible: sp.Paths.VendorExtensible,
+			Paths:            map[string]spec.PathItem{},
+		}
+		for path, pathItem := range sp.Paths.Paths {
+			if prefixes.HasPrefix(path) {
+				ret.Paths.Paths[path] = pathItem
+			}
 		}
 	}
 
-	if !foundOne {
-		return s
+	definitions, parameters, responses := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	walker := &readonlyReferenceWalker{root: &ret}
+	visitDefinition := func(name string) {
+		if def, found := sp.Definitions[name]; found && !definitions[name] {
+			definitions[name] = true
+			walker.walkSchema(&def)
+		}
 	}
-
-	ret := &spec.Swagger{}
-	*ret = *s
-
-	ret = schemamutation.ReplaceReferences(func(ref *spec.Ref) *spec.Ref {
-		refName := ref.String()
-		if newRef, found := refRenames[refName]; found {
-			ret := spec.MustCreateRef(newRef)
-			return &ret
+	walker.walkRefCallback = func(ref *spec.Ref) {
+		refStr := ref.String()
+		switch {
+		case strings.HasPrefix(refStr, definitionPrefix):
+			visitDefinition(refStr[len(definitionPrefix):])
+		case strings.HasPrefix(refStr, parameterPrefix):
+			name := refStr[len(parameterPrefix):]
+			if param, found := sp.Parameters[name]; found && !parameters[name] {
+				parameters[name] = true
+				walker.walkParams([]spec.Parameter{param})
+			}
+		case strings.HasPrefix(refStr, responsePrefix):
+			name := refStr[len(responsePrefix):]
+			if resp, found := sp.Responses[name]; found && !responses[name] {
+				responses[name] = true
+				walker.walkResponse(&resp)
+			}
 		}
-		return ref
-	}, ret)
+	}
+	for _, name := range opts.Definitions {
+		visitDefinition(name)
+	}
+	walker.Start()
 
-	renamedDefinitions := make(spec.Definitions, len(ret.Definitions))
-	for k, v := range ret.Definitions {
-		if newRef, found := renames[k]; found {
-			k = newRef
+	if sp.Definitions != nil {
+		ret.Definitions = make(spec.Definitions, len(definitions))
+		for name := range definitions {
+			ret.Definitions[name] = sp.Definitions[name]
+		}
+	}
+	if sp.Parameters != nil {
+		ret.Parameters = make(map[string]spec.Parameter, len(parameters))
+		for name := range parameters {
+			ret.Parameters[name] = sp.Parameters[name]
 		}
-		renamedDefinitions[k] = v
 	}
-	ret.Definitions = renamedDefinitions
+	if sp.Responses != nil {
+		ret.Responses = make(map[string]spec.Response, len(responses))
+		for name := range responses {
+			ret.Responses[name] = sp.Responses[name]
+		}
+	}
+	return &ret
+}
 
-	return ret
+type rename struct {
+	from, to string
+}
+
+// MergeOptions configures MergeSpecsWithOptions.
+type MergeOptions struct {
+	// RenameModelConflicts renames the conflicting definitions of the source,
+	// instead of failing on definition conflicts.
+	RenameModelConflicts bool
+	// IgnorePathConflicts keeps the paths of the destination on conflicts,
+	// instead of failing.
+	IgnorePathConflicts bool
+	// ConflictPolicy resolves the conflicts of definitions and paths. If set,
+	// RenameModelConflicts and IgnorePathConflicts are ignored.
+	ConflictPolicy ConflictPolicy
+	// RecordSources adds Source to the SourcesExtension of the paths and
+	// definitions merged into dest, to debug where entries of the merged spec
+	// come from. Definitions shared by several sources list all of them.
+	RecordSources bool
+
+	// ExtensionLimits bounds the size of the vendor extensions of the source
+	// spec. Sources exceeding them are rejected before dest is modified. If
+	// nil, the size is not computed.
+	ExtensionLimits *common.ExtensionLimits
+
+	// Source names the source spec in log events, e.g. the service it was downloaded from.
+	Source string
+	// Logger receives structured events about the merge. If nil, events are written to klog.
+	Logger common.Logger
 }
 
 // MergeSpecsIgnorePathConflict is the same as MergeSpecs except it will ignore any path
 // conflicts by keeping the paths of destination. It will rename definition conflicts.
 // The source is not mutated.
 func MergeSpecsIgnorePathConflict(dest, source *spec.Swagger) error {
-	return mergeSpecs(dest, source, true, true)
+	return MergeSpecsWithOptions(dest, source, MergeOptions{RenameModelConflicts: true, IgnorePathConflicts: true})
 }
 
 // MergeSpecsFailOnDefinitionConflict is differ from MergeSpecs as it fails if there is
 // a definition conflict.
 // The source is not mutated.
 func MergeSpecsFailOnDefinitionConflict(dest, source *spec.Swagger) error {
-	return mergeSpecs(dest, source, false, false)
+	return MergeSpecsWithOptions(dest, source, MergeOptions{})
 }
 
 // MergeSpecs copies paths and definitions from source to dest, rename definitions if needed.
 // dest will be mutated, and source will not be changed. It will fail on path conflicts.
 // The source is not mutated.
 func MergeSpecs(dest, source *spec.Swagger) error {
-	return mergeSpecs(dest, source, true, false)
+	return MergeSpecsWithOptions(dest, source, MergeOptions{RenameModelConflicts: true})
+}
+
+// MergeSpecsWithOptions copies paths and definitions from source to dest, resolving
+// conflicts as specified by opts.
+// The source is not mutated.
+func MergeSpecsWithOptions(dest, source *spec.Swagger, opts MergeOptions) error {
+	_, err := MergeSpecsWithReport(dest, source, opts)
+	return err
+}
+
+// MergeSpecsWithReport is like MergeSpecsWithOptions, returning the conflicts
+// resolved by the merge.
+// The source is not mutated.
+func MergeSpecsWithReport(dest, source *spec.Swagger, opts MergeOptions) (*MergeReport, error) {
+	logger := common.LoggerOrDefault(opts.Logger)
+	start := time.Now()
+	if opts.ExtensionLimits != nil {
+		stats := common.SwaggerExtensionStats(source)
+		logger.Info("Computed OpenAPI spec vendor extensions size", "source", opts.Source, "size", stats.Total)
+		if err := opts.ExtensionLimits.Check(stats); err != nil {
+			err = fmt.Errorf("rejecting OpenAPI spec: %v", err)
+			logger.Error(err, "Failed to merge OpenAPI spec", "source", opts.Source)
+			return nil, err
+		}
+	}
+	report := &MergeReport{}
+	if err := mergeSpecs(dest, source, opts, logger, report); err != nil {
+		logger.Error(err, "Failed to merge OpenAPI spec", "source", opts.Source)
+		return nil, err
+	}
+	paths := 0
+	if source.Paths != nil {
+		paths = len(source.Paths.Paths)
+	}
+	logger.Info("Merged OpenAPI spec", "source", opts.Source, "paths", paths, "definitions", len(source.Definitions), "conflicts", len(report.Conflicts), "duration", time.Since(start))
+	return report, nil
 }
 
-// mergeSpecs merges source into dest while resolving conflicts.
+// mergeSpecs merges source into dest while resolving conflicts, adding them
+// to report.
 // The source is not mutated.
-func mergeSpecs(dest, source *spec.Swagger, renameModelConflicts, ignorePathConflicts bool) (err error) {
+func mergeSpecs(dest, source *spec.Swagger, opts MergeOptions, logger common.Logger, report *MergeReport) (err error) {
 	// Paths may be empty, due to [ACL constraints](http://goo.gl/8us55a#securityFiltering).
 	if source.Paths == nil {
 		// When a source spec does not have any path, that means none of the definitions
@@ -168,23 +276,45 @@ func mergeSpecs(dest, source *spec.Swagger, renameModelConflicts, ignorePathConf
 	if dest.Paths == nil {
 		dest.Paths = &spec.Paths{}
 	}
-	if ignorePathConflicts {
-		keepPaths := []string{}
-		hasConflictingPath := false
-		for k := range source.Paths.Paths {
-			if _, found := dest.Paths.Paths[k]; !found {
-				keepPaths = append(keepPaths, k)
-			} else {
-				hasConflictingPath = true
-			}
+	policy := opts.conflictPolicy()
+
+	// Check for path conflicts before modifying dest
+	sourcePaths := make([]string, 0, len(source.Paths.Paths))
+	for k := range source.Paths.Paths {
+		sourcePaths = append(sourcePaths, k)
+	}
+	sort.Strings(sourcePaths)
+	keepPaths := []string{}
+	var conflictingPaths []string
+	var pathConflicts []Conflict
+	replacedPaths := map[string]bool{}
+	for _, k := range sourcePaths {
+		if _, found := dest.Paths.Paths[k]; !found {
+			keepPaths = append(keepPaths, k)
+			continue
+		}
+		resolution := policy.ResolvePath(ConflictKindPath, k)
+		switch resolution {
+		case PreferLocal:
+			conflictingPaths = append(conflictingPaths, k)
+		case PreferNewest:
+			keepPaths = append(keepPaths, k)
+			replacedPaths[k] = true
+		case FailOnConflict:
+			return fmt.Errorf("unable to merge: duplicated path %s", k)
+		default:
+			return fmt.Errorf("unable to merge: duplicated path %s cannot be resolved by %s", k, resolution)
 		}
+		pathConflicts = append(pathConflicts, Conflict{Kind: ConflictKindPath, Name: k, Resolution: resolution})
+	}
+	if len(conflictingPaths) > 0 {
+		logger.Info("Ignoring conflicting paths", "source", opts.Source, "paths", conflictingPaths)
 		if len(keepPaths) == 0 {
 			// There is nothing to merge. All paths are conflicting.
+			report.Conflicts = append(report.Conflicts, pathConflicts...)
 			return nil
 		}
-		if hasConflictingPath {
-			source = FilterSpecByPathsWithoutSideEffects(source, keepPaths)
-		}
+		source = FilterSpecByPathsWithoutSideEffects(source, keepPaths)
 	}
 
 	// Check for model conflicts and rename to make definitions conflict-free (modulo different GVKs)
@@ -192,17 +322,33 @@ func mergeSpecs(dest, source *spec.Swagger, renameModelConflicts, ignorePathConf
 	for k := range dest.Definitions {
 		usedNames[k] = true
 	}
+	sourceDefinitions := make([]string, 0, len(source.Definitions))
+	for k := range source.Definitions {
+		sourceDefinitions = append(sourceDefinitions, k)
+	}
+	sort.Strings(sourceDefinitions)
 	renames := map[string]string{}
-DEFINITIONLOOP:
-	for k, v := range source.Definitions {
+	resolved := map[string]ConflictResolution{}
+	var definitionConflicts []Conflict
+	for _, k := range sourceDefinitions {
+		v := source.Definitions[k]
 		existing, found := dest.Definitions[k]
 		if !found || deepEqualDefinitionsModuloGVKs(&existing, &v) {
 			// skip for now, we copy them after the rename loop
 			continue
 		}
 
-		if !renameModelConflicts {
+		switch resolution := policy.ResolveDefinition(ConflictKindDefinition, k); resolution {
+		case RenameWithSuffix:
+		case PreferLocal, PreferNewest:
+			resolved[k] = resolution
+			definitionConflicts = append(definitionConflicts, Conflict{Kind: ConflictKindDefinition, Name: k, Resolution: resolution})
+			logger.Info("Resolved conflicting definition", "source", opts.Source, "definition", k, "resolution", resolution)
+			continue
+		case FailOnConflict:
 			return fmt.Errorf("model name conflict in merging OpenAPI spec: %s", k)
+		default:
+			return fmt.Errorf("model name conflict in merging OpenAPI spec: %s cannot be resolved by %s", k, resolution)
 		}
 
 		// Reuse previously renamed model if one exists
@@ -213,52 +359,75 @@ DEFINITIONLOOP:
 			newName = fmt.Sprintf("%s_v%d", k, i)
 			existing, found = dest.Definitions[newName]
 			if found && deepEqualDefinitionsModuloGVKs(&existing, &v) {
-				renames[k] = newName
-				continue DEFINITIONLOOP
+				break
 			}
 		}
 
-		_, foundInSource := source.Definitions[newName]
-		for usedNames[newName] || foundInSource {
-			i++
-			newName = fmt.Sprintf("%s_v%d", k, i)
-			_, foundInSource = source.Definitions[newName]
+		if !found {
+			_, foundInSource := source.Definitions[newName]
+			for usedNames[newName] || foundInSource {
+				i++
+				newName = fmt.Sprintf("%s_v%d", k, i)
+				_, foundInSource = source.Definitions[newName]
+			}
+			usedNames[newName] = true
 		}
 		renames[k] = newName
-		usedNames[newName] = true
+		definitionConflicts = append(definitionConflicts, Conflict{Kind: ConflictKindDefinition, Name: k, Resolution: RenameWithSuffix, NewName: newName})
+		logger.Info("Renamed conflicting definition", "source", opts.Source, "definition", k, "newName", newName)
+	}
+	if source, err = schemamutation.RenameDefinitions(source, renames); err != nil {
+		return err
 	}
-	source = renameDefinition(source, renames)
 
 	// now without conflict (modulo different GVKs), copy definitions to dest
 	for k, v := range source.Definitions {
-		if existing, found := dest.Definitions[k]; !found {
+		if resolved[k] == PreferLocal {
+			continue
+		}
+		if existing, found := dest.Definitions[k]; !found || resolved[k] == PreferNewest {
 			if dest.Definitions == nil {
 				dest.Definitions = spec.Definitions{}
 			}
+			if opts.RecordSources {
+				v.Extensions = withSource(v.Extensions, opts.Source)
+			}
 			dest.Definitions[k] = v
-		} else if merged, changed, err := mergedGVKs(&existing, &v); err != nil {
-			return err
-		} else if changed {
-			existing.Extensions[gvkKey] = merged
+		} else {
+			if merged, changed, err := mergedGVKs(&existing, &v); err != nil {
+				return err
+			} else if changed {
+				existing.Extensions = withGVKs(existing.Extensions, merged)
+			}
+			if opts.RecordSources {
+				existing.Extensions = withSource(existing.Extensions, opts.Source)
+			}
+			dest.Definitions[k] = existing
 		}
 	}
 
-	// Check for path conflicts
 	for k, v := range source.Paths.Paths {
-		if _, found := dest.Paths.Paths[k]; found {
-			return fmt.Errorf("unable to merge: duplicated path %s", k)
+		if _, found := dest.Paths.Paths[k]; found && !replacedPaths[k] {
+			// kept by a prefix of keepPaths
+			continue
 		}
 		// PathItem may be empty, due to [ACL constraints](http://goo.gl/8us55a#securityFiltering).
 		if dest.Paths.Paths == nil {
 			dest.Paths.Paths = map[string]spec.PathItem{}
 		}
+		if opts.RecordSources {
+			v.Extensions = withSource(v.Extensions, opts.Source)
+		}
 		dest.Paths.Paths[k] = v
 	}
 
+	report.Conflicts = append(report.Conflicts, pathConflicts...)
+	report.Conflicts = append(report.Conflicts, definitionConflicts...)
 	return nil
 }
 
-// deepEqualDefinitionsModuloGVKs compares s1 and s2, but ignores the x-kubernetes-group-version-kind extension.
+// deepEqualDefinitionsModuloGVKs compares s1 and s2, but ignores the x-kubernetes-group-version-kind
+// and x-kubernetes-openapi-sources extensions.
 func deepEqualDefinitionsModuloGVKs(s1, s2 *spec.Schema) bool {
 	if s1 == nil {
 		return s2 == nil
@@ -267,7 +436,7 @@ func deepEqualDefinitionsModuloGVKs(s1, s2 *spec.Schema) bool {
 	}
 	if !reflect.DeepEqual(s1.Extensions, s2.Extensions) {
 		for k, v := range s1.Extensions {
-			if k == gvkKey {
+			if k == gvkKey || k == SourcesExtension {
 				continue
 			}
 			if !reflect.DeepEqual(v, s2.Extensions[k]) {
@@ -276,11 +445,13 @@ func deepEqualDefinitionsModuloGVKs(s1, s2 *spec.Schema) bool {
 		}
 		len1 := len(s1.Extensions)
 		len2 := len(s2.Extensions)
-		if _, found := s1.Extensions[gvkKey]; found {
-			len1--
-		}
-		if _, found := s2.Extensions[gvkKey]; found {
-			len2--
+		for _, k := range []string{gvkKey, SourcesExtension} {
+			if _, found := s1.Extensions[k]; found {
+				len1--
+			}
+			if _, found := s2.Extensions[k]; found {
+				len2--
+			}
 		}
 		if len1 != len2 {
 			return false
@@ -301,59 +472,87 @@ func deepEqualDefinitionsModuloGVKs(s1, s2 *spec.Schema) bool {
 	return reflect.DeepEqual(s1, s2)
 }
 
-// mergedGVKs merges the x-kubernetes-group-version-kind slices and returns the result, and whether
-// s1's x-kubernetes-group-version-kind slice was changed at all.
-func mergedGVKs(s1, s2 *spec.Schema) (interface{}, bool, error) {
-	gvk1, found1 := s1.Extensions[gvkKey]
-	gvk2, found2 := s2.Extensions[gvkKey]
-
-	if !found1 {
-		return gvk2, found2, nil
-	}
-	if !found2 {
-		return gvk1, false, nil
+// withGVKs returns a copy of ext with the x-kubernetes-group-version-kind
+// extension set to gvks. ext is not mutated, as it might be shared with the
+// spec it was merged from.
+func withGVKs(ext spec.Extensions, gvks interface{}) spec.Extensions {
+	ret := make(spec.Extensions, len(ext)+1)
+	for k, v := range ext {
+		ret[k] = v
 	}
+	ret[gvkKey] = gvks
+	return ret
+}
 
-	slice1, ok := gvk1.([]interface{})
-	if !ok {
-		return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", slice1)
-	}
-	slice2, ok := gvk2.([]interface{})
-	if !ok {
-		return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", slice2)
+// mergedGVKs merges the x-kubernetes-group-version-kind slices of s1 and others, in order, and
+// returns the result, and whether s1's x-kubernetes-group-version-kind slice was changed at all.
+func mergedGVKs(s1 *spec.Schema, others ...*spec.Schema) (interface{}, bool, error) {
+	gvk1, found := s1.Extensions[gvkKey]
+	changed := false
+	for !found && len(others) > 0 {
+		gvk1, found = others[0].Extensions[gvkKey]
+		changed = found
+		others = others[1:]
 	}
 
-	ret := make([]interface{}, len(slice1), len(slice1)+len(slice2))
-	keys := make([]string, 0, len(slice1)+len(slice2))
-	copy(ret, slice1)
-	seen := make(map[string]bool, len(slice1))
-	for _, x := range slice1 {
-		gvk, ok := x.(map[string]interface{})
-		if !ok {
-			return nil, false, fmt.Errorf(`expected {"group": <group>, "kind": <kind>, "version": <version>}, got: %#v`, x)
+	var ret []interface{}
+	var keys []string
+	var seen map[string]bool
+	added := false
+	for _, s2 := range others {
+		gvk2, found2 := s2.Extensions[gvkKey]
+		if !found2 {
+			continue
 		}
-		k := fmt.Sprintf("%s/%s.%s", gvk["group"], gvk["version"], gvk["kind"])
-		keys = append(keys, k)
-		seen[k] = true
-	}
-	changed := false
-	for _, x := range slice2 {
-		gvk, ok := x.(map[string]interface{})
+		if seen == nil {
+			slice1, ok := gvk1.([]interface{})
+			if !ok {
+				return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", slice1)
+			}
+			ret = make([]interface{}, len(slice1))
+			keys = make([]string, 0, len(slice1))
+			copy(ret, slice1)
+			seen = make(map[string]bool, len(slice1))
+			for _, x := range slice1 {
+				gvk, ok := x.(map[string]interface{})
+				if !ok {
+					return nil, false, fmt.Errorf(`expected {"group": <group>, "kind": <kind>, "version": <version>}, got: %#v`, x)
+				}
+				k := fmt.Sprintf("%s/%s.%s", gvk["group"], gvk["version"], gvk["kind"])
+				keys = append(keys, k)
+				seen[k] = true
+			}
+		}
+		slice2, ok := gvk2.([]interface{})
 		if !ok {
-			return nil, false, fmt.Errorf(`expected {"group": <group>, "kind": <kind>, "version": <version>}, got: %#v`, x)
+			return nil, false, fmt.Errorf("expected slice of GroupVersionKinds, got: %+v", slice2)
 		}
-		k := fmt.Sprintf("%s/%s.%s", gvk["group"], gvk["version"], gvk["kind"])
-		if seen[k] {
-			continue
+		n := len(keys)
+		for _, x := range slice2 {
+			gvk, ok := x.(map[string]interface{})
+			if !ok {
+				return nil, false, fmt.Errorf(`expected {"group": <group>, "kind": <kind>, "version": <version>}, got: %#v`, x)
+			}
+			k := fmt.Sprintf("%s/%s.%s", gvk["group"], gvk["version"], gvk["kind"])
+			if seen[k] {
+				continue
+			}
+			ret = append(ret, x)
+			keys = append(keys, k)
+			added = true
 		}
-		ret = append(ret, x)
-		keys = append(keys, k)
-		changed = true
+		for _, k := range keys[n:] {
+			seen[k] = true
+		}
+	}
+	if seen == nil {
+		return gvk1, changed, nil
 	}
 
-	if changed {
+	if added {
 		sort.Sort(byKeys{ret, keys})
 	}
+	changed = changed || added
 
 	return ret, changed, nil
 }
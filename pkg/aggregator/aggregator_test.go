@@ -22,9 +22,11 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/handler"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"sigs.k8s.io/yaml"
@@ -251,6 +253,97 @@ definitions:
 	ast.Equal(DebugSpec{orig_spec1}, DebugSpec{spec1}, "unexpected mutation of input")
 }
 
+func TestPruneSpec(t *testing.T) {
+	var spec1 *spec.Swagger
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /apis/group/v1/widgets:
+    get:
+      parameters:
+      - $ref: "#/parameters/pretty"
+      responses:
+        200:
+          description: OK
+          schema:
+            $ref: "#/definitions/WidgetList"
+        401:
+          $ref: "#/responses/unauthorized"
+  /apis/other/v1/gadgets:
+    get:
+      responses:
+        200:
+          description: OK
+          schema:
+            $ref: "#/definitions/Gadget"
+definitions:
+  WidgetList:
+    type: object
+    properties:
+      items:
+        type: array
+        items:
+          $ref: "#/definitions/Widget"
+  Widget:
+    type: object
+  Gadget:
+    type: object
+  Status:
+    type: object
+  Orphan:
+    type: object
+    properties:
+      status:
+        $ref: "#/definitions/Status"
+  Event:
+    type: object
+    properties:
+      involved:
+        $ref: "#/definitions/Widget"
+parameters:
+  pretty:
+    name: pretty
+    in: query
+    type: string
+  dryRun:
+    name: dryRun
+    in: query
+    type: string
+responses:
+  unauthorized:
+    description: unauthorized
+    schema:
+      $ref: "#/definitions/Status"
+`), &spec1)
+
+	ast := assert.New(t)
+	origSpec1, _ := cloneSpec(spec1)
+	pruned := PruneSpec(spec1, PruneOptions{PathPrefixes: []string{"/apis/group/"}, Definitions: []string{"Event", "Missing"}})
+	ast.Equal(DebugSpec{origSpec1}, DebugSpec{spec1}, "unexpected mutation of input")
+
+	ast.Equal([]string{"/apis/group/v1/widgets"}, keysOf(pruned.Paths.Paths))
+	ast.Equal([]string{"Event", "Status", "Widget", "WidgetList"}, keysOf(pruned.Definitions))
+	ast.Equal([]string{"pretty"}, keysOf(pruned.Parameters))
+	ast.Equal([]string{"unauthorized"}, keysOf(pruned.Responses))
+
+	// definitions only, e.g. for a single kind
+	pruned = PruneSpec(spec1, PruneOptions{Definitions: []string{"Orphan"}})
+	ast.Empty(pruned.Paths.Paths)
+	ast.Equal([]string{"Orphan", "Status"}, keysOf(pruned.Definitions))
+	ast.Empty(pruned.Parameters)
+	ast.Empty(pruned.Responses)
+}
+
+// keysOf returns the sorted keys of the map m.
+func keysOf(m interface{}) []string {
+	var keys []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func TestMergeSpecsSimple(t *testing.T) {
 	var spec1, spec2, expected *spec.Swagger
 	yaml.Unmarshal([]byte(`
@@ -2153,3 +2246,177 @@ func TestDeepEqualDefinitionsModuloGVKs(t *testing.T) {
 		})
 	}
 }
+
+type recordingLogger struct {
+	infos  []string
+	errors []string
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.infos = append(l.infos, fmt.Sprint(append([]interface{}{msg}, keysAndValues...)...))
+}
+
+func (l *recordingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprint(append([]interface{}{msg, err}, keysAndValues...)...))
+}
+
+func TestMergeSpecsWithOptionsLogs(t *testing.T) {
+	ast := assert.New(t)
+	var spec1, spec2 *spec.Swagger
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /foo:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Foo"
+definitions:
+  Foo:
+    type: string
+`), &spec1)
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /foo:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Foo"
+  /bar:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Foo"
+definitions:
+  Foo:
+    type: integer
+`), &spec2)
+
+	logger := &recordingLogger{}
+	ast.NoError(MergeSpecsWithOptions(spec1, spec2, MergeOptions{
+		RenameModelConflicts: true,
+		IgnorePathConflicts:  true,
+		Source:               "svc",
+		Logger:               logger,
+	}))
+	ast.Contains(spec1.Definitions, "Foo_v2")
+	ast.Empty(logger.errors)
+	ast.Len(logger.infos, 3)
+	ast.Contains(logger.infos[0], "Ignoring conflicting paths")
+	ast.Contains(logger.infos[1], "Renamed conflicting definition")
+	ast.Contains(logger.infos[1], "Foo_v2")
+	ast.Contains(logger.infos[2], "Merged OpenAPI spec")
+
+	logger = &recordingLogger{}
+	ast.Error(MergeSpecsWithOptions(spec1, spec2, MergeOptions{Source: "svc", Logger: logger}))
+	ast.Len(logger.errors, 1)
+	ast.Contains(logger.errors[0], "Failed to merge OpenAPI spec")
+}
+
+func TestMergeSpecsRecordSources(t *testing.T) {
+	ast := assert.New(t)
+	var spec1, spec2, spec3 *spec.Swagger
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /foo:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Status"
+definitions:
+  Status:
+    type: string
+`), &spec1)
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /bar:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Status"
+definitions:
+  Status:
+    type: string
+`), &spec2)
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /baz:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Status"
+definitions:
+  Status:
+    type: integer
+`), &spec3)
+	source2, err := cloneSpec(spec2)
+	ast.NoError(err)
+
+	AnnotateSources(spec1, "local")
+	ast.NoError(MergeSpecsWithOptions(spec1, spec2, MergeOptions{RenameModelConflicts: true, RecordSources: true, Source: "svc-b"}))
+	ast.NoError(MergeSpecsWithOptions(spec1, spec3, MergeOptions{RenameModelConflicts: true, RecordSources: true, Source: "svc-c"}))
+
+	ast.Equal([]string{"local"}, PathSources(spec1, "/foo"))
+	ast.Equal([]string{"svc-b"}, PathSources(spec1, "/bar"))
+	ast.Equal([]string{"svc-c"}, PathSources(spec1, "/baz"))
+	ast.Nil(PathSources(spec1, "/missing"))
+	ast.Equal([]string{"local", "svc-b"}, DefinitionSources(spec1, "Status"), "shared definitions list all sources")
+	ast.Equal([]string{"svc-c"}, DefinitionSources(spec1, "Status_v2"))
+	ast.Equal(source2, spec2, "the source must not be mutated")
+}
+
+func TestMergeSpecsExtensionLimits(t *testing.T) {
+	ast := assert.New(t)
+	var spec1, spec2 *spec.Swagger
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /foo:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Foo"
+definitions:
+  Foo:
+    type: string
+`), &spec1)
+	yaml.Unmarshal([]byte(`
+swagger: "2.0"
+paths:
+  /bar:
+    get:
+      x-op: 1
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Bar"
+definitions:
+  Bar:
+    type: object
+    x-kubernetes-validations:
+    - rule: self.a == self.b
+    properties:
+      a:
+        type: string
+        x-kubernetes-list-type: atomic
+`), &spec2)
+
+	err := MergeSpecsWithOptions(spec1, spec2, MergeOptions{ExtensionLimits: &common.ExtensionLimits{MaxPerDefinition: 50}, Logger: common.NoopLogger})
+	ast.EqualError(err, "rejecting OpenAPI spec: vendor extensions of definition Bar are 83 bytes, exceeding the limit of 50 bytes")
+	ast.NotContains(spec1.Paths.Paths, "/bar", "rejected sources must not be merged")
+
+	err = MergeSpecsWithOptions(spec1, spec2, MergeOptions{ExtensionLimits: &common.ExtensionLimits{MaxPerDefinition: 83, MaxTotal: 88}, Logger: common.NoopLogger})
+	ast.NoError(err)
+	ast.Contains(spec1.Definitions, "Bar")
+}
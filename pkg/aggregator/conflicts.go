@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+// ConflictResolution is how a definition or path of the source of a merge,
+// conflicting with one of the destination, is resolved.
+type ConflictResolution string
+
+const (
+	// PreferLocal keeps the entry of the destination. The references of the
+	// source to a definition then point to the one of the destination.
+	PreferLocal ConflictResolution = "prefer-local"
+	// PreferNewest replaces the entry of the destination by the one of the
+	// source, i.e. the spec merged last wins.
+	PreferNewest ConflictResolution = "prefer-newest"
+	// RenameWithSuffix renames the definition of the source with a _vN
+	// suffix, rewriting the references to it. It is not supported for paths.
+	RenameWithSuffix ConflictResolution = "rename-with-suffix"
+	// FailOnConflict fails the merge.
+	FailOnConflict ConflictResolution = "error"
+)
+
+// The kinds of entries of a Conflict besides the kinds of OpenAPI v3
+// components, e.g. "schemas".
+const (
+	ConflictKindDefinition = "definition"
+	ConflictKindPath       = "path"
+	ConflictKindWebhook    = "webhook"
+)
+
+// ConflictPolicy decides how conflicts of a merge are resolved. Definitions
+// which are equal modulo their x-kubernetes-group-version-kind extension are
+// shared and do not conflict.
+type ConflictPolicy interface {
+	// ResolveDefinition returns the resolution of a conflict of the
+	// definition name, of kind ConflictKindDefinition for OpenAPI v2 or of
+	// the kind of components, e.g. "schemas", for OpenAPI v3.
+	ResolveDefinition(kind, name string) ConflictResolution
+	// ResolvePath returns the resolution of a conflict of the path, of kind
+	// ConflictKindPath or ConflictKindWebhook.
+	ResolvePath(kind, path string) ConflictResolution
+}
+
+// StaticConflictPolicy resolves all conflicts of definitions, respectively
+// paths, the same way.
+type StaticConflictPolicy struct {
+	Definitions ConflictResolution
+	Paths       ConflictResolution
+}
+
+var _ ConflictPolicy = StaticConflictPolicy{}
+
+// ResolveDefinition returns p.Definitions.
+func (p StaticConflictPolicy) ResolveDefinition(kind, name string) ConflictResolution {
+	return p.Definitions
+}
+
+// ResolvePath returns p.Paths.
+func (p StaticConflictPolicy) ResolvePath(kind, path string) ConflictResolution {
+	return p.Paths
+}
+
+// conflictPolicy returns the ConflictPolicy of opts, defaulting to the one
+// given by RenameModelConflicts and IgnorePathConflicts.
+func (opts MergeOptions) conflictPolicy() ConflictPolicy {
+	if opts.ConflictPolicy != nil {
+		return opts.ConflictPolicy
+	}
+	p := StaticConflictPolicy{Definitions: FailOnConflict, Paths: FailOnConflict}
+	if opts.RenameModelConflicts {
+		p.Definitions = RenameWithSuffix
+	}
+	if opts.IgnorePathConflicts {
+		p.Paths = PreferLocal
+	}
+	return p
+}
+
+// MergeReport lists the conflicts resolved by a merge, in the order they
+// were resolved.
+type MergeReport struct {
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+// Conflict is an entry of the source of a merge conflicting with one of the
+// destination.
+type Conflict struct {
+	// Kind is the kind of the entry, e.g. ConflictKindPath.
+	Kind string `json:"kind"`
+	// Name is the name of the entry, e.g. the path.
+	Name string `json:"name"`
+	// Resolution is how the conflict was resolved.
+	Resolution ConflictResolution `json:"resolution"`
+	// NewName is the name of the renamed entry of the source, for
+	// RenameWithSuffix.
+	NewName string `json:"newName,omitempty"`
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const conflictingSpec1 = `
+swagger: "2.0"
+paths:
+  /foo:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Foo"
+definitions:
+  Foo:
+    type: string
+  Bar:
+    type: string
+`
+
+const conflictingSpec2 = `
+swagger: "2.0"
+paths:
+  /foo:
+    post:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Foo"
+  /bar:
+    get:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Bar"
+    post:
+      responses:
+        200:
+          schema:
+            $ref: "#/definitions/Foo"
+definitions:
+  Foo:
+    type: integer
+  Bar:
+    type: integer
+`
+
+// byNamePolicy resolves the conflicts of the definitions and paths it names,
+// failing on others.
+type byNamePolicy map[string]ConflictResolution
+
+func (p byNamePolicy) ResolveDefinition(kind, name string) ConflictResolution {
+	if r, found := p[name]; found {
+		return r
+	}
+	return FailOnConflict
+}
+
+func (p byNamePolicy) ResolvePath(kind, path string) ConflictResolution {
+	return p.ResolveDefinition(kind, path)
+}
+
+func parseConflictingSpecs(t *testing.T) (*spec.Swagger, *spec.Swagger) {
+	var spec1, spec2 *spec.Swagger
+	require.NoError(t, yaml.Unmarshal([]byte(conflictingSpec1), &spec1))
+	require.NoError(t, yaml.Unmarshal([]byte(conflictingSpec2), &spec2))
+	return spec1, spec2
+}
+
+func TestMergeSpecsConflictPolicy(t *testing.T) {
+	ast := assert.New(t)
+
+	spec1, spec2 := parseConflictingSpecs(t)
+	report, err := MergeSpecsWithReport(spec1, spec2, MergeOptions{
+		ConflictPolicy: StaticConflictPolicy{Definitions: PreferNewest, Paths: PreferNewest},
+		Logger:         common.NoopLogger,
+	})
+	ast.NoError(err)
+	ast.Equal(spec.StringOrArray{"integer"}, spec1.Definitions["Foo"].Type)
+	ast.Equal(spec.StringOrArray{"integer"}, spec1.Definitions["Bar"].Type)
+	ast.Nil(spec1.Paths.Paths["/foo"].Get)
+	ast.NotNil(spec1.Paths.Paths["/foo"].Post)
+	ast.Equal([]Conflict{
+		{Kind: ConflictKindPath, Name: "/foo", Resolution: PreferNewest},
+		{Kind: ConflictKindDefinition, Name: "Bar", Resolution: PreferNewest},
+		{Kind: ConflictKindDefinition, Name: "Foo", Resolution: PreferNewest},
+	}, report.Conflicts)
+
+	spec1, spec2 = parseConflictingSpecs(t)
+	report, err = MergeSpecsWithReport(spec1, spec2, MergeOptions{
+		ConflictPolicy: byNamePolicy{"/foo": PreferLocal, "Foo": PreferLocal, "Bar": RenameWithSuffix},
+		Logger:         common.NoopLogger,
+	})
+	ast.NoError(err)
+	ast.Equal(spec.StringOrArray{"string"}, spec1.Definitions["Foo"].Type)
+	ast.Equal(spec.StringOrArray{"integer"}, spec1.Definitions["Bar_v2"].Type)
+	ast.Equal("#/definitions/Bar_v2", spec1.Paths.Paths["/bar"].Get.Responses.StatusCodeResponses[200].Schema.Ref.String())
+	ast.NotNil(spec1.Paths.Paths["/foo"].Get)
+	b, err := json.Marshal(report)
+	ast.NoError(err)
+	ast.JSONEq(`{"conflicts": [
+		{"kind": "path", "name": "/foo", "resolution": "prefer-local"},
+		{"kind": "definition", "name": "Bar", "resolution": "rename-with-suffix", "newName": "Bar_v2"},
+		{"kind": "definition", "name": "Foo", "resolution": "prefer-local"}
+	]}`, string(b))
+
+	spec1, spec2 = parseConflictingSpecs(t)
+	_, err = MergeSpecsWithReport(spec1, spec2, MergeOptions{
+		ConflictPolicy: byNamePolicy{"/foo": PreferLocal, "Foo": PreferLocal},
+		Logger:         common.NoopLogger,
+	})
+	ast.EqualError(err, "model name conflict in merging OpenAPI spec: Bar")
+	_, err = MergeSpecsWithReport(spec1, spec2, MergeOptions{
+		ConflictPolicy: StaticConflictPolicy{Definitions: PreferLocal, Paths: RenameWithSuffix},
+		Logger:         common.NoopLogger,
+	})
+	ast.EqualError(err, "unable to merge: duplicated path /foo cannot be resolved by rename-with-suffix")
+	ast.NotContains(spec1.Paths.Paths, "/bar", "dest must not be modified on errors")
+}
+
+func TestMergeSpecsDefaultConflictPolicy(t *testing.T) {
+	ast := assert.New(t)
+	spec1, spec2 := parseConflictingSpecs(t)
+	report, err := MergeSpecsWithReport(spec1, spec2, MergeOptions{RenameModelConflicts: true, IgnorePathConflicts: true, Logger: common.NoopLogger})
+	ast.NoError(err)
+	ast.Equal([]Conflict{
+		{Kind: ConflictKindPath, Name: "/foo", Resolution: PreferLocal},
+		{Kind: ConflictKindDefinition, Name: "Bar", Resolution: RenameWithSuffix, NewName: "Bar_v2"},
+		{Kind: ConflictKindDefinition, Name: "Foo", Resolution: RenameWithSuffix, NewName: "Foo_v2"},
+	}, report.Conflicts)
+
+	spec1, spec2 = parseConflictingSpecs(t)
+	report, err = MergeSpecsWithReport(spec1, spec2, MergeOptions{Logger: common.NoopLogger})
+	ast.EqualError(err, "unable to merge: duplicated path /foo")
+	ast.Nil(report)
+}
+
+func TestMergeSpecsV3ConflictPolicy(t *testing.T) {
+	ast := assert.New(t)
+	spec := `{
+		"openapi": "3.0.0",
+		"paths": {
+			"/foo": {"get": {"responses": {"200": {"description": "%s"}}}}
+		},
+		"webhooks": {"fooCreated": {"post": {}}},
+		"components": {
+			"schemas": {"Foo": {"type": "string"}},
+			"parameters": {"name": {"name": "name", "in": "query"}}
+		}
+	}`
+	dest, source := parseV3(t, spec), parseV3(t, spec)
+	source.Paths.Paths["/foo"].Get.Responses.StatusCodeResponses[200].Description = "newest"
+	source.Components.Schemas["Foo"].Type = []string{"integer"}
+	source.Components.Parameters["name"].In = "path"
+
+	report, err := MergeSpecsV3WithReport(dest, source, MergeOptions{
+		ConflictPolicy: byNamePolicy{"/foo": PreferNewest, "fooCreated": PreferLocal, "Foo": PreferNewest, "name": PreferLocal},
+		Logger:         common.NoopLogger,
+	})
+	ast.NoError(err)
+	ast.Equal("newest", dest.Paths.Paths["/foo"].Get.Responses.StatusCodeResponses[200].Description)
+	ast.Equal([]string{"integer"}, []string(dest.Components.Schemas["Foo"].Type))
+	ast.Equal("query", dest.Components.Parameters["name"].In)
+	ast.Equal([]Conflict{
+		{Kind: ConflictKindPath, Name: "/foo", Resolution: PreferNewest},
+		{Kind: ConflictKindWebhook, Name: "fooCreated", Resolution: PreferLocal},
+		{Kind: "schemas", Name: "Foo", Resolution: PreferNewest},
+		{Kind: "parameters", Name: "name", Resolution: PreferLocal},
+	}, report.Conflicts)
+
+	_, err = MergeSpecsV3WithReport(dest, parseV3(t, spec), MergeOptions{
+		ConflictPolicy: byNamePolicy{"/foo": PreferLocal},
+		Logger:         common.NoopLogger,
+	})
+	ast.EqualError(err, "unable to merge: duplicated webhook fooCreated")
+}
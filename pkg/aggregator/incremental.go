@@ -0,0 +1,442 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// IncrementalAggregator merges the specs of sources, e.g. the delegates of
+// the kube-aggregator, into a local spec. It caches the contribution of each
+// source by ETag and, as long as the merged spec has no conflicts, only walks
+// the sources which changed instead of merging all of them again.
+//
+// The merged spec is the one of merging the sources into the local spec with
+// MergeSpecsWithOptions in the order of their names. Its serializations are
+// cached too, only marshaling the paths and definitions which changed.
+type IncrementalAggregator struct {
+	opts   MergeOptions
+	logger common.Logger
+
+	lock  sync.Mutex
+	local *spec.Swagger
+	// sources are the cached contributions of the sources by name
+	sources map[string]*cachedSource
+	// merged is the merged spec, modified in place when sources change
+	merged *spec.Swagger
+	// conflictFree is true if no conflict was resolved merging the sources,
+	// i.e. the merged spec is the union of the local spec and the sources.
+	conflictFree bool
+	// contributors are the sorted names of the sources contributing each
+	// definition, if conflictFree.
+	contributors map[string][]string
+	// snapshot is the copy of merged returned by Spec, reset on changes
+	snapshot *spec.Swagger
+	// serialized caches the serializations of merged
+	serialized serializedSpec
+}
+
+type cachedSource struct {
+	etag         string
+	contribution *spec.Swagger
+}
+
+// NewIncrementalAggregator returns an IncrementalAggregator merging sources
+// into the local spec with opts. opts.Source is ignored, the sources are
+// named by UpdateSource. local is not mutated.
+func NewIncrementalAggregator(local *spec.Swagger, opts MergeOptions) *IncrementalAggregator {
+	a := &IncrementalAggregator{
+		opts:    opts,
+		logger:  common.LoggerOrDefault(opts.Logger),
+		local:   local,
+		sources: map[string]*cachedSource{},
+	}
+	// merging no source cannot fail
+	a.remerge(a.sources)
+	return a
+}
+
+// UpdateSource sets the spec of the source name, with the given ETag. If
+// etag is not empty and the one of the cached source, sp is ignored.
+// On errors, e.g. conflicts failing the merge, the source is not changed.
+// sp must not be modified afterwards.
+func (a *IncrementalAggregator) UpdateSource(name, etag string, sp *spec.Swagger) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if cached, found := a.sources[name]; found && etag != "" && cached.etag == etag {
+		return nil
+	}
+	start := time.Now()
+	contribution, err := a.contribution(name, sp)
+	if err == nil {
+		err = a.update(name, contribution, start)
+	}
+	if err != nil {
+		a.logger.Error(err, "Failed to merge OpenAPI spec", "source", name)
+		return err
+	}
+	a.sources[name].etag = etag
+	return nil
+}
+
+// RemoveSource removes the source name, if any.
+func (a *IncrementalAggregator) RemoveSource(name string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, found := a.sources[name]; !found {
+		return nil
+	}
+	if err := a.update(name, nil, time.Now()); err != nil {
+		a.logger.Error(err, "Failed to remove OpenAPI spec", "source", name)
+		return err
+	}
+	return nil
+}
+
+// Spec returns the merged spec. It must not be modified, it is shared by
+// the callers until the next change of the sources.
+func (a *IncrementalAggregator) Spec() *spec.Swagger {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.snapshot == nil {
+		a.snapshot = copyPathsAndDefinitions(a.merged)
+	}
+	return a.snapshot
+}
+
+// JSON returns the merged spec serialized to JSON, the same as
+// json.Marshal(a.Spec()), and its ETag. Only the paths and definitions which
+// changed since the last call are marshaled, unless a source changed with
+// conflicts. The bytes must not be modified.
+func (a *IncrementalAggregator) JSON() ([]byte, string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.serialized.JSON(a.merged)
+}
+
+// Protobuf returns the merged spec serialized to the gnostic protobuf
+// format, and its ETag. It is only generated again if the JSON serialization
+// changed. The bytes must not be modified.
+func (a *IncrementalAggregator) Protobuf() ([]byte, string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.serialized.Protobuf(a.merged)
+}
+
+// contribution returns the normalized contribution of the spec of the
+// source name, i.e. what is merged into the local spec.
+func (a *IncrementalAggregator) contribution(name string, sp *spec.Swagger) (*spec.Swagger, error) {
+	if a.opts.ExtensionLimits != nil {
+		stats := common.SwaggerExtensionStats(sp)
+		a.logger.Info("Computed OpenAPI spec vendor extensions size", "source", name, "size", stats.Total)
+		if err := a.opts.ExtensionLimits.Check(stats); err != nil {
+			return nil, fmt.Errorf("rejecting OpenAPI spec: %v", err)
+		}
+	}
+	ret := *sp
+	if sp.Paths == nil {
+		// like MergeSpecsWithOptions, a source without paths contributes nothing
+		ret.Definitions = nil
+		return &ret, nil
+	}
+	if a.opts.RecordSources {
+		// recorded once instead of on each merge
+		paths := *sp.Paths
+		paths.Paths = make(map[string]spec.PathItem, len(sp.Paths.Paths))
+		for k, v := range sp.Paths.Paths {
+			v.Extensions = withSource(v.Extensions, name)
+			paths.Paths[k] = v
+		}
+		ret.Paths = &paths
+		ret.Definitions = make(spec.Definitions, len(sp.Definitions))
+		for k, v := range sp.Definitions {
+			v.Extensions = withSource(v.Extensions, name)
+			ret.Definitions[k] = v
+		}
+	}
+	return &ret, nil
+}
+
+// update replaces the contribution of the source name, removing it if
+// contribution is nil. It walks only the changed source if the merged spec
+// stays conflict-free, and merges all the sources again otherwise.
+func (a *IncrementalAggregator) update(name string, contribution *spec.Swagger, start time.Time) error {
+	incremental := a.conflictFree && a.fits(name, contribution)
+	if incremental {
+		if err := a.replace(name, contribution); err != nil {
+			return err
+		}
+	} else {
+		sources := make(map[string]*cachedSource, len(a.sources)+1)
+		for k, v := range a.sources {
+			sources[k] = v
+		}
+		if contribution == nil {
+			delete(sources, name)
+		} else {
+			sources[name] = &cachedSource{contribution: contribution}
+		}
+		if err := a.remerge(sources); err != nil {
+			return err
+		}
+	}
+	if contribution == nil {
+		delete(a.sources, name)
+	} else {
+		a.sources[name] = &cachedSource{contribution: contribution}
+	}
+	a.snapshot = nil
+	a.logger.Info("Updated OpenAPI spec source", "source", name, "incremental", incremental, "sources", len(a.sources), "duration", time.Since(start))
+	return nil
+}
+
+// fits returns true if contribution can replace the cached contribution of
+// the source name without conflicts.
+func (a *IncrementalAggregator) fits(name string, contribution *spec.Swagger) bool {
+	if contribution == nil || contribution.Paths == nil {
+		return true
+	}
+	var old *spec.Swagger
+	if cached, found := a.sources[name]; found {
+		old = cached.contribution
+	}
+	for k := range contribution.Paths.Paths {
+		if _, found := a.merged.Paths.Paths[k]; found && !hasPath(old, k) {
+			return false
+		}
+	}
+	for k, v := range contribution.Definitions {
+		existing, found := a.merged.Definitions[k]
+		if !found {
+			continue
+		}
+		if _, local := a.local.Definitions[k]; !local {
+			if c := a.contributors[k]; len(c) == 1 && c[0] == name {
+				// replaced
+				continue
+			}
+		}
+		if !deepEqualDefinitionsModuloGVKs(&existing, &v) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasPath(sp *spec.Swagger, path string) bool {
+	if sp == nil || sp.Paths == nil {
+		return false
+	}
+	_, found := sp.Paths.Paths[path]
+	return found
+}
+
+// replace replaces the contribution of the source name in the conflict-free
+// merged spec, recomputing the definitions it shares with other sources.
+func (a *IncrementalAggregator) replace(name string, contribution *spec.Swagger) error {
+	var old *spec.Swagger
+	if cached, found := a.sources[name]; found {
+		old = cached.contribution
+	}
+	contributionOf := func(source string) *spec.Swagger {
+		if source == name {
+			return contribution
+		}
+		return a.sources[source].contribution
+	}
+
+	// compute the changed definitions before modifying the merged spec
+	contributors := map[string][]string{}
+	if old != nil {
+		for k := range old.Definitions {
+			contributors[k] = without(a.contributors[k], name)
+		}
+	}
+	if contribution != nil {
+		for k := range contribution.Definitions {
+			if _, found := contributors[k]; !found {
+				contributors[k] = a.contributors[k]
+			}
+			contributors[k] = with(contributors[k], name)
+		}
+	}
+	definitions := make(spec.Definitions, len(contributors))
+	for k, c := range contributors {
+		def, found, err := a.mergedDefinition(k, c, contributionOf)
+		if err != nil {
+			return err
+		}
+		if found {
+			definitions[k] = def
+		}
+	}
+
+	var changedPaths, changedDefinitions []string
+	if old != nil && old.Paths != nil {
+		for k := range old.Paths.Paths {
+			delete(a.merged.Paths.Paths, k)
+			changedPaths = append(changedPaths, k)
+		}
+	}
+	if contribution != nil && contribution.Paths != nil {
+		if a.merged.Paths.Paths == nil {
+			a.merged.Paths.Paths = map[string]spec.PathItem{}
+		}
+		for k, v := range contribution.Paths.Paths {
+			a.merged.Paths.Paths[k] = v
+			changedPaths = append(changedPaths, k)
+		}
+	}
+	for k, c := range contributors {
+		changedDefinitions = append(changedDefinitions, k)
+		if len(c) == 0 {
+			delete(a.contributors, k)
+		} else {
+			a.contributors[k] = c
+		}
+		if def, found := definitions[k]; found {
+			if a.merged.Definitions == nil {
+				a.merged.Definitions = spec.Definitions{}
+			}
+			a.merged.Definitions[k] = def
+		} else {
+			delete(a.merged.Definitions, k)
+		}
+	}
+	a.serialized.invalidate(changedPaths, changedDefinitions)
+	return nil
+}
+
+// mergedDefinition returns the definition name merged from the local spec
+// and the sources contributors, like merging them in order.
+func (a *IncrementalAggregator) mergedDefinition(name string, contributors []string, contributionOf func(string) *spec.Swagger) (spec.Schema, bool, error) {
+	def, found := a.local.Definitions[name]
+	var others []*spec.Schema
+	var sources []string
+	for _, source := range contributors {
+		v := contributionOf(source).Definitions[name]
+		if !found {
+			def, found = v, true
+			continue
+		}
+		others = append(others, &v)
+		sources = append(sources, source)
+	}
+	if len(others) == 0 {
+		return def, found, nil
+	}
+	// merged at once instead of one by one, which is quadratic for
+	// definitions shared by all sources
+	if gvks, changed, err := mergedGVKs(&def, others...); err != nil {
+		return spec.Schema{}, false, err
+	} else if changed {
+		def.Extensions = withGVKs(def.Extensions, gvks)
+	}
+	if a.opts.RecordSources {
+		def.Extensions = withSources(def.Extensions, sources)
+	}
+	return def, found, nil
+}
+
+// remerge merges all the sources into the local spec, replacing the merged
+// spec on success.
+func (a *IncrementalAggregator) remerge(sources map[string]*cachedSource) error {
+	names := make([]string, 0, len(sources))
+	for k := range sources {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	merged := copyPathsAndDefinitions(a.local)
+	if merged.Paths == nil {
+		merged.Paths = &spec.Paths{}
+	}
+	report := &MergeReport{}
+	for _, name := range names {
+		opts := a.opts
+		opts.Source = name
+		if err := mergeSpecs(merged, sources[name].contribution, opts, a.logger, report); err != nil {
+			return err
+		}
+	}
+
+	a.merged = merged
+	a.serialized.reset()
+	a.conflictFree = len(report.Conflicts) == 0
+	a.contributors = map[string][]string{}
+	if a.conflictFree {
+		for _, name := range names {
+			for k := range sources[name].contribution.Definitions {
+				a.contributors[k] = append(a.contributors[k], name)
+			}
+		}
+	}
+	return nil
+}
+
+// copyPathsAndDefinitions returns a copy of sp with its own maps of paths
+// and definitions, sharing their entries.
+func copyPathsAndDefinitions(sp *spec.Swagger) *spec.Swagger {
+	ret := *sp
+	if sp.Paths != nil {
+		paths := *sp.Paths
+		paths.Paths = make(map[string]spec.PathItem, len(sp.Paths.Paths))
+		for k, v := range sp.Paths.Paths {
+			paths.Paths[k] = v
+		}
+		ret.Paths = &paths
+	}
+	if sp.Definitions != nil {
+		ret.Definitions = make(spec.Definitions, len(sp.Definitions))
+		for k, v := range sp.Definitions {
+			ret.Definitions[k] = v
+		}
+	}
+	return &ret
+}
+
+// with returns the sorted names with name added.
+func with(names []string, name string) []string {
+	i := sort.SearchStrings(names, name)
+	if i < len(names) && names[i] == name {
+		return names
+	}
+	ret := make([]string, 0, len(names)+1)
+	ret = append(ret, names[:i]...)
+	ret = append(ret, name)
+	return append(ret, names[i:]...)
+}
+
+// without returns the sorted names without name.
+func without(names []string, name string) []string {
+	i := sort.SearchStrings(names, name)
+	if i == len(names) || names[i] != name {
+		return names
+	}
+	ret := make([]string, 0, len(names)-1)
+	ret = append(ret, names[:i]...)
+	return append(ret, names[i+1:]...)
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// mergeAll merges the sources into a copy of local with MergeSpecsWithOptions.
+func mergeAll(t *testing.T, local *spec.Swagger, sources map[string]*spec.Swagger, opts MergeOptions) *spec.Swagger {
+	merged, err := cloneSpec(local)
+	require.NoError(t, err)
+	names := make([]string, 0, len(sources))
+	for k := range sources {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		opts.Source = name
+		require.NoError(t, MergeSpecsWithOptions(merged, sources[name], opts))
+	}
+	return merged
+}
+
+func parseSwagger(t *testing.T, s string) *spec.Swagger {
+	var sp *spec.Swagger
+	require.NoError(t, yaml.Unmarshal([]byte(s), &sp))
+	return sp
+}
+
+func groupSpec(t *testing.T, group, fooType string) *spec.Swagger {
+	return parseSwagger(t, fmt.Sprintf(`
+swagger: "2.0"
+info:
+  title: %[1]s
+  version: v1
+paths:
+  /apis/%[1]s:
+    get:
+      responses:
+        200:
+          description: OK
+          schema:
+            $ref: "#/definitions/Foo"
+        default:
+          description: Error
+          schema:
+            $ref: "#/definitions/Status"
+definitions:
+  Foo:
+    type: %[2]s
+  Status:
+    type: object
+    x-kubernetes-group-version-kind:
+    - group: %[1]s
+      version: v1
+      kind: Status
+`, group, fooType))
+}
+
+func TestIncrementalAggregator(t *testing.T) {
+	local := parseSwagger(t, `
+swagger: "2.0"
+paths:
+  /api:
+    get:
+      responses:
+        default:
+          schema:
+            $ref: "#/definitions/Status"
+definitions:
+  Status:
+    type: object
+    x-kubernetes-group-version-kind:
+    - group: ""
+      version: v1
+      kind: Status
+`)
+	localJSON, err := json.Marshal(local)
+	require.NoError(t, err)
+	opts := MergeOptions{RenameModelConflicts: true, RecordSources: true}
+	logger := &recordingLogger{}
+	opts.Logger = logger
+	a := NewIncrementalAggregator(local, opts)
+	opts.Logger = common.NoopLogger
+
+	sources := map[string]*spec.Swagger{}
+	step := func(msg string, incremental bool) {
+		expected, err := json.Marshal(mergeAll(t, local, sources, opts))
+		require.NoError(t, err)
+		actual, err := json.Marshal(a.Spec())
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expected), string(actual), msg)
+		serialized, _, err := a.JSON()
+		require.NoError(t, err)
+		assert.Equal(t, string(actual), string(serialized), msg)
+		require.NotEmpty(t, logger.infos, msg)
+		last := logger.infos[len(logger.infos)-1]
+		assert.Contains(t, last, fmt.Sprintf("incremental%v", incremental), msg)
+		logger.infos = nil
+	}
+
+	for _, group := range []string{"b", "a", "c"} {
+		sources[group] = groupSpec(t, group, "string")
+		require.NoError(t, a.UpdateSource(group, "1", sources[group]))
+		step("adding "+group, true)
+	}
+
+	require.NoError(t, a.UpdateSource("b", "1", groupSpec(t, "b", "integer")))
+	assert.Empty(t, logger.infos, "sources with an unchanged ETag are ignored")
+
+	sources["b"] = groupSpec(t, "b", "integer")
+	require.NoError(t, a.UpdateSource("b", "2", sources["b"]))
+	step("conflicting update", false)
+	assert.Contains(t, a.Spec().Definitions, "Foo_v2")
+
+	sources["b"] = groupSpec(t, "b", "string")
+	require.NoError(t, a.UpdateSource("b", "3", sources["b"]))
+	step("resolving the conflict", false)
+
+	delete(sources, "a")
+	require.NoError(t, a.RemoveSource("a"))
+	step("removing a", true)
+	assert.Equal(t, []string{"b", "c"}, DefinitionSources(a.Spec(), "Foo"))
+
+	require.NoError(t, a.RemoveSource("missing"))
+	assert.Empty(t, logger.infos)
+
+	b, err := json.Marshal(local)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(localJSON), string(b), "local must not be mutated")
+}
+
+func TestIncrementalAggregatorErrors(t *testing.T) {
+	ast := assert.New(t)
+	logger := &recordingLogger{}
+	a := NewIncrementalAggregator(&spec.Swagger{}, MergeOptions{Logger: logger})
+	ast.NoError(a.UpdateSource("a", "1", groupSpec(t, "a", "string")))
+	merged := a.Spec()
+
+	err := a.UpdateSource("b", "1", groupSpec(t, "b", "integer"))
+	ast.EqualError(err, "model name conflict in merging OpenAPI spec: Foo")
+	ast.Len(logger.errors, 1)
+	ast.True(strings.HasPrefix(logger.errors[0], "Failed to merge OpenAPI spec"))
+	ast.Same(merged, a.Spec(), "the merged spec must not change on errors")
+
+	// the rejected source is not cached
+	ast.Error(a.UpdateSource("b", "1", groupSpec(t, "b", "integer")))
+	ast.NoError(a.UpdateSource("b", "1", groupSpec(t, "b", "string")))
+	ast.Contains(a.Spec().Paths.Paths, "/apis/b")
+	ast.NotContains(merged.Paths.Paths, "/apis/b", "returned specs must not change")
+}
+
+func TestIncrementalAggregatorSerialization(t *testing.T) {
+	ast := assert.New(t)
+	local := groupSpec(t, "local", "string")
+	// named like the placeholders of the serialization
+	local.Definitions["\x00"] = spec.Schema{}
+	local.Paths.Paths["/\x00"] = spec.PathItem{}
+	a := NewIncrementalAggregator(local, MergeOptions{Logger: common.NoopLogger})
+	ast.NoError(a.UpdateSource("a", "1", groupSpec(t, "a", "string")))
+
+	expected, err := json.Marshal(a.Spec())
+	ast.NoError(err)
+	b, etag, err := a.JSON()
+	ast.NoError(err)
+	ast.Equal(string(expected), string(b))
+	ast.NotEmpty(etag)
+
+	pb, pbETag, err := a.Protobuf()
+	require.NoError(t, err)
+	require.NotEmpty(t, pb)
+	ast.NotEqual(etag, pbETag)
+
+	// the same content with a new ETag does not change the serializations
+	ast.NoError(a.UpdateSource("a", "2", groupSpec(t, "a", "string")))
+	_, etag2, err := a.JSON()
+	ast.NoError(err)
+	ast.Equal(etag, etag2)
+	pb2, pbETag2, err := a.Protobuf()
+	ast.NoError(err)
+	ast.Equal(pbETag, pbETag2)
+	ast.True(&pb[0] == &pb2[0], "the protobuf serialization must not be generated again")
+
+	ast.NoError(a.RemoveSource("a"))
+	_, etag3, err := a.JSON()
+	ast.NoError(err)
+	ast.NotEqual(etag, etag3)
+	_, pbETag3, err := a.Protobuf()
+	ast.NoError(err)
+	ast.NotEqual(pbETag, pbETag3)
+}
+
+// syntheticCluster returns the spec of a local server and of sources
+// serving 10 resources each, sharing some definitions.
+func syntheticCluster(sources int) (*spec.Swagger, map[string]*spec.Swagger) {
+	shared := func(group string) spec.Definitions {
+		defs := spec.Definitions{}
+		for _, name := range []string{"ObjectMeta", "ListMeta", "Status", "DeleteOptions"} {
+			s := *spec.StringProperty()
+			s.Type = spec.StringOrArray{"object"}
+			s.Properties = map[string]spec.Schema{"name": *spec.StringProperty()}
+			s.Extensions = spec.Extensions{gvkKey: []interface{}{
+				map[string]interface{}{"group": group, "version": "v1", "kind": name},
+			}}
+			defs["io.k8s.meta.v1."+name] = s
+		}
+		return defs
+	}
+	local := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Paths:       &spec.Paths{Paths: map[string]spec.PathItem{}},
+		Definitions: shared(""),
+	}}
+	specs := map[string]*spec.Swagger{}
+	for i := 0; i < sources; i++ {
+		group := fmt.Sprintf("group%d.example.com", i)
+		sp := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+			Paths:       &spec.Paths{Paths: map[string]spec.PathItem{}},
+			Definitions: shared(group),
+		}}
+		for j := 0; j < 10; j++ {
+			name := fmt.Sprintf("com.example.%s.v1.Resource%d", group, j)
+			sp.Definitions[name] = *spec.RefProperty("#/definitions/io.k8s.meta.v1.ObjectMeta")
+			op := &spec.Operation{OperationProps: spec.OperationProps{ID: name, Responses: &spec.Responses{ResponsesProps: spec.ResponsesProps{
+				StatusCodeResponses: map[int]spec.Response{200: {ResponseProps: spec.ResponseProps{Schema: spec.RefProperty("#/definitions/" + name)}}},
+			}}}}
+			sp.Paths.Paths[fmt.Sprintf("/apis/%s/v1/resource%d", group, j)] = spec.PathItem{PathItemProps: spec.PathItemProps{Get: op}}
+		}
+		specs[group] = sp
+	}
+	return local, specs
+}
+
+func BenchmarkMergeAllSources(b *testing.B) {
+	local, sources := syntheticCluster(100)
+	names := make([]string, 0, len(sources))
+	for k := range sources {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		merged := copyPathsAndDefinitions(local)
+		for _, name := range names {
+			if err := MergeSpecsWithOptions(merged, sources[name], MergeOptions{Logger: common.NoopLogger}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkIncrementalAggregatorUpdateSource(b *testing.B) {
+	local, sources := syntheticCluster(100)
+	a := NewIncrementalAggregator(local, MergeOptions{Logger: common.NoopLogger})
+	for name, sp := range sources {
+		if err := a.UpdateSource(name, "0", sp); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := a.UpdateSource("group50.example.com", fmt.Sprint(n+1), sources["group50.example.com"]); err != nil {
+			b.Fatal(err)
+		}
+		a.Spec()
+	}
+}
+
+func BenchmarkMarshalMergedSpec(b *testing.B) {
+	local, sources := syntheticCluster(100)
+	a := NewIncrementalAggregator(local, MergeOptions{Logger: common.NoopLogger})
+	for name, sp := range sources {
+		if err := a.UpdateSource(name, "0", sp); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := a.UpdateSource("group50.example.com", fmt.Sprint(n+1), sources["group50.example.com"]); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(a.Spec()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIncrementalAggregatorJSON(b *testing.B) {
+	local, sources := syntheticCluster(100)
+	a := NewIncrementalAggregator(local, MergeOptions{Logger: common.NoopLogger})
+	for name, sp := range sources {
+		if err := a.UpdateSource(name, "0", sp); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if _, _, err := a.JSON(); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := a.UpdateSource("group50.example.com", fmt.Sprint(n+1), sources["group50.example.com"]); err != nil {
+			b.Fatal(err)
+		}
+		if _, _, err := a.JSON(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
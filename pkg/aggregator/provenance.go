@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"sort"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// SourcesExtension is the vendor extension listing the sources that contributed
+// a path or a definition to a merged spec, when provenance is recorded.
+const SourcesExtension = "x-kubernetes-openapi-sources"
+
+// AnnotateSources records source as the provenance of all the paths and
+// definitions of sp, typically the first spec that others are merged into.
+func AnnotateSources(sp *spec.Swagger, source string) {
+	if sp.Paths != nil {
+		for k, v := range sp.Paths.Paths {
+			v.Extensions = withSource(v.Extensions, source)
+			sp.Paths.Paths[k] = v
+		}
+	}
+	for k, v := range sp.Definitions {
+		v.Extensions = withSource(v.Extensions, source)
+		sp.Definitions[k] = v
+	}
+}
+
+// PathSources returns the sources that contributed the path to sp, as recorded
+// by AnnotateSources or MergeOptions.RecordSources.
+func PathSources(sp *spec.Swagger, path string) []string {
+	if sp.Paths == nil {
+		return nil
+	}
+	sources, _ := sp.Paths.Paths[path].Extensions.GetStringSlice(SourcesExtension)
+	return sources
+}
+
+// DefinitionSources returns the sources that contributed the definition to sp,
+// as recorded by AnnotateSources or MergeOptions.RecordSources. Definitions
+// shared by several sources list all of them.
+func DefinitionSources(sp *spec.Swagger, name string) []string {
+	sources, _ := sp.Definitions[name].Extensions.GetStringSlice(SourcesExtension)
+	return sources
+}
+
+// withSource returns a copy of ext with source added to the sources extension.
+// ext is not mutated, as it might be shared with the spec it was merged from.
+func withSource(ext spec.Extensions, source string) spec.Extensions {
+	return withSources(ext, []string{source})
+}
+
+// withSources returns a copy of ext with sources added to the sources
+// extension, or ext if it lists all of them already. ext is not mutated.
+func withSources(ext spec.Extensions, sources []string) spec.Extensions {
+	existing, _ := ext.GetStringSlice(SourcesExtension)
+	found := make(map[string]bool, len(existing)+len(sources))
+	for _, s := range existing {
+		found[s] = true
+	}
+	merged := existing
+	for _, s := range sources {
+		if !found[s] {
+			merged = append(merged, s)
+			found[s] = true
+		}
+	}
+	if len(merged) == len(existing) {
+		return ext
+	}
+	sort.Strings(merged)
+	values := make([]interface{}, len(merged))
+	for i := range merged {
+		values[i] = merged[i]
+	}
+
+	ret := make(spec.Extensions, len(ext)+1)
+	for k, v := range ext {
+		ret[k] = v
+	}
+	ret[SourcesExtension] = values
+	return ret
+}
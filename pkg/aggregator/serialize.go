@@ -0,0 +1,220 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	openapi_v2 "github.com/googleapis/gnostic/openapiv2"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// placeholder is the name of the path and definition standing for the
+// serialized ones when serializing the rest of a spec.
+const placeholder = "\x00"
+
+// serializedSpec caches the serializations of a spec, and the JSON
+// serialization of its paths and definitions to only marshal those which
+// changed.
+type serializedSpec struct {
+	// paths and definitions are the serialized entries, e.g. `"/foo":{...}`,
+	// removed when the entries change.
+	paths, definitions map[string][]byte
+
+	// json is the JSON serialization, nil if the spec changed
+	json     []byte
+	jsonETag string
+	// proto is the protobuf serialization of the JSON with ETag protoSource
+	proto       []byte
+	protoETag   string
+	protoSource string
+}
+
+// reset drops all the cached serializations of the entries, e.g. when all of
+// them changed.
+func (s *serializedSpec) reset() {
+	s.paths = map[string][]byte{}
+	s.definitions = map[string][]byte{}
+	s.json = nil
+}
+
+// invalidate drops the cached serializations of the given entries.
+func (s *serializedSpec) invalidate(paths, definitions []string) {
+	for _, k := range paths {
+		delete(s.paths, k)
+	}
+	for _, k := range definitions {
+		delete(s.definitions, k)
+	}
+	s.json = nil
+}
+
+// JSON returns the JSON serialization of sp, the same as json.Marshal(sp),
+// and its ETag.
+func (s *serializedSpec) JSON(sp *spec.Swagger) ([]byte, string, error) {
+	if s.json != nil {
+		return s.json, s.jsonETag, nil
+	}
+
+	var paths map[string]spec.PathItem
+	if sp.Paths != nil {
+		paths = sp.Paths.Paths
+	}
+	keys := make([]string, 0, len(paths))
+	for k := range paths {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pathFragments := make([][]byte, 0, len(paths))
+	for _, k := range keys {
+		if !strings.HasPrefix(k, "/") {
+			// dropped by Paths.MarshalJSON
+			continue
+		}
+		b, err := cachedFragment(s.paths, k, paths[k])
+		if err != nil {
+			return nil, "", err
+		}
+		pathFragments = append(pathFragments, b)
+	}
+	keys = make([]string, 0, len(sp.Definitions))
+	for k := range sp.Definitions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	definitionFragments := make([][]byte, 0, len(sp.Definitions))
+	for _, k := range keys {
+		b, err := cachedFragment(s.definitions, k, sp.Definitions[k])
+		if err != nil {
+			return nil, "", err
+		}
+		definitionFragments = append(definitionFragments, b)
+	}
+
+	// serialize the rest of the spec with placeholders for the entries
+	skeleton := *sp
+	if len(pathFragments) > 0 {
+		p := *sp.Paths
+		p.Paths = map[string]spec.PathItem{"/" + placeholder: {}}
+		skeleton.Paths = &p
+	}
+	if len(definitionFragments) > 0 {
+		skeleton.Definitions = spec.Definitions{placeholder: {}}
+	}
+	b, err := json.Marshal(&skeleton)
+	if err != nil {
+		return nil, "", err
+	}
+	if b, err = replacePlaceholder(b, "/"+placeholder, pathFragments); err == nil {
+		b, err = replacePlaceholder(b, placeholder, definitionFragments)
+	}
+	if err != nil {
+		// an entry named like a placeholder
+		if b, err = json.Marshal(sp); err != nil {
+			return nil, "", err
+		}
+	}
+
+	s.json, s.jsonETag = b, computeETag(b)
+	return s.json, s.jsonETag, nil
+}
+
+// Protobuf returns the protobuf serialization of sp and its ETag. It is only
+// generated again if the JSON serialization changed.
+func (s *serializedSpec) Protobuf(sp *spec.Swagger) ([]byte, string, error) {
+	b, etag, err := s.JSON(sp)
+	if err != nil {
+		return nil, "", err
+	}
+	if s.proto != nil && s.protoSource == etag {
+		return s.proto, s.protoETag, nil
+	}
+	document, err := openapi_v2.ParseDocument(b)
+	if err != nil {
+		return nil, "", err
+	}
+	pb, err := proto.Marshal(document)
+	if err != nil {
+		return nil, "", err
+	}
+	s.proto, s.protoETag, s.protoSource = pb, computeETag(pb), etag
+	return s.proto, s.protoETag, nil
+}
+
+// cachedFragment returns the serialization of the map entry k: v, from
+// cache if there.
+func cachedFragment(cache map[string][]byte, k string, v interface{}) ([]byte, error) {
+	if b, found := cache[k]; found {
+		return b, nil
+	}
+	key, err := json.Marshal(k)
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, len(key)+1+len(value))
+	b = append(append(append(b, key...), ':'), value...)
+	cache[k] = b
+	return b, nil
+}
+
+// replacePlaceholder replaces the empty entry named name in b by fragments,
+// if there are any.
+func replacePlaceholder(b []byte, name string, fragments [][]byte) ([]byte, error) {
+	if len(fragments) == 0 {
+		return b, nil
+	}
+	key, err := json.Marshal(name)
+	if err != nil {
+		return nil, err
+	}
+	entry := append(key, ":{}"...)
+	i := bytes.Index(b, entry)
+	if i < 0 || bytes.LastIndex(b, entry) != i {
+		return nil, fmt.Errorf("expected a single placeholder %s", entry)
+	}
+	size := len(b) - len(entry) + len(fragments) - 1
+	for _, f := range fragments {
+		size += len(f)
+	}
+	ret := make([]byte, 0, size)
+	ret = append(ret, b[:i]...)
+	for j, f := range fragments {
+		if j > 0 {
+			ret = append(ret, ',')
+		}
+		ret = append(ret, f...)
+	}
+	return append(ret, b[i+len(entry):]...), nil
+}
+
+func computeETag(data []byte) string {
+	if data == nil {
+		return ""
+	}
+	return fmt.Sprintf("\"%X\"", sha512.Sum512(data))
+}
@@ -0,0 +1,422 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const componentsPrefix = "#/components/"
+
+// MergeSpecsV3 copies the paths, webhooks and components of the OpenAPI v3
+// source to dest, resolving conflicts as specified by opts like
+// MergeSpecsWithOptions. Components of any kind, e.g. schemas or responses,
+// are resolved like definitions, and webhooks like paths.
+//
+// dest keeps its info, servers and security, taking the info of source if
+// it has none, and the newest OpenAPI version of both. The tags of both are
+// kept.
+// The source is not mutated.
+func MergeSpecsV3(dest, source *spec3.OpenAPI, opts MergeOptions) error {
+	_, err := MergeSpecsV3WithReport(dest, source, opts)
+	return err
+}
+
+// MergeSpecsV3WithReport is like MergeSpecsV3, returning the conflicts
+// resolved by the merge.
+// The source is not mutated.
+func MergeSpecsV3WithReport(dest, source *spec3.OpenAPI, opts MergeOptions) (*MergeReport, error) {
+	logger := common.LoggerOrDefault(opts.Logger)
+	start := time.Now()
+	if opts.ExtensionLimits != nil {
+		stats := common.OpenAPIV3ExtensionStats(source)
+		logger.Info("Computed OpenAPI v3 spec vendor extensions size", "source", opts.Source, "size", stats.Total)
+		if err := opts.ExtensionLimits.Check(stats); err != nil {
+			err = fmt.Errorf("rejecting OpenAPI v3 spec: %v", err)
+			logger.Error(err, "Failed to merge OpenAPI v3 spec", "source", opts.Source)
+			return nil, err
+		}
+	}
+	report := &MergeReport{}
+	if err := mergeSpecsV3(dest, source, opts, logger, report); err != nil {
+		logger.Error(err, "Failed to merge OpenAPI v3 spec", "source", opts.Source)
+		return nil, err
+	}
+	paths := 0
+	if source.Paths != nil {
+		paths = len(source.Paths.Paths)
+	}
+	logger.Info("Merged OpenAPI v3 spec", "source", opts.Source, "paths", paths, "conflicts", len(report.Conflicts), "duration", time.Since(start))
+	return report, nil
+}
+
+// mergeSpecsV3 merges source into dest while resolving conflicts, adding them
+// to report.
+// The source is not mutated.
+func mergeSpecsV3(dest, source *spec3.OpenAPI, opts MergeOptions, logger common.Logger, report *MergeReport) (err error) {
+	policy := opts.conflictPolicy()
+
+	// Check for path and webhook conflicts before modifying dest
+	var destPaths, sourcePaths map[string]*spec3.Path
+	if dest.Paths != nil {
+		destPaths = dest.Paths.Paths
+	}
+	if source.Paths != nil {
+		sourcePaths = source.Paths.Paths
+	}
+	replacedPaths, pathConflicts, err := pathConflictsV3(ConflictKindPath, destPaths, sourcePaths, policy)
+	if err != nil {
+		return err
+	}
+	replacedWebhooks, webhookConflicts, err := pathConflictsV3(ConflictKindWebhook, dest.Webhooks, source.Webhooks, policy)
+	if err != nil {
+		return err
+	}
+	var conflictingPaths []string
+	for _, c := range append(pathConflicts, webhookConflicts...) {
+		if c.Resolution == PreferLocal {
+			conflictingPaths = append(conflictingPaths, c.Name)
+		}
+	}
+	if len(conflictingPaths) > 0 {
+		sort.Strings(conflictingPaths)
+		logger.Info("Ignoring conflicting paths", "source", opts.Source, "paths", conflictingPaths)
+	}
+	report.Conflicts = append(report.Conflicts, pathConflicts...)
+	report.Conflicts = append(report.Conflicts, webhookConflicts...)
+
+	// Check for component conflicts and rename to make components conflict-free (modulo different GVKs)
+	if source.Components != nil {
+		if dest.Components == nil {
+			dest.Components = &spec3.Components{}
+		}
+		// Renaming a component rewrites the references to it, possibly making
+		// other components conflict. Rename until no conflict remains.
+		renamed := map[string]string{}
+		resolved := map[string]ConflictResolution{}
+		for {
+			renames := map[string]string{}
+			destComponents, sourceComponents := componentMaps(dest.Components), componentMaps(source.Components)
+			for i, sourceMap := range sourceComponents {
+				kind, destMap := sourceMap.kind, destComponents[i].components
+				for _, k := range sortedMapKeys(sourceMap.components) {
+					v := sourceMap.components.MapIndex(reflect.ValueOf(k))
+					existing := destMap.MapIndex(reflect.ValueOf(k))
+					from := componentsPrefix + kind + "/" + common.EscapeJsonPointer(k)
+					if _, found := resolved[from]; found || !existing.IsValid() || equalComponents(existing, v) {
+						continue
+					}
+					switch resolution := policy.ResolveDefinition(kind, k); resolution {
+					case RenameWithSuffix:
+					case PreferLocal, PreferNewest:
+						resolved[from] = resolution
+						report.Conflicts = append(report.Conflicts, Conflict{Kind: kind, Name: k, Resolution: resolution})
+						logger.Info("Resolved conflicting component", "source", opts.Source, "kind", kind, "component", k, "resolution", resolution)
+						continue
+					case FailOnConflict:
+						return fmt.Errorf("%s name conflict in merging OpenAPI v3 spec: %s", kind, k)
+					default:
+						return fmt.Errorf("%s name conflict in merging OpenAPI v3 spec: %s cannot be resolved by %s", kind, k, resolution)
+					}
+					newName := renamedComponent(k, v, destMap, sourceMap.components, renamed, kind)
+					report.Conflicts = append(report.Conflicts, Conflict{Kind: kind, Name: k, Resolution: RenameWithSuffix, NewName: newName})
+					logger.Info("Renamed conflicting component", "source", opts.Source, "kind", kind, "component", k, "newName", newName)
+					to := componentsPrefix + kind + "/" + common.EscapeJsonPointer(newName)
+					renames[from], renamed[from] = to, to
+				}
+			}
+			if len(renames) == 0 {
+				break
+			}
+			if source, err = renameComponents(source, renames); err != nil {
+				return err
+			}
+		}
+
+		// now without conflict (modulo different GVKs), copy components to dest
+		destComponents, sourceComponents := componentMaps(dest.Components), componentMaps(source.Components)
+		for i, sourceMap := range sourceComponents {
+			destField := destComponents[i].components
+			for _, k := range sortedMapKeys(sourceMap.components) {
+				key := reflect.ValueOf(k)
+				v := sourceMap.components.MapIndex(key)
+				if destField.IsNil() {
+					destField.Set(reflect.MakeMap(destField.Type()))
+				}
+				existing := destField.MapIndex(key)
+				switch resolved[componentsPrefix+sourceMap.kind+"/"+common.EscapeJsonPointer(k)] {
+				case PreferLocal:
+					continue
+				case PreferNewest:
+					existing = reflect.Value{}
+				}
+				if s, ok := v.Interface().(*spec.Schema); ok && s != nil {
+					merged, err := mergeSchemaV3(existing, s, opts)
+					if err != nil {
+						return err
+					}
+					destField.SetMapIndex(key, reflect.ValueOf(merged))
+				} else if !existing.IsValid() {
+					destField.SetMapIndex(key, v)
+				}
+			}
+		}
+	}
+
+	if source.Paths != nil {
+		if dest.Paths == nil {
+			dest.Paths = &spec3.Paths{}
+		}
+		for k, v := range source.Paths.Paths {
+			if _, found := dest.Paths.Paths[k]; found && !replacedPaths[k] {
+				continue
+			}
+			if dest.Paths.Paths == nil {
+				dest.Paths.Paths = map[string]*spec3.Path{}
+			}
+			if opts.RecordSources && v != nil {
+				recorded := *v
+				recorded.Extensions = withSource(v.Extensions, opts.Source)
+				v = &recorded
+			}
+			dest.Paths.Paths[k] = v
+		}
+	}
+	for k, v := range source.Webhooks {
+		if _, found := dest.Webhooks[k]; found && !replacedWebhooks[k] {
+			continue
+		}
+		if dest.Webhooks == nil {
+			dest.Webhooks = map[string]*spec3.Path{}
+		}
+		dest.Webhooks[k] = v
+	}
+
+	if dest.Info == nil {
+		dest.Info = source.Info
+	}
+	if source.Version > dest.Version {
+		dest.Version = source.Version
+	}
+	for _, tag := range source.Tags {
+		found := false
+		for _, t := range dest.Tags {
+			if t.Name == tag.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dest.Tags = append(dest.Tags, tag)
+		}
+	}
+	return nil
+}
+
+// pathConflictsV3 returns the paths or webhooks of source conflicting with
+// those of dest, resolved by policy, and those replaced by the ones of source.
+func pathConflictsV3(kind string, dest, source map[string]*spec3.Path, policy ConflictPolicy) (map[string]bool, []Conflict, error) {
+	keys := make([]string, 0, len(source))
+	for k := range source {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	replaced := map[string]bool{}
+	var conflicts []Conflict
+	for _, k := range keys {
+		if _, found := dest[k]; !found {
+			continue
+		}
+		resolution := policy.ResolvePath(kind, k)
+		switch resolution {
+		case PreferLocal:
+		case PreferNewest:
+			replaced[k] = true
+		case FailOnConflict:
+			return nil, nil, fmt.Errorf("unable to merge: duplicated %s %s", kind, k)
+		default:
+			return nil, nil, fmt.Errorf("unable to merge: duplicated %s %s cannot be resolved by %s", kind, k, resolution)
+		}
+		conflicts = append(conflicts, Conflict{Kind: kind, Name: k, Resolution: resolution})
+	}
+	return replaced, conflicts, nil
+}
+
+// componentMap is the map field of the components of a kind, e.g. schemas.
+type componentMap struct {
+	kind       string
+	components reflect.Value
+}
+
+// componentMaps returns the maps of all the kinds of components, in the order
+// of the fields of spec3.Components.
+func componentMaps(c *spec3.Components) []componentMap {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	var ret []componentMap
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type.Kind() != reflect.Map || t.Field(i).Name == "Extensions" {
+			continue
+		}
+		kind := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		ret = append(ret, componentMap{kind: kind, components: v.Field(i)})
+	}
+	return ret
+}
+
+func sortedMapKeys(m reflect.Value) []string {
+	keys := make([]string, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// equalComponents compares components, ignoring the x-kubernetes-group-version-kind
+// and x-kubernetes-openapi-sources extensions of schemas.
+func equalComponents(c1, c2 reflect.Value) bool {
+	if s1, ok := c1.Interface().(*spec.Schema); ok {
+		return deepEqualDefinitionsModuloGVKs(s1, c2.Interface().(*spec.Schema))
+	}
+	return reflect.DeepEqual(c1.Interface(), c2.Interface())
+}
+
+// renamedComponent returns the name of the component k of source conflicting
+// with dest, reusing a previously renamed component if one exists.
+func renamedComponent(k string, v, dest, source reflect.Value, renames map[string]string, kind string) string {
+	var newName string
+	i := 1
+	for found := true; found; {
+		i++
+		newName = fmt.Sprintf("%s_v%d", k, i)
+		existing := dest.MapIndex(reflect.ValueOf(newName))
+		found = existing.IsValid()
+		if found && equalComponents(existing, v) {
+			return newName
+		}
+	}
+	used := func(name string) bool {
+		if dest.MapIndex(reflect.ValueOf(name)).IsValid() || source.MapIndex(reflect.ValueOf(name)).IsValid() {
+			return true
+		}
+		for _, to := range renames {
+			if to == componentsPrefix+kind+"/"+common.EscapeJsonPointer(name) {
+				return true
+			}
+		}
+		return false
+	}
+	for used(newName) {
+		i++
+		newName = fmt.Sprintf("%s_v%d", k, i)
+	}
+	return newName
+}
+
+// mergeSchemaV3 returns the schema s of the source merged into the existing
+// schema of dest, if any. Neither is mutated, as they might be shared with
+// the specs they were merged from.
+func mergeSchemaV3(existing reflect.Value, s *spec.Schema, opts MergeOptions) (*spec.Schema, error) {
+	if !existing.IsValid() {
+		if !opts.RecordSources {
+			return s, nil
+		}
+		recorded := *s
+		recorded.Extensions = withSource(s.Extensions, opts.Source)
+		return &recorded, nil
+	}
+	merged := *existing.Interface().(*spec.Schema)
+	if gvks, changed, err := mergedGVKs(&merged, s); err != nil {
+		return nil, err
+	} else if changed {
+		merged.Extensions = withGVKs(merged.Extensions, gvks)
+	}
+	if opts.RecordSources {
+		merged.Extensions = withSource(merged.Extensions, opts.Source)
+	}
+	return &merged, nil
+}
+
+// renameComponents returns a copy of sp with the components renamed from the
+// keys of renames to their values, given as references, and the references
+// to them rewritten.
+func renameComponents(sp *spec3.OpenAPI, renames map[string]string) (*spec3.OpenAPI, error) {
+	b, err := json.Marshal(sp)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	components, _ := doc["components"].(map[string]interface{})
+	for from, to := range renames {
+		fromPath := strings.SplitN(strings.TrimPrefix(from, componentsPrefix), "/", 2)
+		toPath := strings.SplitN(strings.TrimPrefix(to, componentsPrefix), "/", 2)
+		m, _ := components[fromPath[0]].(map[string]interface{})
+		fromName, toName := jsonpointer.Unescape(fromPath[1]), jsonpointer.Unescape(toPath[1])
+		m[toName] = m[fromName]
+		delete(m, fromName)
+	}
+	rewriteRefs(doc, renames)
+	if b, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	ret := &spec3.OpenAPI{}
+	if err := json.Unmarshal(b, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// rewriteRefs rewrites the $ref values of v pointing to or into the
+// components renamed by renames.
+func rewriteRefs(v interface{}, renames map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if s, ok := e.(string); ok && k == "$ref" {
+				for from, to := range renames {
+					if s == from || strings.HasPrefix(s, from+"/") {
+						v[k] = to + s[len(from):]
+						break
+					}
+				}
+				continue
+			}
+			rewriteRefs(e, renames)
+		}
+	case []interface{}:
+		for _, e := range v {
+			rewriteRefs(e, renames)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+func parseV3(t *testing.T, s string) *spec3.OpenAPI {
+	sp := &spec3.OpenAPI{}
+	require.NoError(t, json.Unmarshal([]byte(s), sp))
+	return sp
+}
+
+func TestMergeSpecsV3(t *testing.T) {
+	dest := parseV3(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "Kubernetes", "version": "v1.23"},
+		"tags": [{"name": "core"}],
+		"paths": {
+			"/foo": {"get": {"responses": {"200": {"$ref": "#/components/responses/Foo"}}}}
+		},
+		"components": {
+			"schemas": {
+				"Foo": {"type": "string"},
+				"Status": {"type": "object", "x-kubernetes-group-version-kind": [{"group": "", "version": "v1", "kind": "Status"}]}
+			},
+			"responses": {
+				"Foo": {"description": "a foo", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo"}}}}
+			}
+		}
+	}`)
+	source := parseV3(t, `{
+		"openapi": "3.1.0",
+		"info": {"title": "Extension", "version": "v1"},
+		"tags": [{"name": "core"}, {"name": "extension"}],
+		"paths": {
+			"/bar": {"get": {"responses": {
+				"200": {"$ref": "#/components/responses/Foo"},
+				"default": {"description": "status", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Status"}}}}
+			}}}
+		},
+		"webhooks": {
+			"barCreated": {"post": {"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo/properties/name"}}}}}}
+		},
+		"components": {
+			"schemas": {
+				"Foo": {"type": "object", "properties": {"name": {"type": "string"}}},
+				"Bar": {"type": "array", "items": {"$ref": "#/components/schemas/Foo"}},
+				"Status": {"type": "object", "x-kubernetes-group-version-kind": [{"group": "meta.k8s.io", "version": "v1", "kind": "Status"}]}
+			},
+			"responses": {
+				"Foo": {"description": "a foo", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo"}}}}
+			}
+		}
+	}`)
+	sourceJSON, err := json.Marshal(source)
+	require.NoError(t, err)
+
+	require.NoError(t, MergeSpecsV3(dest, source, MergeOptions{RenameModelConflicts: true, Logger: common.NoopLogger}))
+	b, err := json.Marshal(dest)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.1.0",
+		"info": {"title": "Kubernetes", "version": "v1.23"},
+		"tags": [{"name": "core"}, {"name": "extension"}],
+		"paths": {
+			"/foo": {"get": {"responses": {"200": {"$ref": "#/components/responses/Foo"}}}},
+			"/bar": {"get": {"responses": {
+				"200": {"$ref": "#/components/responses/Foo_v2"},
+				"default": {"description": "status", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Status"}}}}
+			}}}
+		},
+		"webhooks": {
+			"barCreated": {"post": {"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo_v2/properties/name"}}}}}}
+		},
+		"components": {
+			"schemas": {
+				"Foo": {"type": "string"},
+				"Foo_v2": {"type": "object", "properties": {"name": {"type": "string"}}},
+				"Bar": {"type": "array", "items": {"$ref": "#/components/schemas/Foo_v2"}},
+				"Status": {"type": "object", "x-kubernetes-group-version-kind": [
+					{"group": "", "version": "v1", "kind": "Status"},
+					{"group": "meta.k8s.io", "version": "v1", "kind": "Status"}
+				]}
+			},
+			"responses": {
+				"Foo": {"description": "a foo", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo"}}}},
+				"Foo_v2": {"description": "a foo", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Foo_v2"}}}}
+			}
+		}
+	}`, string(b))
+
+	b, err = json.Marshal(source)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(sourceJSON), string(b), "the source must not be mutated")
+}
+
+func TestMergeSpecsV3Conflicts(t *testing.T) {
+	ast := assert.New(t)
+	spec := `{
+		"openapi": "3.0.0",
+		"paths": {
+			"/foo": {"get": {"responses": {"200": {"description": "OK"}}}}
+		},
+		"components": {"schemas": {"Foo": {"type": "%s"}}}
+	}`
+	dest, source := parseV3(t, spec), parseV3(t, spec)
+	source.Components.Schemas["Foo"].Type = []string{"integer"}
+
+	err := MergeSpecsV3(dest, source, MergeOptions{RenameModelConflicts: true, Logger: common.NoopLogger})
+	ast.EqualError(err, "unable to merge: duplicated path /foo")
+
+	err = MergeSpecsV3(dest, source, MergeOptions{IgnorePathConflicts: true, Logger: common.NoopLogger})
+	ast.EqualError(err, "schemas name conflict in merging OpenAPI v3 spec: Foo")
+	ast.Len(dest.Components.Schemas, 1, "dest must not be modified on errors")
+
+	logger := &recordingLogger{}
+	ast.NoError(MergeSpecsV3(dest, source, MergeOptions{IgnorePathConflicts: true, RenameModelConflicts: true, Logger: logger}))
+	ast.Len(dest.Paths.Paths, 1)
+	ast.Equal([]string{"integer"}, []string(dest.Components.Schemas["Foo_v2"].Type))
+	ast.Len(logger.infos, 3)
+	ast.Contains(logger.infos[0], "Ignoring conflicting paths")
+	ast.Contains(logger.infos[1], "Renamed conflicting component")
+	ast.Contains(logger.infos[2], "Merged OpenAPI v3 spec")
+
+	// merging the same source again reuses the renamed component
+	ast.NoError(MergeSpecsV3(dest, source, MergeOptions{IgnorePathConflicts: true, RenameModelConflicts: true, Logger: common.NoopLogger}))
+	ast.Len(dest.Components.Schemas, 2)
+
+	webhooks := parseV3(t, `{"webhooks": {"fooCreated": {"post": {}}}}`)
+	ast.NoError(MergeSpecsV3(dest, webhooks, MergeOptions{Logger: common.NoopLogger}))
+	ast.EqualError(MergeSpecsV3(dest, webhooks, MergeOptions{Logger: common.NoopLogger}), "unable to merge: duplicated webhook fooCreated")
+}
+
+func TestMergeSpecsV3RecordSources(t *testing.T) {
+	ast := assert.New(t)
+	dest := parseV3(t, `{
+		"openapi": "3.0.0",
+		"components": {"schemas": {"Status": {"type": "object"}}}
+	}`)
+	source := parseV3(t, `{
+		"openapi": "3.0.0",
+		"paths": {
+			"/bar": {"get": {"responses": {"200": {"description": "OK"}}}}
+		},
+		"components": {"schemas": {"Status": {"type": "object"}, "Bar": {"type": "string"}}}
+	}`)
+
+	ast.NoError(MergeSpecsV3(dest, source, MergeOptions{RecordSources: true, Source: "svc-b", Logger: common.NoopLogger}))
+	ast.Equal([]interface{}{"svc-b"}, dest.Paths.Paths["/bar"].Extensions[SourcesExtension])
+	ast.Equal([]interface{}{"svc-b"}, dest.Components.Schemas["Status"].Extensions[SourcesExtension])
+	ast.Equal([]interface{}{"svc-b"}, dest.Components.Schemas["Bar"].Extensions[SourcesExtension])
+	ast.Empty(source.Paths.Paths["/bar"].Extensions, "the source must not be mutated")
+	ast.Empty(source.Components.Schemas["Bar"].Extensions, "the source must not be mutated")
+}
@@ -24,6 +24,8 @@ import (
 
 const (
 	definitionPrefix = "#/definitions/"
+	parameterPrefix  = "#/parameters/"
+	responsePrefix   = "#/responses/"
 )
 
 // Run a readonlyReferenceWalker method on all references of an OpenAPI spec
@@ -67,7 +69,7 @@ func (s *readonlyReferenceWalker) walkSchema(schema *spec.Schema) {
 	}
 	s.walkRefCallback(&schema.Ref)
 	var v *spec.Schema
-	if len(schema.Definitions)+len(schema.Properties)+len(schema.PatternProperties) > 0 {
+	if len(schema.Definitions)+len(schema.Properties)+len(schema.PatternProperties)+len(schema.DependentSchemas) > 0 {
 		v = &spec.Schema{}
 	}
 	for k := range schema.Definitions {
@@ -82,6 +84,10 @@ func (s *readonlyReferenceWalker) walkSchema(schema *spec.Schema) {
 		*v = schema.PatternProperties[k]
 		s.walkSchema(v)
 	}
+	for k := range schema.DependentSchemas {
+		*v = schema.DependentSchemas[k]
+		s.walkSchema(v)
+	}
 	for i := range schema.AllOf {
 		s.walkSchema(&schema.AllOf[i])
 	}
@@ -91,15 +97,36 @@ func (s *readonlyReferenceWalker) walkSchema(schema *spec.Schema) {
 	for i := range schema.OneOf {
 		s.walkSchema(&schema.OneOf[i])
 	}
+	for i := range schema.PrefixItems {
+		s.walkSchema(&schema.PrefixItems[i])
+	}
 	if schema.Not != nil {
 		s.walkSchema(schema.Not)
 	}
+	if schema.ContentSchema != nil {
+		s.walkSchema(schema.ContentSchema)
+	}
+	if schema.If != nil {
+		s.walkSchema(schema.If)
+	}
+	if schema.Then != nil {
+		s.walkSchema(schema.Then)
+	}
+	if schema.Else != nil {
+		s.walkSchema(schema.Else)
+	}
 	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
 		s.walkSchema(schema.AdditionalProperties.Schema)
 	}
 	if schema.AdditionalItems != nil && schema.AdditionalItems.Schema != nil {
 		s.walkSchema(schema.AdditionalItems.Schema)
 	}
+	if schema.UnevaluatedProperties != nil && schema.UnevaluatedProperties.Schema != nil {
+		s.walkSchema(schema.UnevaluatedProperties.Schema)
+	}
+	if schema.UnevaluatedItems != nil && schema.UnevaluatedItems.Schema != nil {
+		s.walkSchema(schema.UnevaluatedItems.Schema)
+	}
 	if schema.Items != nil {
 		if schema.Items.Schema != nil {
 			s.walkSchema(schema.Items.Schema)
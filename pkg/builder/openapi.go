@@ -20,7 +20,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
+	"time"
 
 	restful "github.com/emicklei/go-restful"
 
@@ -38,16 +40,39 @@ type openAPI struct {
 	swagger      *spec.Swagger
 	protocolList []string
 	definitions  map[string]common.OpenAPIDefinition
+	// securitySchemes are the security schemes declared by the routes
+	securitySchemes map[string]common.SecurityScheme
 }
 
 // BuildOpenAPISpec builds OpenAPI spec given a list of webservices (containing routes) and common.Config to customize it.
 func BuildOpenAPISpec(webServices []*restful.WebService, config *common.Config) (*spec.Swagger, error) {
+	logger := common.LoggerOrDefault(config.Logger)
+	start := time.Now()
 	o := newOpenAPI(config)
 	err := o.buildPaths(webServices)
 	if err != nil {
+		logger.Error(err, "Failed to build OpenAPI spec", "webServices", len(webServices))
 		return nil, err
 	}
-	return o.finalizeSwagger()
+	swagger, err := o.finalizeSwagger()
+	if err != nil {
+		logger.Error(err, "Failed to finalize OpenAPI spec", "webServices", len(webServices))
+		return nil, err
+	}
+	paths := 0
+	if swagger.Paths != nil {
+		paths = len(swagger.Paths.Paths)
+	}
+	if config.ExtensionLimits != nil {
+		stats := common.SwaggerExtensionStats(swagger)
+		logger.Info("Computed OpenAPI spec vendor extensions size", "size", stats.Total)
+		if err := config.ExtensionLimits.Check(stats); err != nil {
+			logger.Error(err, "OpenAPI spec vendor extensions exceed limits")
+			return nil, err
+		}
+	}
+	logger.Info("Built OpenAPI spec", "webServices", len(webServices), "paths", paths, "definitions", len(swagger.Definitions), "duration", time.Since(start))
+	return swagger, nil
 }
 
 // BuildOpenAPIDefinitionsForResource builds a partial OpenAPI spec given a sample object and common.Config to customize it.
@@ -122,6 +147,11 @@ func (o *openAPI) finalizeSwagger() (*spec.Swagger, error) {
 		o.swagger.SecurityDefinitions = *o.config.SecurityDefinitions
 		o.swagger.Security = o.config.DefaultSecurity
 	}
+	if len(o.securitySchemes) > 0 {
+		if err := o.finalizeSecurity(); err != nil {
+			return nil, err
+		}
+	}
 	if o.config.PostProcessSpec != nil {
 		var err error
 		o.swagger, err = o.config.PostProcessSpec(o.swagger)
@@ -133,6 +163,51 @@ func (o *openAPI) finalizeSwagger() (*spec.Swagger, error) {
 	return o.swagger, nil
 }
 
+// finalizeSecurity adds the security schemes declared by the routes to the
+// security definitions. Swagger 2.0 cannot describe some of them, e.g. mutual
+// TLS, the security requirements naming them are removed from the operations.
+func (o *openAPI) finalizeSecurity() error {
+	definitions := make(spec.SecurityDefinitions, len(o.swagger.SecurityDefinitions)+len(o.securitySchemes))
+	for name, s := range o.swagger.SecurityDefinitions {
+		definitions[name] = s
+	}
+	for name, s := range o.securitySchemes {
+		v2, ok := s.V2()
+		if !ok {
+			continue
+		}
+		if existing, exists := definitions[name]; exists && !reflect.DeepEqual(existing, v2) {
+			return fmt.Errorf("security scheme %q of routes conflicts with the security definitions of the config", name)
+		}
+		definitions[name] = v2
+	}
+	o.swagger.SecurityDefinitions = definitions
+
+	for _, pathItem := range o.swagger.Paths.Paths {
+		for _, op := range []*spec.Operation{pathItem.Get, pathItem.Put, pathItem.Post, pathItem.Delete, pathItem.Options, pathItem.Head, pathItem.Patch} {
+			if op == nil || op.Security == nil {
+				continue
+			}
+			security := make([]map[string][]string, 0, len(op.Security))
+			for _, requirement := range op.Security {
+				supported := true
+				for name := range requirement {
+					if _, ok := definitions[name]; !ok {
+						if _, declared := o.securitySchemes[name]; declared {
+							supported = false
+						}
+					}
+				}
+				if supported {
+					security = append(security, requirement)
+				}
+			}
+			op.Security = security
+		}
+	}
+	return nil
+}
+
 func (o *openAPI) buildDefinitionRecursively(name string) error {
 	uniqueName, extensions := o.config.GetDefinitionName(name)
 	if _, ok := o.swagger.Definitions[uniqueName]; ok {
@@ -283,13 +358,28 @@ func (o *openAPI) buildOperations(route restful.Route, inPathCommonParamsMap map
 	if ret.ID, ret.Tags, err = o.config.GetOperationIDAndTags(&route); err != nil {
 		return ret, err
 	}
+	if ret.Security, err = o.buildSecurity(&route); err != nil {
+		return ret, err
+	}
 
 	// Build responses
 	for _, resp := range route.ResponseErrors {
-		ret.Responses.StatusCodeResponses[resp.Code], err = o.buildResponse(resp.Model, resp.Message)
+		r, err := o.buildResponse(resp.Model, resp.Message)
 		if err != nil {
 			return ret, err
 		}
+		setResponse(ret.Responses, resp.Code, resp.IsDefault, r)
+	}
+	declared, err := common.RouteResponses(&route)
+	if err != nil {
+		return ret, err
+	}
+	for _, resp := range declared {
+		r, err := o.buildRouteResponse(resp)
+		if err != nil {
+			return ret, err
+		}
+		setResponse(ret.Responses, resp.Code, resp.Code == 0, r)
 	}
 	// If there is no response but a write sample, assume that write sample is an http.StatusOK response.
 	if len(ret.Responses.StatusCodeResponses) == 0 && route.WriteSample != nil {
@@ -304,7 +394,7 @@ func (o *openAPI) buildOperations(route restful.Route, inPathCommonParamsMap map
 		}
 	}
 	// If there is still no response, use default response provided.
-	if len(ret.Responses.StatusCodeResponses) == 0 {
+	if len(ret.Responses.StatusCodeResponses) == 0 && ret.Responses.Default == nil {
 		ret.Responses.Default = o.config.DefaultResponse
 	}
 
@@ -322,17 +412,77 @@ func (o *openAPI) buildOperations(route restful.Route, inPathCommonParamsMap map
 	return ret, nil
 }
 
-func (o *openAPI) buildResponse(model interface{}, description string) (spec.Response, error) {
-	schema, err := o.toSchema(util.GetCanonicalTypeName(model))
+// buildSecurity records the security schemes declared by the route and
+// returns the security requirements of its operation, nil if it declares
+// none.
+func (o *openAPI) buildSecurity(route *restful.Route) ([]map[string][]string, error) {
+	schemes, err := common.RouteSecuritySchemes(route)
 	if err != nil {
-		return spec.Response{}, err
+		return nil, err
+	}
+	for name, s := range schemes {
+		if existing, exists := o.securitySchemes[name]; exists && existing != s {
+			return nil, fmt.Errorf("security scheme %q of route %s %s conflicts with another route", name, route.Method, route.Path)
+		}
+		if o.securitySchemes == nil {
+			o.securitySchemes = map[string]common.SecurityScheme{}
+		}
+		o.securitySchemes[name] = s
 	}
-	return spec.Response{
+	security, _, err := common.RouteSecurity(route)
+	return security, err
+}
+
+// buildResponse builds a response with a body of the type of model, or no
+// body if model is nil.
+func (o *openAPI) buildResponse(model interface{}, description string) (spec.Response, error) {
+	ret := spec.Response{
 		ResponseProps: spec.ResponseProps{
 			Description: description,
-			Schema:      schema,
 		},
-	}, nil
+	}
+	if model == nil {
+		return ret, nil
+	}
+	schema, err := o.toSchema(util.GetCanonicalTypeName(model))
+	if err != nil {
+		return spec.Response{}, err
+	}
+	ret.Schema = schema
+	return ret, nil
+}
+
+// buildRouteResponse builds a response declared by route metadata.
+func (o *openAPI) buildRouteResponse(resp common.RouteResponse) (spec.Response, error) {
+	ret, err := o.buildResponse(resp.Model, resp.Description)
+	if err != nil {
+		return ret, err
+	}
+	for name, h := range resp.Headers {
+		openAPIType, openAPIFormat := common.OpenAPITypeFormat(h.DataType)
+		if ret.Headers == nil {
+			ret.Headers = make(map[string]spec.Header, len(resp.Headers))
+		}
+		ret.Headers[name] = spec.Header{
+			SimpleSchema: spec.SimpleSchema{
+				Type:   openAPIType,
+				Format: openAPIFormat,
+			},
+			HeaderProps: spec.HeaderProps{
+				Description: h.Description,
+			},
+		}
+	}
+	return ret, nil
+}
+
+// setResponse sets the response of the status code, or the default response.
+func setResponse(responses *spec.Responses, code int, isDefault bool, resp spec.Response) {
+	if isDefault {
+		responses.Default = &resp
+		return
+	}
+	responses.StatusCodeResponses[code] = resp
 }
 
 func (o *openAPI) findCommonParameters(routes []restful.Route) (map[interface{}]spec.Parameter, error) {
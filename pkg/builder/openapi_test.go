@@ -486,6 +486,132 @@ func TestBuildOpenAPISpec(t *testing.T) {
 	assert.Equal(string(expected_json), string(actual_json))
 }
 
+func TestBuildOpenAPISpecExtensionLimits(t *testing.T) {
+	config, container, assert := setUp(t, true)
+	swagger, err := BuildOpenAPISpec(container.RegisteredWebServices(), config)
+	if !assert.NoError(err) {
+		return
+	}
+	stats := openapi.SwaggerExtensionStats(swagger)
+	assert.Equal(map[string]int{"builder.TestInput": 26, "builder.TestOutput": 14}, stats.Definitions)
+	assert.Equal(40, stats.Total)
+
+	config.ExtensionLimits = &openapi.ExtensionLimits{MaxPerDefinition: 26, MaxTotal: 40}
+	_, err = BuildOpenAPISpec(container.RegisteredWebServices(), config)
+	assert.NoError(err)
+
+	config.ExtensionLimits = &openapi.ExtensionLimits{MaxPerDefinition: 20}
+	_, err = BuildOpenAPISpec(container.RegisteredWebServices(), config)
+	assert.EqualError(err, "vendor extensions of definition builder.TestInput are 26 bytes, exceeding the limit of 20 bytes")
+
+	config.ExtensionLimits = &openapi.ExtensionLimits{MaxTotal: 39}
+	_, err = BuildOpenAPISpec(container.RegisteredWebServices(), config)
+	assert.EqualError(err, "vendor extensions are 40 bytes, exceeding the limit of 39 bytes")
+}
+
+func TestBuildOpenAPISpecSecurity(t *testing.T) {
+	assert := assert.New(t)
+	ws := new(restful.WebService)
+	ws.Path("/foo")
+	ws.Route(ws.GET("/token").
+		Operation("getToken").
+		Metadata(openapi.SecuritySchemesMetadataKey, map[string]openapi.SecurityScheme{
+			"BearerToken": {Type: openapi.SecuritySchemeBearer, Description: "Bearer token"},
+			"ClientCert":  {Type: openapi.SecuritySchemeMutualTLS},
+		}).
+		Metadata(openapi.SecurityMetadataKey, []map[string][]string{{"BearerToken": {}}, {"ClientCert": {}}}).
+		To(noOp))
+	ws.Route(ws.GET("/cert").
+		Operation("getCert").
+		Metadata(openapi.SecurityMetadataKey, []map[string][]string{{"ClientCert": {}}}).
+		To(noOp))
+	ws.Route(ws.GET("/public").
+		Operation("getPublic").
+		Metadata(openapi.SecurityMetadataKey, []map[string][]string{}).
+		To(noOp))
+	config, _ := getConfig(false)
+	config.SecurityDefinitions = &spec.SecurityDefinitions{
+		"BasicAuth": &spec.SecurityScheme{SecuritySchemeProps: spec.SecuritySchemeProps{Type: "basic"}},
+	}
+	config.DefaultSecurity = []map[string][]string{{"BasicAuth": {}}}
+
+	swagger, err := BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	if !assert.NoError(err) {
+		return
+	}
+	b, err := json.Marshal(swagger.SecurityDefinitions)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"BasicAuth": {"type": "basic"},
+		"BearerToken": {"type": "apiKey", "description": "Bearer token", "name": "Authorization", "in": "header"}
+	}`, string(b))
+	assert.Equal([]map[string][]string{{"BasicAuth": {}}}, swagger.Security)
+	assert.Equal([]map[string][]string{{"BearerToken": {}}}, swagger.Paths.Paths["/foo/token"].Get.Security)
+	// mutual TLS has no Swagger 2.0 counterpart
+	assert.Equal([]map[string][]string{}, swagger.Paths.Paths["/foo/cert"].Get.Security)
+	assert.Equal([]map[string][]string{}, swagger.Paths.Paths["/foo/public"].Get.Security)
+
+	ws.Route(ws.GET("/other").
+		Operation("getOther").
+		Metadata(openapi.SecuritySchemesMetadataKey, map[string]openapi.SecurityScheme{
+			"BearerToken": {Type: openapi.SecuritySchemeBearer, BearerFormat: "JWT"},
+		}).
+		To(noOp))
+	_, err = BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	assert.Error(err)
+	assert.Contains(err.Error(), `security scheme "BearerToken" of route`)
+}
+
+func TestBuildOpenAPISpecResponses(t *testing.T) {
+	assert := assert.New(t)
+	ws := new(restful.WebService)
+	ws.Path("/foo")
+	ws.Route(ws.GET("/").
+		Operation("getFoo").
+		Returns(http.StatusOK, "OK", TestOutput{}).
+		Returns(http.StatusNoContent, "No content", nil).
+		DefaultReturns("Error", TestInput{}).
+		Metadata(openapi.ResponsesMetadataKey, []openapi.RouteResponse{
+			{
+				Code:        http.StatusOK,
+				Description: "The foo",
+				Model:       TestOutput{},
+				Headers:     map[string]openapi.ResponseHeader{"X-Count": {Description: "number of foos", DataType: "int64"}},
+			},
+			{Code: http.StatusTooManyRequests, Description: "Too many requests", Model: TestInput{}},
+		}).
+		To(noOp))
+	config, _ := getConfig(false)
+	config.CommonResponses = map[int]spec.Response{
+		http.StatusUnauthorized:    {ResponseProps: spec.ResponseProps{Description: "Unauthorized"}},
+		http.StatusTooManyRequests: {ResponseProps: spec.ResponseProps{Description: "Common"}},
+	}
+	swagger, err := BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	if !assert.NoError(err) {
+		return
+	}
+	b, err := json.Marshal(swagger.Paths.Paths["/foo/"].Get.Responses)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"default": {"description": "Error", "schema": {"$ref": "#/definitions/builder.TestInput"}},
+		"200": {
+			"description": "The foo",
+			"schema": {"$ref": "#/definitions/builder.TestOutput"},
+			"headers": {"X-Count": {"description": "number of foos", "type": "integer", "format": "int64"}}
+		},
+		"204": {"description": "No content"},
+		"401": {"description": "Unauthorized"},
+		"429": {"description": "Too many requests", "schema": {"$ref": "#/definitions/builder.TestInput"}}
+	}`, string(b))
+
+	ws.Route(ws.GET("/bar").
+		Operation("getBar").
+		Metadata(openapi.ResponsesMetadataKey, []openapi.RouteResponse{{Code: 42}}).
+		To(noOp))
+	_, err = BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	assert.EqualError(err, "route GET /foo/bar: invalid response status code 42")
+}
+
 func TestBuildOpenAPIDefinitionsForResource(t *testing.T) {
 	config, _, assert := setUp(t, true)
 	expected := &spec.Definitions{
@@ -22,10 +22,14 @@ import (
 	restful "github.com/emicklei/go-restful"
 	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/spec3/convert"
 	"k8s.io/kube-openapi/pkg/util"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"net/http"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 )
 
 const (
@@ -46,27 +50,58 @@ func groupRoutesByPath(routes []restful.Route) map[string][]restful.Route {
 	return pathToRoutes
 }
 
+// buildResponse builds a response with a body of the type of model in each
+// media type of content, or no body if model is nil.
 func (o *openAPI) buildResponse(model interface{}, description string, content []string) (*spec3.Response, error) {
-	response := &spec3.Response{
+	ret := &spec3.Response{
 		ResponseProps: spec3.ResponseProps{
 			Description: description,
-			Content:     make(map[string]*spec3.MediaType),
 		},
 	}
-
+	if model == nil {
+		return ret, nil
+	}
 	s, err := o.toSchema(util.GetCanonicalTypeName(model))
 	if err != nil {
 		return nil, err
 	}
+	ret.Content = buildContent(s, content)
+	return ret, nil
+}
 
-	for _, contentType := range content {
-		response.ResponseProps.Content[contentType] = &spec3.MediaType{
-			MediaTypeProps: spec3.MediaTypeProps{
-				Schema: s,
+// buildRouteResponse builds a response declared by route metadata.
+func (o *openAPI) buildRouteResponse(resp common.RouteResponse, content []string) (*spec3.Response, error) {
+	ret, err := o.buildResponse(resp.Model, resp.Description, content)
+	if err != nil {
+		return nil, err
+	}
+	for name, h := range resp.Headers {
+		openAPIType, openAPIFormat := common.OpenAPITypeFormat(h.DataType)
+		if ret.Headers == nil {
+			ret.Headers = make(map[string]*spec3.Header, len(resp.Headers))
+		}
+		ret.Headers[name] = &spec3.Header{
+			HeaderProps: spec3.HeaderProps{
+				Description: h.Description,
+				Schema: &spec.Schema{
+					SchemaProps: spec.SchemaProps{
+						Type:   []string{openAPIType},
+						Format: openAPIFormat,
+					},
+				},
 			},
 		}
 	}
-	return response, nil
+	return ret, nil
+}
+
+// setResponse sets the response of the status code, or the default response.
+func setResponse(responses *spec3.Responses, code int, isDefault bool, resp *spec3.Response) {
+	if isDefault {
+		responses.Default = resp
+		return
+	}
+	responses.StatusCodeResponses[code] = resp
 }
 
 func (o *openAPI) buildOperations(route restful.Route, inPathCommonParamsMap map[interface{}]*spec3.Parameter) (*spec3.Operation, error) {
@@ -80,17 +115,40 @@ func (o *openAPI) buildOperations(route restful.Route, inPathCommonParamsMap map
 			},
 		},
 	}
+	for k, v := range route.Metadata {
+		if strings.HasPrefix(k, common.ExtensionPrefix) {
+			if ret.Extensions == nil {
+				ret.Extensions = spec.Extensions{}
+			}
+			ret.Extensions.Add(k, v)
+		}
+	}
 	var err error
 	if ret.OperationId, ret.Tags, err = o.config.GetOperationIDAndTags(&route); err != nil {
 		return ret, err
 	}
+	if ret.SecurityRequirement, err = o.buildSecurity(&route); err != nil {
+		return ret, err
+	}
 
 	// Build responses
 	for _, resp := range route.ResponseErrors {
-		ret.Responses.StatusCodeResponses[resp.Code], err = o.buildResponse(resp.Model, resp.Message, route.Produces)
+		r, err := o.buildResponse(resp.Model, resp.Message, route.Produces)
 		if err != nil {
 			return ret, err
 		}
+		setResponse(ret.Responses, resp.Code, resp.IsDefault, r)
+	}
+	declared, err := common.RouteResponses(&route)
+	if err != nil {
+		return ret, err
+	}
+	for _, resp := range declared {
+		r, err := o.buildRouteResponse(resp, route.Produces)
+		if err != nil {
+			return ret, err
+		}
+		setResponse(ret.Responses, resp.Code, resp.Code == 0, r)
 	}
 
 	// If there is no response but a write sample, assume that write sample is an http.StatusOK response.
@@ -100,52 +158,212 @@ func (o *openAPI) buildOperations(route restful.Route, inPathCommonParamsMap map
 			return ret, err
 		}
 	}
+	for code, resp := range o.config.CommonResponses {
+		if _, exists := ret.Responses.StatusCodeResponses[code]; !exists {
+			ret.Responses.StatusCodeResponses[code] = convertResponse(resp, route.Produces)
+		}
+	}
+	// If there is still no response, use default response provided.
+	if len(ret.Responses.StatusCodeResponses) == 0 && ret.Responses.Default == nil && o.config.DefaultResponse != nil {
+		ret.Responses.Default = convertResponse(*o.config.DefaultResponse, route.Produces)
+	}
 
-	// TODO: Default response if needed. Common Response config
-
+	// Build non-common Parameters, body and form parameters make up the request body
 	ret.Parameters = make([]*spec3.Parameter, 0)
 	for _, param := range route.ParameterDocs {
-		_, isCommon := inPathCommonParamsMap[mapKeyFromParam(param)]
-		if !isCommon && param.Data().Kind != restful.BodyParameterKind {
-			openAPIParam, err := o.buildParameter(param.Data())
-			if err != nil {
-				return ret, err
-			}
-			ret.Parameters = append(ret.Parameters, openAPIParam)
+		kind := param.Data().Kind
+		if _, isCommon := inPathCommonParamsMap[mapKeyFromParam(param)]; isCommon || kind == restful.BodyParameterKind || kind == restful.FormParameterKind {
+			continue
 		}
+		openAPIParam, err := o.buildParameter(param.Data())
+		if err != nil {
+			return ret, err
+		}
+		ret.Parameters = append(ret.Parameters, openAPIParam)
 	}
 
-	body, err := o.buildRequestBody(route.ParameterDocs, route.ReadSample)
+	if ret.RequestBody, err = o.buildRequestBody(route.ParameterDocs, route.Consumes, route.ReadSample); err != nil {
+		return ret, err
+	}
+
+	callbacks, err := common.RouteCallbacks(&route)
+	if err != nil {
+		return ret, err
+	}
+	for name, c := range callbacks {
+		pathItem, err := o.buildWebhook(c.Webhook)
+		if err != nil {
+			return ret, err
+		}
+		if ret.Callbacks == nil {
+			ret.Callbacks = make(map[string]*spec3.Callback, len(callbacks))
+		}
+		ret.Callbacks[name] = &spec3.Callback{
+			CallbackProps: spec3.CallbackProps{c.Expression: pathItem},
+		}
+	}
+	return ret, nil
+}
+
+// buildWebhook builds the path item of the requests sent to a webhook.
+func (o *openAPI) buildWebhook(webhook common.Webhook) (*spec3.Path, error) {
+	op := &spec3.Operation{
+		OperationProps: spec3.OperationProps{
+			OperationId: webhook.OperationID,
+			Description: webhook.Description,
+			Responses: &spec3.Responses{
+				ResponsesProps: spec3.ResponsesProps{
+					StatusCodeResponses: make(map[int]*spec3.Response),
+				},
+			},
+		},
+	}
+	if webhook.Request != nil {
+		schema, err := o.toSchema(util.GetCanonicalTypeName(webhook.Request))
+		if err != nil {
+			return nil, err
+		}
+		op.RequestBody = &spec3.RequestBody{
+			RequestBodyProps: spec3.RequestBodyProps{
+				Content:  buildContent(schema, []string{restful.MIME_JSON}),
+				Required: true,
+			},
+		}
+	}
+	resp, err := o.buildResponse(webhook.Response, "OK", []string{restful.MIME_JSON})
 	if err != nil {
-		return nil ,err
+		return nil, err
+	}
+	op.Responses.StatusCodeResponses[http.StatusOK] = resp
+	return &spec3.Path{
+		PathProps: spec3.PathProps{
+			Post: op,
+		},
+	}, nil
+}
+
+// buildWebhooks adds the webhooks of the config to the spec, which makes it
+// an OpenAPI 3.1 spec.
+func (o *openAPI) buildWebhooks() error {
+	if len(o.config.Webhooks) == 0 {
+		return nil
+	}
+	o.spec.Webhooks = make(map[string]*spec3.Path, len(o.config.Webhooks))
+	for name, webhook := range o.config.Webhooks {
+		pathItem, err := o.buildWebhook(webhook)
+		if err != nil {
+			return fmt.Errorf("webhook %s: %v", name, err)
+		}
+		o.spec.Webhooks[name] = pathItem
 	}
+	o.spec.Version = "3.1.0"
+	return nil
+}
 
-	if body != nil {
-		ret.RequestBody = body
+// buildSecurity adds the security schemes declared by the route to the
+// components and returns the security requirements of its operation, nil if
+// it declares none.
+func (o *openAPI) buildSecurity(route *restful.Route) ([]*spec3.SecurityRequirement, error) {
+	schemes, err := common.RouteSecuritySchemes(route)
+	if err != nil {
+		return nil, err
+	}
+	for name, s := range schemes {
+		v3 := s.V3()
+		if existing, exists := o.spec.Components.SecuritySchemes[name]; exists && !reflect.DeepEqual(existing, v3) {
+			return nil, fmt.Errorf("security scheme %q of route %s %s conflicts with another security scheme", name, route.Method, route.Path)
+		}
+		if o.spec.Components.SecuritySchemes == nil {
+			o.spec.Components.SecuritySchemes = spec3.SecuritySchemes{}
+		}
+		o.spec.Components.SecuritySchemes[name] = v3
+	}
+	security, ok, err := common.RouteSecurity(route)
+	if !ok || err != nil {
+		return nil, err
+	}
+	ret := make([]*spec3.SecurityRequirement, len(security))
+	for i, requirement := range security {
+		ret[i] = &spec3.SecurityRequirement{SecurityRequirementProps: requirement}
 	}
 	return ret, nil
 }
 
-func (o *openAPI) buildRequestBody(parameters []*restful.Parameter, bodySample interface{}) (*spec3.RequestBody, error) {
+// buildRequestBody builds the request body of the body or form parameters,
+// with the content of each media type consumed by the route.
+func (o *openAPI) buildRequestBody(parameters []*restful.Parameter, consumes []string, bodySample interface{}) (*spec3.RequestBody, error) {
+	var body *restful.ParameterData
+	var form []restful.ParameterData
 	for _, param := range parameters {
-		if param.Data().Kind == restful.BodyParameterKind && bodySample != nil {
-			schema, err := o.toSchema(util.GetCanonicalTypeName(bodySample))
-			if err != nil {
-				return nil, err
+		switch data := param.Data(); data.Kind {
+		case restful.BodyParameterKind:
+			body = &data
+		case restful.FormParameterKind:
+			form = append(form, data)
+		}
+	}
+
+	switch {
+	case body != nil && len(form) > 0:
+		return nil, fmt.Errorf("body parameter %v cannot be used with form parameters", body.Name)
+	case body != nil:
+		if bodySample == nil {
+			// There is not enough information in the body parameter to build the definition.
+			// Body parameter has a data type that is a short name but we need full package name
+			// of the type to create a definition.
+			return nil, fmt.Errorf("restful body parameters are not supported: %v", body.DataType)
+		}
+		schema, err := o.toSchema(util.GetCanonicalTypeName(bodySample))
+		if err != nil {
+			return nil, err
+		}
+		mediaTypes := filterMediaTypes(consumes, false)
+		if len(mediaTypes) == 0 {
+			mediaTypes = []string{restful.MIME_JSON}
+		}
+		return &spec3.RequestBody{
+			RequestBodyProps: spec3.RequestBodyProps{
+				Description: body.Description,
+				Content:     buildContent(schema, mediaTypes),
+				Required:    body.Required,
+			},
+		}, nil
+	case len(form) > 0:
+		schema := &spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type:       []string{"object"},
+				Properties: make(map[string]spec.Schema, len(form)),
+			},
+		}
+		required := false
+		for _, data := range form {
+			openAPIType, openAPIFormat := common.OpenAPITypeFormat(data.DataType)
+			if openAPIType == "" {
+				return nil, fmt.Errorf("form parameter type should be a simple type, but got : %v", data.DataType)
 			}
-			r := &spec3.RequestBody{
-				RequestBodyProps: spec3.RequestBodyProps{
-					Content: map[string]*spec3.MediaType{
-						"application/json": &spec3.MediaType{
-							MediaTypeProps: spec3.MediaTypeProps{
-								Schema: schema,
-							},
-						},
-					},
+			schema.Properties[data.Name] = spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: data.Description,
+					Type:        []string{openAPIType},
+					Format:      openAPIFormat,
 				},
 			}
-			return r, nil
+			if data.Required {
+				schema.Required = append(schema.Required, data.Name)
+				required = true
+			}
+		}
+		sort.Strings(schema.Required)
+		mediaTypes := filterMediaTypes(consumes, true)
+		if len(mediaTypes) == 0 {
+			mediaTypes = []string{mimeFormURLEncoded}
 		}
+		return &spec3.RequestBody{
+			RequestBodyProps: spec3.RequestBodyProps{
+				Content:  buildContent(schema, mediaTypes),
+				Required: required,
+			},
+		}, nil
 	}
 	return nil, nil
 }
@@ -181,11 +399,38 @@ func newOpenAPI(config *common.Config) openAPI {
 		return spec.MustCreateRef("#/components/schemas/" + common.EscapeJsonPointer(defName))
 	})
 
+	// the shared responses and security schemes of the config are written for
+	// Swagger 2.0, they are converted to their OpenAPI v3 counterparts
+	for name, resp := range o.config.ResponseDefinitions {
+		if o.spec.Components.Responses == nil {
+			o.spec.Components.Responses = make(map[string]*spec3.Response, len(o.config.ResponseDefinitions))
+		}
+		o.spec.Components.Responses[name] = convertResponse(resp, []string{restful.MIME_JSON})
+	}
+	if o.config.SecurityDefinitions != nil {
+		v3, _ := convert.ToV3(&spec.Swagger{
+			SwaggerProps: spec.SwaggerProps{
+				SecurityDefinitions: *o.config.SecurityDefinitions,
+				Security:            o.config.DefaultSecurity,
+			},
+		})
+		if v3.Components != nil {
+			o.spec.Components.SecuritySchemes = v3.Components.SecuritySchemes
+		}
+		o.spec.SecurityRequirement = v3.SecurityRequirement
+	}
 	return o
 }
 
 func (o *openAPI) buildOpenAPISpec(webServices []*restful.WebService) error {
+	return o.buildPaths(webServices, func(string) *openAPI { return o })
+}
+
+// buildPaths builds the paths of the web services, each in the spec of the
+// openAPI returned by specFor, with the schemas it references.
+func (o *openAPI) buildPaths(webServices []*restful.WebService, specFor func(path string) *openAPI) error {
 	pathsToIgnore := util.NewTrie(o.config.IgnorePrefixes)
+	duplicateOpId := make(map[string]string)
 	for _, w := range webServices {
 		rootPath := w.RootPath()
 		if pathsToIgnore.HasPrefix(rootPath) {
@@ -212,7 +457,8 @@ func (o *openAPI) buildOpenAPISpec(webServices []*restful.WebService) error {
 			if err != nil {
 				return err
 			}
-			pathItem, exists := o.spec.Paths.Paths[path]
+			target := specFor(path)
+			pathItem, exists := target.spec.Paths.Paths[path]
 			if exists {
 				return fmt.Errorf("duplicate webservice route has been found for path: %v", path)
 			}
@@ -231,7 +477,16 @@ func (o *openAPI) buildOpenAPISpec(webServices []*restful.WebService) error {
 			sortParameters(pathItem.Parameters)
 
 			for _, route := range routes {
-				op, _ := o.buildOperations(route, inPathCommonParamsMap)
+				op, err := target.buildOperations(route, inPathCommonParamsMap)
+				if err != nil {
+					return err
+				}
+				sortParameters(op.Parameters)
+				dpath, exists := duplicateOpId[op.OperationId]
+				if exists {
+					return fmt.Errorf("duplicate Operation ID %v for path %v and %v", op.OperationId, dpath, path)
+				}
+				duplicateOpId[op.OperationId] = path
 
 				switch strings.ToUpper(route.Method) {
 				case "GET":
@@ -251,21 +506,107 @@ func (o *openAPI) buildOpenAPISpec(webServices []*restful.WebService) error {
 				}
 
 			}
-			o.spec.Paths.Paths[path] = pathItem
+			target.spec.Paths.Paths[path] = pathItem
 		}
 	}
 	return nil
 }
 
 func BuildOpenAPISpec(webServices []*restful.WebService, config *common.Config) (*spec3.OpenAPI, error) {
+	logger := common.LoggerOrDefault(config.Logger)
+	start := time.Now()
 	a := newOpenAPI(config)
 	err := a.buildOpenAPISpec(webServices)
+	if err == nil {
+		err = a.buildWebhooks()
+	}
 	if err != nil {
+		logger.Error(err, "Failed to build OpenAPI v3 spec", "webServices", len(webServices))
+		return nil, err
+	}
+	if err := a.checkExtensionLimits(); err != nil {
 		return nil, err
 	}
+	logger.Info("Built OpenAPI v3 spec", "webServices", len(webServices), "paths", len(a.spec.Paths.Paths), "schemas", len(a.spec.Components.Schemas), "duration", time.Since(start))
 	return a.spec, nil
 }
 
+// BuildOpenAPISpecsByGroupVersion builds an OpenAPI v3 spec for each
+// group-version of the paths of the web services, keyed by the path prefix
+// of the group-version, e.g. "apis/apps/v1" or "api/v1", as served by
+// handler3.OpenAPIService. Other paths are keyed by their first segment, e.g.
+// "version".
+//
+// The specs share the definitions of config, each holds the schemas
+// referenced by its own paths only. The webhooks of config belong to no
+// group-version, they are left out.
+func BuildOpenAPISpecsByGroupVersion(webServices []*restful.WebService, config *common.Config) (map[string]*spec3.OpenAPI, error) {
+	logger := common.LoggerOrDefault(config.Logger)
+	start := time.Now()
+	shared := newOpenAPI(config)
+	builders := map[string]*openAPI{}
+	err := shared.buildPaths(webServices, func(path string) *openAPI {
+		gv := groupVersion(path)
+		b, ok := builders[gv]
+		if !ok {
+			b = shared.emptyCopy()
+			builders[gv] = b
+		}
+		return b
+	})
+	if err != nil {
+		logger.Error(err, "Failed to build OpenAPI v3 specs", "webServices", len(webServices))
+		return nil, err
+	}
+	specs := make(map[string]*spec3.OpenAPI, len(builders))
+	for gv, b := range builders {
+		if err := b.checkExtensionLimits(); err != nil {
+			return nil, err
+		}
+		specs[gv] = b.spec
+	}
+	logger.Info("Built OpenAPI v3 specs", "webServices", len(webServices), "groupVersions", len(specs), "duration", time.Since(start))
+	return specs, nil
+}
+
+// emptyCopy returns an openAPI with the config and definitions of o, building
+// a spec with no paths or schemas yet.
+func (o *openAPI) emptyCopy() *openAPI {
+	openAPISpec := *o.spec
+	openAPISpec.Paths = &spec3.Paths{
+		Paths: map[string]*spec3.Path{},
+	}
+	components := *o.spec.Components
+	components.Schemas = map[string]*spec.Schema{}
+	// the security schemes of the routes are added to the copy
+	if o.spec.Components.SecuritySchemes != nil {
+		components.SecuritySchemes = make(spec3.SecuritySchemes, len(o.spec.Components.SecuritySchemes))
+		for name, s := range o.spec.Components.SecuritySchemes {
+			components.SecuritySchemes[name] = s
+		}
+	}
+	openAPISpec.Components = &components
+	return &openAPI{
+		config:      o.config,
+		spec:        &openAPISpec,
+		definitions: o.definitions,
+	}
+}
+
+func (o *openAPI) checkExtensionLimits() error {
+	if o.config.ExtensionLimits == nil {
+		return nil
+	}
+	logger := common.LoggerOrDefault(o.config.Logger)
+	stats := common.OpenAPIV3ExtensionStats(o.spec)
+	logger.Info("Computed OpenAPI v3 spec vendor extensions size", "size", stats.Total)
+	if err := o.config.ExtensionLimits.Check(stats); err != nil {
+		logger.Error(err, "OpenAPI v3 spec vendor extensions exceed limits")
+		return err
+	}
+	return nil
+}
+
 func (o *openAPI) findCommonParameters(routes []restful.Route) (map[interface{}]*spec3.Parameter, error) {
 	commonParamsMap := make(map[interface{}]*spec3.Parameter, 0)
 	paramOpsCountByName := make(map[interface{}]int, 0)
@@ -288,7 +629,7 @@ func (o *openAPI) findCommonParameters(routes []restful.Route) (map[interface{}]
 	}
 	for key, count := range paramOpsCountByName {
 		paramData := paramNameKindToDataMap[key]
-		if count == len(routes) && paramData.Kind != restful.BodyParameterKind {
+		if count == len(routes) && paramData.Kind != restful.BodyParameterKind && paramData.Kind != restful.FormParameterKind {
 			openAPIParam, err := o.buildParameter(paramData)
 			if err != nil {
 				return commonParamsMap, err
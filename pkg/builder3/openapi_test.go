@@ -0,0 +1,323 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder3
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+type TestInput struct {
+	Name string `json:"name,omitempty"`
+}
+
+type TestOutput struct {
+	Count int `json:"count,omitempty"`
+}
+
+func noOp(request *restful.Request, response *restful.Response) {}
+
+func getTestConfig() *common.Config {
+	object := func(description string) common.OpenAPIDefinition {
+		return common.OpenAPIDefinition{
+			Schema: spec.Schema{SchemaProps: spec.SchemaProps{Description: description, Type: []string{"object"}}},
+		}
+	}
+	return &common.Config{
+		Info: &spec.Info{InfoProps: spec.InfoProps{Title: "TestAPI", Version: "unversioned"}},
+		GetDefinitions: func(_ common.ReferenceCallback) map[string]common.OpenAPIDefinition {
+			return map[string]common.OpenAPIDefinition{
+				"k8s.io/kube-openapi/pkg/builder3.TestInput":  object("Test input"),
+				"k8s.io/kube-openapi/pkg/builder3.TestOutput": object("Test output"),
+			}
+		},
+		GetDefinitionName: func(name string) (string, spec.Extensions) {
+			return name[strings.LastIndex(name, "/")+1:], nil
+		},
+	}
+}
+
+func TestBuildOpenAPISpec(t *testing.T) {
+	ws := new(restful.WebService)
+	ws.Path("/foo")
+	ws.Route(ws.POST("/{name}").
+		Operation("createFoo").
+		Consumes(restful.MIME_JSON, "application/yaml").
+		Produces(restful.MIME_JSON).
+		Metadata("x-kubernetes-action", "post").
+		Metadata(common.SecuritySchemesMetadataKey, map[string]common.SecurityScheme{
+			"ClientCert": {Type: common.SecuritySchemeMutualTLS, Description: "Client certificate"},
+		}).
+		Metadata(common.SecurityMetadataKey, []map[string][]string{{"BearerToken": {}}, {"ClientCert": {}}}).
+		Param(ws.PathParameter("name", "name of the foo").DataType("string")).
+		Param(ws.QueryParameter("pretty", "pretty print").DataType("boolean")).
+		Reads(TestInput{}).
+		Returns(http.StatusCreated, "Created", TestOutput{}).
+		To(noOp))
+	ws.Route(ws.PUT("/{name}").
+		Operation("uploadFoo").
+		Consumes("multipart/form-data").
+		Metadata(common.SecurityMetadataKey, []map[string][]string{}).
+		Param(ws.PathParameter("name", "name of the foo").DataType("string")).
+		Param(ws.FormParameter("size", "size of the foo").DataType("integer").Required(true)).
+		Param(ws.FormParameter("label", "label of the foo").DataType("string")).
+		To(noOp))
+
+	config := getTestConfig()
+	config.CommonResponses = map[int]spec.Response{
+		http.StatusUnauthorized: {ResponseProps: spec.ResponseProps{Description: "Unauthorized"}},
+	}
+	config.SecurityDefinitions = &spec.SecurityDefinitions{
+		"BearerToken": &spec.SecurityScheme{SecuritySchemeProps: spec.SecuritySchemeProps{Type: "apiKey", Name: "authorization", In: "header"}},
+	}
+	config.DefaultSecurity = []map[string][]string{{"BearerToken": {}}}
+
+	openAPI, err := BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	require.NoError(t, err)
+	b, err := json.Marshal(openAPI)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "TestAPI", "version": "unversioned"},
+		"paths": {
+			"/foo/{name}": {
+				"parameters": [{"name": "name", "in": "path", "description": "name of the foo", "required": true, "schema": {"type": "string", "uniqueItems": true}}],
+				"post": {
+					"operationId": "createFoo",
+					"parameters": [{"name": "pretty", "in": "query", "description": "pretty print", "schema": {"type": "boolean", "uniqueItems": true}}],
+					"requestBody": {
+						"required": true,
+						"content": {
+							"application/json": {"schema": {"$ref": "#/components/schemas/builder3.TestInput"}},
+							"application/yaml": {"schema": {"$ref": "#/components/schemas/builder3.TestInput"}}
+						}
+					},
+					"responses": {
+						"201": {"description": "Created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/builder3.TestOutput"}}}},
+						"401": {"description": "Unauthorized"}
+					},
+					"security": [{"BearerToken": []}, {"ClientCert": []}],
+					"x-kubernetes-action": "post"
+				},
+				"put": {
+					"operationId": "uploadFoo",
+					"requestBody": {
+						"required": true,
+						"content": {
+							"multipart/form-data": {
+								"schema": {
+									"type": "object",
+									"required": ["size"],
+									"properties": {
+										"label": {"description": "label of the foo", "type": "string"},
+										"size": {"description": "size of the foo", "type": "integer"}
+									}
+								}
+							}
+						}
+					},
+					"responses": {"401": {"description": "Unauthorized"}},
+					"security": []
+				}
+			}
+		},
+		"security": [{"BearerToken": []}],
+		"components": {
+			"schemas": {
+				"builder3.TestInput": {"description": "Test input", "type": "object"},
+				"builder3.TestOutput": {"description": "Test output", "type": "object"}
+			},
+			"securitySchemes": {
+				"BearerToken": {"type": "apiKey", "name": "authorization", "in": "header"},
+				"ClientCert": {"type": "mutualTLS", "description": "Client certificate"}
+			}
+		}
+	}`, string(b))
+}
+
+func TestBuildOpenAPISpecErrors(t *testing.T) {
+	ws := new(restful.WebService)
+	ws.Path("/foo")
+	ws.Route(ws.GET("/a").Operation("getFoo").To(noOp))
+	ws.Route(ws.GET("/b").Operation("getFoo").To(noOp))
+	_, err := BuildOpenAPISpec([]*restful.WebService{ws}, getTestConfig())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate Operation ID getFoo")
+
+	ws = new(restful.WebService)
+	ws.Path("/foo")
+	ws.Route(ws.POST("/").
+		Operation("createFoo").
+		Param(ws.FormParameter("size", "size of the foo").DataType("integer")).
+		Reads(TestInput{}).
+		To(noOp))
+	_, err = BuildOpenAPISpec([]*restful.WebService{ws}, getTestConfig())
+	assert.EqualError(t, err, "body parameter body cannot be used with form parameters")
+}
+
+func TestBuildOpenAPISpecsByGroupVersion(t *testing.T) {
+	apps := new(restful.WebService)
+	apps.Path("/apis/apps/v1")
+	apps.Route(apps.POST("/widgets").Operation("createWidget").Reads(TestInput{}).Writes(TestOutput{}).To(noOp))
+	core := new(restful.WebService)
+	core.Path("/api/v1")
+	core.Route(core.GET("/pods").Operation("listPods").Writes(TestOutput{}).To(noOp))
+	version := new(restful.WebService)
+	version.Path("/version")
+	version.Route(version.GET("/").Operation("getVersion").To(noOp))
+
+	specs, err := BuildOpenAPISpecsByGroupVersion([]*restful.WebService{apps, core, version}, getTestConfig())
+	require.NoError(t, err)
+	paths := map[string][]string{}
+	schemas := map[string][]string{}
+	for gv, s := range specs {
+		for path := range s.Paths.Paths {
+			paths[gv] = append(paths[gv], path)
+		}
+		for name := range s.Components.Schemas {
+			schemas[gv] = append(schemas[gv], name)
+		}
+		sort.Strings(schemas[gv])
+	}
+	assert.Equal(t, map[string][]string{
+		"apis/apps/v1": {"/apis/apps/v1/widgets"},
+		"api/v1":       {"/api/v1/pods"},
+		"version":      {"/version/"},
+	}, paths)
+	assert.Equal(t, map[string][]string{
+		"apis/apps/v1": {"builder3.TestInput", "builder3.TestOutput"},
+		"api/v1":       {"builder3.TestOutput"},
+	}, schemas)
+}
+
+func TestGroupVersion(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/apis/apps/v1/namespaces/{namespace}/deployments": "apis/apps/v1",
+		"/apis/apps/v1":  "apis/apps/v1",
+		"/apis/apps":     "apis/apps",
+		"/api/v1/pods":   "api/v1",
+		"/api":           "api",
+		"/version/":      "version",
+		"/.well-known/x": ".well-known",
+		"/":              "",
+	} {
+		assert.Equal(t, expected, groupVersion(path), path)
+	}
+}
+
+func TestBuildOpenAPISpecResponses(t *testing.T) {
+	ws := new(restful.WebService)
+	ws.Path("/foo")
+	ws.Route(ws.GET("/").
+		Operation("getFoo").
+		Produces(restful.MIME_JSON).
+		Returns(http.StatusNoContent, "No content", nil).
+		DefaultReturns("Error", TestInput{}).
+		Metadata(common.ResponsesMetadataKey, []common.RouteResponse{
+			{
+				Code:        http.StatusOK,
+				Description: "The foo",
+				Model:       TestOutput{},
+				Headers:     map[string]common.ResponseHeader{"X-Count": {Description: "number of foos", DataType: "int64"}},
+			},
+		}).
+		To(noOp))
+	openAPI, err := BuildOpenAPISpec([]*restful.WebService{ws}, getTestConfig())
+	require.NoError(t, err)
+	b, err := json.Marshal(openAPI.Paths.Paths["/foo/"].Get.Responses)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/builder3.TestInput"}}}},
+		"200": {
+			"description": "The foo",
+			"headers": {"X-Count": {"description": "number of foos", "schema": {"type": "integer", "format": "int64"}}},
+			"content": {"application/json": {"schema": {"$ref": "#/components/schemas/builder3.TestOutput"}}}
+		},
+		"204": {"description": "No content"}
+	}`, string(b))
+}
+
+func TestBuildOpenAPISpecWebhooks(t *testing.T) {
+	ws := new(restful.WebService)
+	ws.Path("/foo")
+	ws.Route(ws.POST("/").
+		Operation("registerFoo").
+		Metadata(common.CallbacksMetadataKey, map[string]common.Callback{
+			"review": {
+				Expression: "{$request.body#/url}",
+				Webhook:    common.Webhook{Description: "Reviews a foo", Request: TestInput{}, Response: TestOutput{}},
+			},
+		}).
+		To(noOp))
+	config := getTestConfig()
+	config.Webhooks = map[string]common.Webhook{
+		"convertFoo": {OperationID: "convertFoo", Request: TestInput{}},
+	}
+
+	openAPI, err := BuildOpenAPISpec([]*restful.WebService{ws}, config)
+	require.NoError(t, err)
+	b, err := json.Marshal(openAPI)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"openapi": "3.1.0",
+		"info": {"title": "TestAPI", "version": "unversioned"},
+		"paths": {
+			"/foo/": {
+				"post": {
+					"operationId": "registerFoo",
+					"responses": {},
+					"callbacks": {
+						"review": {
+							"{$request.body#/url}": {
+								"post": {
+									"description": "Reviews a foo",
+									"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/builder3.TestInput"}}}},
+									"responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/builder3.TestOutput"}}}}}
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"webhooks": {
+			"convertFoo": {
+				"post": {
+					"operationId": "convertFoo",
+					"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/builder3.TestInput"}}}},
+					"responses": {"200": {"description": "OK"}}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"builder3.TestInput": {"description": "Test input", "type": "object"},
+				"builder3.TestOutput": {"description": "Test output", "type": "object"}
+			}
+		}
+	}`, string(b))
+}
@@ -18,9 +18,17 @@ package builder3
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/emicklei/go-restful"
 	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/spec3/convert"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	mimeFormURLEncoded = "application/x-www-form-urlencoded"
+	mimeMultipartForm  = "multipart/form-data"
 )
 
 func mapKeyFromParam(param *restful.Parameter) interface{} {
@@ -50,3 +58,62 @@ func (s byNameIn) Less(i, j int) bool {
 func sortParameters(p []*spec3.Parameter) {
 	sort.Sort(byNameIn{p})
 }
+
+// buildContent returns the content of the media types, all described by schema.
+func buildContent(schema *spec.Schema, mediaTypes []string) map[string]*spec3.MediaType {
+	if len(mediaTypes) == 0 {
+		return nil
+	}
+	content := make(map[string]*spec3.MediaType, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = &spec3.MediaType{
+			MediaTypeProps: spec3.MediaTypeProps{
+				Schema: schema,
+			},
+		}
+	}
+	return content
+}
+
+// filterMediaTypes returns the form media types of mediaTypes if form is
+// true, the others otherwise.
+func filterMediaTypes(mediaTypes []string, form bool) []string {
+	var ret []string
+	for _, mediaType := range mediaTypes {
+		base := strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+		if isForm := base == mimeFormURLEncoded || base == mimeMultipartForm; isForm == form {
+			ret = append(ret, mediaType)
+		}
+	}
+	return ret
+}
+
+// convertResponse converts a Swagger 2.0 response of the config to an
+// OpenAPI v3 response with the content of the produced media types.
+func convertResponse(resp spec.Response, produces []string) *spec3.Response {
+	v3, _ := convert.ToV3(&spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Produces:  produces,
+			Responses: map[string]spec.Response{"response": resp},
+		},
+	})
+	return v3.Components.Responses["response"]
+}
+
+// groupVersion returns the path prefix of the group-version of path, e.g.
+// "apis/apps/v1" for "/apis/apps/v1/deployments" or "api/v1" for
+// "/api/v1/pods", or the first segment of other paths.
+func groupVersion(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	n := 1
+	switch segments[0] {
+	case "api":
+		n = 2
+	case "apis":
+		n = 3
+	}
+	if n > len(segments) {
+		n = len(segments)
+	}
+	return strings.Join(segments[:n], "/")
+}
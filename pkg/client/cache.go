@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache stores the documents downloaded by a Client, keyed by URL and
+// format, so that they are only downloaded again when they changed.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the document stored for key, if any.
+	Get(key string) (*Document, bool)
+	// Set stores doc for key.
+	Set(key string, doc *Document) error
+}
+
+// NewMemoryCache returns a Cache keeping documents in memory.
+func NewMemoryCache() Cache {
+	return &memoryCache{docs: map[string]*Document{}}
+}
+
+type memoryCache struct {
+	mu   sync.RWMutex
+	docs map[string]*Document
+}
+
+func (c *memoryCache) Get(key string) (*Document, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	doc, ok := c.docs[key]
+	return doc, ok
+}
+
+func (c *memoryCache) Set(key string, doc *Document) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docs[key] = doc
+	return nil
+}
+
+// NewDiskCache returns a Cache keeping documents in files under dir, which is
+// created if needed, so that they survive restarts of the client.
+func NewDiskCache(dir string) Cache {
+	return &diskCache{dir: dir}
+}
+
+type diskCache struct {
+	dir string
+}
+
+func (c *diskCache) path(key string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%x", sha256.Sum256([]byte(key))))
+}
+
+// Get treats unreadable entries as missing, so that a corrupted cache only
+// costs a download.
+func (c *diskCache) Get(key string) (*Document, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry diskEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil || entry.Key != key {
+		return nil, false
+	}
+	return &entry.Document, true
+}
+
+func (c *diskCache) Set(key string, doc *Document) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(diskEntry{Key: key, Document: *doc}); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that readers never see partial entries
+	f, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+// diskEntry is the content of a disk cache file. The key guards against
+// hash collisions.
+type diskEntry struct {
+	Key      string
+	Document Document
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package client fetches the OpenAPI documents served by the handler and
+// handler3 packages, using conditional requests and a pluggable cache to only
+// download documents that changed.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	v2Path = "/openapi/v2"
+	v3Path = "/openapi/v3"
+
+	mimeJSON = "application/json"
+	mimePbV2 = "application/com.github.proto-openapi.spec.v2@v1.0+protobuf"
+	mimePbV3 = "application/com.github.proto-openapi.spec.v3@v1.0+protobuf"
+)
+
+// Format is the serialization of a document.
+type Format int
+
+const (
+	// JSON documents can be parsed with encoding/json.
+	JSON Format = iota
+	// Protobuf documents are serialized gnostic OpenAPI messages.
+	Protobuf
+)
+
+func (f Format) String() string {
+	switch f {
+	case JSON:
+		return "json"
+	case Protobuf:
+		return "protobuf"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// Document is a downloaded OpenAPI document.
+type Document struct {
+	Data   []byte
+	Format Format
+	// ETag is the entity tag of the document, used to download it again only
+	// when it changed. Documents without ETag are not cached.
+	ETag string
+}
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL is the URL the documents are served under, e.g. https://localhost:6443.
+	BaseURL string
+	// HTTPClient sends the requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// Cache stores the downloaded documents. If nil, an in-memory cache is used.
+	Cache Cache
+	// Format is the preferred format of documents. Servers not supporting
+	// Protobuf answer with JSON.
+	Format Format
+	// Logger receives events about requests and caching. If nil, events are written to klog.
+	Logger common.Logger
+}
+
+// Client fetches the OpenAPI v2 document and the OpenAPI v3 documents of a server.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	cache      Cache
+	format     Format
+	logger     common.Logger
+
+	lock sync.Mutex
+	// v3URLs are the hash-addressed server-relative URLs of the OpenAPI v3
+	// documents, by path, as listed by the last discovery document.
+	v3URLs map[string]string
+}
+
+// NewClient returns a Client for config.
+func NewClient(config Config) (*Client, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("BaseURL must be set")
+	}
+	if config.Format != JSON && config.Format != Protobuf {
+		return nil, fmt.Errorf("unsupported format %v", config.Format)
+	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+		httpClient: config.HTTPClient,
+		cache:      config.Cache,
+		format:     config.Format,
+		logger:     common.LoggerOrDefault(config.Logger),
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.cache == nil {
+		c.cache = NewMemoryCache()
+	}
+	return c, nil
+}
+
+// V2 returns the OpenAPI v2 document, in the preferred format if the server
+// supports it.
+func (c *Client) V2(ctx context.Context) (*Document, error) {
+	return c.fetch(ctx, v2Path, mimePbV2, c.format)
+}
+
+// V2Spec returns the parsed OpenAPI v2 document. It is always downloaded as JSON.
+func (c *Client) V2Spec(ctx context.Context) (*spec.Swagger, error) {
+	doc, err := c.fetch(ctx, v2Path, mimePbV2, JSON)
+	if err != nil {
+		return nil, err
+	}
+	ret := &spec.Swagger{}
+	if err := json.Unmarshal(doc.Data, ret); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", v2Path, err)
+	}
+	return ret, nil
+}
+
+// V3Paths returns the paths of the OpenAPI v3 documents listed by the
+// discovery document, e.g. "apis/apps/v1". The hash-addressed URLs of the
+// documents it lists are used by V3 and V3Spec until the next call.
+func (c *Client) V3Paths(ctx context.Context) ([]string, error) {
+	doc, err := c.fetch(ctx, v3Path, mimePbV3, JSON)
+	if err != nil {
+		return nil, err
+	}
+	var discovery struct {
+		Paths map[string]struct {
+			ServerRelativeURL string `json:"serverRelativeURL"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(doc.Data, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", v3Path, err)
+	}
+	paths := make([]string, 0, len(discovery.Paths))
+	urls := make(map[string]string, len(discovery.Paths))
+	for p, gv := range discovery.Paths {
+		paths = append(paths, p)
+		if gv.ServerRelativeURL != "" {
+			urls[p] = gv.ServerRelativeURL
+		}
+	}
+	sort.Strings(paths)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.v3URLs = urls
+	return paths, nil
+}
+
+// V3 returns the OpenAPI v3 document for path, as returned by V3Paths, in the
+// preferred format if the server supports it. Documents downloaded from
+// hash-addressed URLs are immutable: once cached, they are returned without
+// contacting the server.
+func (c *Client) V3(ctx context.Context, path string) (*Document, error) {
+	url, immutable := c.v3URL(path)
+	return c.fetchURL(ctx, url, immutable, mimePbV3, c.format)
+}
+
+// V3Spec returns the parsed OpenAPI v3 document for path, cached as by V3.
+// It is always downloaded as JSON.
+func (c *Client) V3Spec(ctx context.Context, path string) (*spec3.OpenAPI, error) {
+	url, immutable := c.v3URL(path)
+	doc, err := c.fetchURL(ctx, url, immutable, mimePbV3, JSON)
+	if err != nil {
+		return nil, err
+	}
+	ret := &spec3.OpenAPI{}
+	if err := json.Unmarshal(doc.Data, ret); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return ret, nil
+}
+
+// v3URL returns the URL of the OpenAPI v3 document for path, and whether it
+// is hash-addressed, i.e. its document never changes.
+func (c *Client) v3URL(path string) (string, bool) {
+	path = strings.TrimPrefix(path, "/")
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if u, ok := c.v3URLs[path]; ok {
+		return c.baseURL + u, true
+	}
+	return c.baseURL + v3Path + "/" + path, false
+}
+
+// fetch downloads the document at path, in format if possible. mimePb is the
+// protobuf media type for the document. The cached document is returned if
+// the server reports it did not change.
+func (c *Client) fetch(ctx context.Context, path, mimePb string, format Format) (*Document, error) {
+	return c.fetchURL(ctx, c.baseURL+path, false, mimePb, format)
+}
+
+// fetchURL downloads the document at url as fetch. If immutable, the document
+// at url never changes and is returned from the cache without request if found.
+func (c *Client) fetchURL(ctx context.Context, url string, immutable bool, mimePb string, format Format) (*Document, error) {
+	key := cacheKey(format, url)
+	cached, found := c.cache.Get(key)
+	if found && immutable {
+		c.logger.Info("OpenAPI document served from cache", "url", url, "etag", cached.ETag)
+		return cached, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if format == Protobuf {
+		req.Header.Set("Accept", mimePb+", "+mimeJSON+";q=0.9")
+	} else {
+		req.Header.Set("Accept", mimeJSON)
+	}
+	if found {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && found:
+		c.logger.Info("OpenAPI document not modified", "url", url, "etag", cached.ETag)
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %q fetching %s", resp.Status, url)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", url, err)
+	}
+	if len(data) == 0 {
+		// older handler3 versions answer unknown groups with an empty 200 response
+		return nil, fmt.Errorf("empty document at %s", url)
+	}
+	doc := &Document{
+		Data:   data,
+		Format: detectFormat(resp.Header.Get("Content-Type"), data),
+		ETag:   resp.Header.Get("ETag"),
+	}
+	if format == JSON && doc.Format != JSON {
+		return nil, fmt.Errorf("expected JSON fetching %s, got %s", url, resp.Header.Get("Content-Type"))
+	}
+	c.logger.Info("Downloaded OpenAPI document", "url", url, "format", doc.Format, "size", len(data), "etag", doc.ETag)
+	if final := resp.Request.URL.String(); final != url {
+		// stale hash-addressed URLs are redirected to the current ones
+		key = cacheKey(format, final)
+	}
+	if doc.ETag != "" {
+		if err := c.cache.Set(key, doc); err != nil {
+			// the document is still usable, it will just be downloaded again
+			c.logger.Error(err, "Failed to cache OpenAPI document", "url", url)
+		}
+	}
+	return doc, nil
+}
+
+func cacheKey(format Format, url string) string {
+	return format.String() + " " + url
+}
+
+// detectFormat returns the format of a response. The handlers serve content
+// with sniffed content types, so protobuf is also told apart from JSON by
+// the content.
+func detectFormat(contentType string, data []byte) Format {
+	if strings.Contains(contentType, "protobuf") {
+		return Protobuf
+	}
+	if strings.HasPrefix(contentType, mimeJSON) {
+		return JSON
+	}
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return JSON
+	}
+	return Protobuf
+}
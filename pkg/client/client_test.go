@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/handler"
+	"k8s.io/kube-openapi/pkg/handler3"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+type testServer struct {
+	*httptest.Server
+	v3 *handler3.OpenAPIService
+	// requests counts the requests per path, and notModified the 304 responses.
+	requests, notModified map[string]int
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func newTestServer(t *testing.T) *testServer {
+	mux := http.NewServeMux()
+	v2 := &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Swagger: "2.0",
+		Info:    &spec.Info{InfoProps: spec.InfoProps{Title: "test", Version: "v1"}},
+		Paths:   &spec.Paths{Paths: map[string]spec.PathItem{}},
+	}}
+	v2Service, err := handler.NewOpenAPIService(v2)
+	require.NoError(t, err)
+	v2Service.SetLogger(common.NoopLogger)
+	require.NoError(t, v2Service.RegisterOpenAPIVersionedService("/openapi/v2", mux))
+
+	v3Service, err := handler3.NewOpenAPIService(nil)
+	require.NoError(t, err)
+	v3Service.SetLogger(common.NoopLogger)
+	require.NoError(t, v3Service.UpdateGroupVersion("apis/apps/v1", &spec3.OpenAPI{
+		Version: "3.0.0",
+		Info:    &spec.Info{InfoProps: spec.InfoProps{Title: "apps", Version: "v1"}},
+		Paths:   &spec3.Paths{Paths: map[string]*spec3.Path{}},
+	}))
+	mux.HandleFunc("/openapi/v3", v3Service.HandleDiscovery)
+	mux.HandleFunc("/openapi/v3/", v3Service.HandleGroupVersion)
+
+	s := &testServer{v3: v3Service, requests: map[string]int{}, notModified: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &countingWriter{ResponseWriter: w}
+		mux.ServeHTTP(cw, r)
+		s.requests[r.URL.Path]++
+		if cw.status == http.StatusNotModified {
+			s.notModified[r.URL.Path]++
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestClientV2(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	for _, format := range []Format{JSON, Protobuf} {
+		c, err := NewClient(Config{BaseURL: s.URL, Format: format, Logger: common.NoopLogger})
+		require.NoError(t, err)
+		doc, err := c.V2(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, format, doc.Format)
+		assert.NotEmpty(t, doc.ETag)
+		if format == JSON {
+			assert.True(t, strings.HasPrefix(string(doc.Data), "{"))
+		}
+
+		again, err := c.V2(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, doc, again)
+	}
+	assert.Equal(t, 4, s.requests["/openapi/v2"])
+	assert.Equal(t, 2, s.notModified["/openapi/v2"])
+
+	c, err := NewClient(Config{BaseURL: s.URL, Format: Protobuf, Logger: common.NoopLogger})
+	require.NoError(t, err)
+	sw, err := c.V2Spec(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "test", sw.Info.Title)
+}
+
+func TestClientV3(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	c, err := NewClient(Config{BaseURL: s.URL + "/", Format: Protobuf, Logger: common.NoopLogger})
+	require.NoError(t, err)
+
+	paths, err := c.V3Paths(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apis/apps/v1"}, paths)
+
+	doc, err := c.V3(ctx, paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, Protobuf, doc.Format)
+
+	openapi, err := c.V3Spec(ctx, paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, "apps", openapi.Info.Title)
+	// documents at hash-addressed URLs are served from the cache
+	_, err = c.V3Spec(ctx, paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.requests["/openapi/v3/apis/apps/v1"])
+
+	_, err = c.V3(ctx, "apis/missing/v1")
+	assert.Error(t, err)
+}
+
+func TestClientV3StaleHash(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	c, err := NewClient(Config{BaseURL: s.URL, Format: JSON, Logger: common.NoopLogger})
+	require.NoError(t, err)
+
+	// without discovery, documents are fetched with conditional requests
+	_, err = c.V3Spec(ctx, "apis/apps/v1")
+	require.NoError(t, err)
+	_, err = c.V3Spec(ctx, "apis/apps/v1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.notModified["/openapi/v3/apis/apps/v1"])
+
+	_, err = c.V3Paths(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.v3.UpdateGroupVersion("apis/apps/v1", &spec3.OpenAPI{
+		Version: "3.0.0",
+		Info:    &spec.Info{InfoProps: spec.InfoProps{Title: "apps updated", Version: "v1"}},
+		Paths:   &spec3.Paths{Paths: map[string]*spec3.Path{}},
+	}))
+
+	// the stale hash is redirected to the current document
+	openapi, err := c.V3Spec(ctx, "apis/apps/v1")
+	require.NoError(t, err)
+	assert.Equal(t, "apps updated", openapi.Info.Title)
+
+	_, err = c.V3Paths(ctx)
+	require.NoError(t, err)
+	openapi, err = c.V3Spec(ctx, "apis/apps/v1")
+	require.NoError(t, err)
+	assert.Equal(t, "apps updated", openapi.Info.Title)
+	assert.Equal(t, 4, s.requests["/openapi/v3/apis/apps/v1"])
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "openapi-client")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := newTestServer(t)
+	ctx := context.Background()
+	first, err := NewClient(Config{BaseURL: s.URL, Cache: NewDiskCache(dir), Logger: common.NoopLogger})
+	require.NoError(t, err)
+	doc, err := first.V2(ctx)
+	require.NoError(t, err)
+
+	// a new client with the same cache directory does not download again
+	second, err := NewClient(Config{BaseURL: s.URL, Cache: NewDiskCache(dir), Logger: common.NoopLogger})
+	require.NoError(t, err)
+	again, err := second.V2(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, doc, again)
+	assert.Equal(t, 1, s.notModified["/openapi/v2"])
+
+	cache := NewDiskCache(dir)
+	_, found := cache.Get("missing")
+	assert.False(t, found)
+}
+
+func TestNewClientErrors(t *testing.T) {
+	_, err := NewClient(Config{})
+	assert.Error(t, err)
+	_, err = NewClient(Config{BaseURL: "http://localhost", Format: Format(3)})
+	assert.Error(t, err)
+}
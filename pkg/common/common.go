@@ -62,6 +62,18 @@ type PathHandlerByGroupVersion interface {
 	HandlePrefix(path string, handler http.Handler)
 }
 
+// SpecFilter makes the OpenAPI services serve each request a variant of the
+// spec, e.g. a spec redacted for the tenant of the peer.
+type SpecFilter struct {
+	// Key returns the key of the variant served for r. The empty key serves
+	// the spec unfiltered. Variants are cached per key, so the keys should be
+	// few, e.g. one per tenant.
+	Key func(r *http.Request) string
+	// Apply returns the variant key of the JSON serialized spec. It is called
+	// once per key and spec update. spec must not be modified.
+	Apply func(key string, spec []byte) ([]byte, error)
+}
+
 // Config is set of configuration for openAPI spec generation.
 type Config struct {
 	// List of supported protocols such as https, http, etc.
@@ -108,6 +120,18 @@ type Config struct {
 	// DefaultSecurity for all operations. This will pass as spec.SwaggerProps.Security to OpenAPI.
 	// For most cases, this will be list of acceptable definitions in SecurityDefinitions.
 	DefaultSecurity []map[string][]string
+
+	// Logger receives structured events about spec building. If nil, events are written to klog.
+	Logger Logger
+
+	// ExtensionLimits bounds the size of the vendor extensions of the built spec.
+	// If set, the size is also logged. If nil, the size is not computed.
+	ExtensionLimits *ExtensionLimits
+
+	// Webhooks describe the requests the API sends to webhooks, e.g. admission reviews or
+	// CRD conversions, by name. They are added to the webhooks of OpenAPI v3 specs, which
+	// makes them OpenAPI 3.1 specs. Swagger 2.0 specs cannot describe them.
+	Webhooks map[string]Webhook
 }
 
 type typeInfo struct {
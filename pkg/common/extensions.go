@@ -0,0 +1,288 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ExtensionStats is the size of the vendor extensions of an OpenAPI document.
+// The size of an extension is the length of its key and JSON-serialized value.
+type ExtensionStats struct {
+	// Total is the size of all the vendor extensions of the document.
+	Total int
+	// Definitions is the size of the vendor extensions of each definition,
+	// including those of nested schemas. Definitions without extensions are omitted.
+	Definitions map[string]int
+}
+
+// ExtensionLimits bounds the size of the vendor extensions of a document.
+// Zero values mean no limit.
+type ExtensionLimits struct {
+	// MaxTotal is the maximum size of all the vendor extensions of a document.
+	MaxTotal int
+	// MaxPerDefinition is the maximum size of the vendor extensions of a single definition.
+	MaxPerDefinition int
+}
+
+// Check returns an error if stats exceed the limits, naming the offending
+// definitions in order.
+func (l *ExtensionLimits) Check(stats ExtensionStats) error {
+	if l == nil {
+		return nil
+	}
+	if l.MaxPerDefinition > 0 {
+		var names []string
+		for name, size := range stats.Definitions {
+			if size > l.MaxPerDefinition {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return fmt.Errorf("vendor extensions of definition %s are %d bytes, exceeding the limit of %d bytes", names[0], stats.Definitions[names[0]], l.MaxPerDefinition)
+		}
+	}
+	if l.MaxTotal > 0 && stats.Total > l.MaxTotal {
+		return fmt.Errorf("vendor extensions are %d bytes, exceeding the limit of %d bytes", stats.Total, l.MaxTotal)
+	}
+	return nil
+}
+
+// SwaggerExtensionStats returns the size of the vendor extensions of sp.
+func SwaggerExtensionStats(sp *spec.Swagger) ExtensionStats {
+	stats := ExtensionStats{Definitions: map[string]int{}}
+	stats.Total = extensionsSize(sp.Extensions)
+	if sp.Info != nil {
+		stats.Total += extensionsSize(sp.Info.Extensions)
+	}
+	for name, def := range sp.Definitions {
+		def := def
+		if size := schemaExtensionsSize(&def); size > 0 {
+			stats.Definitions[name] = size
+			stats.Total += size
+		}
+	}
+	for _, p := range sp.Parameters {
+		p := p
+		stats.Total += parameterExtensionsSize(&p)
+	}
+	for _, r := range sp.Responses {
+		r := r
+		stats.Total += responseExtensionsSize(&r)
+	}
+	if sp.Paths == nil {
+		return stats
+	}
+	stats.Total += extensionsSize(sp.Paths.Extensions)
+	for _, item := range sp.Paths.Paths {
+		stats.Total += extensionsSize(item.Extensions)
+		for i := range item.Parameters {
+			stats.Total += parameterExtensionsSize(&item.Parameters[i])
+		}
+		for _, op := range []*spec.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+			if op == nil {
+				continue
+			}
+			stats.Total += extensionsSize(op.Extensions)
+			for i := range op.Parameters {
+				stats.Total += parameterExtensionsSize(&op.Parameters[i])
+			}
+			if op.Responses == nil {
+				continue
+			}
+			stats.Total += extensionsSize(op.Responses.Extensions)
+			if op.Responses.Default != nil {
+				stats.Total += responseExtensionsSize(op.Responses.Default)
+			}
+			for _, r := range op.Responses.StatusCodeResponses {
+				r := r
+				stats.Total += responseExtensionsSize(&r)
+			}
+		}
+	}
+	return stats
+}
+
+// OpenAPIV3ExtensionStats returns the size of the vendor extensions of the
+// schemas, paths and operations of an OpenAPI v3 document, including their
+// parameters, request bodies and responses. Component schemas are reported
+// as definitions.
+func OpenAPIV3ExtensionStats(openapi *spec3.OpenAPI) ExtensionStats {
+	stats := ExtensionStats{Definitions: map[string]int{}}
+	if openapi.Info != nil {
+		stats.Total += extensionsSize(openapi.Info.Extensions)
+	}
+	if openapi.Components != nil {
+		for name, s := range openapi.Components.Schemas {
+			if size := schemaExtensionsSize(s); size > 0 {
+				stats.Definitions[name] = size
+				stats.Total += size
+			}
+		}
+		for _, p := range openapi.Components.Parameters {
+			stats.Total += v3ParameterExtensionsSize(p)
+		}
+		for _, b := range openapi.Components.RequestBodies {
+			stats.Total += v3RequestBodyExtensionsSize(b)
+		}
+		for _, r := range openapi.Components.Responses {
+			stats.Total += v3ResponseExtensionsSize(r)
+		}
+	}
+	if openapi.Paths == nil {
+		return stats
+	}
+	stats.Total += extensionsSize(openapi.Paths.Extensions)
+	for _, p := range openapi.Paths.Paths {
+		if p == nil {
+			continue
+		}
+		stats.Total += extensionsSize(p.Extensions)
+		for _, param := range p.Parameters {
+			stats.Total += v3ParameterExtensionsSize(param)
+		}
+		for _, op := range []*spec3.Operation{p.Get, p.Put, p.Post, p.Delete, p.Options, p.Head, p.Patch, p.Trace} {
+			if op == nil {
+				continue
+			}
+			stats.Total += extensionsSize(op.Extensions)
+			for _, param := range op.Parameters {
+				stats.Total += v3ParameterExtensionsSize(param)
+			}
+			stats.Total += v3RequestBodyExtensionsSize(op.RequestBody)
+			if op.Responses == nil {
+				continue
+			}
+			stats.Total += extensionsSize(op.Responses.Extensions)
+			stats.Total += v3ResponseExtensionsSize(op.Responses.Default)
+			for _, r := range op.Responses.StatusCodeResponses {
+				stats.Total += v3ResponseExtensionsSize(r)
+			}
+		}
+	}
+	return stats
+}
+
+func extensionsSize(ext spec.Extensions) int {
+	size := 0
+	for k, v := range ext {
+		data, err := json.Marshal(v)
+		if err != nil {
+			data = []byte(fmt.Sprint(v))
+		}
+		size += len(k) + len(data)
+	}
+	return size
+}
+
+func parameterExtensionsSize(p *spec.Parameter) int {
+	return extensionsSize(p.Extensions) + schemaExtensionsSize(p.Schema)
+}
+
+func responseExtensionsSize(r *spec.Response) int {
+	return extensionsSize(r.Extensions) + schemaExtensionsSize(r.Schema)
+}
+
+func v3ParameterExtensionsSize(p *spec3.Parameter) int {
+	if p == nil {
+		return 0
+	}
+	return extensionsSize(p.Extensions) + schemaExtensionsSize(p.Schema) + mediaTypesExtensionsSize(p.Content)
+}
+
+func v3RequestBodyExtensionsSize(b *spec3.RequestBody) int {
+	if b == nil {
+		return 0
+	}
+	return extensionsSize(b.Extensions) + mediaTypesExtensionsSize(b.Content)
+}
+
+func v3ResponseExtensionsSize(r *spec3.Response) int {
+	if r == nil {
+		return 0
+	}
+	size := extensionsSize(r.Extensions) + mediaTypesExtensionsSize(r.Content)
+	for _, h := range r.Headers {
+		if h != nil {
+			size += extensionsSize(h.Extensions) + schemaExtensionsSize(h.Schema) + mediaTypesExtensionsSize(h.Content)
+		}
+	}
+	return size
+}
+
+func mediaTypesExtensionsSize(content map[string]*spec3.MediaType) int {
+	size := 0
+	for _, m := range content {
+		if m != nil {
+			size += extensionsSize(m.Extensions) + schemaExtensionsSize(m.Schema)
+		}
+	}
+	return size
+}
+
+// schemaExtensionsSize returns the size of the vendor extensions of s and its
+// nested schemas.
+func schemaExtensionsSize(s *spec.Schema) int {
+	if s == nil {
+		return 0
+	}
+	size := extensionsSize(s.Extensions)
+	for _, m := range []map[string]spec.Schema{s.Properties, s.PatternProperties, s.DependentSchemas, s.Definitions} {
+		for _, v := range m {
+			v := v
+			size += schemaExtensionsSize(&v)
+		}
+	}
+	for _, l := range [][]spec.Schema{s.AllOf, s.AnyOf, s.OneOf, s.PrefixItems} {
+		for i := range l {
+			size += schemaExtensionsSize(&l[i])
+		}
+	}
+	size += schemaExtensionsSize(s.Not)
+	size += schemaExtensionsSize(s.ContentSchema)
+	size += schemaExtensionsSize(s.If)
+	size += schemaExtensionsSize(s.Then)
+	size += schemaExtensionsSize(s.Else)
+	if s.Items != nil {
+		size += schemaExtensionsSize(s.Items.Schema)
+		for i := range s.Items.Schemas {
+			size += schemaExtensionsSize(&s.Items.Schemas[i])
+		}
+	}
+	if s.AdditionalProperties != nil {
+		size += schemaExtensionsSize(s.AdditionalProperties.Schema)
+	}
+	if s.AdditionalItems != nil {
+		size += schemaExtensionsSize(s.AdditionalItems.Schema)
+	}
+	if s.UnevaluatedProperties != nil {
+		size += schemaExtensionsSize(s.UnevaluatedProperties.Schema)
+	}
+	if s.UnevaluatedItems != nil {
+		size += schemaExtensionsSize(s.UnevaluatedItems.Schema)
+	}
+	for _, d := range s.Dependencies {
+		size += schemaExtensionsSize(d.Schema)
+	}
+	return size
+}
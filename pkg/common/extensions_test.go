@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ext is an extension of size 4.
+const ext = `"x-a": 1`
+
+func TestSchemaExtensionsSize(t *testing.T) {
+	var s spec.Schema
+	if err := json.Unmarshal([]byte(`{`+ext+`,
+		"properties": {"p": {`+ext+`}},
+		"patternProperties": {"^p": {`+ext+`}},
+		"dependentSchemas": {"p": {`+ext+`}},
+		"definitions": {"d": {`+ext+`}},
+		"allOf": [{`+ext+`}],
+		"anyOf": [{`+ext+`}],
+		"oneOf": [{`+ext+`}],
+		"prefixItems": [{`+ext+`}],
+		"not": {`+ext+`},
+		"contentSchema": {`+ext+`},
+		"if": {`+ext+`},
+		"then": {`+ext+`},
+		"else": {`+ext+`},
+		"items": {`+ext+`},
+		"additionalProperties": {`+ext+`},
+		"additionalItems": {`+ext+`},
+		"unevaluatedProperties": {`+ext+`},
+		"unevaluatedItems": {`+ext+`},
+		"dependencies": {"p": {`+ext+`}}
+	}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := schemaExtensionsSize(&s), 20*4; got != want {
+		t.Errorf("expected size %d, got %d", want, got)
+	}
+}
+
+func TestOpenAPIV3ExtensionStats(t *testing.T) {
+	content := `{"application/json": {` + ext + `, "schema": {` + ext + `}}}`
+	response := `{` + ext + `, "description": "ok",
+		"headers": {"h": {` + ext + `, "schema": {` + ext + `}}},
+		"content": ` + content + `}`
+	var openapi spec3.OpenAPI
+	if err := json.Unmarshal([]byte(`{
+		"info": {`+ext+`, "title": "t", "version": "v"},
+		"components": {
+			"schemas": {"s": {`+ext+`, "properties": {"p": {`+ext+`}}}},
+			"parameters": {"p": {`+ext+`, "name": "p", "in": "query", "schema": {`+ext+`}}},
+			"requestBodies": {"b": {`+ext+`, "content": `+content+`}},
+			"responses": {"r": `+response+`}
+		},
+		"paths": {`+ext+`,
+			"/a": {`+ext+`,
+				"parameters": [{`+ext+`, "name": "q", "in": "query", "content": `+content+`}],
+				"get": {`+ext+`,
+					"parameters": [{`+ext+`, "name": "r", "in": "query", "schema": {`+ext+`}}],
+					"requestBody": {`+ext+`, "content": `+content+`},
+					"responses": {
+						"default": `+response+`,
+						"200": `+response+`
+					}
+				}
+			}
+		}
+	}`), &openapi); err != nil {
+		t.Fatal(err)
+	}
+	// Responses objects with extensions fail to unmarshal.
+	openapi.Paths.Paths["/a"].Get.Responses.Extensions = spec.Extensions{"x-a": 1}
+	stats := OpenAPIV3ExtensionStats(&openapi)
+	if got, want := stats.Definitions, map[string]int{"s": 2 * 4}; len(got) != 1 || got["s"] != want["s"] {
+		t.Errorf("expected definitions %v, got %v", want, got)
+	}
+	// info: 1, schemas: 2, parameters: 2, request bodies: 3, responses: 5,
+	// paths: 1, path: 1, path parameters: 3, operation: 1, operation
+	// parameters: 2, request body: 3, responses: 1 + 2*5.
+	if got, want := stats.Total, 35*4; got != want {
+		t.Errorf("expected total %d, got %d", want, got)
+	}
+}
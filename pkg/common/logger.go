@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	klog "k8s.io/klog/v2"
+)
+
+// Logger receives structured events from spec building, aggregation and serving.
+// keysAndValues are alternating keys and values, as in klog.InfoS.
+// It is satisfied by logr.Logger, and KlogLogger adapts the global klog logger.
+type Logger interface {
+	Info(msg string, keysAndValues ...interface{})
+	Error(err error, msg string, keysAndValues ...interface{})
+}
+
+// KlogLogger returns a Logger writing to klog, events at verbosity level v.
+// Errors are always written.
+func KlogLogger(v klog.Level) Logger {
+	return klogLogger{v: v}
+}
+
+type klogLogger struct {
+	v klog.Level
+}
+
+func (l klogLogger) Info(msg string, keysAndValues ...interface{}) {
+	klog.V(l.v).InfoS(msg, keysAndValues...)
+}
+
+func (l klogLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	klog.ErrorS(err, msg, keysAndValues...)
+}
+
+// NoopLogger discards all events.
+var NoopLogger Logger = noopLogger{}
+
+type noopLogger struct{}
+
+func (noopLogger) Info(string, ...interface{})         {}
+func (noopLogger) Error(error, string, ...interface{}) {}
+
+// LoggerOrDefault returns l, or the klog logger at verbosity 4 if l is nil.
+func LoggerOrDefault(l Logger) Logger {
+	if l == nil {
+		return KlogLogger(4)
+	}
+	return l
+}
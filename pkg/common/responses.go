@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+)
+
+// ResponsesMetadataKey is the key of the route metadata declaring the
+// responses of the operation, a []RouteResponse. They replace the responses
+// of the route and of Config.CommonResponses with the same status code.
+const ResponsesMetadataKey = "openapi.responses"
+
+// RouteResponse is a response declared by route metadata.
+type RouteResponse struct {
+	// Code is the HTTP status code of the response, 0 for the default
+	// response of the operation.
+	Code        int
+	Description string
+	// Model is a sample of the type of the body, e.g. an error envelope, or
+	// nil if the response has no body.
+	Model interface{}
+	// Headers are the headers of the response, by name.
+	Headers map[string]ResponseHeader
+}
+
+// ResponseHeader is a header of a RouteResponse.
+type ResponseHeader struct {
+	Description string
+	// DataType is the type of the value, e.g. "string" or "int64", as
+	// understood by OpenAPITypeFormat.
+	DataType string
+}
+
+// RouteResponses returns the responses declared by the metadata of the
+// route. It fails if the metadata is not a list of responses, if a status
+// code is not a valid HTTP status code or if a header is not of a simple
+// type.
+func RouteResponses(r *restful.Route) ([]RouteResponse, error) {
+	v, ok := r.Metadata[ResponsesMetadataKey]
+	if !ok {
+		return nil, nil
+	}
+	responses, ok := v.([]RouteResponse)
+	if !ok {
+		return nil, fmt.Errorf("route %s %s: metadata %s must be a []RouteResponse, got %T", r.Method, r.Path, ResponsesMetadataKey, v)
+	}
+	for _, resp := range responses {
+		if resp.Code != 0 && (resp.Code < 100 || resp.Code > 599) {
+			return nil, fmt.Errorf("route %s %s: invalid response status code %d", r.Method, r.Path, resp.Code)
+		}
+		for name, h := range resp.Headers {
+			if openAPIType, _ := OpenAPITypeFormat(h.DataType); openAPIType == "" {
+				return nil, fmt.Errorf("route %s %s: header %s of response %d should be a simple type, but got : %v", r.Method, r.Path, name, resp.Code, h.DataType)
+			}
+		}
+	}
+	return responses, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// The keys of the route metadata declaring the security of operations.
+const (
+	// SecuritySchemesMetadataKey declares the security schemes used by the
+	// route, a map[string]SecurityScheme by name. They are added to the
+	// security definitions of the spec.
+	SecuritySchemesMetadataKey = "openapi.securitySchemes"
+	// SecurityMetadataKey declares the security requirements of the
+	// operation, a []map[string][]string, overriding Config.DefaultSecurity.
+	// An empty list makes the operation not require any security.
+	SecurityMetadataKey = "openapi.security"
+)
+
+// SecuritySchemeType is the type of a SecurityScheme.
+type SecuritySchemeType string
+
+const (
+	// SecuritySchemeBearer is a bearer token of the Authorization header.
+	SecuritySchemeBearer SecuritySchemeType = "bearer"
+	// SecuritySchemeMutualTLS is a client certificate of a TLS connection.
+	// Swagger 2.0 has no counterpart, it is omitted from Swagger 2.0 specs.
+	SecuritySchemeMutualTLS SecuritySchemeType = "mutualTLS"
+)
+
+// SecurityScheme is a security scheme declared by route metadata, built as
+// a security definition of Swagger 2.0 specs and a security scheme of
+// OpenAPI v3 specs.
+type SecurityScheme struct {
+	Type        SecuritySchemeType
+	Description string
+	// BearerFormat hints the format of bearer tokens, e.g. "JWT".
+	BearerFormat string
+}
+
+// V2 returns the Swagger 2.0 security definition of the scheme, or false if
+// Swagger 2.0 cannot describe it. Bearer tokens are API keys of the
+// Authorization header.
+func (s SecurityScheme) V2() (*spec.SecurityScheme, bool) {
+	if s.Type != SecuritySchemeBearer {
+		return nil, false
+	}
+	return &spec.SecurityScheme{
+		SecuritySchemeProps: spec.SecuritySchemeProps{
+			Type:        "apiKey",
+			Description: s.Description,
+			Name:        "Authorization",
+			In:          "header",
+		},
+	}, true
+}
+
+// V3 returns the OpenAPI v3 security scheme of the scheme.
+func (s SecurityScheme) V3() *spec3.SecurityScheme {
+	ret := &spec3.SecurityScheme{
+		SecuritySchemeProps: spec3.SecuritySchemeProps{
+			Description: s.Description,
+		},
+	}
+	switch s.Type {
+	case SecuritySchemeBearer:
+		ret.Type = "http"
+		ret.Scheme = "bearer"
+		ret.BearerFormat = s.BearerFormat
+	default:
+		ret.Type = string(s.Type)
+	}
+	return ret
+}
+
+// RouteSecuritySchemes returns the security schemes declared by the metadata
+// of the route. It fails if the metadata is not a map of schemes of known
+// types.
+func RouteSecuritySchemes(r *restful.Route) (map[string]SecurityScheme, error) {
+	v, ok := r.Metadata[SecuritySchemesMetadataKey]
+	if !ok {
+		return nil, nil
+	}
+	schemes, ok := v.(map[string]SecurityScheme)
+	if !ok {
+		return nil, fmt.Errorf("route %s %s: metadata %s must be a map[string]SecurityScheme, got %T", r.Method, r.Path, SecuritySchemesMetadataKey, v)
+	}
+	for name, s := range schemes {
+		switch s.Type {
+		case SecuritySchemeBearer, SecuritySchemeMutualTLS:
+		default:
+			return nil, fmt.Errorf("route %s %s: security scheme %q has unknown type %q", r.Method, r.Path, name, s.Type)
+		}
+	}
+	return schemes, nil
+}
+
+// RouteSecurity returns the security requirements declared by the metadata
+// of the route, and whether they are declared. It fails if the metadata is
+// not a list of requirements.
+func RouteSecurity(r *restful.Route) ([]map[string][]string, bool, error) {
+	v, ok := r.Metadata[SecurityMetadataKey]
+	if !ok {
+		return nil, false, nil
+	}
+	security, ok := v.([]map[string][]string)
+	if !ok {
+		return nil, false, fmt.Errorf("route %s %s: metadata %s must be a []map[string][]string, got %T", r.Method, r.Path, SecurityMetadataKey, v)
+	}
+	if security == nil {
+		security = []map[string][]string{}
+	}
+	return security, true, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+)
+
+// CallbacksMetadataKey is the key of the route metadata declaring the
+// callbacks of the operation, a map[string]Callback by name, e.g. the
+// admission reviews sent to the webhooks registered by the operation.
+const CallbacksMetadataKey = "openapi.callbacks"
+
+// Webhook describes the requests sent to a webhook.
+type Webhook struct {
+	OperationID string
+	Description string
+	// Request is a sample of the type of the body sent to the webhook,
+	// e.g. an AdmissionReview.
+	Request interface{}
+	// Response is a sample of the type of the body the webhook responds
+	// with, or nil if it has no body.
+	Response interface{}
+}
+
+// Callback describes the requests sent to a webhook registered by an
+// operation.
+type Callback struct {
+	// Expression is the runtime expression evaluating to the URL of the
+	// webhook, e.g. "{$request.body#/webhooks/0/clientConfig/url}".
+	Expression string
+	Webhook
+}
+
+// RouteCallbacks returns the callbacks declared by the metadata of the
+// route. It fails if the metadata is not a map of callbacks with an
+// expression.
+func RouteCallbacks(r *restful.Route) (map[string]Callback, error) {
+	v, ok := r.Metadata[CallbacksMetadataKey]
+	if !ok {
+		return nil, nil
+	}
+	callbacks, ok := v.(map[string]Callback)
+	if !ok {
+		return nil, fmt.Errorf("route %s %s: metadata %s must be a map[string]Callback, got %T", r.Method, r.Path, CallbacksMetadataKey, v)
+	}
+	for name, c := range callbacks {
+		if c.Expression == "" {
+			return nil, fmt.Errorf("route %s %s: callback %q has no expression", r.Method, r.Path, name)
+		}
+	}
+	return callbacks, nil
+}
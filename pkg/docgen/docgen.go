@@ -0,0 +1,331 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package docgen generates human-readable field reference documentation,
+// in Markdown or HTML, from OpenAPI schemas.
+package docgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	definitionPrefix   = "#/definitions/"
+	validationsExtName = "x-kubernetes-validations"
+)
+
+// Format is an output format of the documentation.
+type Format int
+
+const (
+	// Markdown renders GitHub flavored Markdown tables.
+	Markdown Format = iota
+	// HTML renders an HTML fragment.
+	HTML
+)
+
+// Rule is a CEL validation rule declared with x-kubernetes-validations.
+type Rule struct {
+	Rule    string
+	Message string
+}
+
+// Field describes one field of a schema.
+type Field struct {
+	// Path is the dotted path of the field from the documented schema.
+	// Array items are marked with "[]" and map values with ".*".
+	Path string
+	Type string
+	// Ref is the name of the definition the field refers to, if any.
+	Ref         string
+	Description string
+	Required    bool
+	Default     string
+	// Constraints are the validations of the field, e.g. "maxLength: 63".
+	Constraints []string
+	Rules       []Rule
+}
+
+// Section documents one schema, e.g. one definition of a document.
+type Section struct {
+	Name        string
+	Description string
+	Type        string
+	Constraints []string
+	Rules       []Rule
+	Fields      []Field
+}
+
+// SchemaSection builds the documentation section of s. Fields referring to
+// other definitions are not expanded: their Ref is set instead.
+func SchemaSection(name string, s *spec.Schema) Section {
+	sec := Section{
+		Name:        name,
+		Description: s.Description,
+		Type:        typeName(s),
+		Constraints: constraints(s),
+		Rules:       rules(s),
+	}
+	collectChildFields(&sec.Fields, "", s)
+	return sec
+}
+
+// DocumentSections builds one section per definition of doc, sorted by name.
+func DocumentSections(doc *spec.Swagger) []Section {
+	names := make([]string, 0, len(doc.Definitions))
+	for name := range doc.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sections := make([]Section, 0, len(names))
+	for _, name := range names {
+		s := doc.Definitions[name]
+		sections = append(sections, SchemaSection(name, &s))
+	}
+	return sections
+}
+
+// WriteSchema writes the documentation of s to w.
+func WriteSchema(w io.Writer, format Format, name string, s *spec.Schema) error {
+	return Write(w, format, []Section{SchemaSection(name, s)})
+}
+
+// WriteDocument writes the documentation of every definition of doc to w.
+func WriteDocument(w io.Writer, format Format, doc *spec.Swagger) error {
+	return Write(w, format, DocumentSections(doc))
+}
+
+// Write renders sections to w.
+func Write(w io.Writer, format Format, sections []Section) error {
+	switch format {
+	case Markdown:
+		return writeMarkdown(w, sections)
+	case HTML:
+		return writeHTML(w, sections)
+	default:
+		return fmt.Errorf("unknown documentation format %d", format)
+	}
+}
+
+func collectFields(fields *[]Field, prefix string, s *spec.Schema) {
+	if s == nil || s.Ref.String() != "" {
+		return
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	for _, name := range names {
+		prop := s.Properties[name]
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		*fields = append(*fields, newField(path, &prop, required[name]))
+		collectChildFields(fields, path, &prop)
+	}
+}
+
+// collectChildFields documents the fields nested in s, through arrays and maps.
+func collectChildFields(fields *[]Field, path string, s *spec.Schema) {
+	switch {
+	case s.Items != nil && s.Items.Schema != nil:
+		collectFields(fields, path+"[]", s.Items.Schema)
+	case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+		mapPath := "*"
+		if path != "" {
+			mapPath = path + ".*"
+		}
+		collectFields(fields, mapPath, s.AdditionalProperties.Schema)
+	default:
+		collectFields(fields, path, s)
+	}
+}
+
+func newField(path string, s *spec.Schema, required bool) Field {
+	f := Field{
+		Path:        path,
+		Type:        typeName(s),
+		Ref:         refName(s),
+		Description: s.Description,
+		Required:    required,
+		Constraints: constraints(s),
+		Rules:       rules(s),
+	}
+	if s.Default != nil {
+		if b, err := json.Marshal(s.Default); err == nil {
+			f.Default = string(b)
+		}
+	}
+	return f
+}
+
+// refName returns the definition s, or its items or values, refers to.
+func refName(s *spec.Schema) string {
+	for s != nil {
+		if ref := s.Ref.String(); ref != "" {
+			return strings.TrimPrefix(ref, definitionPrefix)
+		}
+		switch {
+		case s.Items != nil && s.Items.Schema != nil:
+			s = s.Items.Schema
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+			s = s.AdditionalProperties.Schema
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func typeName(s *spec.Schema) string {
+	if s == nil {
+		return "any"
+	}
+	if ref := s.Ref.String(); ref != "" {
+		return strings.TrimPrefix(ref, definitionPrefix)
+	}
+	switch {
+	case s.Type.Contains("array"):
+		if s.Items != nil && s.Items.Schema != nil {
+			return "array of " + typeName(s.Items.Schema)
+		}
+		return "array"
+	case s.Type.Contains("object") || (len(s.Type) == 0 && s.AdditionalProperties != nil):
+		if len(s.Properties) == 0 && s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			return "map of " + typeName(s.AdditionalProperties.Schema)
+		}
+		return "object"
+	case len(s.Type) > 0:
+		t := strings.Join(s.Type, " or ")
+		if s.Format != "" {
+			t += " (" + s.Format + ")"
+		}
+		return t
+	}
+	if v, ok := s.Extensions.GetBool("x-kubernetes-int-or-string"); ok && v {
+		return "integer or string"
+	}
+	return "any"
+}
+
+func constraints(s *spec.Schema) []string {
+	var c []string
+	if s.Nullable {
+		c = append(c, "nullable")
+	}
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum {
+			c = append(c, fmt.Sprintf("exclusiveMinimum: %v", *s.Minimum))
+		} else {
+			c = append(c, fmt.Sprintf("minimum: %v", *s.Minimum))
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum {
+			c = append(c, fmt.Sprintf("exclusiveMaximum: %v", *s.Maximum))
+		} else {
+			c = append(c, fmt.Sprintf("maximum: %v", *s.Maximum))
+		}
+	}
+	if s.MultipleOf != nil {
+		c = append(c, fmt.Sprintf("multipleOf: %v", *s.MultipleOf))
+	}
+	if s.MinLength != nil {
+		c = append(c, fmt.Sprintf("minLength: %d", *s.MinLength))
+	}
+	if s.MaxLength != nil {
+		c = append(c, fmt.Sprintf("maxLength: %d", *s.MaxLength))
+	}
+	if s.Pattern != "" {
+		c = append(c, fmt.Sprintf("pattern: %s", s.Pattern))
+	}
+	if s.MinItems != nil {
+		c = append(c, fmt.Sprintf("minItems: %d", *s.MinItems))
+	}
+	if s.MaxItems != nil {
+		c = append(c, fmt.Sprintf("maxItems: %d", *s.MaxItems))
+	}
+	if s.UniqueItems {
+		c = append(c, "uniqueItems")
+	}
+	if s.MinProperties != nil {
+		c = append(c, fmt.Sprintf("minProperties: %d", *s.MinProperties))
+	}
+	if s.MaxProperties != nil {
+		c = append(c, fmt.Sprintf("maxProperties: %d", *s.MaxProperties))
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			b, _ := json.Marshal(v)
+			values = append(values, string(b))
+		}
+		c = append(c, "enum: "+strings.Join(values, ", "))
+	}
+	if listType, ok := s.Extensions.GetString("x-kubernetes-list-type"); ok {
+		c = append(c, "listType: "+listType)
+	}
+	if keys, ok := s.Extensions.GetStringSlice("x-kubernetes-list-map-keys"); ok {
+		c = append(c, "listMapKeys: "+strings.Join(keys, ", "))
+	}
+	if mapType, ok := s.Extensions.GetString("x-kubernetes-map-type"); ok {
+		c = append(c, "mapType: "+mapType)
+	}
+	return c
+}
+
+// rules reads x-kubernetes-validations, made either of rule strings or of
+// objects with rule and message.
+func rules(s *spec.Schema) []Rule {
+	v, ok := s.Extensions[validationsExtName]
+	if !ok {
+		return nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	var ret []Rule
+	for _, item := range list {
+		switch item := item.(type) {
+		case string:
+			ret = append(ret, Rule{Rule: item})
+		case map[string]interface{}:
+			r := Rule{}
+			r.Rule, _ = item["rule"].(string)
+			r.Message, _ = item["message"].(string)
+			if r.Rule != "" {
+				ret = append(ret, r)
+			}
+		}
+	}
+	return ret
+}
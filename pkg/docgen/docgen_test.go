@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const testDocument = `{
+	"swagger": "2.0",
+	"definitions": {
+		"Bar": {
+			"description": "Bar is a port.",
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "maxLength": 15},
+				"port": {"type": "integer", "format": "int32", "default": 80, "minimum": 1}
+			}
+		},
+		"Foo": {
+			"type": "object",
+			"x-kubernetes-validations": [{"rule": "self.min <= self.max", "message": "min must not exceed max"}],
+			"properties": {
+				"min": {"type": "integer"},
+				"max": {"type": "integer"},
+				"mode": {"type": "string", "enum": ["a", "b"], "description": "Mode | kind."},
+				"bars": {
+					"type": "array",
+					"x-kubernetes-list-type": "map",
+					"x-kubernetes-list-map-keys": ["name"],
+					"items": {"$ref": "#/definitions/Bar"}
+				},
+				"labels": {
+					"type": "object",
+					"additionalProperties": {
+						"type": "object",
+						"properties": {"value": {"type": "string", "x-kubernetes-validations": ["self != ''"]}}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func loadTestDocument(t *testing.T) *spec.Swagger {
+	doc := &spec.Swagger{}
+	require.NoError(t, json.Unmarshal([]byte(testDocument), doc))
+	return doc
+}
+
+func TestDocumentSections(t *testing.T) {
+	sections := DocumentSections(loadTestDocument(t))
+	require.Len(t, sections, 2)
+	assert.Equal(t, "Bar", sections[0].Name)
+
+	foo := sections[1]
+	assert.Equal(t, []Rule{{Rule: "self.min <= self.max", Message: "min must not exceed max"}}, foo.Rules)
+
+	var paths []string
+	for _, f := range foo.Fields {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"bars", "labels", "labels.*.value", "max", "min", "mode"}, paths)
+	assert.Equal(t, "array of Bar", foo.Fields[0].Type)
+	assert.Equal(t, "Bar", foo.Fields[0].Ref)
+	assert.Equal(t, []string{"listType: map", "listMapKeys: name"}, foo.Fields[0].Constraints)
+	assert.Equal(t, "map of object", foo.Fields[1].Type)
+	assert.Equal(t, []Rule{{Rule: "self != ''"}}, foo.Fields[2].Rules)
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	doc := loadTestDocument(t)
+	delete(doc.Definitions, "Foo")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDocument(&buf, Markdown, doc))
+	assert.Equal(t, "<a id=\"bar\"></a>\n\n"+
+		"## Bar\n\n"+
+		"Bar is a port.\n\n"+
+		"Type: `object`\n\n"+
+		"| Field | Type | Required | Description |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| `name` | string | yes | maxLength: 15 |\n"+
+		"| `port` | integer (int32) |  | Default: `80`<br>minimum: 1 |\n", buf.String())
+}
+
+func TestWriteEscaping(t *testing.T) {
+	doc := loadTestDocument(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteDocument(&buf, Markdown, doc))
+	assert.Contains(t, buf.String(), "| `bars` | array of [Bar](#bar) |  | listType: map<br>listMapKeys: name |")
+	assert.Contains(t, buf.String(), "Mode \\| kind.")
+	assert.Contains(t, buf.String(), "- Rule: `self.min <= self.max` (min must not exceed max)")
+
+	buf.Reset()
+	require.NoError(t, WriteDocument(&buf, HTML, doc))
+	assert.Contains(t, buf.String(), `<h2 id="foo">Foo</h2>`)
+	assert.Contains(t, buf.String(), `<td>array of <a href="#bar">Bar</a></td>`)
+	assert.Contains(t, buf.String(), "<li>Rule: <code>self.min &lt;= self.max</code> (min must not exceed max)</li>")
+
+	assert.Error(t, WriteDocument(&buf, Format(42), doc))
+}
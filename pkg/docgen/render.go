@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docgen
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// anchor is the HTML id of the section documenting a definition.
+func anchor(name string) string {
+	return strings.ToLower(strings.NewReplacer("/", "-", " ", "-", "~", "-").Replace(name))
+}
+
+func writeMarkdown(out io.Writer, sections []Section) error {
+	w := bufio.NewWriter(out)
+	known := sectionNames(sections)
+
+	for i, sec := range sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "<a id=%q></a>\n\n## %s\n\n", anchor(sec.Name), sec.Name)
+		if sec.Description != "" {
+			fmt.Fprintf(w, "%s\n\n", sec.Description)
+		}
+		fmt.Fprintf(w, "Type: `%s`\n", sec.Type)
+		for _, c := range sec.Constraints {
+			fmt.Fprintf(w, "\n- %s", c)
+		}
+		for _, r := range sec.Rules {
+			fmt.Fprintf(w, "\n- %s", markdownRule(r))
+		}
+		if len(sec.Constraints)+len(sec.Rules) > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(sec.Fields) == 0 {
+			continue
+		}
+
+		fmt.Fprint(w, "\n| Field | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+		for _, f := range sec.Fields {
+			typ := markdownCell(f.Type)
+			if f.Ref != "" && known[f.Ref] {
+				typ = strings.Replace(typ, markdownCell(f.Ref), fmt.Sprintf("[%s](#%s)", markdownCell(f.Ref), anchor(f.Ref)), 1)
+			}
+			required := ""
+			if f.Required {
+				required = "yes"
+			}
+
+			var details []string
+			if f.Description != "" {
+				details = append(details, markdownCell(f.Description))
+			}
+			if f.Default != "" {
+				details = append(details, fmt.Sprintf("Default: `%s`", markdownCell(f.Default)))
+			}
+			for _, c := range f.Constraints {
+				details = append(details, markdownCell(c))
+			}
+			for _, r := range f.Rules {
+				details = append(details, markdownCell(markdownRule(r)))
+			}
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", f.Path, typ, required, strings.Join(details, "<br>"))
+		}
+	}
+	return w.Flush()
+}
+
+func markdownRule(r Rule) string {
+	if r.Message == "" {
+		return fmt.Sprintf("Rule: `%s`", r.Rule)
+	}
+	return fmt.Sprintf("Rule: `%s` (%s)", r.Rule, r.Message)
+}
+
+// markdownCell escapes s for use in a table cell.
+func markdownCell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(strings.TrimSpace(s), "\n", "<br>", -1)
+}
+
+func writeHTML(out io.Writer, sections []Section) error {
+	w := bufio.NewWriter(out)
+	known := sectionNames(sections)
+	esc := html.EscapeString
+
+	for _, sec := range sections {
+		fmt.Fprintf(w, "<h2 id=\"%s\">%s</h2>\n", esc(anchor(sec.Name)), esc(sec.Name))
+		if sec.Description != "" {
+			fmt.Fprintf(w, "<p>%s</p>\n", esc(sec.Description))
+		}
+		fmt.Fprintf(w, "<p>Type: <code>%s</code></p>\n", esc(sec.Type))
+		if len(sec.Constraints)+len(sec.Rules) > 0 {
+			fmt.Fprintln(w, "<ul>")
+			for _, c := range sec.Constraints {
+				fmt.Fprintf(w, "<li>%s</li>\n", esc(c))
+			}
+			for _, r := range sec.Rules {
+				fmt.Fprintf(w, "<li>%s</li>\n", htmlRule(r))
+			}
+			fmt.Fprintln(w, "</ul>")
+		}
+		if len(sec.Fields) == 0 {
+			continue
+		}
+
+		fmt.Fprintln(w, "<table>")
+		fmt.Fprintln(w, "<thead><tr><th>Field</th><th>Type</th><th>Required</th><th>Description</th></tr></thead>")
+		fmt.Fprintln(w, "<tbody>")
+		for _, f := range sec.Fields {
+			typ := esc(f.Type)
+			if f.Ref != "" && known[f.Ref] {
+				typ = strings.Replace(typ, esc(f.Ref), fmt.Sprintf("<a href=\"#%s\">%s</a>", esc(anchor(f.Ref)), esc(f.Ref)), 1)
+			}
+			required := ""
+			if f.Required {
+				required = "yes"
+			}
+
+			var details []string
+			if f.Description != "" {
+				details = append(details, esc(f.Description))
+			}
+			if f.Default != "" {
+				details = append(details, fmt.Sprintf("Default: <code>%s</code>", esc(f.Default)))
+			}
+			for _, c := range f.Constraints {
+				details = append(details, esc(c))
+			}
+			for _, r := range f.Rules {
+				details = append(details, htmlRule(r))
+			}
+			fmt.Fprintf(w, "<tr><td><code>%s</code></td><td>%s</td><td>%s</td><td>%s</td></tr>\n", esc(f.Path), typ, required, strings.Join(details, "<br>"))
+		}
+		fmt.Fprintln(w, "</tbody>")
+		fmt.Fprintln(w, "</table>")
+	}
+	return w.Flush()
+}
+
+func htmlRule(r Rule) string {
+	if r.Message == "" {
+		return fmt.Sprintf("Rule: <code>%s</code>", html.EscapeString(r.Rule))
+	}
+	return fmt.Sprintf("Rule: <code>%s</code> (%s)", html.EscapeString(r.Rule), html.EscapeString(r.Message))
+}
+
+func sectionNames(sections []Section) map[string]bool {
+	names := make(map[string]bool, len(sections))
+	for _, sec := range sections {
+		names[sec.Name] = true
+	}
+	return names
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package golden compares OpenAPI definitions, such as those generated by
+// openapi-gen, against golden files, to detect accidental schema changes.
+//
+// A typical test is:
+//
+//	func TestOpenAPIDefinitions(t *testing.T) {
+//		golden.CheckDefinitions(t, "testdata/definitions.json", generated.GetOpenAPIDefinitions)
+//	}
+//
+// Run the tests with UPDATE_OPENAPI_GOLDEN=true to write the golden files.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// UpdateEnvVar is the environment variable which, when set to "true", makes
+// Check write the golden files instead of comparing against them.
+const UpdateEnvVar = "UPDATE_OPENAPI_GOLDEN"
+
+// TestingT is the subset of testing.TB used by Check.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// RenderDefinitions renders the definitions returned by getDefinitions as
+// canonical JSON, with references to "#/definitions/<name>".
+func RenderDefinitions(getDefinitions common.GetOpenAPIDefinitions) ([]byte, error) {
+	return Render(getDefinitions(func(name string) spec.Ref {
+		return spec.MustCreateRef("#/definitions/" + common.EscapeJsonPointer(name))
+	}))
+}
+
+// Render renders definitions as canonical JSON: an object mapping each name
+// to its schema and sorted dependencies, with sorted keys and indentation.
+func Render(definitions map[string]common.OpenAPIDefinition) ([]byte, error) {
+	type definition struct {
+		Schema       spec.Schema `json:"schema"`
+		Dependencies []string    `json:"dependencies,omitempty"`
+	}
+	out := make(map[string]definition, len(definitions))
+	for name, def := range definitions {
+		deps := append([]string(nil), def.Dependencies...)
+		sort.Strings(deps)
+		out[name] = definition{Schema: def.Schema, Dependencies: deps}
+	}
+	return Canonicalize(out)
+}
+
+// Canonicalize renders v as JSON with sorted keys and indentation, so that
+// equal documents are rendered identically.
+func Canonicalize(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// round-trip through generic values so that all object keys are sorted,
+	// including those written by custom marshalers
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CheckDefinitions renders the definitions returned by getDefinitions and
+// compares them against the golden file at path, as Check.
+func CheckDefinitions(t TestingT, path string, getDefinitions common.GetOpenAPIDefinitions) {
+	t.Helper()
+	got, err := RenderDefinitions(getDefinitions)
+	if err != nil {
+		t.Fatalf("failed to render definitions: %v", err)
+		return
+	}
+	Check(t, path, got)
+}
+
+// Check compares got against the golden file at path, and reports the
+// differences as JSON pointers to the changed values. If UpdateEnvVar is set
+// to "true", it writes got to path instead. It returns after t.Fatalf, so
+// that t may record failures instead of stopping the test.
+func Check(t TestingT, path string, got []byte) {
+	t.Helper()
+	if os.Getenv(UpdateEnvVar) == "true" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+			return
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with %s=true to create it): %v", UpdateEnvVar, err)
+		return
+	}
+	diffs, err := Diff(want, got)
+	if err != nil {
+		t.Fatalf("failed to compare with golden file %s: %v", path, err)
+		return
+	}
+	if len(diffs) == 0 {
+		return
+	}
+	lines := make([]string, len(diffs))
+	for i, d := range diffs {
+		lines[i] = d.String()
+	}
+	t.Errorf("definitions differ from golden file %s (run with %s=true to update it):\n%s", path, UpdateEnvVar, strings.Join(lines, "\n"))
+}
+
+// Difference is a difference between two JSON documents.
+type Difference struct {
+	// Path is the JSON pointer to the differing value.
+	Path string
+	// Want and Got are the values in each document. Want is nil for added
+	// values and Got is nil for removed values.
+	Want, Got interface{}
+	// Added and Removed tell missing values apart from null values.
+	Added, Removed bool
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.Added:
+		return fmt.Sprintf("%s: added %s", d.Path, compact(d.Got))
+	case d.Removed:
+		return fmt.Sprintf("%s: removed %s", d.Path, compact(d.Want))
+	}
+	return fmt.Sprintf("%s: changed from %s to %s", d.Path, compact(d.Want), compact(d.Got))
+}
+
+func compact(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// Diff returns the differences between the JSON documents want and got,
+// sorted by path. Arrays are compared element by element.
+func Diff(want, got []byte) ([]Difference, error) {
+	var w, g interface{}
+	if err := json.Unmarshal(want, &w); err != nil {
+		return nil, fmt.Errorf("invalid golden document: %v", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return nil, fmt.Errorf("invalid document: %v", err)
+	}
+	var diffs []Difference
+	diff("", w, g, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+func diff(path string, want, got interface{}, diffs *[]Difference) {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for k, wv := range w {
+			p := path + "/" + common.EscapeJsonPointer(k)
+			if gv, found := g[k]; found {
+				diff(p, wv, gv, diffs)
+			} else {
+				*diffs = append(*diffs, Difference{Path: p, Want: wv, Removed: true})
+			}
+		}
+		for k, gv := range g {
+			if _, found := w[k]; !found {
+				*diffs = append(*diffs, Difference{Path: path + "/" + common.EscapeJsonPointer(k), Got: gv, Added: true})
+			}
+		}
+		return
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			break
+		}
+		for i := range w {
+			p := path + "/" + strconv.Itoa(i)
+			if i < len(g) {
+				diff(p, w[i], g[i], diffs)
+			} else {
+				*diffs = append(*diffs, Difference{Path: p, Want: w[i], Removed: true})
+			}
+		}
+		for i := len(w); i < len(g); i++ {
+			*diffs = append(*diffs, Difference{Path: path + "/" + strconv.Itoa(i), Got: g[i], Added: true})
+		}
+		return
+	default:
+		if want == got {
+			return
+		}
+	}
+	*diffs = append(*diffs, Difference{Path: path, Want: want, Got: got})
+}
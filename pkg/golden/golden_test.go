@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golden
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func getTestDefinitions(maxLength int64) common.GetOpenAPIDefinitions {
+	return func(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
+		return map[string]common.OpenAPIDefinition{
+			"example.com/pkg.Foo": {
+				Schema: spec.Schema{SchemaProps: spec.SchemaProps{
+					Type: []string{"object"},
+					Properties: map[string]spec.Schema{
+						"name": {SchemaProps: spec.SchemaProps{Type: []string{"string"}, MaxLength: &maxLength}},
+						"bar":  {SchemaProps: spec.SchemaProps{Ref: ref("example.com/pkg.Bar")}},
+					},
+				}},
+				Dependencies: []string{"example.com/pkg.Bar"},
+			},
+			"example.com/pkg.Bar": {
+				Schema: spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"string"}, Enum: []interface{}{"a", "b"}}},
+			},
+		}
+	}
+}
+
+// recordingT records the failures of Check.
+type recordingT struct {
+	errors, fatals []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...interface{}) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+}
+
+func TestCheckDefinitions(t *testing.T) {
+	CheckDefinitions(t, "testdata/definitions.json", getTestDefinitions(10))
+}
+
+func TestCheckDefinitionsChanged(t *testing.T) {
+	rt := &recordingT{}
+	CheckDefinitions(rt, "testdata/definitions.json", getTestDefinitions(20))
+	require.Len(t, rt.errors, 1)
+	assert.Contains(t, rt.errors[0], "/example.com~1pkg.Foo/schema/properties/name/maxLength: changed from 10 to 20")
+	assert.Empty(t, rt.fatals)
+
+	rt = &recordingT{}
+	CheckDefinitions(rt, "testdata/missing.json", getTestDefinitions(10))
+	require.Len(t, rt.fatals, 1)
+	assert.Contains(t, rt.fatals[0], UpdateEnvVar)
+}
+
+func TestCheckUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub", "definitions.json")
+
+	os.Setenv(UpdateEnvVar, "true")
+	CheckDefinitions(t, path, getTestDefinitions(10))
+	os.Unsetenv(UpdateEnvVar)
+
+	written, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	want, err := ioutil.ReadFile("testdata/definitions.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(written))
+}
+
+func TestDiff(t *testing.T) {
+	diffs, err := Diff(
+		[]byte(`{"a": 1, "b": {"c": [1, 2, 3]}, "d": null, "e": "x"}`),
+		[]byte(`{"a": 1, "b": {"c": [1, 4]}, "e": {"f": true}, "g": null}`),
+	)
+	require.NoError(t, err)
+	var lines []string
+	for _, d := range diffs {
+		lines = append(lines, d.String())
+	}
+	assert.Equal(t, []string{
+		"/b/c/1: changed from 2 to 4",
+		"/b/c/2: removed 3",
+		"/d: removed null",
+		`/e: changed from "x" to {"f":true}`,
+		"/g: added null",
+	}, lines)
+
+	_, err = Diff([]byte(`{`), []byte(`{}`))
+	assert.Error(t, err)
+}
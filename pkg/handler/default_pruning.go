@@ -104,6 +104,21 @@ func PruneDefaultsSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	dependentSchemasCloned := false
+	for k, v := range schema.DependentSchemas {
+		if s := PruneDefaultsSchema(&v); s != &v {
+			if !dependentSchemasCloned {
+				dependentSchemasCloned = true
+				clone()
+				schema.DependentSchemas = make(map[string]spec.Schema, len(orig.DependentSchemas))
+				for k2, v2 := range orig.DependentSchemas {
+					schema.DependentSchemas[k2] = v2
+				}
+			}
+			schema.DependentSchemas[k] = *s
+		}
+	}
+
 	dependenciesCloned := false
 	for k, v := range schema.Dependencies {
 		if s := PruneDefaultsSchema(v.Schema); s != v.Schema {
@@ -159,6 +174,19 @@ func PruneDefaultsSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	prefixItemsCloned := false
+	for i := range schema.PrefixItems {
+		if s := PruneDefaultsSchema(&schema.PrefixItems[i]); s != &schema.PrefixItems[i] {
+			if !prefixItemsCloned {
+				prefixItemsCloned = true
+				clone()
+				schema.PrefixItems = make([]spec.Schema, len(orig.PrefixItems))
+				copy(schema.PrefixItems, orig.PrefixItems)
+			}
+			schema.PrefixItems[i] = *s
+		}
+	}
+
 	if schema.Not != nil {
 		if s := PruneDefaultsSchema(schema.Not); s != schema.Not {
 			clone()
@@ -166,6 +194,34 @@ func PruneDefaultsSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	if schema.ContentSchema != nil {
+		if s := PruneDefaultsSchema(schema.ContentSchema); s != schema.ContentSchema {
+			clone()
+			schema.ContentSchema = s
+		}
+	}
+
+	if schema.If != nil {
+		if s := PruneDefaultsSchema(schema.If); s != schema.If {
+			clone()
+			schema.If = s
+		}
+	}
+
+	if schema.Then != nil {
+		if s := PruneDefaultsSchema(schema.Then); s != schema.Then {
+			clone()
+			schema.Then = s
+		}
+	}
+
+	if schema.Else != nil {
+		if s := PruneDefaultsSchema(schema.Else); s != schema.Else {
+			clone()
+			schema.Else = s
+		}
+	}
+
 	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
 		if s := PruneDefaultsSchema(schema.AdditionalProperties.Schema); s != schema.AdditionalProperties.Schema {
 			clone()
@@ -180,6 +236,20 @@ func PruneDefaultsSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	if schema.UnevaluatedProperties != nil && schema.UnevaluatedProperties.Schema != nil {
+		if s := PruneDefaultsSchema(schema.UnevaluatedProperties.Schema); s != schema.UnevaluatedProperties.Schema {
+			clone()
+			schema.UnevaluatedProperties = &spec.SchemaOrBool{Schema: s, Allows: schema.UnevaluatedProperties.Allows}
+		}
+	}
+
+	if schema.UnevaluatedItems != nil && schema.UnevaluatedItems.Schema != nil {
+		if s := PruneDefaultsSchema(schema.UnevaluatedItems.Schema); s != schema.UnevaluatedItems.Schema {
+			clone()
+			schema.UnevaluatedItems = &spec.SchemaOrBool{Schema: s, Allows: schema.UnevaluatedItems.Allows}
+		}
+	}
+
 	if schema.Items != nil {
 		if schema.Items.Schema != nil {
 			if s := PruneDefaultsSchema(schema.Items.Schema); s != schema.Items.Schema {
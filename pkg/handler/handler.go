@@ -27,15 +27,14 @@ import (
 	"sync"
 	"time"
 
-	"github.com/NYTimes/gziphandler"
 	"github.com/emicklei/go-restful"
 	"github.com/golang/protobuf/proto"
 	openapi_v2 "github.com/googleapis/gnostic/openapiv2"
 	"github.com/munnerz/goautoneg"
 	"gopkg.in/yaml.v2"
-	klog "k8s.io/klog/v2"
 	"k8s.io/kube-openapi/pkg/builder"
 	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/internal/compression"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
@@ -54,31 +53,74 @@ type OpenAPIService struct {
 	// rwMutex protects All members of this service.
 	rwMutex sync.RWMutex
 
-	lastModified time.Time
+	jsonCache  cache
+	protoCache cache
+
+	// filter selects the variant of the spec served for each request, and
+	// variants caches the variants by key.
+	filter   *common.SpecFilter
+	variants map[string]*variant
+
+	logger common.Logger
+}
 
+// variant is a filtered spec, cached as the spec it is derived from.
+type variant struct {
 	jsonCache  cache
 	protoCache cache
 }
 
 type cache struct {
 	BuildCache func() ([]byte, error)
-	once       sync.Once
-	bytes      []byte
-	etag       string
-	err        error
+	// buildWithETag builds the cache with its ETag, instead of BuildCache if set.
+	buildWithETag func() ([]byte, string, error)
+	once          sync.Once
+	bytes         []byte
+	encoded       *compression.Bytes
+	etag          string
+	err           error
+	// updated is the time of the update which created the cache, and
+	// lastModified the one of the last update changing the bytes.
+	updated      time.Time
+	lastModified time.Time
+
+	// format and logger are used to report cache builds, if logger is set.
+	format string
+	logger common.Logger
 }
 
 func (c *cache) Get() ([]byte, string, error) {
 	c.once.Do(func() {
-		bytes, err := c.BuildCache()
+		start := time.Now()
+		var bytes []byte
+		var etag string
+		var err error
+		if c.buildWithETag != nil {
+			bytes, etag, err = c.buildWithETag()
+		} else {
+			bytes, err = c.BuildCache()
+			etag = computeETag(bytes)
+		}
 		// if there is an error updating the cache, there can be situations where
 		// c.bytes contains a valid value (carried over from the previous update)
 		// but c.err is also not nil; the cache user is expected to check for this
 		c.err = err
 		if c.err == nil {
 			// don't override previous spec if we had an error
+			if etag != c.etag || c.lastModified.IsZero() {
+				c.lastModified = c.updated
+			}
 			c.bytes = bytes
-			c.etag = computeETag(c.bytes)
+			c.encoded = compression.New(bytes)
+			c.etag = etag
+		}
+		if c.logger == nil {
+			return
+		}
+		if err != nil {
+			c.logger.Error(err, "Failed to serialize OpenAPI spec", "format", c.format)
+		} else {
+			c.logger.Info("Serialized OpenAPI spec", "format", c.format, "size", len(bytes), "digest", c.etag, "duration", time.Since(start))
 		}
 	})
 	return c.bytes, c.etag, c.err
@@ -86,9 +128,22 @@ func (c *cache) Get() ([]byte, string, error) {
 
 func (c *cache) New(cacheBuilder func() ([]byte, error)) cache {
 	return cache{
-		bytes:      c.bytes,
-		etag:       c.etag,
-		BuildCache: cacheBuilder,
+		bytes:        c.bytes,
+		encoded:      c.encoded,
+		etag:         c.etag,
+		lastModified: c.lastModified,
+		BuildCache:   cacheBuilder,
+	}
+}
+
+// newWithETag is like New for a cache built with its ETag.
+func (c *cache) newWithETag(cacheBuilder func() ([]byte, string, error)) cache {
+	return cache{
+		bytes:         c.bytes,
+		encoded:       c.encoded,
+		etag:          c.etag,
+		lastModified:  c.lastModified,
+		buildWithETag: cacheBuilder,
 	}
 }
 
@@ -105,6 +160,15 @@ func computeETag(data []byte) string {
 	return fmt.Sprintf("\"%X\"", sha512.Sum512(data))
 }
 
+// variantETag returns the ETag of the data of the variant key, which differs
+// from the ETags of other variants, and of the spec, with the same data.
+func variantETag(key string, data []byte) string {
+	if data == nil {
+		return ""
+	}
+	return computeETag(append([]byte(key+"\x00"), data...))
+}
+
 // NewOpenAPIService builds an OpenAPIService starting with the given spec.
 func NewOpenAPIService(spec *spec.Swagger) (*OpenAPIService, error) {
 	o := &OpenAPIService{}
@@ -114,32 +178,148 @@ func NewOpenAPIService(spec *spec.Swagger) (*OpenAPIService, error) {
 	return o, nil
 }
 
-func (o *OpenAPIService) getSwaggerBytes() ([]byte, string, time.Time, error) {
+func (o *OpenAPIService) getSwaggerBytes(r *http.Request) (*compression.Bytes, string, time.Time, error) {
+	v := o.variant(r)
 	o.rwMutex.RLock()
 	defer o.rwMutex.RUnlock()
-	specBytes, etag, err := o.jsonCache.Get()
+	c := &o.jsonCache
+	if v != nil {
+		c = &v.jsonCache
+	}
+	_, etag, err := c.Get()
 	if err != nil {
 		return nil, "", time.Time{}, err
 	}
-	return specBytes, etag, o.lastModified, nil
+	return c.encoded, etag, c.lastModified, nil
 }
 
-func (o *OpenAPIService) getSwaggerPbBytes() ([]byte, string, time.Time, error) {
+func (o *OpenAPIService) getSwaggerPbBytes(r *http.Request) (*compression.Bytes, string, time.Time, error) {
+	v := o.variant(r)
 	o.rwMutex.RLock()
 	defer o.rwMutex.RUnlock()
-	specPb, etag, err := o.protoCache.Get()
+	c := &o.protoCache
+	if v != nil {
+		c = &v.protoCache
+	}
+	_, etag, err := c.Get()
 	if err != nil {
 		return nil, "", time.Time{}, err
 	}
-	return specPb, etag, o.lastModified, nil
+	return c.encoded, etag, c.lastModified, nil
+}
+
+// SetFilter makes the service serve each request the variant of the spec
+// selected by filter, e.g. redact.NewSpecFilter. Variants are served with
+// their own ETags. A nil filter serves the spec to all requests.
+func (o *OpenAPIService) SetFilter(filter *common.SpecFilter) {
+	o.rwMutex.Lock()
+	defer o.rwMutex.Unlock()
+	o.filter = filter
+	o.variants = nil
+}
+
+// variant returns the variant of the spec served for r, or nil to serve the
+// spec itself.
+func (o *OpenAPIService) variant(r *http.Request) *variant {
+	o.rwMutex.RLock()
+	filter := o.filter
+	o.rwMutex.RUnlock()
+	if filter == nil {
+		return nil
+	}
+	key := filter.Key(r)
+	if key == "" {
+		return nil
+	}
+
+	o.rwMutex.RLock()
+	v, ok := o.variants[key]
+	o.rwMutex.RUnlock()
+	if ok {
+		return v
+	}
+	o.rwMutex.Lock()
+	defer o.rwMutex.Unlock()
+	if v, ok := o.variants[key]; ok {
+		return v
+	}
+	if o.filter == nil {
+		return nil
+	}
+	v = &variant{}
+	o.updateVariant(key, v)
+	if o.variants == nil {
+		o.variants = map[string]*variant{}
+	}
+	o.variants[key] = v
+	return v
+}
+
+// updateVariants derives the variants from the updated spec. The caller must
+// hold rwMutex.
+func (o *OpenAPIService) updateVariants() {
+	for key, v := range o.variants {
+		o.updateVariant(key, v)
+	}
+}
+
+// updateVariant derives the variant key from the spec, keeping its previous
+// serialization until the first request. The caller must hold rwMutex.
+func (o *OpenAPIService) updateVariant(key string, v *variant) {
+	apply := o.filter.Apply
+	v.jsonCache = v.jsonCache.newWithETag(func() ([]byte, string, error) {
+		spec, _, err := o.jsonCache.Get()
+		if err != nil {
+			return nil, "", err
+		}
+		filtered, err := apply(key, spec)
+		if err != nil {
+			return nil, "", err
+		}
+		return filtered, variantETag(key, filtered), nil
+	})
+	v.jsonCache.format, v.jsonCache.logger, v.jsonCache.updated = "json", o.log(), o.jsonCache.updated
+	v.protoCache = v.protoCache.newWithETag(func() ([]byte, string, error) {
+		json, _, err := v.jsonCache.Get()
+		if err != nil {
+			return nil, "", err
+		}
+		pb, err := ToProtoBinary(json)
+		if err != nil {
+			return nil, "", err
+		}
+		return pb, variantETag(key, pb), nil
+	})
+	v.protoCache.format, v.protoCache.logger, v.protoCache.updated = "protobuf", o.log(), o.protoCache.updated
+}
+
+// SetLogger sets the logger receiving events about spec updates and serving.
+// By default, events are written to klog.
+func (o *OpenAPIService) SetLogger(logger common.Logger) {
+	o.rwMutex.Lock()
+	defer o.rwMutex.Unlock()
+	o.logger = logger
+}
+
+// log returns the logger of the service. The caller must hold rwMutex.
+func (o *OpenAPIService) log() common.Logger {
+	return common.LoggerOrDefault(o.logger)
+}
+
+func (o *OpenAPIService) getLogger() common.Logger {
+	o.rwMutex.RLock()
+	defer o.rwMutex.RUnlock()
+	return o.log()
 }
 
 func (o *OpenAPIService) UpdateSpec(openapiSpec *spec.Swagger) (err error) {
 	o.rwMutex.Lock()
 	defer o.rwMutex.Unlock()
+	now := time.Now()
 	o.jsonCache = o.jsonCache.New(func() ([]byte, error) {
 		return json.Marshal(openapiSpec)
 	})
+	o.jsonCache.format, o.jsonCache.logger, o.jsonCache.updated = "json", o.log(), now
 	o.protoCache = o.protoCache.New(func() ([]byte, error) {
 		json, _, err := o.jsonCache.Get()
 		if err != nil {
@@ -147,8 +327,38 @@ func (o *OpenAPIService) UpdateSpec(openapiSpec *spec.Swagger) (err error) {
 		}
 		return ToProtoBinary(json)
 	})
-	o.lastModified = time.Now()
+	o.protoCache.format, o.protoCache.logger, o.protoCache.updated = "protobuf", o.log(), now
+	o.updateVariants()
 
+	paths := 0
+	if openapiSpec != nil && openapiSpec.Paths != nil {
+		paths = len(openapiSpec.Paths.Paths)
+	}
+	definitions := 0
+	if openapiSpec != nil {
+		definitions = len(openapiSpec.Definitions)
+	}
+	o.log().Info("Updated OpenAPI spec", "paths", paths, "definitions", definitions)
+
+	return nil
+}
+
+// UpdateSpecBytes updates the served spec to the one serialized by getJSON and
+// getProtobuf, which return the bytes and their ETag. They are called on the
+// first request of each format after the update, e.g. to serve the
+// serializations cached by an aggregator.IncrementalAggregator instead of
+// marshaling the spec again. The bytes must not be modified.
+func (o *OpenAPIService) UpdateSpecBytes(getJSON, getProtobuf func() ([]byte, string, error)) error {
+	o.rwMutex.Lock()
+	defer o.rwMutex.Unlock()
+	now := time.Now()
+	o.jsonCache = o.jsonCache.newWithETag(getJSON)
+	o.jsonCache.format, o.jsonCache.logger, o.jsonCache.updated = "json", o.log(), now
+	o.protoCache = o.protoCache.newWithETag(getProtobuf)
+	o.protoCache.format, o.protoCache.logger, o.protoCache.updated = "protobuf", o.log(), now
+	o.updateVariants()
+
+	o.log().Info("Updated serialized OpenAPI spec")
 	return nil
 }
 
@@ -222,18 +432,27 @@ func RegisterOpenAPIVersionedService(spec *spec.Swagger, servePath string, handl
 }
 
 // RegisterOpenAPIVersionedService registers a handler to provide access to provided swagger spec.
+// It answers GET and HEAD requests, with the hash of the served bytes as ETag and the time
+// of the last update changing them as Last-Modified, and conditional requests with 304.
+// Responses are compressed with gzip or zstd according to Accept-Encoding, each encoding
+// being computed once per spec update. The spec is filtered per request if a filter is set.
 func (o *OpenAPIService) RegisterOpenAPIVersionedService(servePath string, handler common.PathHandler) error {
 	accepted := []struct {
 		Type           string
 		SubType        string
-		GetDataAndETag func() ([]byte, string, time.Time, error)
+		GetDataAndETag func(r *http.Request) (*compression.Bytes, string, time.Time, error)
 	}{
 		{"application", "json", o.getSwaggerBytes},
 		{"application", "com.github.proto-openapi.spec.v2@v1.0+protobuf", o.getSwaggerPbBytes},
 	}
 
-	handler.Handle(servePath, gziphandler.GzipHandler(http.HandlerFunc(
+	handler.Handle(servePath, http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				w.Header().Set("Allow", "GET, HEAD")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
 			decipherableFormats := r.Header.Get("Accept")
 			if decipherableFormats == "" {
 				decipherableFormats = "*/*"
@@ -250,18 +469,17 @@ func (o *OpenAPIService) RegisterOpenAPIVersionedService(servePath string, handl
 					}
 
 					// serve the first matching media type in the sorted clause list
-					data, etag, lastModified, err := accepts.GetDataAndETag()
+					data, etag, lastModified, err := accepts.GetDataAndETag(r)
 					if err != nil {
-						klog.Errorf("Error in OpenAPI handler: %s", err)
+						o.getLogger().Error(err, "Error in OpenAPI handler", "path", servePath, "mediaType", accepts.Type+"/"+accepts.SubType)
 						// only return a 503 if we have no older cache data to serve
 						if data == nil {
 							w.WriteHeader(http.StatusServiceUnavailable)
 							return
 						}
 					}
-					w.Header().Set("Etag", etag)
 					// ServeContent will take care of caching using eTag.
-					http.ServeContent(w, r, servePath, lastModified, bytes.NewReader(data))
+					compression.ServeContent(w, r, servePath, lastModified, etag, data)
 					return
 				}
 			}
@@ -269,7 +487,7 @@ func (o *OpenAPIService) RegisterOpenAPIVersionedService(servePath string, handl
 			w.WriteHeader(406)
 			return
 		}),
-	))
+	)
 
 	return nil
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	yaml "gopkg.in/yaml.v2"
+	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
@@ -261,3 +262,263 @@ func TestCacheRefresh(t *testing.T) {
 		t.Fatalf("got value of %s from cache (expected %s)", value, newVal)
 	}
 }
+
+func TestUpdateSpecBytes(t *testing.T) {
+	o, err := NewOpenAPIService(&spec.Swagger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := map[string]int{}
+	serialized := func(format, etag string) func() ([]byte, string, error) {
+		return func() ([]byte, string, error) {
+			calls[format]++
+			return []byte(format + " " + etag), `"` + etag + `"`, nil
+		}
+	}
+	if err := o.UpdateSpecBytes(serialized("json", "1"), serialized("protobuf", "1")); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	if err := o.RegisterOpenAPIVersionedService("/openapi/v2", mux); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(accept, expectedBody, expectedETag string) {
+		req, err := http.NewRequest("GET", server.URL+"/openapi/v2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Add("Accept", accept)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != expectedBody {
+			t.Errorf("Accept: %v: expected body %q, got %q", accept, expectedBody, body)
+		}
+		if etag := resp.Header.Get("Etag"); etag != expectedETag {
+			t.Errorf("Accept: %v: expected ETag %s, got %s", accept, expectedETag, etag)
+		}
+	}
+
+	get("application/json", "json 1", `"1"`)
+	get("application/json", "json 1", `"1"`)
+	if calls["json"] != 1 || calls["protobuf"] != 0 {
+		t.Errorf("expected the JSON serialization only to be requested once, got %v", calls)
+	}
+	get("application/com.github.proto-openapi.spec.v2@v1.0+protobuf", "protobuf 1", `"1"`)
+
+	if err := o.UpdateSpecBytes(serialized("json", "2"), serialized("protobuf", "2")); err != nil {
+		t.Fatal(err)
+	}
+	get("application/json", "json 2", `"2"`)
+	if calls["json"] != 2 || calls["protobuf"] != 1 {
+		t.Errorf("expected the serializations to be requested on demand, got %v", calls)
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	o, err := NewOpenAPIService(&spec.Swagger{SwaggerProps: spec.SwaggerProps{Swagger: "2.0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	if err := o.RegisterOpenAPIVersionedService("/openapi/v2", mux); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	do := func(method string, header map[string]string) (*http.Response, []byte) {
+		req, err := http.NewRequest(method, server.URL+"/openapi/v2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/json")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, body
+	}
+
+	resp, body := do("GET", nil)
+	etag, lastModified := resp.Header.Get("Etag"), resp.Header.Get("Last-Modified")
+	if resp.StatusCode != 200 || len(body) == 0 || etag == "" || lastModified == "" {
+		t.Fatalf("unexpected response: %d, %q, Etag %q, Last-Modified %q", resp.StatusCode, body, etag, lastModified)
+	}
+
+	resp, body = do("GET", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != 304 || len(body) != 0 {
+		t.Errorf("If-None-Match: expected 304 without body, got %d, %q", resp.StatusCode, body)
+	}
+	resp, body = do("GET", map[string]string{"If-Modified-Since": lastModified})
+	if resp.StatusCode != 304 || len(body) != 0 {
+		t.Errorf("If-Modified-Since: expected 304 without body, got %d, %q", resp.StatusCode, body)
+	}
+	resp, body = do("HEAD", nil)
+	if resp.StatusCode != 200 || len(body) != 0 || resp.Header.Get("Etag") != etag {
+		t.Errorf("HEAD: expected 200 without body and Etag %q, got %d, %q, Etag %q", etag, resp.StatusCode, body, resp.Header.Get("Etag"))
+	}
+	resp, _ = do("POST", nil)
+	if resp.StatusCode != 405 || resp.Header.Get("Allow") != "GET, HEAD" {
+		t.Errorf("POST: expected 405 allowing GET, HEAD, got %d, Allow %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+
+	// updating to the same spec keeps the last modification time
+	modified := o.jsonCache.lastModified
+	if err := o.UpdateSpec(&spec.Swagger{SwaggerProps: spec.SwaggerProps{Swagger: "2.0"}}); err != nil {
+		t.Fatal(err)
+	}
+	resp, _ = do("GET", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != 304 {
+		t.Errorf("unchanged spec: expected 304, got %d", resp.StatusCode)
+	}
+	if !o.jsonCache.lastModified.Equal(modified) {
+		t.Errorf("unchanged spec: expected last modification time %v, got %v", modified, o.jsonCache.lastModified)
+	}
+
+	if err := o.UpdateSpec(&spec.Swagger{SwaggerProps: spec.SwaggerProps{Swagger: "2.0", Host: "example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	resp, body = do("GET", map[string]string{"If-None-Match": etag})
+	if resp.StatusCode != 200 || len(body) == 0 || resp.Header.Get("Etag") == etag {
+		t.Errorf("changed spec: expected 200 with a new Etag, got %d, %q, Etag %q", resp.StatusCode, body, resp.Header.Get("Etag"))
+	}
+	if !o.jsonCache.lastModified.After(modified) {
+		t.Errorf("changed spec: expected last modification time after %v, got %v", modified, o.jsonCache.lastModified)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	info := &spec.Info{InfoProps: spec.InfoProps{Title: "Kubernetes", Version: "v1.11.0"}}
+	o, err := NewOpenAPIService(&spec.Swagger{SwaggerProps: spec.SwaggerProps{Swagger: "2.0", Info: info}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	if err := o.RegisterOpenAPIVersionedService("/openapi/v2", mux); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := map[string]int{}
+	o.SetFilter(&common.SpecFilter{
+		Key: func(r *http.Request) string {
+			return r.Header.Get("X-Tenant")
+		},
+		Apply: func(key string, data []byte) ([]byte, error) {
+			applied[key]++
+			if key == "broken" {
+				return nil, errors.New("broken")
+			}
+			var s spec.Swagger
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, err
+			}
+			if key != "identity" {
+				s.Host = key + ".example.com"
+			}
+			return json.Marshal(&s)
+		},
+	})
+
+	do := func(tenant, accept, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/openapi/v2", nil)
+		req.Header.Set("Accept", accept)
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	host := func(w *httptest.ResponseRecorder) string {
+		var s spec.Swagger
+		if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+			t.Fatalf("unexpected response %q: %v", w.Body.String(), err)
+		}
+		return s.Host
+	}
+
+	unfiltered := do("", "application/json", "")
+	if unfiltered.Code != 200 || host(unfiltered) != "" {
+		t.Fatalf("unfiltered: expected 200 with the spec, got %d, %q", unfiltered.Code, unfiltered.Body.String())
+	}
+	etag := unfiltered.Header().Get("Etag")
+
+	w := do("a", "application/json", "")
+	if w.Code != 200 || host(w) != "a.example.com" {
+		t.Errorf("tenant a: expected 200 with the filtered spec, got %d, %q", w.Code, w.Body.String())
+	}
+	etagA := w.Header().Get("Etag")
+	if etagA == "" || etagA == etag {
+		t.Errorf("tenant a: expected an Etag other than %q, got %q", etag, etagA)
+	}
+	if w := do("a", "application/json", etagA); w.Code != 304 {
+		t.Errorf("tenant a: expected 304 for its Etag, got %d", w.Code)
+	}
+	if w := do("a", "application/json", etag); w.Code != 200 {
+		t.Errorf("tenant a: expected 200 for the unfiltered Etag, got %d", w.Code)
+	}
+	if w := do("", "application/json", etagA); w.Code != 200 {
+		t.Errorf("unfiltered: expected 200 for the Etag of tenant a, got %d", w.Code)
+	}
+
+	// the same bytes served to another key have another Etag
+	w = do("identity", "application/json", etag)
+	if w.Code != 200 || w.Body.String() != unfiltered.Body.String() || w.Header().Get("Etag") == etag {
+		t.Errorf("identity: expected 200 with the spec and an Etag other than %q, got %d, %q, Etag %q", etag, w.Code, w.Body.String(), w.Header().Get("Etag"))
+	}
+
+	const protobuf = "application/com.github.proto-openapi.spec.v2@v1.0+protobuf"
+	pb := do("", protobuf, "")
+	pbA := do("a", protobuf, "")
+	if pbA.Code != 200 || pbA.Body.Len() == 0 || pbA.Body.String() == pb.Body.String() {
+		t.Errorf("tenant a: expected 200 with the filtered protobuf spec, got %d, %q", pbA.Code, pbA.Body.String())
+	}
+	if e := pbA.Header().Get("Etag"); e == "" || e == pb.Header().Get("Etag") || e == etagA {
+		t.Errorf("tenant a: expected a protobuf Etag of its own, got %q", e)
+	}
+
+	if w := do("broken", "application/json", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("broken: expected 503, got %d", w.Code)
+	}
+
+	// variants are computed once per spec update
+	if applied["a"] != 1 {
+		t.Errorf("expected the filter to be applied once for tenant a, got %d", applied["a"])
+	}
+	if err := o.UpdateSpec(&spec.Swagger{SwaggerProps: spec.SwaggerProps{Swagger: "2.0", Info: info, BasePath: "/v2"}}); err != nil {
+		t.Fatal(err)
+	}
+	w = do("a", "application/json", etagA)
+	if w.Code != 200 || host(w) != "a.example.com" || w.Header().Get("Etag") == etagA {
+		t.Errorf("updated spec: expected 200 with the filtered spec and a new Etag, got %d, %q, Etag %q", w.Code, w.Body.String(), w.Header().Get("Etag"))
+	}
+	if applied["a"] != 2 {
+		t.Errorf("updated spec: expected the filter to be applied again for tenant a, got %d", applied["a"])
+	}
+
+	o.SetFilter(nil)
+	if w := do("a", "application/json", etag); w.Code == 304 || host(w) != "" {
+		t.Errorf("no filter: expected the updated spec, got %d, %q", w.Code, w.Body.String())
+	}
+}
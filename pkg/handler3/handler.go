@@ -24,7 +24,6 @@ import (
 	"fmt"
 	"mime"
 	"net/http"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +32,7 @@ import (
 	openapi_v3 "github.com/googleapis/gnostic/openapiv3"
 	"github.com/munnerz/goautoneg"
 	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/internal/compression"
 	"k8s.io/kube-openapi/pkg/spec3"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
@@ -47,16 +47,41 @@ const (
 
 	subTypeProtobuf = "com.github.proto-openapi.spec.v3@v1.0+protobuf"
 	subTypeJSON     = "json"
+
+	defaultServePath = "/openapi/v3"
+	// immutableMaxAge is the max-age of the specs requested by hash, one year.
+	immutableMaxAge = 365 * 24 * time.Hour
 )
 
+// OpenAPIV3Discovery is the discovery document served at /openapi/v3, mapping
+// the groups to the URLs of their specs.
+type OpenAPIV3Discovery struct {
+	Paths map[string]OpenAPIV3DiscoveryGroupVersion `json:"paths"`
+}
+
+// OpenAPIV3DiscoveryGroupVersion is the entry of a group in OpenAPIV3Discovery.
+type OpenAPIV3DiscoveryGroupVersion struct {
+	// ServerRelativeURL is the URL of the spec of the group, with the hash of
+	// the spec as query parameter. The URL changes with the spec, so the
+	// response can be cached forever.
+	ServerRelativeURL string `json:"serverRelativeURL"`
+}
+
 // OpenAPIService is the service responsible for serving OpenAPI spec. It has
 // the ability to safely change the spec while serving it.
 // OpenAPI V3 currently does not use the lazy marshaling strategy that OpenAPI V2 is using
 type OpenAPIService struct {
 	// rwMutex protects All members of this service.
-	rwMutex      sync.RWMutex
+	rwMutex sync.RWMutex
+	// lastModified is the time the discovery document last changed.
 	lastModified time.Time
 	v3Schema     map[string]*OpenAPIV3Group
+	// servePath is the path of the discovery document.
+	servePath string
+	// filter selects the variant of the specs served for each request.
+	filter *common.SpecFilter
+
+	logger common.Logger
 }
 
 type OpenAPIV3Group struct {
@@ -68,9 +93,19 @@ type OpenAPIV3Group struct {
 	specPb    []byte
 	specPbGz  []byte
 
+	// specBytesEncoded and specPbEncoded hold the compressed encodings of
+	// specBytes and specPb.
+	specBytesEncoded *compression.Bytes
+	specPbEncoded    *compression.Bytes
+
 	specBytesETag string
 	specPbETag    string
 	specPbGzETag  string
+
+	// key is the key of the group if it is a variant of the spec of a group.
+	key string
+	// variants caches the variants of the spec by key.
+	variants map[string]*OpenAPIV3Group
 }
 
 func init() {
@@ -83,68 +118,187 @@ func computeETag(data []byte) string {
 	return fmt.Sprintf("\"%X\"", sha512.Sum512(data))
 }
 
+// variantETag returns the ETag of the data of the variant key, which differs
+// from the ETags of other variants, and of the spec, with the same data.
+func variantETag(key string, data []byte) string {
+	if key == "" {
+		return computeETag(data)
+	}
+	return computeETag(append([]byte(key+"\x00"), data...))
+}
+
 // NewOpenAPIService builds an OpenAPIService starting with the given spec.
 func NewOpenAPIService(spec *spec.Swagger) (*OpenAPIService, error) {
-	o := &OpenAPIService{}
+	o := &OpenAPIService{lastModified: time.Now(), servePath: defaultServePath}
 	o.v3Schema = make(map[string]*OpenAPIV3Group)
 	return o, nil
 }
 
-func (o *OpenAPIService) getGroupBytes() ([]byte, error) {
+// getGroupBytes returns the discovery document listing the variant key of
+// the specs of the groups.
+func (o *OpenAPIService) getGroupBytes(key string) ([]byte, error) {
 	o.rwMutex.RLock()
 	defer o.rwMutex.RUnlock()
-	keys := make([]string, len(o.v3Schema))
-	i := 0
-	for k := range o.v3Schema {
-		keys[i] = k
-		i++
+	discovery := &OpenAPIV3Discovery{Paths: make(map[string]OpenAPIV3DiscoveryGroupVersion, len(o.v3Schema))}
+	for k, g := range o.v3Schema {
+		v, err := g.variant(key, o.filter)
+		if err != nil {
+			return nil, err
+		}
+		discovery.Paths[k] = OpenAPIV3DiscoveryGroupVersion{
+			ServerRelativeURL: o.servePath + "/" + k + "?hash=" + v.hash(),
+		}
 	}
 
-	sort.Strings(keys)
-	group := make(map[string][]string)
-	group["Paths"] = keys
-
-	j, err := json.Marshal(group)
+	j, err := json.Marshal(discovery)
 	if err != nil {
 		return nil, err
 	}
 	return j, nil
 }
 
-func (o *OpenAPIService) getSingleGroupBytes(getType string, group string) ([]byte, string, time.Time, error) {
+func (o *OpenAPIService) getGroup(group string) (*OpenAPIV3Group, bool) {
 	o.rwMutex.RLock()
 	defer o.rwMutex.RUnlock()
 	v, ok := o.v3Schema[group]
-	if !ok {
-		return nil, "", time.Now(), fmt.Errorf("Cannot find CRD group %s", group)
-	}
+	return v, ok
+}
+
+func (o *OpenAPIV3Group) getBytes(getType string) (*compression.Bytes, string, time.Time, error) {
+	o.rwMutex.RLock()
+	defer o.rwMutex.RUnlock()
 	if getType == subTypeJSON {
-		return v.specBytes, v.specBytesETag, v.lastModified, nil
+		return o.specBytesEncoded, o.specBytesETag, o.lastModified, nil
 	} else if getType == subTypeProtobuf {
-		return v.specPb, v.specPbETag, v.lastModified, nil
+		return o.specPbEncoded, o.specPbETag, o.lastModified, nil
 	}
 	return nil, "", time.Now(), fmt.Errorf("Invalid accept clause %s", getType)
 }
 
+// variant returns the variant key of the spec of the group, derived with
+// filter, or the group itself for the empty key.
+func (o *OpenAPIV3Group) variant(key string, filter *common.SpecFilter) (*OpenAPIV3Group, error) {
+	if key == "" || filter == nil {
+		return o, nil
+	}
+	o.rwMutex.RLock()
+	v, ok := o.variants[key]
+	specBytes, etag, lastModified := o.specBytes, o.specBytesETag, o.lastModified
+	o.rwMutex.RUnlock()
+	if ok {
+		return v, nil
+	}
+
+	filtered, err := filter.Apply(key, specBytes)
+	if err != nil {
+		return nil, err
+	}
+	v = &OpenAPIV3Group{key: key}
+	if err := v.UpdateSpec(filtered); err != nil {
+		return nil, err
+	}
+	v.lastModified = lastModified
+	o.rwMutex.Lock()
+	defer o.rwMutex.Unlock()
+	if o.specBytesETag != etag {
+		// the spec was updated meanwhile, serve the variant once
+		return v, nil
+	}
+	if o.variants == nil {
+		o.variants = map[string]*OpenAPIV3Group{}
+	}
+	o.variants[key] = v
+	return v, nil
+}
+
+// hash returns the hash of the JSON spec of the group, addressing the spec in
+// the URLs of the discovery document.
+func (o *OpenAPIV3Group) hash() string {
+	o.rwMutex.RLock()
+	defer o.rwMutex.RUnlock()
+	return strings.Trim(o.specBytesETag, `"`)
+}
+
+// SetLogger sets the logger receiving events about spec updates and serving.
+// By default, events are written to klog.
+func (o *OpenAPIService) SetLogger(logger common.Logger) {
+	o.rwMutex.Lock()
+	defer o.rwMutex.Unlock()
+	o.logger = logger
+}
+
+// SetFilter makes the service serve each request the variant of the specs
+// selected by filter. Variants are served with their own ETags and hashes.
+// A nil filter serves the specs to all requests.
+func (o *OpenAPIService) SetFilter(filter *common.SpecFilter) {
+	o.rwMutex.Lock()
+	defer o.rwMutex.Unlock()
+	o.filter = filter
+	for _, g := range o.v3Schema {
+		g.rwMutex.Lock()
+		g.variants = nil
+		g.rwMutex.Unlock()
+	}
+}
+
+// filterKey returns the key of the variant of the specs served for r, and
+// the filter deriving it.
+func (o *OpenAPIService) filterKey(r *http.Request) (string, *common.SpecFilter) {
+	o.rwMutex.RLock()
+	filter := o.filter
+	o.rwMutex.RUnlock()
+	if filter == nil {
+		return "", nil
+	}
+	return filter.Key(r), filter
+}
+
+// log returns the logger of the service. The caller must hold rwMutex.
+func (o *OpenAPIService) log() common.Logger {
+	return common.LoggerOrDefault(o.logger)
+}
+
+func (o *OpenAPIService) getLogger() common.Logger {
+	o.rwMutex.RLock()
+	defer o.rwMutex.RUnlock()
+	return o.log()
+}
+
 func (o *OpenAPIService) UpdateGroupVersion(group string, openapi *spec3.OpenAPI) (err error) {
 	o.rwMutex.Lock()
 	defer o.rwMutex.Unlock()
 
+	start := time.Now()
 	specBytes, err := json.Marshal(openapi)
 	if err != nil {
+		o.log().Error(err, "Failed to serialize OpenAPI v3 spec", "group", group)
 		return err
 	}
 
 	if _, ok := o.v3Schema[group]; !ok {
 		o.v3Schema[group] = &OpenAPIV3Group{}
 	}
-	return o.v3Schema[group].UpdateSpec(specBytes)
+	hash := o.v3Schema[group].hash()
+	if err := o.v3Schema[group].UpdateSpec(specBytes); err != nil {
+		o.log().Error(err, "Failed to update OpenAPI v3 spec", "group", group)
+		return err
+	}
+	if o.v3Schema[group].hash() != hash {
+		// the URL of the group in the discovery document changed
+		o.lastModified = time.Now()
+	}
+	o.log().Info("Updated OpenAPI v3 spec", "group", group, "size", len(specBytes), "digest", o.v3Schema[group].specBytesETag, "duration", time.Since(start))
+	return nil
 }
 
 func (o *OpenAPIService) DeleteGroupVersion(group string) {
 	o.rwMutex.Lock()
 	defer o.rwMutex.Unlock()
-	delete(o.v3Schema, group)
+	if _, ok := o.v3Schema[group]; ok {
+		delete(o.v3Schema, group)
+		o.lastModified = time.Now()
+	}
+	o.log().Info("Deleted OpenAPI v3 spec", "group", group)
 }
 
 func ToV3ProtoBinary(json []byte) ([]byte, error) {
@@ -163,14 +317,78 @@ func toGzip(data []byte) []byte {
 	return buf.Bytes()
 }
 
+// allowMethod answers requests with other methods than GET and HEAD with 405,
+// returning false for them.
+func allowMethod(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	return false
+}
+
+// HandleDiscovery serves the OpenAPIV3Discovery document listing the groups
+// and the hash-addressed URLs of their specs, with the hash of the document
+// as ETag and the time it last changed as Last-Modified.
 func (o *OpenAPIService) HandleDiscovery(w http.ResponseWriter, r *http.Request) {
-	data, _ := o.getGroupBytes()
-	http.ServeContent(w, r, "/openapi/v3", time.Now(), bytes.NewReader(data))
+	if !allowMethod(w, r) {
+		return
+	}
+	key, _ := o.filterKey(r)
+	data, err := o.getGroupBytes(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	o.rwMutex.RLock()
+	servePath, lastModified := o.servePath, o.lastModified
+	o.rwMutex.RUnlock()
+	compression.ServeContent(w, r, servePath, lastModified, variantETag(key, data), compression.New(data))
 }
 
+// HandleGroupVersion serves the spec of a group in the negotiated format,
+// with the hash of the spec as ETag and the time it last changed as
+// Last-Modified. Conditional requests are answered with 304, and unknown
+// groups with 404. Responses are compressed with gzip or zstd according to
+// Accept-Encoding, each encoding being computed once per spec update.
+//
+// Requests for the hash-addressed URL of the discovery document are answered
+// with immutable caching headers, or redirected to the current URL if the
+// spec changed.
 func (o *OpenAPIService) HandleGroupVersion(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r) {
+		return
+	}
 	url := strings.SplitAfterN(r.URL.Path, "/", 4)
 	group := url[3]
+	g, ok := o.getGroup(group)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	g, err := g.variant(o.filterKey(r))
+	if err != nil {
+		o.getLogger().Error(err, "Failed to filter OpenAPI v3 spec", "group", group)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if hash := r.URL.Query().Get("hash"); hash != "" {
+		if current := g.hash(); hash != current {
+			o.rwMutex.RLock()
+			url := o.servePath + "/" + group + "?hash=" + current
+			o.rwMutex.RUnlock()
+			http.Redirect(w, r, url, http.StatusMovedPermanently)
+			return
+		}
+		// filtered specs must not be served from shared caches to other peers
+		visibility := "public"
+		if g.key != "" {
+			visibility = "private"
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, immutable, max-age=%d", visibility, int(immutableMaxAge.Seconds())))
+		w.Header().Set("Expires", time.Now().Add(immutableMaxAge).UTC().Format(http.TimeFormat))
+	}
 
 	decipherableFormats := r.Header.Get("Accept")
 	if decipherableFormats == "" {
@@ -199,12 +417,12 @@ func (o *OpenAPIService) HandleGroupVersion(w http.ResponseWriter, r *http.Reque
 			if clause.SubType != accepts.SubType && clause.SubType != "*" {
 				continue
 			}
-			data, etag, lastModified, err := o.getSingleGroupBytes(accepts.SubType, group)
+			data, etag, lastModified, err := g.getBytes(accepts.SubType)
 			if err != nil {
+				o.getLogger().Error(err, "Error in OpenAPI v3 handler", "group", group, "mediaType", accepts.Type+"/"+accepts.SubType)
 				return
 			}
-			w.Header().Set("Etag", etag)
-			http.ServeContent(w, r, "", lastModified, bytes.NewReader(data))
+			compression.ServeContent(w, r, "", lastModified, etag, data)
 			return
 		}
 	}
@@ -213,6 +431,9 @@ func (o *OpenAPIService) HandleGroupVersion(w http.ResponseWriter, r *http.Reque
 }
 
 func (o *OpenAPIService) RegisterOpenAPIV3VersionedService(servePath string, handler common.PathHandlerByGroupVersion) error {
+	o.rwMutex.Lock()
+	o.servePath = servePath
+	o.rwMutex.Unlock()
 	handler.Handle(servePath, http.HandlerFunc(o.HandleDiscovery))
 	handler.HandlePrefix(servePath+"/", http.HandlerFunc(o.HandleGroupVersion))
 	return nil
@@ -222,6 +443,12 @@ func (o *OpenAPIV3Group) UpdateSpec(specBytes []byte) (err error) {
 	o.rwMutex.Lock()
 	defer o.rwMutex.Unlock()
 
+	specBytesETag := variantETag(o.key, specBytes)
+	if specBytesETag == o.specBytesETag {
+		// unchanged, keep the encodings and the last modification time
+		return nil
+	}
+
 	specPb, err := ToV3ProtoBinary(specBytes)
 	if err != nil {
 		return err
@@ -229,20 +456,22 @@ func (o *OpenAPIV3Group) UpdateSpec(specBytes []byte) (err error) {
 
 	specPbGz := toGzip(specPb)
 
-	specBytesETag := computeETag(specBytes)
-	specPbETag := computeETag(specPb)
-	specPbGzETag := computeETag(specPbGz)
+	specPbETag := variantETag(o.key, specPb)
+	specPbGzETag := variantETag(o.key, specPbGz)
 
 	lastModified := time.Now()
 
 	o.specBytes = specBytes
 	o.specPb = specPb
 	o.specPbGz = specPbGz
+	o.specBytesEncoded = compression.New(specBytes)
+	o.specPbEncoded = compression.New(specPb)
 
 	o.specBytesETag = specBytesETag
 	o.specPbETag = specPbETag
 	o.specPbGzETag = specPbGzETag
 
 	o.lastModified = lastModified
+	o.variants = nil
 	return nil
 }
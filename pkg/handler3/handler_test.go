@@ -22,14 +22,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
 	"encoding/json"
+	"k8s.io/kube-openapi/pkg/common"
 	"k8s.io/kube-openapi/pkg/spec3"
 )
 
-var returnedGroupVersionListJSON = []byte(`{"Paths":["apis/apps/v1"]}`)
-
 var returnedOpenAPI = []byte(`{
   "openapi": "3.0",
   "info": {
@@ -53,6 +53,8 @@ func TestRegisterOpenAPIVersionedService(t *testing.T) {
 		t.Fatalf("Unexpected error in preparing returnedJSON: %v", err)
 	}
 
+	returnedGroupVersionListJSON := []byte(`{"paths":{"apis/apps/v1":{"serverRelativeURL":"/openapi/v3/apis/apps/v1?hash=` + strings.Trim(computeETag(returnedJSON), `"`) + `"}}}`)
+
 	returnedPb, err := ToV3ProtoBinary(compactOpenAPI)
 	_ = returnedPb
 
@@ -120,3 +122,280 @@ func TestRegisterOpenAPIVersionedService(t *testing.T) {
 		}
 	}
 }
+
+func TestConditionalRequests(t *testing.T) {
+	var s *spec3.OpenAPI
+	if err := json.Unmarshal(returnedOpenAPI, &s); err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/openapi/v3", http.HandlerFunc(o.HandleDiscovery))
+	mux.Handle("/openapi/v3/", http.HandlerFunc(o.HandleGroupVersion))
+	if err := o.UpdateGroupVersion("apis/apps/v1", s); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	do := func(method, path string, header map[string]string) (*http.Response, []byte) {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/json")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, body
+	}
+
+	for _, path := range []string{"/openapi/v3", "/openapi/v3/apis/apps/v1"} {
+		resp, body := do("GET", path, nil)
+		etag, lastModified := resp.Header.Get("Etag"), resp.Header.Get("Last-Modified")
+		if resp.StatusCode != 200 || len(body) == 0 || etag == "" || lastModified == "" {
+			t.Fatalf("%s: unexpected response: %d, %q, Etag %q, Last-Modified %q", path, resp.StatusCode, body, etag, lastModified)
+		}
+		resp, body = do("GET", path, map[string]string{"If-None-Match": etag})
+		if resp.StatusCode != 304 || len(body) != 0 {
+			t.Errorf("%s: If-None-Match: expected 304 without body, got %d, %q", path, resp.StatusCode, body)
+		}
+		resp, body = do("GET", path, map[string]string{"If-Modified-Since": lastModified})
+		if resp.StatusCode != 304 || len(body) != 0 {
+			t.Errorf("%s: If-Modified-Since: expected 304 without body, got %d, %q", path, resp.StatusCode, body)
+		}
+		resp, body = do("HEAD", path, nil)
+		if resp.StatusCode != 200 || len(body) != 0 || resp.Header.Get("Etag") != etag {
+			t.Errorf("%s: HEAD: expected 200 without body and Etag %q, got %d, %q, Etag %q", path, etag, resp.StatusCode, body, resp.Header.Get("Etag"))
+		}
+		resp, _ = do("POST", path, nil)
+		if resp.StatusCode != 405 || resp.Header.Get("Allow") != "GET, HEAD" {
+			t.Errorf("%s: POST: expected 405 allowing GET, HEAD, got %d, Allow %q", path, resp.StatusCode, resp.Header.Get("Allow"))
+		}
+	}
+
+	if resp, _ := do("GET", "/openapi/v3/apis/batch/v1", nil); resp.StatusCode != 404 {
+		t.Errorf("unknown group: expected 404, got %d", resp.StatusCode)
+	}
+
+	// updating to the same spec keeps the last modification time
+	group := o.v3Schema["apis/apps/v1"]
+	modified := group.lastModified
+	if err := o.UpdateGroupVersion("apis/apps/v1", s); err != nil {
+		t.Fatal(err)
+	}
+	if !group.lastModified.Equal(modified) {
+		t.Errorf("unchanged spec: expected last modification time %v, got %v", modified, group.lastModified)
+	}
+	s.Info.Version = "v1.24.0"
+	if err := o.UpdateGroupVersion("apis/apps/v1", s); err != nil {
+		t.Fatal(err)
+	}
+	if !group.lastModified.After(modified) {
+		t.Errorf("changed spec: expected last modification time after %v, got %v", modified, group.lastModified)
+	}
+
+	// the discovery document changes with the groups
+	modified = o.lastModified
+	o.DeleteGroupVersion("apis/apps/v1")
+	if !o.lastModified.After(modified) {
+		t.Errorf("deleted group: expected last modification time after %v, got %v", modified, o.lastModified)
+	}
+	if resp, _ := do("GET", "/openapi/v3/apis/apps/v1", nil); resp.StatusCode != 404 {
+		t.Errorf("deleted group: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestHashAddressedURLs(t *testing.T) {
+	var s *spec3.OpenAPI
+	if err := json.Unmarshal(returnedOpenAPI, &s); err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.UpdateGroupVersion("apis/apps/v1", s); err != nil {
+		t.Fatal(err)
+	}
+
+	serve := func(handler http.HandlerFunc, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", url, nil))
+		return w
+	}
+	discover := func() string {
+		w := serve(o.HandleDiscovery, "/openapi/v3")
+		var discovery OpenAPIV3Discovery
+		if err := json.Unmarshal(w.Body.Bytes(), &discovery); err != nil {
+			t.Fatal(err)
+		}
+		if len(discovery.Paths) != 1 {
+			t.Fatalf("expected the discovery document to list apis/apps/v1, got %s", w.Body.String())
+		}
+		return discovery.Paths["apis/apps/v1"].ServerRelativeURL
+	}
+
+	url := discover()
+	if expected := "/openapi/v3/apis/apps/v1?hash=" + o.v3Schema["apis/apps/v1"].hash(); url != expected {
+		t.Errorf("expected URL %q, got %q", expected, url)
+	}
+	w := serve(o.HandleGroupVersion, url)
+	if w.Code != 200 || w.Body.Len() == 0 {
+		t.Errorf("%s: expected 200 with the spec, got %d, %q", url, w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, immutable, max-age=31536000" {
+		t.Errorf("%s: expected immutable Cache-Control, got %q", url, cc)
+	}
+	if w.Header().Get("Expires") == "" {
+		t.Errorf("%s: expected Expires", url)
+	}
+	w = serve(o.HandleGroupVersion, "/openapi/v3/apis/apps/v1")
+	if w.Code != 200 || w.Header().Get("Cache-Control") != "" {
+		t.Errorf("unhashed URL: expected 200 without Cache-Control, got %d, %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+
+	// a changed spec gets a new URL, the old one redirecting to it
+	modified := o.lastModified
+	s.Info.Version = "v1.24.0"
+	if err := o.UpdateGroupVersion("apis/apps/v1", s); err != nil {
+		t.Fatal(err)
+	}
+	newURL := discover()
+	if newURL == url {
+		t.Errorf("expected a new URL for the changed spec, got %q", newURL)
+	}
+	if !o.lastModified.After(modified) {
+		t.Errorf("changed spec: expected discovery last modification time after %v, got %v", modified, o.lastModified)
+	}
+	w = serve(o.HandleGroupVersion, url)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != newURL {
+		t.Errorf("%s: expected a redirect to %q, got %d, %q", url, newURL, w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var s *spec3.OpenAPI
+	if err := json.Unmarshal(returnedOpenAPI, &s); err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOpenAPIService(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.UpdateGroupVersion("apis/apps/v1", s); err != nil {
+		t.Fatal(err)
+	}
+	applied := map[string]int{}
+	o.SetFilter(&common.SpecFilter{
+		Key: func(r *http.Request) string {
+			return r.Header.Get("X-Tenant")
+		},
+		Apply: func(key string, data []byte) ([]byte, error) {
+			applied[key]++
+			var s *spec3.OpenAPI
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, err
+			}
+			if key != "identity" {
+				s.Info.Title = key
+			}
+			return json.Marshal(s)
+		},
+	})
+
+	serve := func(handler http.HandlerFunc, url, tenant, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set("Accept", "application/json")
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+	discover := func(tenant string) (string, string) {
+		w := serve(o.HandleDiscovery, "/openapi/v3", tenant, "")
+		var discovery OpenAPIV3Discovery
+		if err := json.Unmarshal(w.Body.Bytes(), &discovery); err != nil {
+			t.Fatal(err)
+		}
+		return discovery.Paths["apis/apps/v1"].ServerRelativeURL, w.Header().Get("Etag")
+	}
+	title := func(w *httptest.ResponseRecorder) string {
+		var s *spec3.OpenAPI
+		if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+			t.Fatalf("unexpected response %q: %v", w.Body.String(), err)
+		}
+		return s.Info.Title
+	}
+
+	url, etag := discover("")
+	urlA, etagA := discover("a")
+	if urlA == url || etagA == etag {
+		t.Errorf("tenant a: expected a discovery document of its own, got URL %q, Etag %q", urlA, etagA)
+	}
+	if w := serve(o.HandleDiscovery, "/openapi/v3", "a", etag); w.Code != 200 {
+		t.Errorf("tenant a: expected 200 for the unfiltered discovery Etag, got %d", w.Code)
+	}
+
+	w := serve(o.HandleGroupVersion, url, "", "")
+	if w.Code != 200 || title(w) != "Kubernetes" {
+		t.Errorf("unfiltered: expected 200 with the spec, got %d, %q", w.Code, w.Body.String())
+	}
+	specETag := w.Header().Get("Etag")
+	w = serve(o.HandleGroupVersion, urlA, "a", "")
+	if w.Code != 200 || title(w) != "a" {
+		t.Errorf("tenant a: expected 200 with the filtered spec, got %d, %q", w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "private,") {
+		t.Errorf("tenant a: expected private Cache-Control, got %q", cc)
+	}
+	if e := w.Header().Get("Etag"); e == "" || e == specETag {
+		t.Errorf("tenant a: expected an Etag other than %q, got %q", specETag, e)
+	}
+	if w := serve(o.HandleGroupVersion, urlA, "a", specETag); w.Code != 200 {
+		t.Errorf("tenant a: expected 200 for the unfiltered Etag, got %d", w.Code)
+	}
+	// the URL of another variant redirects to the own one
+	if w := serve(o.HandleGroupVersion, url, "a", ""); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != urlA {
+		t.Errorf("tenant a: expected a redirect from %q to %q, got %d, %q", url, urlA, w.Code, w.Header().Get("Location"))
+	}
+
+	// the same bytes served to another key have another Etag
+	urlIdentity, _ := discover("identity")
+	w = serve(o.HandleGroupVersion, urlIdentity, "identity", specETag)
+	if w.Code != 200 || title(w) != "Kubernetes" || w.Header().Get("Etag") == specETag {
+		t.Errorf("identity: expected 200 with the spec and an Etag other than %q, got %d, %q, Etag %q", specETag, w.Code, w.Body.String(), w.Header().Get("Etag"))
+	}
+
+	// variants are computed once per spec update
+	if applied["a"] != 1 {
+		t.Errorf("expected the filter to be applied once for tenant a, got %d", applied["a"])
+	}
+	s.Info.Version = "v1.24.0"
+	if err := o.UpdateGroupVersion("apis/apps/v1", s); err != nil {
+		t.Fatal(err)
+	}
+	if newURL, _ := discover("a"); newURL == urlA {
+		t.Errorf("updated spec: expected a new URL for tenant a, got %q", newURL)
+	}
+	if applied["a"] != 2 {
+		t.Errorf("updated spec: expected the filter to be applied again for tenant a, got %d", applied["a"])
+	}
+}
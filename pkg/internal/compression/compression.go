@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compression holds the pre-compressed encodings of the specs served
+// by the handler packages.
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// The content codings of Bytes, in order of preference.
+const (
+	Zstd = "zstd"
+	Gzip = "gzip"
+)
+
+// minSize is the size under which bytes are served uncompressed.
+const minSize = 512
+
+// zstdEncoder is safe for concurrent use by EncodeAll.
+var zstdEncoder, _ = zstd.NewWriter(nil)
+
+// Bytes are bytes along with their compressed encodings. Each encoding is
+// computed once, when first used, and then served from memory.
+type Bytes struct {
+	raw        []byte
+	gzip, zstd encoding
+}
+
+type encoding struct {
+	once sync.Once
+	data []byte
+}
+
+// New returns raw along with its encodings. raw must not be modified.
+func New(raw []byte) *Bytes {
+	return &Bytes{raw: raw}
+}
+
+// Raw returns the uncompressed bytes.
+func (b *Bytes) Raw() []byte {
+	if b == nil {
+		return nil
+	}
+	return b.raw
+}
+
+// Encoded returns the bytes encoded with the content coding, the raw bytes
+// for "" or an unknown coding.
+func (b *Bytes) Encoded(coding string) []byte {
+	switch coding {
+	case Gzip:
+		b.gzip.once.Do(func() {
+			var buf bytes.Buffer
+			zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+			zw.Write(b.raw)
+			zw.Close()
+			b.gzip.data = buf.Bytes()
+		})
+		return b.gzip.data
+	case Zstd:
+		b.zstd.once.Do(func() {
+			b.zstd.data = zstdEncoder.EncodeAll(b.raw, nil)
+		})
+		return b.zstd.data
+	}
+	return b.raw
+}
+
+// Negotiate returns the preferred content coding of Bytes acceptable
+// according to the value of an Accept-Encoding header, or "" if none is.
+func Negotiate(acceptEncoding string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[len("q="):], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{Zstd, Gzip} {
+		q, ok := qualities[coding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// ServeContent serves b like http.ServeContent, encoded with the content
+// coding negotiated from the Accept-Encoding header of r unless it is smaller
+// than 512 bytes. etag is the ETag of the raw bytes, the coding being appended
+// to it for encoded responses.
+func ServeContent(w http.ResponseWriter, r *http.Request, name string, modtime time.Time, etag string, b *Bytes) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	coding := ""
+	if len(b.Raw()) >= minSize {
+		coding = Negotiate(r.Header.Get("Accept-Encoding"))
+	}
+	if coding == "" {
+		if etag != "" {
+			w.Header().Set("Etag", etag)
+		}
+		http.ServeContent(w, r, name, modtime, bytes.NewReader(b.Raw()))
+		return
+	}
+
+	if _, ok := w.Header()["Content-Type"]; !ok {
+		// as sniffed by http.ServeContent for the raw bytes
+		ctype := mime.TypeByExtension(filepath.Ext(name))
+		if ctype == "" {
+			ctype = http.DetectContentType(b.raw)
+		}
+		w.Header().Set("Content-Type", ctype)
+	}
+	if etag != "" {
+		w.Header().Set("Etag", encodedETag(etag, coding))
+	}
+	w.Header().Set("Content-Encoding", coding)
+	http.ServeContent(w, r, name, modtime, bytes.NewReader(b.Encoded(coding)))
+}
+
+// encodedETag returns the strong ETag of the encoding of the bytes with etag,
+// which must differ from the one of the raw bytes.
+func encodedETag(etag, coding string) string {
+	if len(etag) >= 2 && strings.HasSuffix(etag, `"`) {
+		return etag[:len(etag)-1] + "-" + coding + `"`
+	}
+	return etag + "-" + coding
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", Gzip},
+		{"gzip, deflate, br", Gzip},
+		{"zstd", Zstd},
+		{"gzip, zstd", Zstd},
+		{"GZIP;q=0.8, zstd;q=0.5", Gzip},
+		{"zstd;q=0, gzip", Gzip},
+		{"gzip;q=0", ""},
+		{"*", Zstd},
+		{"zstd;q=0, *;q=0.1", Gzip},
+		{"gzip;q=invalid", Gzip},
+	} {
+		assert.Equal(t, tc.expected, Negotiate(tc.acceptEncoding), "Accept-Encoding: %q", tc.acceptEncoding)
+	}
+}
+
+func TestEncoded(t *testing.T) {
+	raw := []byte(strings.Repeat(`{"swagger":"2.0"}`, 100))
+	b := New(raw)
+	assert.Equal(t, raw, b.Raw())
+	assert.Equal(t, raw, b.Encoded(""))
+
+	gz := b.Encoded(Gzip)
+	assert.Less(t, len(gz), len(raw))
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	require.NoError(t, err)
+	decoded, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, raw, decoded)
+
+	zs := b.Encoded(Zstd)
+	assert.Less(t, len(zs), len(raw))
+	dec, err := zstd.NewReader(nil)
+	require.NoError(t, err)
+	defer dec.Close()
+	decoded, err = dec.DecodeAll(zs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, raw, decoded)
+
+	// encodings are computed once
+	assert.True(t, &gz[0] == &b.Encoded(Gzip)[0])
+	assert.True(t, &zs[0] == &b.Encoded(Zstd)[0])
+}
+
+func TestServeContent(t *testing.T) {
+	raw := []byte(strings.Repeat(`{"swagger":"2.0"}`, 100))
+	b := New(raw)
+	modtime := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	serve := func(method string, content *Bytes, header map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/openapi/v2", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		w.Header().Set("Content-Type", "application/json")
+		ServeContent(w, r, "/openapi/v2", modtime, `"ABC"`, content)
+		return w
+	}
+
+	w := serve("GET", b, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, raw, w.Body.Bytes())
+	assert.Equal(t, `"ABC"`, w.Header().Get("Etag"))
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+	for _, coding := range []string{Gzip, Zstd} {
+		w = serve("GET", b, map[string]string{"Accept-Encoding": coding})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, b.Encoded(coding), w.Body.Bytes())
+		assert.Equal(t, `"ABC-`+coding+`"`, w.Header().Get("Etag"))
+		assert.Equal(t, coding, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		w = serve("GET", b, map[string]string{"Accept-Encoding": coding, "If-None-Match": `"ABC-` + coding + `"`})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.Bytes())
+
+		w = serve("HEAD", b, map[string]string{"Accept-Encoding": coding})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.Bytes())
+		assert.Equal(t, coding, w.Header().Get("Content-Encoding"))
+	}
+
+	// small contents are not compressed
+	w = serve("GET", New([]byte(`{}`)), map[string]string{"Accept-Encoding": Gzip})
+	assert.Equal(t, `{}`, w.Body.String())
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, `"ABC"`, w.Header().Get("Etag"))
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package internal holds helpers shared by the spec packages.
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// YAMLToJSON decodes a YAML value with unmarshal, as passed to the
+// UnmarshalYAML method of yaml.Unmarshaler, and returns its JSON encoding.
+// Scalar mapping keys, e.g. the status codes of responses, become strings.
+// Integers keep their precision.
+func YAMLToJSON(unmarshal func(interface{}) error) ([]byte, error) {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return nil, err
+	}
+	j, err := yamlToJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+func yamlToJSONValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			var key string
+			switch k := k.(type) {
+			case string:
+				key = k
+			case int, int64, uint64, float64, bool:
+				key = fmt.Sprint(k)
+			default:
+				return nil, fmt.Errorf("unsupported map key of type %T: %v", k, k)
+			}
+			j, err := yamlToJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			ret[key] = j
+		}
+		return ret, nil
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, e := range v {
+			j, err := yamlToJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = j
+		}
+		return ret, nil
+	}
+	return v, nil
+}
+
+// JSONToYAML returns the value to marshal as YAML for data, a JSON document,
+// as returned by the MarshalYAML method of yaml.Marshaler. Keys are sorted,
+// as in JSON, and numbers keep their precision.
+func JSONToYAML(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return jsonToYAMLValue(v), nil
+}
+
+func jsonToYAMLValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		ret := make(yaml.MapSlice, 0, len(v))
+		for _, k := range keys {
+			ret = append(ret, yaml.MapItem{Key: k, Value: jsonToYAMLValue(v[k])})
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, e := range v {
+			ret[i] = jsonToYAMLValue(e)
+		}
+		return ret
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return u
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// NewSpecFilter returns a filter for the OpenAPI v2 service redacting the spec
+// served to each request with the options of its key. key returns the empty
+// string for the requests served the whole spec, e.g. the cluster admins.
+func NewSpecFilter(key func(r *http.Request) string, options func(key string) Options) *common.SpecFilter {
+	return &common.SpecFilter{
+		Key: key,
+		Apply: func(key string, data []byte) ([]byte, error) {
+			var sp spec.Swagger
+			if err := json.Unmarshal(data, &sp); err != nil {
+				return nil, err
+			}
+			return json.Marshal(Redact(&sp, options(key)))
+		},
+	}
+}
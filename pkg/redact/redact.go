@@ -0,0 +1,369 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact removes designated paths and definitions from an OpenAPI
+// spec, e.g. to serve each tenant a spec without the other tenants' custom
+// resources. Redact can be applied per peer before serving a spec, or offline
+// with the openapi-redact command.
+package redact
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/schemamutation"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	gvkKey           = "x-kubernetes-group-version-kind"
+	definitionPrefix = "#/definitions/"
+	parameterPrefix  = "#/parameters/"
+	responsePrefix   = "#/responses/"
+)
+
+// GroupVersionKind selects definitions and operations by their
+// x-kubernetes-group-version-kind extension. Empty fields match any value.
+type GroupVersionKind struct {
+	Group   string
+	Version string
+	Kind    string
+}
+
+func (gvk GroupVersionKind) matches(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return matchField(gvk.Group, m["group"]) && matchField(gvk.Version, m["version"]) && matchField(gvk.Kind, m["kind"])
+}
+
+func matchField(want string, got interface{}) bool {
+	return want == "" || want == fmt.Sprint(got)
+}
+
+// Options specifies what Redact removes.
+type Options struct {
+	// PathPrefixes removes the paths starting with one of the prefixes.
+	PathPrefixes []string
+	// GroupVersionKinds removes the definitions whose group-version-kinds all
+	// match one of the selectors, and the paths with an operation matching one
+	// of the selectors.
+	GroupVersionKinds []GroupVersionKind
+	// Extensions removes the definitions and the paths with an operation
+	// whose vendor extension, the key, has one of the string values.
+	Extensions map[string][]string
+
+	// ScrubDescriptions clears the descriptions of the schemas, operations,
+	// parameters and responses left.
+	ScrubDescriptions bool
+	// ScrubExamples clears the examples of the schemas and responses left.
+	ScrubExamples bool
+}
+
+// Redact returns sp without the paths and definitions selected by opts.
+// Definitions, top-level parameters and top-level responses referencing a
+// removed definition are removed too, as well as the paths referencing any of
+// them. Definitions, parameters and responses only used by removed paths are
+// dropped, so that they do not reveal what was removed.
+//
+// The input is not mutated, but the output shares data with it.
+func Redact(sp *spec.Swagger, opts Options) *spec.Swagger {
+	refs := make(map[string][]string, len(sp.Definitions))
+	for name, def := range sp.Definitions {
+		def := def
+		refs[name] = schemaRefs(&def)
+	}
+
+	removed := map[string]bool{}
+	for name, def := range sp.Definitions {
+		if opts.matchesDefinition(def.Extensions) {
+			removed[name] = true
+		}
+	}
+	// remove the definitions referencing removed ones, until there is no more
+	for changed := len(removed) > 0; changed; {
+		changed = false
+		for name, names := range refs {
+			if removed[name] {
+				continue
+			}
+			for _, n := range names {
+				if removed[n] {
+					removed[name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	// top-level parameters and responses referencing removed definitions
+	// are removed too
+	removedParameters := map[string]bool{}
+	for name, p := range sp.Parameters {
+		if referencesAny(parameterRefs(p), removed) {
+			removedParameters[name] = true
+		}
+	}
+	removedResponses := map[string]bool{}
+	for name, r := range sp.Responses {
+		if referencesAny(responseRefs(r), removed) {
+			removedResponses[name] = true
+		}
+	}
+
+	ret := *sp
+	var initiallyUsed, used map[string]bool
+	var initiallyUsedParameters, usedParameters, initiallyUsedResponses, usedResponses map[string]bool
+	if sp.Paths != nil {
+		initiallyUsedParameters = toSet(allPathRefs(sp.Paths.Paths, parameterPrefix))
+		initiallyUsedResponses = toSet(allPathRefs(sp.Paths.Paths, responsePrefix))
+		initiallyUsed = reachable(refs, append(allPathRefs(sp.Paths.Paths, definitionPrefix),
+			topLevelRefs(sp, initiallyUsedParameters, initiallyUsedResponses)...))
+		ret.Paths = &spec.Paths{
+			VendorExtensible: sp.Paths.VendorExtensible,
+			Paths:            map[string]spec.PathItem{},
+		}
+		for path, item := range sp.Paths.Paths {
+			if opts.removesPath(path, &item, removed) ||
+				referencesAny(pathRefs(&item, parameterPrefix), removedParameters) ||
+				referencesAny(pathRefs(&item, responsePrefix), removedResponses) {
+				continue
+			}
+			ret.Paths.Paths[path] = item
+		}
+		usedParameters = toSet(allPathRefs(ret.Paths.Paths, parameterPrefix))
+		usedResponses = toSet(allPathRefs(ret.Paths.Paths, responsePrefix))
+	}
+
+	if sp.Parameters != nil {
+		ret.Parameters = map[string]spec.Parameter{}
+		for name, p := range sp.Parameters {
+			if removedParameters[name] || (initiallyUsedParameters[name] && !usedParameters[name]) {
+				continue
+			}
+			ret.Parameters[name] = p
+		}
+	}
+	if sp.Responses != nil {
+		ret.Responses = map[string]spec.Response{}
+		for name, r := range sp.Responses {
+			if removedResponses[name] || (initiallyUsedResponses[name] && !usedResponses[name]) {
+				continue
+			}
+			ret.Responses[name] = r
+		}
+	}
+	if ret.Paths != nil {
+		used = reachable(refs, append(allPathRefs(ret.Paths.Paths, definitionPrefix),
+			topLevelRefs(&ret, nil, nil)...))
+	}
+
+	ret.Definitions = spec.Definitions{}
+	for name, def := range sp.Definitions {
+		if removed[name] || (initiallyUsed[name] && !used[name]) {
+			continue
+		}
+		ret.Definitions[name] = def
+	}
+
+	if opts.ScrubDescriptions || opts.ScrubExamples {
+		scrub(&ret, opts)
+	}
+	return &ret
+}
+
+func (opts *Options) matchesDefinition(ext spec.Extensions) bool {
+	if opts.matchesExtensions(ext) {
+		return true
+	}
+	gvks, ok := ext[gvkKey].([]interface{})
+	if !ok || len(gvks) == 0 || len(opts.GroupVersionKinds) == 0 {
+		return false
+	}
+	// definitions shared by several group-versions, e.g. DeleteOptions, are
+	// only removed if all of them are
+	for _, gvk := range gvks {
+		if !opts.matchesGVK(gvk) {
+			return false
+		}
+	}
+	return true
+}
+
+func (opts *Options) matchesGVK(v interface{}) bool {
+	for _, gvk := range opts.GroupVersionKinds {
+		if gvk.matches(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts *Options) matchesExtensions(ext spec.Extensions) bool {
+	for key, values := range opts.Extensions {
+		v, ok := ext.GetString(key)
+		if !ok {
+			continue
+		}
+		for _, value := range values {
+			if v == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (opts *Options) removesPath(path string, item *spec.PathItem, removed map[string]bool) bool {
+	for _, prefix := range opts.PathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	if opts.matchesExtensions(item.Extensions) {
+		return true
+	}
+	for _, op := range operations(item) {
+		if opts.matchesExtensions(op.Extensions) {
+			return true
+		}
+		if gvk, ok := op.Extensions[gvkKey]; ok && opts.matchesGVK(gvk) {
+			return true
+		}
+	}
+	return referencesAny(pathRefs(item, definitionPrefix), removed)
+}
+
+func referencesAny(names []string, set map[string]bool) bool {
+	for _, name := range names {
+		if set[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func operations(item *spec.PathItem) []*spec.Operation {
+	var ret []*spec.Operation
+	for _, op := range []*spec.Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch} {
+		if op != nil {
+			ret = append(ret, op)
+		}
+	}
+	return ret
+}
+
+// refCollector returns a walker appending the names of the items referenced
+// under prefix, e.g. the definitions, to names. Names are unescaped as JSON
+// pointer tokens.
+func refCollector(prefix string, names *[]string) *schemamutation.Walker {
+	return &schemamutation.Walker{
+		SchemaCallback: schemamutation.SchemaCallBackNoop,
+		RefCallback: func(ref *spec.Ref) *spec.Ref {
+			if r := ref.String(); strings.HasPrefix(r, prefix) {
+				*names = append(*names, jsonpointer.Unescape(r[len(prefix):]))
+			}
+			return ref
+		},
+	}
+}
+
+// schemaRefs returns the definitions directly referenced by s.
+func schemaRefs(s *spec.Schema) []string {
+	var names []string
+	refCollector(definitionPrefix, &names).WalkSchema(s)
+	return names
+}
+
+// parameterRefs returns the definitions directly referenced by p.
+func parameterRefs(p spec.Parameter) []string {
+	var names []string
+	refCollector(definitionPrefix, &names).WalkRoot(&spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Parameters: map[string]spec.Parameter{"": p},
+	}})
+	return names
+}
+
+// responseRefs returns the definitions directly referenced by r.
+func responseRefs(r spec.Response) []string {
+	var names []string
+	refCollector(definitionPrefix, &names).WalkRoot(&spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Responses: map[string]spec.Response{"": r},
+	}})
+	return names
+}
+
+// topLevelRefs returns the definitions directly referenced by the top-level
+// parameters and responses of sp in the given sets, or by all of them if the
+// sets are nil.
+func topLevelRefs(sp *spec.Swagger, parameters, responses map[string]bool) []string {
+	var names []string
+	for name, p := range sp.Parameters {
+		if parameters == nil || parameters[name] {
+			names = append(names, parameterRefs(p)...)
+		}
+	}
+	for name, r := range sp.Responses {
+		if responses == nil || responses[name] {
+			names = append(names, responseRefs(r)...)
+		}
+	}
+	return names
+}
+
+// pathRefs returns the items referenced under prefix by the path item.
+func pathRefs(item *spec.PathItem, prefix string) []string {
+	var names []string
+	refCollector(prefix, &names).WalkRoot(&spec.Swagger{SwaggerProps: spec.SwaggerProps{
+		Paths: &spec.Paths{Paths: map[string]spec.PathItem{"": *item}},
+	}})
+	return names
+}
+
+func allPathRefs(paths map[string]spec.PathItem, prefix string) []string {
+	var names []string
+	for _, item := range paths {
+		item := item
+		names = append(names, pathRefs(&item, prefix)...)
+	}
+	return names
+}
+
+func toSet(names []string) map[string]bool {
+	ret := make(map[string]bool, len(names))
+	for _, name := range names {
+		ret[name] = true
+	}
+	return ret
+}
+
+// reachable returns the definitions in roots and those they reference, transitively.
+func reachable(refs map[string][]string, roots []string) map[string]bool {
+	ret := map[string]bool{}
+	for len(roots) > 0 {
+		name := roots[len(roots)-1]
+		roots = roots[:len(roots)-1]
+		if ret[name] {
+			continue
+		}
+		ret[name] = true
+		roots = append(roots, refs[name]...)
+	}
+	return ret
+}
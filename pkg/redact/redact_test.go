@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const testSpec = `{
+	"swagger": "2.0",
+	"paths": {
+		"/apis/a.example.com/v1/widgets": {
+			"get": {
+				"description": "list widgets",
+				"x-kubernetes-group-version-kind": {"group": "a.example.com", "version": "v1", "kind": "Widget"},
+				"parameters": [{"name": "limit", "in": "query", "type": "integer", "description": "page size"}],
+				"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/com.example.a.v1.WidgetList"}, "examples": {"application/json": {}}}}
+			}
+		},
+		"/apis/b.example.com/v1/gadgets": {
+			"get": {
+				"x-kubernetes-group-version-kind": {"group": "b.example.com", "version": "v1", "kind": "Gadget"},
+				"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/com.example.b.v1.Gadget"}}}
+			}
+		},
+		"/apis/c.example.com/v1/things": {
+			"get": {
+				"x-tenant": "c",
+				"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/com.example.c.v1.Thing"}}}
+			}
+		},
+		"/apis/d.example.com/v1/mixed": {
+			"get": {
+				"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/com.example.d.v1.Mixed"}}}
+			}
+		},
+		"/debug/pprof": {"get": {"responses": {"200": {"description": "OK"}}}}
+	},
+	"definitions": {
+		"com.example.a.v1.Widget": {
+			"description": "Widget is secret",
+			"x-kubernetes-group-version-kind": [{"group": "a.example.com", "version": "v1", "kind": "Widget"}],
+			"properties": {"spec": {"$ref": "#/definitions/com.example.a.v1.WidgetSpec"}}
+		},
+		"com.example.a.v1.WidgetSpec": {"type": "object"},
+		"com.example.a.v1.WidgetList": {
+			"x-kubernetes-group-version-kind": [{"group": "a.example.com", "version": "v1", "kind": "WidgetList"}],
+			"properties": {"items": {"type": "array", "items": {"$ref": "#/definitions/com.example.a.v1.Widget"}}}
+		},
+		"com.example.b.v1.Gadget": {
+			"description": "Gadget is public",
+			"example": {"name": "g"},
+			"x-kubernetes-group-version-kind": [{"group": "b.example.com", "version": "v1", "kind": "Gadget"}],
+			"properties": {"name": {"type": "string", "description": "the name"}, "options": {"$ref": "#/definitions/DeleteOptions"}}
+		},
+		"com.example.c.v1.Thing": {"x-tenant": "c", "type": "object"},
+		"com.example.d.v1.Mixed": {"properties": {"widget": {"$ref": "#/definitions/com.example.a.v1.Widget"}}},
+		"DeleteOptions": {
+			"x-kubernetes-group-version-kind": [
+				{"group": "a.example.com", "version": "v1", "kind": "DeleteOptions"},
+				{"group": "b.example.com", "version": "v1", "kind": "DeleteOptions"}
+			]
+		},
+		"Unused": {"type": "string"}
+	}
+}`
+
+func loadTestSpec(t *testing.T) *spec.Swagger {
+	sp := &spec.Swagger{}
+	require.NoError(t, json.Unmarshal([]byte(testSpec), sp))
+	return sp
+}
+
+func pathNames(sp *spec.Swagger) []string {
+	var ret []string
+	for k := range sp.Paths.Paths {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func definitionNames(sp *spec.Swagger) []string {
+	var ret []string
+	for k := range sp.Definitions {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestRedact(t *testing.T) {
+	sp := loadTestSpec(t)
+	ret := Redact(sp, Options{
+		PathPrefixes:      []string{"/debug/"},
+		GroupVersionKinds: []GroupVersionKind{{Group: "a.example.com"}},
+		Extensions:        map[string][]string{"x-tenant": {"c"}},
+	})
+
+	assert.Equal(t, []string{"/apis/b.example.com/v1/gadgets"}, pathNames(ret))
+	assert.Equal(t, []string{"DeleteOptions", "Unused", "com.example.b.v1.Gadget"}, definitionNames(ret))
+	assert.Equal(t, loadTestSpec(t), sp, "the input must not be mutated")
+}
+
+func TestRedactNothing(t *testing.T) {
+	sp := loadTestSpec(t)
+	ret := Redact(sp, Options{})
+	assert.Equal(t, sp, ret)
+}
+
+func TestNewSpecFilter(t *testing.T) {
+	filter := NewSpecFilter(func(r *http.Request) string {
+		return r.Header.Get("X-Tenant")
+	}, func(key string) Options {
+		return Options{GroupVersionKinds: []GroupVersionKind{{Group: key + ".example.com"}}}
+	})
+
+	r := httptest.NewRequest("GET", "/openapi/v2", nil)
+	r.Header.Set("X-Tenant", "a")
+	key := filter.Key(r)
+	require.Equal(t, "a", key)
+
+	data, err := json.Marshal(loadTestSpec(t))
+	require.NoError(t, err)
+	filtered, err := filter.Apply(key, data)
+	require.NoError(t, err)
+	var ret spec.Swagger
+	require.NoError(t, json.Unmarshal(filtered, &ret))
+	assert.Equal(t, pathNames(Redact(loadTestSpec(t), Options{GroupVersionKinds: []GroupVersionKind{{Group: "a.example.com"}}})), pathNames(&ret))
+	assert.NotContains(t, pathNames(&ret), "/apis/a.example.com/v1/widgets")
+
+	_, err = filter.Apply(key, []byte("{"))
+	assert.Error(t, err)
+}
+
+func TestScrub(t *testing.T) {
+	sp := loadTestSpec(t)
+	ret := Redact(sp, Options{ScrubDescriptions: true, ScrubExamples: true})
+
+	gadget := ret.Definitions["com.example.b.v1.Gadget"]
+	assert.Empty(t, gadget.Description)
+	assert.Nil(t, gadget.Example)
+	assert.Empty(t, gadget.Properties["name"].Description)
+
+	op := ret.Paths.Paths["/apis/a.example.com/v1/widgets"].Get
+	assert.Empty(t, op.Description)
+	assert.Empty(t, op.Parameters[0].Description)
+	assert.Nil(t, op.Responses.StatusCodeResponses[200].Examples)
+	assert.Empty(t, op.Responses.StatusCodeResponses[200].Description)
+
+	assert.Equal(t, loadTestSpec(t), sp, "the input must not be mutated")
+}
+
+const topLevelTestSpec = `{
+	"swagger": "2.0",
+	"parameters": {
+		"widget": {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/io.example~1widget.v1.Widget"}},
+		"gadget": {"name": "body", "in": "body", "description": "a gadget", "schema": {"$ref": "#/definitions/Gadget"}}
+	},
+	"responses": {
+		"widget": {"description": "a widget", "schema": {"$ref": "#/definitions/io.example~1widget.v1.Widget"}},
+		"gadget": {"description": "a gadget", "schema": {"$ref": "#/definitions/Gadget"}},
+		"status": {"description": "a status", "schema": {"$ref": "#/definitions/Status~0v1"}}
+	},
+	"paths": {
+		"/widgets": {"post": {
+			"parameters": [{"$ref": "#/parameters/widget"}],
+			"responses": {"200": {"description": "OK"}}
+		}},
+		"/widgets/status": {"get": {
+			"responses": {"200": {"$ref": "#/responses/widget"}}
+		}},
+		"/gadgets": {"post": {
+			"parameters": [{"$ref": "#/parameters/gadget"}],
+			"responses": {"200": {"$ref": "#/responses/gadget"}}
+		}},
+		"/debug": {"get": {
+			"responses": {"200": {"$ref": "#/responses/status"}}
+		}}
+	},
+	"definitions": {
+		"io.example/widget.v1.Widget": {"x-tenant": "w", "type": "object"},
+		"Gadget": {"type": "object"},
+		"Status~v1": {"type": "object"}
+	}
+}`
+
+func TestRedactTopLevelParametersAndResponses(t *testing.T) {
+	sp := &spec.Swagger{}
+	require.NoError(t, json.Unmarshal([]byte(topLevelTestSpec), sp))
+	ret := Redact(sp, Options{
+		PathPrefixes:      []string{"/debug"},
+		Extensions:        map[string][]string{"x-tenant": {"w"}},
+		ScrubDescriptions: true,
+	})
+
+	assert.Equal(t, []string{"/gadgets"}, pathNames(ret))
+	assert.Equal(t, []string{"Gadget"}, definitionNames(ret))
+	assert.Len(t, ret.Parameters, 1)
+	assert.Contains(t, ret.Parameters, "gadget")
+	assert.Len(t, ret.Responses, 1)
+	assert.Contains(t, ret.Responses, "gadget")
+	assert.Empty(t, ret.Parameters["gadget"].Description)
+	assert.Empty(t, ret.Responses["gadget"].Description)
+}
+
+func TestRefCollectorUnescapesTokens(t *testing.T) {
+	s := spec.RefSchema("#/definitions/a~1b~0c~01")
+	assert.Equal(t, []string{"a/b~c~1"}, schemaRefs(s))
+}
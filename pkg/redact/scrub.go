@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"k8s.io/kube-openapi/pkg/schemamutation"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// scrub clears the descriptions and examples of sp as specified by opts.
+// Everything changed is copied first, as sp shares data with the input of Redact.
+func scrub(sp *spec.Swagger, opts Options) {
+	walker := &schemamutation.Walker{
+		SchemaCallback: func(s *spec.Schema) *spec.Schema {
+			if (!opts.ScrubDescriptions || s.Description == "") && (!opts.ScrubExamples || s.Example == nil) {
+				return s
+			}
+			clone := *s
+			if opts.ScrubDescriptions {
+				clone.Description = ""
+			}
+			if opts.ScrubExamples {
+				clone.Example = nil
+			}
+			return &clone
+		},
+		RefCallback: schemamutation.RefCallbackNoop,
+	}
+	*sp = *walker.WalkRoot(sp)
+
+	if sp.Parameters != nil {
+		sp.Parameters = scrubParameterMap(sp.Parameters, opts)
+	}
+	if sp.Responses != nil {
+		responses := make(map[string]spec.Response, len(sp.Responses))
+		for name, r := range sp.Responses {
+			responses[name] = scrubResponse(r, opts)
+		}
+		sp.Responses = responses
+	}
+	if sp.Paths == nil {
+		return
+	}
+	paths := make(map[string]spec.PathItem, len(sp.Paths.Paths))
+	for path, item := range sp.Paths.Paths {
+		item.Parameters = scrubParameters(item.Parameters, opts)
+		item.Get = scrubOperation(item.Get, opts)
+		item.Put = scrubOperation(item.Put, opts)
+		item.Post = scrubOperation(item.Post, opts)
+		item.Delete = scrubOperation(item.Delete, opts)
+		item.Options = scrubOperation(item.Options, opts)
+		item.Head = scrubOperation(item.Head, opts)
+		item.Patch = scrubOperation(item.Patch, opts)
+		paths[path] = item
+	}
+	sp.Paths = &spec.Paths{VendorExtensible: sp.Paths.VendorExtensible, Paths: paths}
+}
+
+func scrubOperation(op *spec.Operation, opts Options) *spec.Operation {
+	if op == nil {
+		return nil
+	}
+	clone := *op
+	if opts.ScrubDescriptions {
+		clone.Description = ""
+	}
+	clone.Parameters = scrubParameters(op.Parameters, opts)
+	if op.Responses != nil {
+		responses := *op.Responses
+		if responses.Default != nil {
+			r := scrubResponse(*responses.Default, opts)
+			responses.Default = &r
+		}
+		if responses.StatusCodeResponses != nil {
+			responses.StatusCodeResponses = make(map[int]spec.Response, len(op.Responses.StatusCodeResponses))
+			for code, r := range op.Responses.StatusCodeResponses {
+				responses.StatusCodeResponses[code] = scrubResponse(r, opts)
+			}
+		}
+		clone.Responses = &responses
+	}
+	return &clone
+}
+
+func scrubResponse(r spec.Response, opts Options) spec.Response {
+	if opts.ScrubDescriptions {
+		r.Description = ""
+	}
+	if opts.ScrubExamples {
+		r.Examples = nil
+	}
+	return r
+}
+
+func scrubParameters(params []spec.Parameter, opts Options) []spec.Parameter {
+	if !opts.ScrubDescriptions || params == nil {
+		return params
+	}
+	ret := make([]spec.Parameter, len(params))
+	for i, p := range params {
+		p.Description = ""
+		ret[i] = p
+	}
+	return ret
+}
+
+func scrubParameterMap(params map[string]spec.Parameter, opts Options) map[string]spec.Parameter {
+	if !opts.ScrubDescriptions {
+		return params
+	}
+	ret := make(map[string]spec.Parameter, len(params))
+	for name, p := range params {
+		p.Description = ""
+		ret[name] = p
+	}
+	return ret
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamutation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const definitionPrefix = "#/definitions/"
+
+// InlineOptions controls which references are inlined by InlineDefinition.
+// The zero value inlines everything but cycles.
+type InlineOptions struct {
+	// KeepRef returns true for the definitions that stay references, e.g. the
+	// shared meta/v1 types. If nil, all definitions are inlined.
+	KeepRef func(name string) bool
+	// MaxDepth is the number of nested references that are inlined. References
+	// nested deeper are kept. Zero means no limit.
+	MaxDepth int
+}
+
+// KeepRefPrefixes returns an InlineOptions.KeepRef function keeping the
+// definitions whose name starts with one of prefixes.
+func KeepRefPrefixes(prefixes ...string) func(name string) bool {
+	return func(name string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(name, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InlineDefinition returns the definition called name with the references to
+// other definitions replaced by their content, as specified by opts. It also
+// returns the definitions still referenced by the result, directly or not, so
+// that the result can be published along with them.
+//
+// References forming a cycle are always kept, as the schema cannot be fully
+// inlined. The description and default of a referring schema take precedence
+// over those of the inlined definition. defs is not mutated.
+func InlineDefinition(defs spec.Definitions, name string, opts InlineOptions) (*spec.Schema, spec.Definitions, error) {
+	def, ok := defs[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("definition %q not found", name)
+	}
+	in := &inliner{defs: defs, opts: opts, stack: map[string]bool{name: true}}
+	result := in.inline(def, 0)
+	if in.err != nil {
+		return nil, nil, in.err
+	}
+
+	// collect the definitions still referenced, transitively
+	kept := spec.Definitions{}
+	var queue []string
+	collect := &Walker{
+		SchemaCallback: SchemaCallBackNoop,
+		RefCallback: func(ref *spec.Ref) *spec.Ref {
+			if n, ok := ref.LocalName(definitionPrefix); ok {
+				if _, found := kept[n]; !found {
+					kept[n] = spec.Schema{}
+					queue = append(queue, n)
+				}
+			}
+			return ref
+		},
+	}
+	collect.WalkSchema(&result)
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		s, ok := defs[n]
+		if !ok {
+			return nil, nil, fmt.Errorf("reference to unknown definition %q", n)
+		}
+		kept[n] = s
+		collect.WalkSchema(&s)
+	}
+	return &result, kept, nil
+}
+
+type inliner struct {
+	defs spec.Definitions
+	opts InlineOptions
+	// stack holds the definitions being inlined, to detect cycles.
+	stack map[string]bool
+	err   error
+}
+
+// inline returns a copy of s with references inlined. depth is the number of
+// references inlined to reach s.
+func (in *inliner) inline(s spec.Schema, depth int) spec.Schema {
+	if in.err != nil {
+		return s
+	}
+	if ref := s.Ref.String(); ref != "" {
+		name, ok := s.Ref.LocalName(definitionPrefix)
+		if !ok {
+			in.err = fmt.Errorf("unsupported reference %q", ref)
+			return s
+		}
+		def, found := in.defs[name]
+		if !found {
+			in.err = fmt.Errorf("reference to unknown definition %q", name)
+			return s
+		}
+		if in.stack[name] || (in.opts.MaxDepth > 0 && depth >= in.opts.MaxDepth) || (in.opts.KeepRef != nil && in.opts.KeepRef(name)) {
+			return s
+		}
+
+		in.stack[name] = true
+		inlined := in.inline(def, depth+1)
+		delete(in.stack, name)
+		if s.Description != "" {
+			inlined.Description = s.Description
+		}
+		if s.Default != nil {
+			inlined.Default = s.Default
+		}
+		return inlined
+	}
+
+	if s.Items != nil {
+		items := *s.Items
+		if items.Schema != nil {
+			c := in.inline(*items.Schema, depth)
+			items.Schema = &c
+		}
+		items.Schemas = in.inlineSlice(items.Schemas, depth)
+		s.Items = &items
+	}
+	s.AllOf = in.inlineSlice(s.AllOf, depth)
+	s.AnyOf = in.inlineSlice(s.AnyOf, depth)
+	s.OneOf = in.inlineSlice(s.OneOf, depth)
+	s.PrefixItems = in.inlineSlice(s.PrefixItems, depth)
+	if s.Not != nil {
+		c := in.inline(*s.Not, depth)
+		s.Not = &c
+	}
+	if s.ContentSchema != nil {
+		c := in.inline(*s.ContentSchema, depth)
+		s.ContentSchema = &c
+	}
+	if s.If != nil {
+		c := in.inline(*s.If, depth)
+		s.If = &c
+	}
+	if s.Then != nil {
+		c := in.inline(*s.Then, depth)
+		s.Then = &c
+	}
+	if s.Else != nil {
+		c := in.inline(*s.Else, depth)
+		s.Else = &c
+	}
+	s.Properties = in.inlineMap(s.Properties, depth)
+	s.PatternProperties = in.inlineMap(s.PatternProperties, depth)
+	s.DependentSchemas = in.inlineMap(s.DependentSchemas, depth)
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		c := in.inline(*s.AdditionalProperties.Schema, depth)
+		s.AdditionalProperties = &spec.SchemaOrBool{Allows: s.AdditionalProperties.Allows, Schema: &c}
+	}
+	if s.AdditionalItems != nil && s.AdditionalItems.Schema != nil {
+		c := in.inline(*s.AdditionalItems.Schema, depth)
+		s.AdditionalItems = &spec.SchemaOrBool{Allows: s.AdditionalItems.Allows, Schema: &c}
+	}
+	if s.UnevaluatedProperties != nil && s.UnevaluatedProperties.Schema != nil {
+		c := in.inline(*s.UnevaluatedProperties.Schema, depth)
+		s.UnevaluatedProperties = &spec.SchemaOrBool{Allows: s.UnevaluatedProperties.Allows, Schema: &c}
+	}
+	if s.UnevaluatedItems != nil && s.UnevaluatedItems.Schema != nil {
+		c := in.inline(*s.UnevaluatedItems.Schema, depth)
+		s.UnevaluatedItems = &spec.SchemaOrBool{Allows: s.UnevaluatedItems.Allows, Schema: &c}
+	}
+	if s.Dependencies != nil {
+		deps := make(spec.Dependencies, len(s.Dependencies))
+		for k, v := range s.Dependencies {
+			if v.Schema != nil {
+				c := in.inline(*v.Schema, depth)
+				v.Schema = &c
+			}
+			deps[k] = v
+		}
+		s.Dependencies = deps
+	}
+	return s
+}
+
+func (in *inliner) inlineSlice(schemas []spec.Schema, depth int) []spec.Schema {
+	if schemas == nil {
+		return nil
+	}
+	ret := make([]spec.Schema, len(schemas))
+	for i := range schemas {
+		ret[i] = in.inline(schemas[i], depth)
+	}
+	return ret
+}
+
+func (in *inliner) inlineMap(schemas map[string]spec.Schema, depth int) map[string]spec.Schema {
+	if schemas == nil {
+		return nil
+	}
+	// inline in a stable order so that the first error is deterministic
+	keys := make([]string, 0, len(schemas))
+	for k := range schemas {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ret := make(map[string]spec.Schema, len(schemas))
+	for _, k := range keys {
+		ret[k] = in.inline(schemas[k], depth)
+	}
+	return ret
+}
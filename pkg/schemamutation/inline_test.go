@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamutation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const inlineDefinitions = `{
+	"io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+		"type": "object",
+		"properties": {"name": {"type": "string"}, "owner": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference"}}
+	},
+	"io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference": {
+		"type": "object",
+		"properties": {"uid": {"type": "string"}}
+	},
+	"example.Widget": {
+		"type": "object",
+		"properties": {
+			"metadata": {"$ref": "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"},
+			"spec": {"description": "the spec", "$ref": "#/definitions/example.WidgetSpec"}
+		}
+	},
+	"example.WidgetSpec": {
+		"description": "WidgetSpec is the spec",
+		"type": "object",
+		"properties": {
+			"parts": {"type": "array", "items": {"$ref": "#/definitions/example.Part"}}
+		}
+	},
+	"example.Part": {
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"children": {"type": "array", "items": {"$ref": "#/definitions/example.Part"}}
+		}
+	}
+}`
+
+func loadInlineDefinitions(t *testing.T) spec.Definitions {
+	var defs spec.Definitions
+	require.NoError(t, json.Unmarshal([]byte(inlineDefinitions), &defs))
+	return defs
+}
+
+func keys(defs spec.Definitions) []string {
+	var ret []string
+	for k := range defs {
+		ret = append(ret, k)
+	}
+	return ret
+}
+
+func TestInlineDefinition(t *testing.T) {
+	defs := loadInlineDefinitions(t)
+
+	t.Run("full", func(t *testing.T) {
+		s, kept, err := InlineDefinition(defs, "example.Widget", InlineOptions{})
+		require.NoError(t, err)
+		owner := s.Properties["metadata"].Properties["owner"]
+		assert.Equal(t, "string", owner.Properties["uid"].Type[0])
+
+		widgetSpec := s.Properties["spec"]
+		assert.Equal(t, "the spec", widgetSpec.Description)
+		part := widgetSpec.Properties["parts"].Items.Schema
+		assert.Equal(t, "#/definitions/example.Part", part.Properties["children"].Items.Schema.Ref.String(), "cycles are kept")
+		assert.ElementsMatch(t, []string{"example.Part"}, keys(kept))
+	})
+
+	t.Run("keep shared", func(t *testing.T) {
+		s, kept, err := InlineDefinition(defs, "example.Widget", InlineOptions{
+			KeepRef: KeepRefPrefixes("io.k8s.apimachinery.pkg.apis.meta.v1."),
+		})
+		require.NoError(t, err)
+		metadata := s.Properties["metadata"]
+		assert.Equal(t, "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta", metadata.Ref.String())
+		assert.ElementsMatch(t, []string{
+			"io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta",
+			"io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference",
+			"example.Part",
+		}, keys(kept))
+	})
+
+	t.Run("depth limited", func(t *testing.T) {
+		s, kept, err := InlineDefinition(defs, "example.Widget", InlineOptions{MaxDepth: 1})
+		require.NoError(t, err)
+		widgetSpec := s.Properties["spec"]
+		assert.Equal(t, "#/definitions/example.Part", widgetSpec.Properties["parts"].Items.Schema.Ref.String())
+		assert.ElementsMatch(t, []string{"io.k8s.apimachinery.pkg.apis.meta.v1.OwnerReference", "example.Part"}, keys(kept))
+	})
+
+	// the input is left untouched
+	assert.Equal(t, loadInlineDefinitions(t), defs)
+}
+
+func TestInlineDefinitionErrors(t *testing.T) {
+	defs := loadInlineDefinitions(t)
+	_, _, err := InlineDefinition(defs, "example.Missing", InlineOptions{})
+	assert.Error(t, err)
+
+	defs["example.Broken"] = *spec.RefSchema("#/definitions/example.Missing")
+	_, _, err = InlineDefinition(defs, "example.Broken", InlineOptions{})
+	assert.Error(t, err)
+}
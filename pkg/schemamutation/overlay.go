@@ -0,0 +1,362 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamutation
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ConflictPolicy decides the value of a keyword set to different values by
+// the base and the overlay of Overlay.
+type ConflictPolicy int
+
+const (
+	// OverlayWins keeps the value of the overlay.
+	OverlayWins ConflictPolicy = iota
+	// BaseWins keeps the value of the base.
+	BaseWins
+	// FailOnConflict makes Overlay return an error.
+	FailOnConflict
+)
+
+// OverlayOptions configures Overlay.
+type OverlayOptions struct {
+	// Conflicts is the policy for keywords set to different values.
+	Conflicts ConflictPolicy
+}
+
+// Overlay returns base with the keywords set in overlay, e.g. to add
+// descriptions, defaults or validation rules to a generated schema. Neither
+// base nor overlay is mutated.
+//
+// The keywords set in overlay, i.e. with a non-zero value, are added to base.
+// Subschemas, e.g. properties, items or additionalProperties, are merged
+// recursively. The required properties, allOf subschemas and
+// x-kubernetes-validations rules of both are kept. Other keywords set in both
+// to different values are resolved by opts.Conflicts.
+func Overlay(base, overlay *spec.Schema, opts OverlayOptions) (*spec.Schema, error) {
+	result := base.DeepCopy()
+	if result == nil {
+		result = &spec.Schema{}
+	}
+	if overlay == nil {
+		return result, nil
+	}
+	o := &overlayer{opts: opts}
+	if err := o.merge("", result, overlay.DeepCopy()); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type overlayer struct {
+	opts OverlayOptions
+}
+
+// conflict returns true if the overlay value of keyword at path replaces the
+// base one.
+func (o *overlayer) conflict(path, keyword string) (bool, error) {
+	switch o.opts.Conflicts {
+	case BaseWins:
+		return false, nil
+	case FailOnConflict:
+		if path == "" {
+			path = "/"
+		}
+		return false, fmt.Errorf("%s: conflicting values for %s", path, keyword)
+	}
+	return true, nil
+}
+
+// merge merges overlay into base. overlay is owned by merge, its values are
+// moved to base.
+func (o *overlayer) merge(path string, base, overlay *spec.Schema) error {
+	if s := overlay.Ref.String(); s != "" && s != base.Ref.String() {
+		if base.Ref.String() == "" {
+			base.Ref = overlay.Ref
+		} else if replace, err := o.conflict(path, "$ref"); err != nil {
+			return err
+		} else if replace {
+			base.Ref = overlay.Ref
+		}
+	}
+
+	for _, r := range overlay.Required {
+		if !spec.StringOrArray(base.Required).Contains(r) {
+			base.Required = append(base.Required, r)
+		}
+	}
+	base.AllOf = append(base.AllOf, overlay.AllOf...)
+
+	for _, m := range []struct {
+		keyword       string
+		base, overlay *map[string]spec.Schema
+	}{
+		{"properties", &base.Properties, &overlay.Properties},
+		{"patternProperties", &base.PatternProperties, &overlay.PatternProperties},
+		{"dependentSchemas", &base.DependentSchemas, &overlay.DependentSchemas},
+		{"definitions", (*map[string]spec.Schema)(&base.Definitions), (*map[string]spec.Schema)(&overlay.Definitions)},
+	} {
+		if err := o.mergeSchemaMap(path+"/"+m.keyword, m.base, *m.overlay); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range []struct {
+		keyword       string
+		base, overlay **spec.Schema
+	}{
+		{"not", &base.Not, &overlay.Not},
+		{"if", &base.If, &overlay.If},
+		{"then", &base.Then, &overlay.Then},
+		{"else", &base.Else, &overlay.Else},
+		{"contentSchema", &base.ContentSchema, &overlay.ContentSchema},
+	} {
+		if *s.base != nil && *s.overlay != nil {
+			if err := o.merge(path+"/"+s.keyword, *s.base, *s.overlay); err != nil {
+				return err
+			}
+		} else if *s.overlay != nil {
+			*s.base = *s.overlay
+		}
+	}
+
+	for _, s := range []struct {
+		keyword       string
+		base, overlay **spec.SchemaOrBool
+	}{
+		{"additionalProperties", &base.AdditionalProperties, &overlay.AdditionalProperties},
+		{"additionalItems", &base.AdditionalItems, &overlay.AdditionalItems},
+		{"unevaluatedProperties", &base.UnevaluatedProperties, &overlay.UnevaluatedProperties},
+		{"unevaluatedItems", &base.UnevaluatedItems, &overlay.UnevaluatedItems},
+	} {
+		b, ov := *s.base, *s.overlay
+		switch {
+		case ov == nil:
+		case b == nil:
+			*s.base = ov
+		case b.Schema != nil && ov.Schema != nil:
+			if err := o.merge(path+"/"+s.keyword, b.Schema, ov.Schema); err != nil {
+				return err
+			}
+		case !reflect.DeepEqual(b, ov):
+			if replace, err := o.conflict(path, s.keyword); err != nil {
+				return err
+			} else if replace {
+				*s.base = ov
+			}
+		}
+	}
+
+	if err := o.mergeItems(path, base, overlay); err != nil {
+		return err
+	}
+	if err := o.mergeSchemaList(path+"/prefixItems", &base.PrefixItems, overlay.PrefixItems); err != nil {
+		return err
+	}
+
+	// the remaining keywords are values, merged generically
+	for _, props := range []struct {
+		base, overlay reflect.Value
+	}{
+		{reflect.ValueOf(&base.SchemaProps).Elem(), reflect.ValueOf(&overlay.SchemaProps).Elem()},
+		{reflect.ValueOf(&base.SwaggerSchemaProps).Elem(), reflect.ValueOf(&overlay.SwaggerSchemaProps).Elem()},
+	} {
+		t := props.base.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if mergedKeywords[t.Field(i).Name] {
+				continue
+			}
+			if err := o.mergeValue(path, jsonName(t.Field(i)), props.base.Field(i), props.overlay.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := o.mergeExtensions(path, &base.Extensions, overlay.Extensions); err != nil {
+		return err
+	}
+	extra := spec.Extensions(base.ExtraProps)
+	if err := o.mergeExtensions(path, &extra, overlay.ExtraProps); err != nil {
+		return err
+	}
+	base.ExtraProps = extra
+	return nil
+}
+
+// mergedKeywords are the fields of SchemaProps and SwaggerSchemaProps merged
+// specifically by merge.
+var mergedKeywords = map[string]bool{
+	"Ref": true, "Required": true, "AllOf": true, "Items": true,
+	"Properties": true, "PatternProperties": true, "DependentSchemas": true, "Definitions": true,
+	"Not": true, "If": true, "Then": true, "Else": true, "ContentSchema": true,
+	"AdditionalProperties": true, "AdditionalItems": true, "UnevaluatedProperties": true, "UnevaluatedItems": true,
+	"PrefixItems": true,
+}
+
+func jsonName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
+
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func (o *overlayer) mergeValue(path, keyword string, base, overlay reflect.Value) error {
+	if isZeroValue(overlay) {
+		return nil
+	}
+	if isZeroValue(base) || reflect.DeepEqual(base.Interface(), overlay.Interface()) {
+		base.Set(overlay)
+		return nil
+	}
+	replace, err := o.conflict(path, keyword)
+	if err != nil {
+		return err
+	}
+	if replace {
+		base.Set(overlay)
+	}
+	return nil
+}
+
+func (o *overlayer) mergeSchemaMap(path string, base *map[string]spec.Schema, overlay map[string]spec.Schema) error {
+	// sorted for errors to be deterministic
+	keys := make([]string, 0, len(overlay))
+	for k := range overlay {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ov := overlay[k]
+		b, ok := (*base)[k]
+		if ok {
+			if err := o.merge(path+"/"+jsonpointer.Escape(k), &b, &ov); err != nil {
+				return err
+			}
+		} else {
+			b = ov
+		}
+		if *base == nil {
+			*base = map[string]spec.Schema{}
+		}
+		(*base)[k] = b
+	}
+	return nil
+}
+
+func (o *overlayer) mergeItems(path string, base, overlay *spec.Schema) error {
+	b, ov := base.Items, overlay.Items
+	switch {
+	case ov == nil:
+	case b == nil:
+		base.Items = ov
+	case b.Schema != nil && ov.Schema != nil:
+		return o.merge(path+"/items", b.Schema, ov.Schema)
+	case b.Schema == nil && ov.Schema == nil:
+		return o.mergeSchemaList(path+"/items", &b.Schemas, ov.Schemas)
+	default:
+		if replace, err := o.conflict(path, "items"); err != nil {
+			return err
+		} else if replace {
+			base.Items = ov
+		}
+	}
+	return nil
+}
+
+// mergeSchemaList merges the schemas of overlay into those of base with the
+// same index, e.g. tuple items.
+func (o *overlayer) mergeSchemaList(path string, base *[]spec.Schema, overlay []spec.Schema) error {
+	for i := range overlay {
+		if i >= len(*base) {
+			*base = append(*base, overlay[i:]...)
+			break
+		}
+		if err := o.merge(path+"/"+strconv.Itoa(i), &(*base)[i], &overlay[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *overlayer) mergeExtensions(path string, base *spec.Extensions, overlay spec.Extensions) error {
+	keys := make([]string, 0, len(overlay))
+	for k := range overlay {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ov := overlay[k]
+		b, ok := (*base)[k]
+		switch {
+		case !ok:
+		case reflect.DeepEqual(b, ov):
+			continue
+		case k == spec.ValidationsExtension:
+			ov = appendRules(b, ov)
+		default:
+			if replace, err := o.conflict(path, k); err != nil {
+				return err
+			} else if !replace {
+				continue
+			}
+		}
+		if *base == nil {
+			*base = spec.Extensions{}
+		}
+		(*base)[k] = ov
+	}
+	return nil
+}
+
+// appendRules returns the rules of base followed by those of overlay not in
+// base. Values which are not lists are replaced.
+func appendRules(base, overlay interface{}) interface{} {
+	b, ok := base.([]interface{})
+	ov, ok2 := overlay.([]interface{})
+	if !ok || !ok2 {
+		return overlay
+	}
+	for _, r := range ov {
+		found := false
+		for _, e := range b {
+			if reflect.DeepEqual(e, r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			b = append(b, r)
+		}
+	}
+	return b
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamutation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestOverlay(t *testing.T) {
+	parse := func(s string) *spec.Schema {
+		schema := &spec.Schema{}
+		require.NoError(t, json.Unmarshal([]byte(s), schema))
+		return schema
+	}
+	base := parse(`{
+		"type": "object",
+		"required": ["spec"],
+		"properties": {
+			"spec": {
+				"type": "object",
+				"properties": {
+					"replicas": {"type": "integer", "format": "int32"},
+					"ports": {"type": "array", "items": {"type": "integer"}}
+				},
+				"x-kubernetes-validations": [{"rule": "self.replicas >= 0"}]
+			}
+		}
+	}`)
+	baseJSON, err := json.Marshal(base)
+	require.NoError(t, err)
+	overlay := parse(`{
+		"description": "A widget.",
+		"required": ["spec", "status"],
+		"properties": {
+			"spec": {
+				"properties": {
+					"replicas": {"description": "Number of replicas.", "default": 1, "maximum": 10, "format": "int64"},
+					"ports": {"items": {"minimum": 1}}
+				},
+				"x-kubernetes-validations": [{"rule": "self.replicas >= 0"}, {"rule": "self.replicas <= 10"}]
+			},
+			"status": {"type": "object"}
+		}
+	}`)
+
+	result, err := Overlay(base, overlay, OverlayOptions{})
+	require.NoError(t, err)
+	b, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"description": "A widget.",
+		"required": ["spec", "status"],
+		"properties": {
+			"spec": {
+				"type": "object",
+				"properties": {
+					"replicas": {"type": "integer", "format": "int64", "description": "Number of replicas.", "default": 1, "maximum": 10},
+					"ports": {"type": "array", "items": {"type": "integer", "minimum": 1}}
+				},
+				"x-kubernetes-validations": [{"rule": "self.replicas >= 0"}, {"rule": "self.replicas <= 10"}]
+			},
+			"status": {"type": "object"}
+		}
+	}`, string(b))
+
+	// base and overlay are not mutated
+	b, err = json.Marshal(base)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(baseJSON), string(b))
+
+	result, err = Overlay(base, overlay, OverlayOptions{Conflicts: BaseWins})
+	require.NoError(t, err)
+	assert.Equal(t, "int32", result.Properties["spec"].Properties["replicas"].Format)
+	assert.Equal(t, "Number of replicas.", result.Properties["spec"].Properties["replicas"].Description)
+
+	_, err = Overlay(base, overlay, OverlayOptions{Conflicts: FailOnConflict})
+	assert.EqualError(t, err, "/properties/spec/properties/replicas: conflicting values for format")
+
+	result, err = Overlay(nil, overlay, OverlayOptions{Conflicts: FailOnConflict})
+	require.NoError(t, err)
+	assert.Equal(t, overlay, result)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamutation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const definitionsPrefix = "#/definitions/"
+
+// RenameDefinitions renames the definitions of sp by renames, from old to
+// new names, and rewrites the references to them and into them, e.g. in
+// the schemas of parameters and responses, without mutating the input.
+// The output might share data with the input.
+//
+// It fails if a new name is empty, is the new name of several definitions,
+// or is the name of a definition which is not renamed itself.
+func RenameDefinitions(sp *spec.Swagger, renames map[string]string) (*spec.Swagger, error) {
+	names := make([]string, 0, len(renames))
+	for from, to := range renames {
+		if from != to {
+			names = append(names, from)
+		}
+	}
+	if len(names) == 0 {
+		return sp, nil
+	}
+	// sorted for errors to be deterministic
+	sort.Strings(names)
+
+	renamedFrom := make(map[string]string, len(names))
+	for _, from := range names {
+		to := renames[from]
+		if to == "" {
+			return nil, fmt.Errorf("cannot rename definition %q to an empty name", from)
+		}
+		if other, found := renamedFrom[to]; found {
+			return nil, fmt.Errorf("cannot rename definitions %q and %q to %q", other, from, to)
+		}
+		renamedFrom[to] = from
+		if _, exists := sp.Definitions[to]; exists && (renames[to] == "" || renames[to] == to) {
+			return nil, fmt.Errorf("cannot rename definition %q to %q: definition %q exists", from, to, to)
+		}
+	}
+
+	ret := ReplaceReferences(func(ref *spec.Ref) *spec.Ref {
+		s := ref.String()
+		if !strings.HasPrefix(s, definitionsPrefix) {
+			return ref
+		}
+		// the name is followed by the end of the reference, or a pointer into the definition
+		name, rest := strings.TrimPrefix(s, definitionsPrefix), ""
+		if i := strings.Index(name, "/"); i >= 0 {
+			name, rest = name[:i], name[i:]
+		}
+		to, found := renames[jsonpointer.Unescape(name)]
+		if !found {
+			return ref
+		}
+		r := spec.MustCreateRef(definitionsPrefix + jsonpointer.Escape(to) + rest)
+		return &r
+	}, sp)
+
+	if ret == sp {
+		ret = &spec.Swagger{}
+		*ret = *sp
+	}
+	renamed := make(spec.Definitions, len(ret.Definitions))
+	for k, v := range ret.Definitions {
+		if to, found := renames[k]; found {
+			k = to
+		}
+		renamed[k] = v
+	}
+	ret.Definitions = renamed
+	return ret, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemamutation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestRenameDefinitions(t *testing.T) {
+	input := `{
+		"paths": {
+			"/widgets": {
+				"get": {
+					"parameters": [{"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Widget"}}],
+					"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Widget/properties/part"}}}
+				}
+			}
+		},
+		"definitions": {
+			"Widget": {"type": "object", "properties": {"part": {"$ref": "#/definitions/Part"}}},
+			"Part": {"type": "string"},
+			"a/b": {"items": {"$ref": "#/definitions/a~1b"}}
+		},
+		"responses": {
+			"error": {"description": "error", "schema": {"$ref": "#/definitions/Part"}}
+		}
+	}`
+	var sp spec.Swagger
+	require.NoError(t, json.Unmarshal([]byte(input), &sp))
+
+	// definitions can be swapped
+	result, err := RenameDefinitions(&sp, map[string]string{"Widget": "Part", "Part": "Widget", "a/b": "c/d"})
+	require.NoError(t, err)
+	b, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"paths": {
+			"/widgets": {
+				"get": {
+					"parameters": [{"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Part"}}],
+					"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Part/properties/part"}}}
+				}
+			}
+		},
+		"definitions": {
+			"Part": {"type": "object", "properties": {"part": {"$ref": "#/definitions/Widget"}}},
+			"Widget": {"type": "string"},
+			"c/d": {"items": {"$ref": "#/definitions/c~1d"}}
+		},
+		"responses": {
+			"error": {"description": "error", "schema": {"$ref": "#/definitions/Widget"}}
+		}
+	}`, string(b))
+
+	// the input is not mutated
+	b, err = json.Marshal(&sp)
+	require.NoError(t, err)
+	assert.JSONEq(t, input, string(b))
+
+	result, err = RenameDefinitions(&sp, map[string]string{"Part": "Part"})
+	require.NoError(t, err)
+	assert.Equal(t, &sp, result)
+
+	for _, tc := range []struct {
+		renames map[string]string
+		err     string
+	}{
+		{map[string]string{"Part": ""}, `cannot rename definition "Part" to an empty name`},
+		{map[string]string{"Part": "Widget"}, `cannot rename definition "Part" to "Widget": definition "Widget" exists`},
+		{map[string]string{"Part": "Thing", "Widget": "Thing"}, `cannot rename definitions "Part" and "Widget" to "Thing"`},
+	} {
+		_, err := RenameDefinitions(&sp, tc.renames)
+		assert.EqualError(t, err, tc.err)
+	}
+}
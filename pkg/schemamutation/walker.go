@@ -119,6 +119,21 @@ func (w *Walker) WalkSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	dependentSchemasCloned := false
+	for k, v := range schema.DependentSchemas {
+		if s := w.WalkSchema(&v); s != &v {
+			if !dependentSchemasCloned {
+				dependentSchemasCloned = true
+				clone()
+				schema.DependentSchemas = make(map[string]spec.Schema, len(orig.DependentSchemas))
+				for k2, v2 := range orig.DependentSchemas {
+					schema.DependentSchemas[k2] = v2
+				}
+			}
+			schema.DependentSchemas[k] = *s
+		}
+	}
+
 	allOfCloned := false
 	for i := range schema.AllOf {
 		if s := w.WalkSchema(&schema.AllOf[i]); s != &schema.AllOf[i] {
@@ -158,6 +173,19 @@ func (w *Walker) WalkSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	prefixItemsCloned := false
+	for i := range schema.PrefixItems {
+		if s := w.WalkSchema(&schema.PrefixItems[i]); s != &schema.PrefixItems[i] {
+			if !prefixItemsCloned {
+				prefixItemsCloned = true
+				clone()
+				schema.PrefixItems = make([]spec.Schema, len(orig.PrefixItems))
+				copy(schema.PrefixItems, orig.PrefixItems)
+			}
+			schema.PrefixItems[i] = *s
+		}
+	}
+
 	if schema.Not != nil {
 		if s := w.WalkSchema(schema.Not); s != schema.Not {
 			clone()
@@ -165,6 +193,34 @@ func (w *Walker) WalkSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	if schema.ContentSchema != nil {
+		if s := w.WalkSchema(schema.ContentSchema); s != schema.ContentSchema {
+			clone()
+			schema.ContentSchema = s
+		}
+	}
+
+	if schema.If != nil {
+		if s := w.WalkSchema(schema.If); s != schema.If {
+			clone()
+			schema.If = s
+		}
+	}
+
+	if schema.Then != nil {
+		if s := w.WalkSchema(schema.Then); s != schema.Then {
+			clone()
+			schema.Then = s
+		}
+	}
+
+	if schema.Else != nil {
+		if s := w.WalkSchema(schema.Else); s != schema.Else {
+			clone()
+			schema.Else = s
+		}
+	}
+
 	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
 		if s := w.WalkSchema(schema.AdditionalProperties.Schema); s != schema.AdditionalProperties.Schema {
 			clone()
@@ -179,6 +235,20 @@ func (w *Walker) WalkSchema(schema *spec.Schema) *spec.Schema {
 		}
 	}
 
+	if schema.UnevaluatedProperties != nil && schema.UnevaluatedProperties.Schema != nil {
+		if s := w.WalkSchema(schema.UnevaluatedProperties.Schema); s != schema.UnevaluatedProperties.Schema {
+			clone()
+			schema.UnevaluatedProperties = &spec.SchemaOrBool{Schema: s, Allows: schema.UnevaluatedProperties.Allows}
+		}
+	}
+
+	if schema.UnevaluatedItems != nil && schema.UnevaluatedItems.Schema != nil {
+		if s := w.WalkSchema(schema.UnevaluatedItems.Schema); s != schema.UnevaluatedItems.Schema {
+			clone()
+			schema.UnevaluatedItems = &spec.SchemaOrBool{Schema: s, Allows: schema.UnevaluatedItems.Allows}
+		}
+	}
+
 	if schema.Items != nil {
 		if schema.Items.Schema != nil {
 			if s := w.WalkSchema(schema.Items.Schema); s != schema.Items.Schema {
@@ -319,9 +319,16 @@ func TestReplaceSchema(t *testing.T) {
 				if c.RandBool() {
 					c.Fuzz(&s.PatternProperties)
 				}
+				if c.RandBool() {
+					c.Fuzz(&s.DependentSchemas)
+				}
 				if c.RandBool() {
 					c.Fuzz(&s.AdditionalItems)
 				}
+				if c.RandBool() {
+					c.Fuzz(&s.UnevaluatedProperties)
+					c.Fuzz(&s.UnevaluatedItems)
+				}
 				if c.RandBool() {
 					c.Fuzz(&s.AnyOf)
 				}
@@ -331,9 +338,20 @@ func TestReplaceSchema(t *testing.T) {
 				if c.RandBool() {
 					c.Fuzz(&s.OneOf)
 				}
+				if c.RandBool() {
+					c.Fuzz(&s.PrefixItems)
+				}
 				if c.RandBool() {
 					c.Fuzz(&s.Not)
 				}
+				if c.RandBool() {
+					c.Fuzz(&s.ContentSchema)
+				}
+				if c.RandBool() {
+					c.Fuzz(&s.If)
+					c.Fuzz(&s.Then)
+					c.Fuzz(&s.Else)
+				}
 				if c.RandBool() {
 					c.Fuzz(&s.Definitions)
 				}
@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-openapi/swag"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Callback describes the out-of-band requests an API may send in relation to an operation, more at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#callbackObject
+//
+// Note that this struct is actually a thin wrapper around CallbackProps to make it referable and extensible
+type Callback struct {
+	spec.Refable
+	CallbackProps
+	spec.VendorExtensible
+}
+
+// CallbackProps maps runtime expressions, e.g. "{$request.body#/callbackUrl}", to the path items describing the requests sent to the URLs they evaluate to
+type CallbackProps map[string]*Path
+
+// MarshalJSON is a custom marshal function that knows how to encode Callback as JSON
+func (c Callback) MarshalJSON() ([]byte, error) {
+	b1, err := json.Marshal(c.Refable)
+	if err != nil {
+		return nil, err
+	}
+	b2, err := json.Marshal(c.CallbackProps)
+	if err != nil {
+		return nil, err
+	}
+	b3, err := json.Marshal(c.VendorExtensible)
+	if err != nil {
+		return nil, err
+	}
+	return swag.ConcatJSON(b1, b2, b3), nil
+}
+
+// UnmarshalJSON hydrates this items instance with the data from JSON
+func (c *Callback) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Refable); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &c.VendorExtensible); err != nil {
+		return err
+	}
+	var res map[string]json.RawMessage
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	c.CallbackProps = nil
+	for k, v := range res {
+		if k == "$ref" || strings.HasPrefix(strings.ToLower(k), "x-") {
+			continue
+		}
+		if c.CallbackProps == nil {
+			c.CallbackProps = make(CallbackProps)
+		}
+		var p *Path
+		if err := json.Unmarshal(v, &p); err != nil {
+			return err
+		}
+		c.CallbackProps[k] = p
+	}
+	return nil
+}
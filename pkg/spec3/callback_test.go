@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestCallbackJSONSerialization(t *testing.T) {
+	cases := []struct {
+		name           string
+		target         *spec3.Callback
+		expectedOutput string
+	}{
+		{
+			name: "basic",
+			target: &spec3.Callback{
+				CallbackProps: spec3.CallbackProps{
+					"{$request.body#/callbackUrl}": &spec3.Path{
+						PathProps: spec3.PathProps{
+							Post: &spec3.Operation{
+								OperationProps: spec3.OperationProps{
+									Responses: &spec3.Responses{
+										ResponsesProps: spec3.ResponsesProps{
+											StatusCodeResponses: map[int]*spec3.Response{
+												200: {ResponseProps: spec3.ResponseProps{Description: "callback received"}},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{"x-retries": float64(3)}},
+			},
+			expectedOutput: `{"{$request.body#/callbackUrl}":{"post":{"responses":{"200":{"description":"callback received"}}}},"x-retries":3}`,
+		},
+		{
+			name: "reference",
+			target: &spec3.Callback{
+				Refable: spec.Refable{Ref: spec.MustCreateRef("#/components/callbacks/onEvent")},
+			},
+			expectedOutput: `{"$ref":"#/components/callbacks/onEvent"}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawTarget, err := json.Marshal(tc.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serializedTarget := string(rawTarget)
+			if !cmp.Equal(serializedTarget, tc.expectedOutput) {
+				t.Fatalf("diff %s", cmp.Diff(serializedTarget, tc.expectedOutput))
+			}
+
+			var decoded spec3.Callback
+			if err := json.Unmarshal(rawTarget, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			rawDecoded, err := json.Marshal(&decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(rawDecoded) != serializedTarget {
+				t.Errorf("round trip changed the callback: %s", rawDecoded)
+			}
+		})
+	}
+}
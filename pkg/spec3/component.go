@@ -16,7 +16,12 @@ limitations under the License.
 
 package spec3
 
-import "k8s.io/kube-openapi/pkg/validation/spec"
+import (
+	"encoding/json"
+
+	"github.com/go-openapi/swag"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
 
 // Components holds a set of reusable objects for different aspects of the OAS.
 // All objects defined within the components object will have no effect on the API
@@ -40,8 +45,42 @@ type Components struct {
 	Links map[string]*Link `json:"links,omitempty"`
 	// Headers holds a maps of a headers name to its definition
 	Headers map[string]*Header `json:"headers,omitempty"`
+	// Callbacks holds reusable Callback objects
+	Callbacks map[string]*Callback `json:"callbacks,omitempty"`
+	// PathItems holds reusable Path Item objects, added in OpenAPI 3.1
+	PathItems map[string]*Path `json:"pathItems,omitempty"`
+	// Extensions holds the vendor extensions of the components
+	Extensions spec.Extensions `json:"-"`
 	// all fields are defined at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#componentsObject
 }
 
+// MarshalJSON is a custom marshal function that knows how to encode Components as JSON
+func (c Components) MarshalJSON() ([]byte, error) {
+	type props Components
+	b1, err := json.Marshal(props(c))
+	if err != nil {
+		return nil, err
+	}
+	b2, err := json.Marshal(spec.VendorExtensible{Extensions: c.Extensions})
+	if err != nil {
+		return nil, err
+	}
+	return swag.ConcatJSON(b1, b2), nil
+}
+
+// UnmarshalJSON hydrates this items instance with the data from JSON
+func (c *Components) UnmarshalJSON(data []byte) error {
+	type props Components
+	if err := json.Unmarshal(data, (*props)(c)); err != nil {
+		return err
+	}
+	var ext spec.VendorExtensible
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return err
+	}
+	c.Extensions = ext.Extensions
+	return nil
+}
+
 // SecuritySchemes holds reusable Security Scheme Objects, more at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#securitySchemeObject
 type SecuritySchemes map[string]*SecurityScheme
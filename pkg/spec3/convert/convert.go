@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package convert converts between Swagger 2.0 documents and OpenAPI v3
+// documents, e.g. for aggregation layers serving both versions from one
+// source:
+//
+//	v3, losses := convert.ToV3(swagger)
+//	...
+//	v2, losses := convert.ToV2(v3)
+//
+// Swagger 2.0 body and formData parameters become request bodies, the
+// consumes and produces media types become the content of request bodies
+// and responses, and definitions, shared parameters and responses become
+// components. References are rewritten accordingly.
+//
+// Losses list what the target version cannot represent, e.g. cookie
+// parameters or callbacks of OpenAPI v3 documents, with their JSON pointers
+// in the converted document.
+//
+// Neither conversion mutates its input, but the result might share data,
+// e.g. schemas without references or examples, with the input.
+package convert
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/validation/compat"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// The prefixes of local references to the shared parts of documents.
+const (
+	definitionsPrefix = "#/definitions/"
+	parametersPrefix  = "#/parameters/"
+	responsesPrefix   = "#/responses/"
+
+	schemasPrefix       = "#/components/schemas/"
+	parametersV3Prefix  = "#/components/parameters/"
+	requestBodiesPrefix = "#/components/requestBodies/"
+	responsesV3Prefix   = "#/components/responses/"
+)
+
+const (
+	jsonMediaType      = "application/json"
+	urlEncodedFormType = "application/x-www-form-urlencoded"
+	multipartFormType  = "multipart/form-data"
+)
+
+func isFormMediaType(mediaType string) bool {
+	return mediaType == urlEncodedFormType || mediaType == multipartFormType
+}
+
+// rewriteRef returns ref with the prefix of a local reference replaced by
+// the one of prefixes, or nil if ref has none of them.
+func rewriteRef(ref *spec.Ref, prefixes map[string]string) *spec.Ref {
+	s := ref.String()
+	for from, to := range prefixes {
+		if strings.HasPrefix(s, from) {
+			r := spec.MustCreateRef(to + strings.TrimPrefix(s, from))
+			return &r
+		}
+	}
+	return nil
+}
+
+// losses collects the losses of a conversion.
+type losses []compat.Loss
+
+func (l *losses) dropped(path string, value interface{}) {
+	*l = append(*l, compat.Loss{Path: path, Value: value})
+}
+
+func pointer(path string, tokens ...string) string {
+	for _, t := range tokens {
+		path += "/" + jsonpointer.Escape(t)
+	}
+	return path
+}
+
+func sortedStrings(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sorted returns the losses sorted by path, without the duplicates of
+// parts converted more than once, e.g. request bodies inlined into several
+// operations.
+func (l losses) sorted() []compat.Loss {
+	sort.SliceStable(l, func(i, j int) bool { return l[i].Path < l[j].Path })
+	var out []compat.Loss
+	for i, loss := range l {
+		if i == 0 || loss.Path != l[i-1].Path {
+			out = append(out, loss)
+		}
+	}
+	return out
+}
@@ -0,0 +1,439 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/spec3/convert"
+	"k8s.io/kube-openapi/pkg/validation/compat"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const swagger = `{
+	"swagger": "2.0",
+	"id": "widgets",
+	"info": {"title": "Widgets", "version": "v1"},
+	"host": "example.com",
+	"basePath": "/api",
+	"schemes": ["https"],
+	"consumes": ["application/json"],
+	"produces": ["application/json", "application/yaml"],
+	"paths": {
+		"/widgets/{name}": {
+			"parameters": [
+				{"name": "name", "in": "path", "required": true, "type": "string"},
+				{"$ref": "#/parameters/pretty"}
+			],
+			"get": {
+				"operationId": "readWidget",
+				"parameters": [
+					{"name": "fields", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "tsv"}
+				],
+				"responses": {
+					"200": {"description": "OK", "schema": {"$ref": "#/definitions/Widget"}, "headers": {"X-Rate-Limit": {"type": "integer", "description": "calls left"}}},
+					"default": {"$ref": "#/responses/error"}
+				}
+			},
+			"put": {
+				"operationId": "replaceWidget",
+				"parameters": [{"$ref": "#/parameters/widget"}],
+				"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Widget"}}}
+			},
+			"post": {
+				"operationId": "uploadWidget",
+				"consumes": ["multipart/form-data"],
+				"parameters": [
+					{"name": "file", "in": "formData", "type": "file", "required": true},
+					{"name": "tags", "in": "formData", "type": "array", "items": {"type": "string"}, "collectionFormat": "multi"}
+				],
+				"responses": {"204": {"description": "uploaded"}}
+			}
+		}
+	},
+	"definitions": {
+		"Widget": {"type": "object", "properties": {"parts": {"type": "array", "items": {"$ref": "#/definitions/Part"}}}},
+		"Part": {"type": "string"}
+	},
+	"parameters": {
+		"pretty": {"name": "pretty", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "multi"},
+		"widget": {"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Widget"}}
+	},
+	"responses": {
+		"error": {"description": "error", "schema": {"type": "string"}}
+	},
+	"securityDefinitions": {
+		"basic": {"type": "basic"},
+		"oauth": {"type": "oauth2", "flow": "accessCode", "authorizationUrl": "https://example.com/auth", "tokenUrl": "https://example.com/token", "scopes": {"read": "read widgets"}}
+	},
+	"security": [{"basic": []}],
+	"x-origin": "test"
+}`
+
+func TestToV3(t *testing.T) {
+	var in spec.Swagger
+	if err := json.Unmarshal([]byte(swagger), &in); err != nil {
+		t.Fatal(err)
+	}
+	out, losses := convert.ToV3(&in)
+
+	checkJSON(t, out, `{
+		"openapi": "3.0.0",
+		"info": {"title": "Widgets", "version": "v1"},
+		"servers": [{"url": "https://example.com/api"}],
+		"paths": {
+			"/widgets/{name}": {
+				"parameters": [
+					{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+					{"$ref": "#/components/parameters/pretty"}
+				],
+				"get": {
+					"operationId": "readWidget",
+					"parameters": [
+						{"name": "fields", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}}
+					],
+					"responses": {
+						"200": {
+							"description": "OK",
+							"headers": {"X-Rate-Limit": {"description": "calls left", "schema": {"type": "integer"}}},
+							"content": {
+								"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}},
+								"application/yaml": {"schema": {"$ref": "#/components/schemas/Widget"}}
+							}
+						},
+						"default": {"$ref": "#/components/responses/error"}
+					}
+				},
+				"put": {
+					"operationId": "replaceWidget",
+					"requestBody": {"$ref": "#/components/requestBodies/widget"},
+					"responses": {
+						"200": {
+							"description": "OK",
+							"content": {
+								"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}},
+								"application/yaml": {"schema": {"$ref": "#/components/schemas/Widget"}}
+							}
+						}
+					}
+				},
+				"post": {
+					"operationId": "uploadWidget",
+					"requestBody": {
+						"required": true,
+						"content": {
+							"multipart/form-data": {
+								"schema": {
+									"type": "object",
+									"required": ["file"],
+									"properties": {
+										"file": {"type": "string", "format": "binary"},
+										"tags": {"type": "array", "items": {"type": "string"}}
+									}
+								},
+								"encoding": {"tags": {"style": "form", "explode": true}}
+							}
+						}
+					},
+					"responses": {"204": {"description": "uploaded"}}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"Widget": {"type": "object", "properties": {"parts": {"type": "array", "items": {"$ref": "#/components/schemas/Part"}}}},
+				"Part": {"type": "string"}
+			},
+			"parameters": {
+				"pretty": {"name": "pretty", "in": "query", "style": "form", "explode": true, "schema": {"type": "array", "items": {"type": "string"}}}
+			},
+			"requestBodies": {
+				"widget": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}}}}
+			},
+			"responses": {
+				"error": {
+					"description": "error",
+					"content": {
+						"application/json": {"schema": {"type": "string"}},
+						"application/yaml": {"schema": {"type": "string"}}
+					}
+				}
+			},
+			"securitySchemes": {
+				"basic": {"type": "http", "scheme": "basic"},
+				"oauth": {
+					"type": "oauth2",
+					"flows": {
+						"authorizationCode": {"authorizationUrl": "https://example.com/auth", "tokenUrl": "https://example.com/token", "scopes": {"read": "read widgets"}}
+					}
+				}
+			}
+		},
+		"security": [{"basic": []}],
+		"x-origin": "test"
+	}`)
+	checkLosses(t, losses, "/id", "/paths/~1widgets~1{name}/get/parameters/0/collectionFormat")
+
+	// the input is not mutated
+	checkJSON(t, &in, swagger)
+}
+
+func TestToV2(t *testing.T) {
+	var in spec3.OpenAPI
+	if err := json.Unmarshal([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Widgets", "version": "v1"},
+		"servers": [
+			{"url": "http://example.com/{base}", "variables": {"base": {"default": "api"}}},
+			{"url": "https://example.com/api"},
+			{"url": "https://mirror.example.com/api"}
+		],
+		"paths": {
+			"/widgets": {
+				"get": {
+					"operationId": "listWidgets",
+					"parameters": [
+						{"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1}},
+						{"name": "session", "in": "cookie", "schema": {"type": "string"}},
+						{"name": "filter", "in": "query", "schema": {"$ref": "#/components/schemas/Filter"}},
+						{"$ref": "#/components/parameters/tracking"}
+					],
+					"responses": {
+						"200": {
+							"description": "OK",
+							"content": {
+								"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Widget"}}, "example": []},
+								"application/yaml": {"schema": {"type": "string"}}
+							},
+							"links": {"next": {"operationId": "listWidgets"}}
+						}
+					},
+					"callbacks": {"created": {"{$request.query.url}": {"post": {"responses": {"200": {"description": "OK"}}}}}}
+				},
+				"post": {
+					"operationId": "createWidget",
+					"requestBody": {"$ref": "#/components/requestBodies/widget"},
+					"responses": {"201": {"$ref": "#/components/responses/created"}}
+				},
+				"put": {
+					"operationId": "uploadWidgets",
+					"requestBody": {
+						"content": {
+							"application/x-www-form-urlencoded": {
+								"schema": {
+									"type": "object",
+									"required": ["name"],
+									"properties": {
+										"name": {"type": "string", "description": "the widget name"},
+										"colors": {"type": "array", "items": {"type": "string"}}
+									}
+								},
+								"encoding": {"colors": {"style": "pipeDelimited"}}
+							}
+						}
+					},
+					"responses": {"204": {"description": "uploaded"}}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"Widget": {"type": "object", "nullable": true},
+				"Filter": {"type": "object"}
+			},
+			"parameters": {
+				"tracking": {"name": "tracking", "in": "cookie", "schema": {"type": "string"}}
+			},
+			"requestBodies": {
+				"widget": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}}}}
+			},
+			"responses": {
+				"created": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}}}}
+			},
+			"securitySchemes": {
+				"bearer": {"type": "http", "scheme": "bearer"},
+				"oauth": {
+					"type": "oauth2",
+					"flows": {
+						"clientCredentials": {"tokenUrl": "https://example.com/token", "scopes": {}},
+						"implicit": {"authorizationUrl": "https://example.com/auth", "scopes": {}}
+					}
+				}
+			}
+		}
+	}`), &in); err != nil {
+		t.Fatal(err)
+	}
+	out, losses := convert.ToV2(&in)
+
+	checkJSON(t, out, `{
+		"swagger": "2.0",
+		"info": {"title": "Widgets", "version": "v1"},
+		"schemes": ["http", "https"],
+		"host": "example.com",
+		"basePath": "/api",
+		"paths": {
+			"/widgets": {
+				"get": {
+					"operationId": "listWidgets",
+					"produces": ["application/json", "application/yaml"],
+					"parameters": [
+						{"name": "limit", "in": "query", "type": "integer", "minimum": 1},
+						{"name": "filter", "in": "query"}
+					],
+					"responses": {
+						"200": {
+							"description": "OK",
+							"schema": {"type": "array", "items": {"$ref": "#/definitions/Widget"}},
+							"examples": {"application/json": []}
+						}
+					}
+				},
+				"post": {
+					"operationId": "createWidget",
+					"consumes": ["application/json"],
+					"produces": ["application/json"],
+					"parameters": [{"$ref": "#/parameters/widget"}],
+					"responses": {"201": {"$ref": "#/responses/created"}}
+				},
+				"put": {
+					"operationId": "uploadWidgets",
+					"consumes": ["application/x-www-form-urlencoded"],
+					"parameters": [
+						{"name": "colors", "in": "formData", "type": "array", "items": {"type": "string"}, "collectionFormat": "pipes"},
+						{"name": "name", "in": "formData", "type": "string", "required": true, "description": "the widget name"}
+					],
+					"responses": {"204": {"description": "uploaded"}}
+				}
+			}
+		},
+		"definitions": {
+			"Widget": {"type": "object", "nullable": true},
+			"Filter": {"type": "object"}
+		},
+		"parameters": {
+			"widget": {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Widget"}}
+		},
+		"responses": {
+			"created": {"description": "created", "schema": {"$ref": "#/definitions/Widget"}}
+		},
+		"securityDefinitions": {
+			"oauth": {"type": "oauth2", "flow": "application", "tokenUrl": "https://example.com/token"}
+		}
+	}`)
+	checkLosses(t, losses,
+		"/components/parameters/tracking",
+		"/components/securitySchemes/bearer",
+		"/components/securitySchemes/oauth/flows/implicit",
+		"/paths/~1widgets/get/callbacks",
+		"/paths/~1widgets/get/parameters/1",
+		"/paths/~1widgets/get/parameters/2/schema",
+		"/paths/~1widgets/get/parameters/3",
+		"/paths/~1widgets/get/responses/200/content/application~1yaml/schema",
+		"/paths/~1widgets/get/responses/200/links",
+		"/servers/0/variables",
+		"/servers/2",
+	)
+}
+
+func TestRoundTrip(t *testing.T) {
+	doc := `{
+		"swagger": "2.0",
+		"info": {"title": "Widgets", "version": "v1"},
+		"host": "example.com",
+		"basePath": "/api",
+		"schemes": ["http", "https"],
+		"paths": {
+			"/widgets/{name}": {
+				"parameters": [{"name": "name", "in": "path", "required": true, "type": "string", "x-origin": "test"}],
+				"get": {
+					"operationId": "readWidget",
+					"produces": ["application/json"],
+					"parameters": [
+						{"name": "fields", "in": "query", "type": "array", "items": {"type": "string", "enum": ["name", "parts"]}},
+						{"name": "ids", "in": "query", "type": "array", "items": {"type": "integer"}, "collectionFormat": "ssv"},
+						{"name": "If-Match", "in": "header", "type": "string", "example": "v1"}
+					],
+					"responses": {
+						"200": {"description": "OK", "schema": {"$ref": "#/definitions/Widget"}},
+						"404": {"$ref": "#/responses/notFound"}
+					},
+					"security": [{"oauth": ["read"]}]
+				},
+				"patch": {
+					"operationId": "patchWidget",
+					"consumes": ["application/merge-patch+json"],
+					"produces": ["application/json"],
+					"parameters": [{"name": "body", "in": "body", "required": true, "schema": {"type": "object"}}],
+					"responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Widget"}}},
+					"x-kubernetes-action": "patch"
+				}
+			}
+		},
+		"definitions": {
+			"Widget": {"type": "object", "properties": {"name": {"type": "string"}}}
+		},
+		"responses": {
+			"notFound": {"description": "not found"}
+		},
+		"securityDefinitions": {
+			"oauth": {"type": "oauth2", "flow": "password", "tokenUrl": "https://example.com/token", "scopes": {"read": "read widgets"}}
+		}
+	}`
+	var in spec.Swagger
+	if err := json.Unmarshal([]byte(doc), &in); err != nil {
+		t.Fatal(err)
+	}
+	v3, losses := convert.ToV3(&in)
+	checkLosses(t, losses)
+	out, losses := convert.ToV2(v3)
+	checkLosses(t, losses)
+	checkJSON(t, out, doc)
+}
+
+func checkJSON(t *testing.T, actual interface{}, expected string) {
+	t.Helper()
+	b, err := json.Marshal(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected document (-want +got):\n%s", diff)
+	}
+}
+
+func checkLosses(t *testing.T, losses []compat.Loss, paths ...string) {
+	t.Helper()
+	var got []string
+	for _, l := range losses {
+		got = append(got, l.Path)
+	}
+	if diff := cmp.Diff(paths, got); diff != "" {
+		t.Errorf("unexpected losses (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,820 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/schemamutation"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/compat"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ToV2 converts the OpenAPI v3 document in to a Swagger 2.0 document.
+//
+// Request bodies become body parameters, or formData parameters for form
+// media types, and the media types of request bodies and responses become
+// the consumes and produces of the operations. Where media types have
+// different schemas, the one of application/json, or else of the first
+// media type, is kept. Components request bodies become shared body
+// parameters unless their name is taken by a parameter, or they are forms,
+// in which case they are inlined into the operations.
+func ToV2(in *spec3.OpenAPI) (*spec.Swagger, []compat.Loss) {
+	c := &v2Converter{in: in, sharedBodies: map[string]bool{}, droppedParams: map[string]bool{}}
+	c.walker = &schemamutation.Walker{
+		SchemaCallback: schemamutation.SchemaCallBackNoop,
+		RefCallback: func(ref *spec.Ref) *spec.Ref {
+			if r := rewriteRef(ref, map[string]string{schemasPrefix: definitionsPrefix}); r != nil {
+				return r
+			}
+			return ref
+		},
+	}
+
+	out := &spec.Swagger{
+		VendorExtensible: spec.VendorExtensible{Extensions: in.Extensions},
+		SwaggerProps: spec.SwaggerProps{
+			Swagger:      "2.0",
+			Info:         in.Info,
+			ExternalDocs: externalDocsV2(in.ExternalDocs),
+			Security:     securityV2(in.SecurityRequirement),
+			Tags:         in.Tags,
+		},
+	}
+	out.Schemes, out.Host, out.BasePath = c.servers(in.Servers)
+	// components first, for the operations to refer to the shared parameters
+	c.components(out)
+	out.Paths = c.paths(in.Paths)
+	if len(in.Webhooks) > 0 {
+		c.losses.dropped("/webhooks", in.Webhooks)
+	}
+	if in.JSONSchemaDialect != "" {
+		c.losses.dropped("/jsonSchemaDialect", in.JSONSchemaDialect)
+	}
+	return out, c.losses.sorted()
+}
+
+type v2Converter struct {
+	in     *spec3.OpenAPI
+	walker *schemamutation.Walker
+	losses losses
+
+	// sharedBodies are the components request bodies converted to shared
+	// body parameters.
+	sharedBodies map[string]bool
+	// droppedParams are the components parameters which cannot be converted.
+	droppedParams map[string]bool
+}
+
+func (c *v2Converter) schema(s *spec.Schema) *spec.Schema {
+	if s == nil {
+		return nil
+	}
+	return c.walker.WalkSchema(s)
+}
+
+// servers returns the schemes, host and base path of servers. Only servers
+// differing by their scheme from the first one can be represented.
+func (c *v2Converter) servers(servers []*spec3.Server) (schemes []string, host, basePath string) {
+	first := true
+	for i, s := range servers {
+		if s == nil {
+			continue
+		}
+		path := pointer("/servers", strconv.Itoa(i))
+		u, err := url.Parse(c.serverURL(path, s))
+		if err != nil {
+			c.losses.dropped(path, s)
+			continue
+		}
+		if first {
+			host, basePath, first = u.Host, u.Path, false
+		} else if u.Host != host || u.Path != basePath {
+			c.losses.dropped(path, s)
+			continue
+		}
+		if u.Scheme != "" && !containsString(schemes, u.Scheme) {
+			schemes = append(schemes, u.Scheme)
+		}
+	}
+	return schemes, host, basePath
+}
+
+// serverURL returns the URL of s with the default values of its variables.
+func (c *v2Converter) serverURL(path string, s *spec3.Server) string {
+	u := s.URL
+	for name, v := range s.Variables {
+		if v != nil {
+			u = strings.Replace(u, "{"+name+"}", v.Default, -1)
+		}
+	}
+	if len(s.Variables) > 0 {
+		c.losses.dropped(path+"/variables", s.Variables)
+	}
+	return u
+}
+
+func (c *v2Converter) components(out *spec.Swagger) {
+	in := c.in.Components
+	if in == nil {
+		return
+	}
+	for name, s := range in.Schemas {
+		if s == nil {
+			continue
+		}
+		if out.Definitions == nil {
+			out.Definitions = make(spec.Definitions, len(in.Schemas))
+		}
+		out.Definitions[name] = *c.schema(s)
+	}
+	for name, p := range in.Parameters {
+		param, ok := c.parameter(pointer("/components/parameters", name), p)
+		if !ok {
+			c.droppedParams[name] = true
+			continue
+		}
+		if out.Parameters == nil {
+			out.Parameters = map[string]spec.Parameter{}
+		}
+		out.Parameters[name] = param
+	}
+	for name, rb := range in.RequestBodies {
+		if _, taken := in.Parameters[name]; taken || rb == nil || rb.Ref.String() != "" {
+			continue
+		}
+		param, ok := c.bodyParameter(pointer("/components/requestBodies", name), rb)
+		if !ok {
+			continue
+		}
+		if out.Parameters == nil {
+			out.Parameters = map[string]spec.Parameter{}
+		}
+		out.Parameters[name] = param
+		c.sharedBodies[name] = true
+	}
+	for name, r := range in.Responses {
+		if r == nil {
+			continue
+		}
+		if out.Responses == nil {
+			out.Responses = make(map[string]spec.Response, len(in.Responses))
+		}
+		out.Responses[name] = c.response(pointer("/components/responses", name), r)
+	}
+	for name, s := range in.SecuritySchemes {
+		scheme, ok := c.securityScheme(pointer("/components/securitySchemes", name), s)
+		if !ok {
+			continue
+		}
+		if out.SecurityDefinitions == nil {
+			out.SecurityDefinitions = make(spec.SecurityDefinitions, len(in.SecuritySchemes))
+		}
+		out.SecurityDefinitions[name] = scheme
+	}
+
+	// the components without Swagger 2.0 counterparts
+	for _, m := range []struct {
+		keyword string
+		value   interface{}
+	}{
+		{"examples", in.Examples},
+		{"links", in.Links},
+		{"headers", in.Headers},
+		{"callbacks", in.Callbacks},
+		{"pathItems", in.PathItems},
+	} {
+		v := reflect.ValueOf(m.value)
+		for _, k := range v.MapKeys() {
+			c.losses.dropped(pointer("/components", m.keyword, k.String()), v.MapIndex(k).Interface())
+		}
+	}
+	for k, v := range in.Extensions {
+		c.losses.dropped(pointer("/components", k), v)
+	}
+}
+
+func (c *v2Converter) paths(in *spec3.Paths) *spec.Paths {
+	out := &spec.Paths{}
+	if in == nil {
+		return out
+	}
+	out.VendorExtensible = in.VendorExtensible
+	if in.Paths != nil {
+		out.Paths = make(map[string]spec.PathItem, len(in.Paths))
+	}
+	for p, item := range in.Paths {
+		if item != nil {
+			out.Paths[p] = c.pathItem(pointer("/paths", p), item)
+		}
+	}
+	return out
+}
+
+func (c *v2Converter) pathItem(path string, in *spec3.Path) spec.PathItem {
+	out := spec.PathItem{Refable: in.Refable, VendorExtensible: in.VendorExtensible}
+	if in.Summary != "" {
+		c.losses.dropped(path+"/summary", in.Summary)
+	}
+	if in.Description != "" {
+		c.losses.dropped(path+"/description", in.Description)
+	}
+	if len(in.Servers) > 0 {
+		c.losses.dropped(path+"/servers", in.Servers)
+	}
+	if in.Trace != nil {
+		c.losses.dropped(path+"/trace", in.Trace)
+	}
+	for i, p := range in.Parameters {
+		if param, ok := c.parameter(pointer(path, "parameters", strconv.Itoa(i)), p); ok {
+			out.Parameters = append(out.Parameters, param)
+		}
+	}
+	for _, op := range []struct {
+		method string
+		in     *spec3.Operation
+		out    **spec.Operation
+	}{
+		{"get", in.Get, &out.Get},
+		{"put", in.Put, &out.Put},
+		{"post", in.Post, &out.Post},
+		{"delete", in.Delete, &out.Delete},
+		{"options", in.Options, &out.Options},
+		{"head", in.Head, &out.Head},
+		{"patch", in.Patch, &out.Patch},
+	} {
+		if op.in != nil {
+			*op.out = c.operation(pointer(path, op.method), op.in)
+		}
+	}
+	return out
+}
+
+func (c *v2Converter) operation(path string, in *spec3.Operation) *spec.Operation {
+	out := &spec.Operation{
+		OperationProps: spec.OperationProps{
+			Description:  in.Description,
+			Tags:         in.Tags,
+			Summary:      in.Summary,
+			ExternalDocs: externalDocsV2(in.ExternalDocs),
+			ID:           in.OperationId,
+			Deprecated:   in.Deprecated,
+			Security:     securityV2(in.SecurityRequirement),
+		},
+		VendorExtensible: in.VendorExtensible,
+	}
+	for i, p := range in.Parameters {
+		if param, ok := c.parameter(pointer(path, "parameters", strconv.Itoa(i)), p); ok {
+			out.Parameters = append(out.Parameters, param)
+		}
+	}
+	if in.RequestBody != nil {
+		var params []spec.Parameter
+		params, out.Consumes = c.requestBody(pointer(path, "requestBody"), in.RequestBody)
+		out.Parameters = append(out.Parameters, params...)
+	}
+	out.Responses, out.Produces = c.responses(pointer(path, "responses"), in.Responses)
+	if len(in.Callbacks) > 0 {
+		c.losses.dropped(path+"/callbacks", in.Callbacks)
+	}
+	if len(in.Servers) > 0 {
+		c.losses.dropped(path+"/servers", in.Servers)
+	}
+	return out
+}
+
+func (c *v2Converter) parameter(path string, in *spec3.Parameter) (spec.Parameter, bool) {
+	if in == nil {
+		return spec.Parameter{}, false
+	}
+	if in.Ref.String() != "" {
+		if name, ok := in.Ref.LocalName(parametersV3Prefix); ok && c.droppedParams[name] {
+			c.losses.dropped(path, in)
+			return spec.Parameter{}, false
+		}
+		if r := rewriteRef(&in.Ref, map[string]string{parametersV3Prefix: parametersPrefix}); r != nil {
+			return spec.Parameter{Refable: spec.Refable{Ref: *r}}, true
+		}
+		return spec.Parameter{Refable: in.Refable}, true
+	}
+	if in.In == "cookie" {
+		c.losses.dropped(path, in)
+		return spec.Parameter{}, false
+	}
+	out := spec.Parameter{
+		ParamProps: spec.ParamProps{
+			Name:            in.Name,
+			In:              in.In,
+			Description:     in.Description,
+			Required:        in.Required,
+			AllowEmptyValue: in.AllowEmptyValue,
+		},
+		VendorExtensible: in.VendorExtensible,
+	}
+	out.SimpleSchema, out.CommonValidations = c.simpleSchema(path+"/schema", in.Schema)
+	if in.Example != nil {
+		out.Example = in.Example
+	}
+	if out.Type == "array" {
+		out.CollectionFormat = c.collectionFormat(path, in.In, in.Style, in.Explode)
+	}
+	if in.Deprecated {
+		c.losses.dropped(path+"/deprecated", in.Deprecated)
+	}
+	if in.AllowReserved {
+		c.losses.dropped(path+"/allowReserved", in.AllowReserved)
+	}
+	if len(in.Content) > 0 {
+		c.losses.dropped(path+"/content", in.Content)
+	}
+	if len(in.Examples) > 0 {
+		c.losses.dropped(path+"/examples", in.Examples)
+	}
+	return out, true
+}
+
+// collectionFormat returns the collection format of array parameters of
+// location in with the given style and explode.
+func (c *v2Converter) collectionFormat(path, in, style string, explode bool) string {
+	switch style {
+	case "":
+		if in == "query" || in == "formData" {
+			// exploded by default
+			return "multi"
+		}
+		return ""
+	case "form":
+		if explode {
+			return "multi"
+		}
+		return ""
+	case "simple":
+		return ""
+	case "spaceDelimited":
+		return "ssv"
+	case "pipeDelimited":
+		return "pipes"
+	}
+	c.losses.dropped(path+"/style", style)
+	return ""
+}
+
+// simpleSchema returns the type and validations of the schema s of non-body
+// parameters, items and headers, reporting s as dropped if they do not
+// represent it.
+func (c *v2Converter) simpleSchema(path string, s *spec.Schema) (spec.SimpleSchema, spec.CommonValidations) {
+	simple, validations, ok := toSimpleSchema(s)
+	if !ok {
+		c.losses.dropped(path, s)
+	}
+	return simple, validations
+}
+
+func toSimpleSchema(s *spec.Schema) (spec.SimpleSchema, spec.CommonValidations, bool) {
+	if s == nil {
+		return spec.SimpleSchema{}, spec.CommonValidations{}, true
+	}
+	simple := spec.SimpleSchema{
+		Nullable: s.Nullable,
+		Format:   s.Format,
+		Default:  s.Default,
+		Example:  s.Example,
+	}
+	validations := spec.CommonValidations{
+		Maximum:          s.Maximum,
+		ExclusiveMaximum: s.ExclusiveMaximum,
+		Minimum:          s.Minimum,
+		ExclusiveMinimum: s.ExclusiveMinimum,
+		MaxLength:        s.MaxLength,
+		MinLength:        s.MinLength,
+		Pattern:          s.Pattern,
+		MaxItems:         s.MaxItems,
+		MinItems:         s.MinItems,
+		UniqueItems:      s.UniqueItems,
+		MultipleOf:       s.MultipleOf,
+		Enum:             s.Enum,
+	}
+	ok := len(s.Type) <= 1
+	if len(s.Type) == 1 {
+		simple.Type = s.Type[0]
+	}
+	if s.Items != nil {
+		if s.Items.Schema == nil {
+			ok = false
+		} else {
+			// extensions of items are kept by the items
+			items := *s.Items.Schema
+			items.Extensions = nil
+			itemsSimple, itemsValidations, itemsOK := toSimpleSchema(&items)
+			simple.Items = &spec.Items{
+				SimpleSchema:      itemsSimple,
+				CommonValidations: itemsValidations,
+				VendorExtensible:  spec.VendorExtensible{Extensions: s.Items.Schema.Extensions},
+			}
+			ok = ok && itemsOK
+		}
+	}
+
+	// s is represented if it has no other keywords
+	rest := *s
+	rest.Type, rest.Nullable, rest.Format, rest.Default, rest.Example = nil, false, "", nil, nil
+	rest.Maximum, rest.ExclusiveMaximum, rest.Minimum, rest.ExclusiveMinimum = nil, false, nil, false
+	rest.MaxLength, rest.MinLength, rest.Pattern = nil, nil, ""
+	rest.MaxItems, rest.MinItems, rest.UniqueItems = nil, nil, false
+	rest.MultipleOf, rest.Enum, rest.Items = nil, nil, nil
+	b, err := json.Marshal(&rest)
+	return simple, validations, ok && err == nil && string(b) == "{}"
+}
+
+// requestBody returns the parameters of the request body in and its media
+// types.
+func (c *v2Converter) requestBody(path string, in *spec3.RequestBody) ([]spec.Parameter, []string) {
+	if in.Ref.String() != "" {
+		name, ok := in.Ref.LocalName(requestBodiesPrefix)
+		var resolved *spec3.RequestBody
+		if ok && c.in.Components != nil {
+			resolved = c.in.Components.RequestBodies[name]
+		}
+		if resolved == nil {
+			c.losses.dropped(path, in)
+			return nil, nil
+		}
+		if c.sharedBodies[name] {
+			body, _ := splitMediaTypes(resolved.Content)
+			return []spec.Parameter{{Refable: spec.Refable{Ref: spec.MustCreateRef(parametersPrefix + jsonpointer.Escape(name))}}}, body
+		}
+		path, in = pointer("/components/requestBodies", name), resolved
+	}
+	if p, ok := c.bodyParameter(path, in); ok {
+		body, _ := splitMediaTypes(in.Content)
+		return []spec.Parameter{p}, body
+	}
+	_, form := splitMediaTypes(in.Content)
+	return c.formParameters(path, in, form), form
+}
+
+// bodyParameter returns the body parameter of the request body in, or false
+// if it is a form.
+func (c *v2Converter) bodyParameter(path string, in *spec3.RequestBody) (spec.Parameter, bool) {
+	body, form := splitMediaTypes(in.Content)
+	if len(body) == 0 && len(form) > 0 {
+		return spec.Parameter{}, false
+	}
+	for _, mt := range form {
+		c.losses.dropped(pointer(path, "content", mt), in.Content[mt])
+	}
+	for _, mt := range body {
+		c.mediaTypeLosses(pointer(path, "content", mt), in.Content[mt], true)
+	}
+	return spec.Parameter{
+		ParamProps: spec.ParamProps{
+			Name:        "body",
+			In:          "body",
+			Description: in.Description,
+			Required:    in.Required,
+			Schema:      c.contentSchema(path, in.Content, body),
+		},
+		VendorExtensible: in.VendorExtensible,
+	}, true
+}
+
+// formParameters returns the formData parameters of the properties of the
+// schema of the form request body in.
+func (c *v2Converter) formParameters(path string, in *spec3.RequestBody, mediaTypes []string) []spec.Parameter {
+	schema := c.contentSchema(path, in.Content, mediaTypes)
+	if schema == nil {
+		return nil
+	}
+	mt := in.Content[mediaTypes[0]]
+	schemaPath := pointer(path, "content", mediaTypes[0], "schema")
+	if schema.Ref.String() != "" || len(schema.Properties) == 0 {
+		c.losses.dropped(schemaPath, schema)
+		return nil
+	}
+	for _, t := range mediaTypes {
+		c.mediaTypeLosses(pointer(path, "content", t), in.Content[t], false)
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	params := make([]spec.Parameter, 0, len(names))
+	for _, name := range names {
+		prop := schema.Properties[name]
+		p := spec.Parameter{
+			ParamProps: spec.ParamProps{
+				Name:        name,
+				In:          "formData",
+				Description: prop.Description,
+				Required:    spec.StringOrArray(schema.Required).Contains(name),
+			},
+			VendorExtensible: spec.VendorExtensible{Extensions: prop.Extensions},
+		}
+		prop.Description, prop.Extensions = "", nil
+		p.SimpleSchema, p.CommonValidations = c.simpleSchema(pointer(schemaPath, "properties", name), &prop)
+		if p.Type == "string" && p.Format == "binary" {
+			p.Type, p.Format = "file", ""
+		}
+		if e := mt.Encoding[name]; e != nil {
+			encodingPath := pointer(path, "content", mediaTypes[0], "encoding", name)
+			if p.Type == "array" {
+				p.CollectionFormat = c.collectionFormat(encodingPath, "formData", e.Style, e.Explode)
+			}
+			if e.ContentType != "" || len(e.Headers) > 0 || e.AllowReserved {
+				c.losses.dropped(encodingPath, e)
+			}
+		} else if p.Type == "array" {
+			p.CollectionFormat = "multi"
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// contentSchema returns the schema of the given media types of content,
+// preferring the one of application/json, and reports the media types with
+// other schemas as dropped.
+func (c *v2Converter) contentSchema(path string, content map[string]*spec3.MediaType, mediaTypes []string) *spec.Schema {
+	if len(mediaTypes) == 0 {
+		return nil
+	}
+	kept := mediaTypes[0]
+	if containsString(mediaTypes, jsonMediaType) {
+		kept = jsonMediaType
+	}
+	schema := content[kept].Schema
+	for _, mt := range mediaTypes {
+		if mt != kept && !reflect.DeepEqual(content[mt].Schema, schema) {
+			c.losses.dropped(pointer(path, "content", mt, "schema"), content[mt].Schema)
+		}
+	}
+	return c.schema(schema)
+}
+
+// mediaTypeLosses reports the parts of mt without Swagger 2.0 counterparts
+// in request bodies. Encodings are only used by forms.
+func (c *v2Converter) mediaTypeLosses(path string, mt *spec3.MediaType, encoding bool) {
+	if mt.Example != nil {
+		c.losses.dropped(path+"/example", mt.Example)
+	}
+	if len(mt.Examples) > 0 {
+		c.losses.dropped(path+"/examples", mt.Examples)
+	}
+	if encoding && len(mt.Encoding) > 0 {
+		c.losses.dropped(path+"/encoding", mt.Encoding)
+	}
+}
+
+// splitMediaTypes returns the sorted media types of content, split into
+// those of bodies and those of forms.
+func splitMediaTypes(content map[string]*spec3.MediaType) (body, form []string) {
+	for mt, m := range content {
+		if m == nil {
+			continue
+		}
+		if isFormMediaType(mt) {
+			form = append(form, mt)
+		} else {
+			body = append(body, mt)
+		}
+	}
+	sort.Strings(body)
+	sort.Strings(form)
+	return body, form
+}
+
+// responses returns the responses of in and the media types they produce.
+func (c *v2Converter) responses(path string, in *spec3.Responses) (*spec.Responses, []string) {
+	if in == nil {
+		return nil, nil
+	}
+	out := &spec.Responses{VendorExtensible: in.VendorExtensible}
+	produces := map[string]bool{}
+	if in.Default != nil {
+		r := c.response(pointer(path, "default"), in.Default)
+		out.Default = &r
+		c.addMediaTypes(produces, in.Default)
+	}
+	for code, r := range in.StatusCodeResponses {
+		if r == nil {
+			continue
+		}
+		if out.StatusCodeResponses == nil {
+			out.StatusCodeResponses = make(map[int]spec.Response, len(in.StatusCodeResponses))
+		}
+		out.StatusCodeResponses[code] = c.response(pointer(path, strconv.Itoa(code)), r)
+		c.addMediaTypes(produces, r)
+	}
+	return out, sortedStrings(produces)
+}
+
+// addMediaTypes adds the media types of the response r, resolving
+// references to components responses, to set.
+func (c *v2Converter) addMediaTypes(set map[string]bool, r *spec3.Response) {
+	if name, ok := r.Ref.LocalName(responsesV3Prefix); ok && c.in.Components != nil {
+		r = c.in.Components.Responses[name]
+	}
+	if r == nil {
+		return
+	}
+	for mt := range r.Content {
+		set[mt] = true
+	}
+}
+
+func (c *v2Converter) response(path string, in *spec3.Response) spec.Response {
+	if in.Ref.String() != "" {
+		if r := rewriteRef(&in.Ref, map[string]string{responsesV3Prefix: responsesPrefix}); r != nil {
+			return spec.Response{Refable: spec.Refable{Ref: *r}}
+		}
+		return spec.Response{Refable: in.Refable}
+	}
+	out := spec.Response{
+		ResponseProps:    spec.ResponseProps{Description: in.Description},
+		VendorExtensible: in.VendorExtensible,
+	}
+	mediaTypes, form := splitMediaTypes(in.Content)
+	mediaTypes = append(mediaTypes, form...)
+	sort.Strings(mediaTypes)
+	out.Schema = c.contentSchema(path, in.Content, mediaTypes)
+	for _, mt := range mediaTypes {
+		m := in.Content[mt]
+		if m.Example != nil {
+			if out.Examples == nil {
+				out.Examples = map[string]interface{}{}
+			}
+			out.Examples[mt] = m.Example
+		}
+		if len(m.Examples) > 0 {
+			c.losses.dropped(pointer(path, "content", mt, "examples"), m.Examples)
+		}
+		if len(m.Encoding) > 0 {
+			c.losses.dropped(pointer(path, "content", mt, "encoding"), m.Encoding)
+		}
+	}
+	for name, h := range in.Headers {
+		header, ok := c.header(pointer(path, "headers", name), h)
+		if !ok {
+			continue
+		}
+		if out.Headers == nil {
+			out.Headers = make(map[string]spec.Header, len(in.Headers))
+		}
+		out.Headers[name] = header
+	}
+	if len(in.Links) > 0 {
+		c.losses.dropped(path+"/links", in.Links)
+	}
+	return out
+}
+
+func (c *v2Converter) header(path string, in *spec3.Header) (spec.Header, bool) {
+	if in == nil {
+		return spec.Header{}, false
+	}
+	if in.Ref.String() != "" {
+		c.losses.dropped(path, in)
+		return spec.Header{}, false
+	}
+	out := spec.Header{
+		HeaderProps:      spec.HeaderProps{Description: in.Description},
+		VendorExtensible: in.VendorExtensible,
+	}
+	out.SimpleSchema, out.CommonValidations = c.simpleSchema(path+"/schema", in.Schema)
+	if in.Example != nil {
+		out.Example = in.Example
+	}
+	if out.Type == "array" {
+		out.CollectionFormat = c.collectionFormat(path, "header", in.Style, in.Explode)
+	}
+	for _, f := range []struct {
+		keyword string
+		set     bool
+		value   interface{}
+	}{
+		{"required", in.Required, in.Required},
+		{"deprecated", in.Deprecated, in.Deprecated},
+		{"content", len(in.Content) > 0, in.Content},
+		{"examples", len(in.Examples) > 0, in.Examples},
+	} {
+		if f.set {
+			c.losses.dropped(path+"/"+f.keyword, f.value)
+		}
+	}
+	return out, true
+}
+
+func (c *v2Converter) securityScheme(path string, in *spec3.SecurityScheme) (*spec.SecurityScheme, bool) {
+	if in == nil {
+		return nil, false
+	}
+	if in.Ref.String() != "" {
+		c.losses.dropped(path, in)
+		return nil, false
+	}
+	out := &spec.SecurityScheme{
+		SecuritySchemeProps: spec.SecuritySchemeProps{
+			Description: in.Description,
+			Type:        in.Type,
+		},
+		VendorExtensible: in.VendorExtensible,
+	}
+	switch {
+	case in.Type == "http" && strings.EqualFold(in.Scheme, "basic"):
+		out.Type = "basic"
+	case in.Type == "apiKey" && in.In != "cookie":
+		out.Name = in.Name
+		out.In = in.In
+	case in.Type == "oauth2":
+		// Swagger 2.0 security schemes have a single flow
+		names := make([]string, 0, len(in.Flows))
+		for name, flow := range in.Flows {
+			if flow != nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			flow := in.Flows[name]
+			if out.Flow != "" || oauthFlowsV2[name] == "" {
+				c.losses.dropped(pointer(path, "flows", name), flow)
+				continue
+			}
+			out.Flow = oauthFlowsV2[name]
+			out.AuthorizationURL = flow.AuthorizationUrl
+			out.TokenURL = flow.TokenUrl
+			out.Scopes = flow.Scopes
+			if flow.RefreshUrl != "" {
+				c.losses.dropped(pointer(path, "flows", name, "refreshUrl"), flow.RefreshUrl)
+			}
+		}
+		if out.Flow == "" {
+			return nil, false
+		}
+	default:
+		c.losses.dropped(path, in)
+		return nil, false
+	}
+	return out, true
+}
+
+// oauthFlowsV2 are the names of the OAuth2 flows of Swagger 2.0 by their
+// OpenAPI v3 names.
+var oauthFlowsV2 = map[string]string{
+	"implicit":          "implicit",
+	"password":          "password",
+	"clientCredentials": "application",
+	"authorizationCode": "accessCode",
+}
+
+func externalDocsV2(in *spec3.ExternalDocumentation) *spec.ExternalDocumentation {
+	if in == nil {
+		return nil
+	}
+	return &spec.ExternalDocumentation{Description: in.Description, URL: in.URL}
+}
+
+func securityV2(in []*spec3.SecurityRequirement) []map[string][]string {
+	if in == nil {
+		return nil
+	}
+	out := make([]map[string][]string, 0, len(in))
+	for _, r := range in {
+		if r != nil {
+			out = append(out, r.SecurityRequirementProps)
+		}
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
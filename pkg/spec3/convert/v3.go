@@ -0,0 +1,587 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/schemamutation"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/compat"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ToV3 converts the Swagger 2.0 document in to an OpenAPI v3.0 document.
+//
+// Body and formData parameters, including those shared through the
+// parameters of in, become request bodies with the consumes media types of
+// their operations, defaulting to application/json for body parameters and
+// to application/x-www-form-urlencoded, or multipart/form-data for files,
+// for formData parameters. Shared body parameters become components
+// request bodies.
+func ToV3(in *spec.Swagger) (*spec3.OpenAPI, []compat.Loss) {
+	c := &v3Converter{in: in}
+	c.walker = &schemamutation.Walker{
+		SchemaCallback: schemamutation.SchemaCallBackNoop,
+		RefCallback: func(ref *spec.Ref) *spec.Ref {
+			if r := rewriteRef(ref, map[string]string{definitionsPrefix: schemasPrefix}); r != nil {
+				return r
+			}
+			return ref
+		},
+	}
+
+	out := &spec3.OpenAPI{
+		Version:             "3.0.0",
+		Info:                in.Info,
+		Servers:             c.servers("", in.Schemes),
+		Paths:               c.paths(in.Paths),
+		Components:          c.components(),
+		ExternalDocs:        externalDocsV3(in.ExternalDocs),
+		SecurityRequirement: securityV3(in.Security),
+		Tags:                in.Tags,
+		Extensions:          in.Extensions,
+	}
+	if in.ID != "" {
+		c.losses.dropped("/id", in.ID)
+	}
+	return out, c.losses.sorted()
+}
+
+type v3Converter struct {
+	in     *spec.Swagger
+	walker *schemamutation.Walker
+	losses losses
+}
+
+func (c *v3Converter) schema(s *spec.Schema) *spec.Schema {
+	if s == nil {
+		return nil
+	}
+	return c.walker.WalkSchema(s)
+}
+
+// servers returns the servers of the host and base path of the document
+// with the given schemes.
+func (c *v3Converter) servers(path string, schemes []string) []*spec3.Server {
+	host, basePath := c.in.Host, c.in.BasePath
+	if host == "" {
+		// the schemes of the host serving the document
+		if len(schemes) > 0 {
+			c.losses.dropped(path+"/schemes", schemes)
+		}
+		if basePath == "" {
+			return nil
+		}
+		return []*spec3.Server{{ServerProps: spec3.ServerProps{URL: basePath}}}
+	}
+	if len(schemes) == 0 {
+		return []*spec3.Server{{ServerProps: spec3.ServerProps{URL: "//" + host + basePath}}}
+	}
+	servers := make([]*spec3.Server, 0, len(schemes))
+	for _, scheme := range schemes {
+		servers = append(servers, &spec3.Server{ServerProps: spec3.ServerProps{URL: scheme + "://" + host + basePath}})
+	}
+	return servers
+}
+
+func (c *v3Converter) components() *spec3.Components {
+	in := c.in
+	out := &spec3.Components{}
+	for name, s := range in.Definitions {
+		if out.Schemas == nil {
+			out.Schemas = make(map[string]*spec.Schema, len(in.Definitions))
+		}
+		s := s
+		out.Schemas[name] = c.schema(&s)
+	}
+	for name, p := range in.Parameters {
+		p := p
+		switch p.In {
+		case "body":
+			if out.RequestBodies == nil {
+				out.RequestBodies = map[string]*spec3.RequestBody{}
+			}
+			out.RequestBodies[name] = c.bodyParameter(&p, in.Consumes)
+		case "formData":
+			// inlined into the request bodies of the operations using them
+		default:
+			if out.Parameters == nil {
+				out.Parameters = map[string]*spec3.Parameter{}
+			}
+			out.Parameters[name] = c.parameter(pointer("/parameters", name), p)
+		}
+	}
+	for name, r := range in.Responses {
+		if out.Responses == nil {
+			out.Responses = make(map[string]*spec3.Response, len(in.Responses))
+		}
+		out.Responses[name] = c.response(pointer("/responses", name), r, in.Produces)
+	}
+	for name, s := range in.SecurityDefinitions {
+		if s == nil {
+			continue
+		}
+		if out.SecuritySchemes == nil {
+			out.SecuritySchemes = make(spec3.SecuritySchemes, len(in.SecurityDefinitions))
+		}
+		out.SecuritySchemes[name] = c.securityScheme(pointer("/securityDefinitions", name), s)
+	}
+	if out.Schemas == nil && out.RequestBodies == nil && out.Parameters == nil && out.Responses == nil && out.SecuritySchemes == nil {
+		return nil
+	}
+	return out
+}
+
+func (c *v3Converter) paths(in *spec.Paths) *spec3.Paths {
+	if in == nil {
+		return nil
+	}
+	out := &spec3.Paths{VendorExtensible: in.VendorExtensible}
+	if in.Paths != nil {
+		out.Paths = make(map[string]*spec3.Path, len(in.Paths))
+	}
+	for p, item := range in.Paths {
+		out.Paths[p] = c.pathItem(pointer("/paths", p), item)
+	}
+	return out
+}
+
+func (c *v3Converter) pathItem(path string, in spec.PathItem) *spec3.Path {
+	out := &spec3.Path{Refable: in.Refable, VendorExtensible: in.VendorExtensible}
+	// body and formData parameters are part of the request bodies of the operations
+	var bodyParams []locatedParameter
+	for i, p := range in.Parameters {
+		path := pointer(path, "parameters", strconv.Itoa(i))
+		if isBodyParameter(c.resolveParameter(p)) {
+			bodyParams = append(bodyParams, locatedParameter{path, p})
+			continue
+		}
+		out.Parameters = append(out.Parameters, c.parameter(path, p))
+	}
+	for _, op := range []struct {
+		method string
+		in     *spec.Operation
+		out    **spec3.Operation
+	}{
+		{"get", in.Get, &out.Get},
+		{"put", in.Put, &out.Put},
+		{"post", in.Post, &out.Post},
+		{"delete", in.Delete, &out.Delete},
+		{"options", in.Options, &out.Options},
+		{"head", in.Head, &out.Head},
+		{"patch", in.Patch, &out.Patch},
+	} {
+		if op.in != nil {
+			*op.out = c.operation(pointer(path, op.method), op.in, bodyParams)
+		}
+	}
+	return out
+}
+
+func (c *v3Converter) operation(path string, in *spec.Operation, pathBodyParams []locatedParameter) *spec3.Operation {
+	out := &spec3.Operation{
+		OperationProps: spec3.OperationProps{
+			Tags:                in.Tags,
+			Summary:             in.Summary,
+			Description:         in.Description,
+			ExternalDocs:        externalDocsV3(in.ExternalDocs),
+			OperationId:         in.ID,
+			Deprecated:          in.Deprecated,
+			SecurityRequirement: securityV3(in.Security),
+		},
+		VendorExtensible: in.VendorExtensible,
+	}
+	if len(in.Schemes) > 0 {
+		out.Servers = c.servers(path, in.Schemes)
+	}
+	consumes, produces := in.Consumes, in.Produces
+	if consumes == nil {
+		consumes = c.in.Consumes
+	}
+	if produces == nil {
+		produces = c.in.Produces
+	}
+
+	var bodyParams []locatedParameter
+	for i, p := range in.Parameters {
+		path := pointer(path, "parameters", strconv.Itoa(i))
+		if isBodyParameter(c.resolveParameter(p)) {
+			bodyParams = append(bodyParams, locatedParameter{path, p})
+			continue
+		}
+		out.Parameters = append(out.Parameters, c.parameter(path, p))
+	}
+	for _, p := range pathBodyParams {
+		if !c.overridden(p.param, bodyParams) {
+			bodyParams = append(bodyParams, p)
+		}
+	}
+	out.RequestBody = c.requestBody(bodyParams, consumes)
+	out.Responses = c.responses(pointer(path, "responses"), in.Responses, produces)
+	return out
+}
+
+// locatedParameter is a parameter with its JSON pointer in the document.
+type locatedParameter struct {
+	path  string
+	param spec.Parameter
+}
+
+// resolveParameter returns the shared parameter p refers to, or p.
+func (c *v3Converter) resolveParameter(p spec.Parameter) spec.Parameter {
+	if name, ok := p.Ref.LocalName(parametersPrefix); ok {
+		if shared, ok := c.in.Parameters[name]; ok {
+			return shared
+		}
+	}
+	return p
+}
+
+func isBodyParameter(p spec.Parameter) bool {
+	return p.In == "body" || p.In == "formData"
+}
+
+// overridden returns true if params has a parameter with the name and
+// location of p.
+func (c *v3Converter) overridden(p spec.Parameter, params []locatedParameter) bool {
+	p = c.resolveParameter(p)
+	for _, o := range params {
+		if o := c.resolveParameter(o.param); o.Name == p.Name && o.In == p.In {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *v3Converter) parameter(path string, p spec.Parameter) *spec3.Parameter {
+	if p.Ref.String() != "" {
+		if r := rewriteRef(&p.Ref, map[string]string{parametersPrefix: parametersV3Prefix}); r != nil {
+			return &spec3.Parameter{Refable: spec.Refable{Ref: *r}}
+		}
+		return &spec3.Parameter{Refable: p.Refable}
+	}
+	schema := c.simpleSchema(path, p.SimpleSchema, p.CommonValidations)
+	out := &spec3.Parameter{
+		ParameterProps: spec3.ParameterProps{
+			Name:            p.Name,
+			In:              p.In,
+			Description:     p.Description,
+			Required:        p.Required,
+			AllowEmptyValue: p.AllowEmptyValue,
+			Schema:          schema,
+			Example:         schema.Example,
+		},
+		VendorExtensible: p.VendorExtensible,
+	}
+	schema.Example = nil
+	if p.Type == "array" {
+		out.Style, out.Explode = c.style(path, p.In, p.CollectionFormat)
+	}
+	return out
+}
+
+// style returns the style and explode of array parameters of location in
+// with the given collection format.
+func (c *v3Converter) style(path, in, collectionFormat string) (string, bool) {
+	switch collectionFormat {
+	case "", "csv":
+		if in == "query" || in == "formData" {
+			return "form", false
+		}
+		// the default style of path and header parameters
+		return "", false
+	case "multi":
+		return "form", true
+	case "ssv":
+		return "spaceDelimited", false
+	case "pipes":
+		return "pipeDelimited", false
+	}
+	c.losses.dropped(path+"/collectionFormat", collectionFormat)
+	return "", false
+}
+
+// simpleSchema returns the schema of the type and validations of non-body
+// parameters, items and headers.
+func (c *v3Converter) simpleSchema(path string, s spec.SimpleSchema, v spec.CommonValidations) *spec.Schema {
+	out := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Format:           s.Format,
+			Nullable:         s.Nullable,
+			Default:          s.Default,
+			Maximum:          v.Maximum,
+			ExclusiveMaximum: v.ExclusiveMaximum,
+			Minimum:          v.Minimum,
+			ExclusiveMinimum: v.ExclusiveMinimum,
+			MaxLength:        v.MaxLength,
+			MinLength:        v.MinLength,
+			Pattern:          v.Pattern,
+			MaxItems:         v.MaxItems,
+			MinItems:         v.MinItems,
+			UniqueItems:      v.UniqueItems,
+			MultipleOf:       v.MultipleOf,
+			Enum:             v.Enum,
+		},
+		SwaggerSchemaProps: spec.SwaggerSchemaProps{Example: s.Example},
+	}
+	switch s.Type {
+	case "":
+	case "file":
+		out.Type = spec.StringOrArray{"string"}
+		out.Format = "binary"
+	default:
+		out.Type = spec.StringOrArray{s.Type}
+	}
+	if s.Items != nil {
+		items := c.simpleSchema(path+"/items", s.Items.SimpleSchema, s.Items.CommonValidations)
+		items.Extensions = s.Items.Extensions
+		if s.Items.CollectionFormat != "" {
+			// nested arrays are serialized by the style of the parameter only
+			c.losses.dropped(path+"/items/collectionFormat", s.Items.CollectionFormat)
+		}
+		out.Items = &spec.SchemaOrArray{Schema: items}
+	}
+	return out
+}
+
+// requestBody returns the request body of the body or formData parameters
+// params of an operation.
+func (c *v3Converter) requestBody(params []locatedParameter, consumes []string) *spec3.RequestBody {
+	var form []locatedParameter
+	for _, p := range params {
+		if name, ok := p.param.Ref.LocalName(parametersPrefix); ok {
+			if c.in.Parameters[name].In == "body" {
+				return &spec3.RequestBody{Refable: spec.Refable{Ref: *rewriteRef(&p.param.Ref, map[string]string{parametersPrefix: requestBodiesPrefix})}}
+			}
+			p = locatedParameter{pointer("/parameters", name), c.resolveParameter(p.param)}
+		}
+		if p.param.In == "body" {
+			return c.bodyParameter(&p.param, consumes)
+		}
+		form = append(form, p)
+	}
+	if len(form) == 0 {
+		return nil
+	}
+	return c.formParameters(form, consumes)
+}
+
+func (c *v3Converter) bodyParameter(p *spec.Parameter, consumes []string) *spec3.RequestBody {
+	if len(consumes) == 0 {
+		consumes = []string{jsonMediaType}
+	}
+	return &spec3.RequestBody{
+		RequestBodyProps: spec3.RequestBodyProps{
+			Description: p.Description,
+			Required:    p.Required,
+			Content:     c.content(consumes, p.Schema, nil),
+		},
+		VendorExtensible: p.VendorExtensible,
+	}
+}
+
+// formParameters returns the request body of formData parameters, an
+// object with a property for each parameter.
+func (c *v3Converter) formParameters(params []locatedParameter, consumes []string) *spec3.RequestBody {
+	out := &spec3.RequestBody{}
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       spec.StringOrArray{"object"},
+		Properties: make(map[string]spec.Schema, len(params)),
+	}}
+	var encoding map[string]*spec3.Encoding
+	file := false
+	for _, located := range params {
+		p := located.param
+		prop := c.simpleSchema(located.path, p.SimpleSchema, p.CommonValidations)
+		prop.Description = p.Description
+		prop.Extensions = p.Extensions
+		schema.Properties[p.Name] = *prop
+		if p.Required {
+			schema.Required = append(schema.Required, p.Name)
+			out.Required = true
+		}
+		switch p.Type {
+		case "file":
+			file = true
+		case "array":
+			if encoding == nil {
+				encoding = map[string]*spec3.Encoding{}
+			}
+			style, explode := c.style(located.path, p.In, p.CollectionFormat)
+			encoding[p.Name] = &spec3.Encoding{EncodingProps: spec3.EncodingProps{Style: style, Explode: explode}}
+		}
+	}
+
+	var mediaTypes []string
+	for _, mt := range consumes {
+		if isFormMediaType(mt) {
+			mediaTypes = append(mediaTypes, mt)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{urlEncodedFormType}
+		if file {
+			mediaTypes = []string{multipartFormType}
+		}
+	}
+	out.Content = make(map[string]*spec3.MediaType, len(mediaTypes))
+	for i, mt := range mediaTypes {
+		if i > 0 {
+			schema = schema.DeepCopy()
+		}
+		out.Content[mt] = &spec3.MediaType{MediaTypeProps: spec3.MediaTypeProps{Schema: schema, Encoding: encoding}}
+	}
+	return out
+}
+
+// content returns the media types of the given schema and examples.
+func (c *v3Converter) content(mediaTypes []string, schema *spec.Schema, examples map[string]interface{}) map[string]*spec3.MediaType {
+	content := map[string]*spec3.MediaType{}
+	if schema != nil {
+		for _, mt := range mediaTypes {
+			content[mt] = &spec3.MediaType{MediaTypeProps: spec3.MediaTypeProps{Schema: c.schema(schema)}}
+		}
+	}
+	for mt, example := range examples {
+		if content[mt] == nil {
+			content[mt] = &spec3.MediaType{MediaTypeProps: spec3.MediaTypeProps{Schema: c.schema(schema)}}
+		}
+		content[mt].Example = example
+	}
+	return content
+}
+
+func (c *v3Converter) responses(path string, in *spec.Responses, produces []string) *spec3.Responses {
+	if in == nil {
+		return nil
+	}
+	out := &spec3.Responses{VendorExtensible: in.VendorExtensible}
+	if in.Default != nil {
+		out.Default = c.response(pointer(path, "default"), *in.Default, produces)
+	}
+	for code, r := range in.StatusCodeResponses {
+		if out.StatusCodeResponses == nil {
+			out.StatusCodeResponses = make(map[int]*spec3.Response, len(in.StatusCodeResponses))
+		}
+		out.StatusCodeResponses[code] = c.response(pointer(path, strconv.Itoa(code)), r, produces)
+	}
+	return out
+}
+
+func (c *v3Converter) response(path string, in spec.Response, produces []string) *spec3.Response {
+	if in.Ref.String() != "" {
+		if r := rewriteRef(&in.Ref, map[string]string{responsesPrefix: responsesV3Prefix}); r != nil {
+			return &spec3.Response{Refable: spec.Refable{Ref: *r}}
+		}
+		return &spec3.Response{Refable: in.Refable}
+	}
+	out := &spec3.Response{
+		ResponseProps:    spec3.ResponseProps{Description: in.Description},
+		VendorExtensible: in.VendorExtensible,
+	}
+	if in.Schema != nil || len(in.Examples) > 0 {
+		if len(produces) == 0 {
+			produces = []string{jsonMediaType}
+		}
+		out.Content = c.content(produces, in.Schema, in.Examples)
+	}
+	for name, h := range in.Headers {
+		if out.Headers == nil {
+			out.Headers = make(map[string]*spec3.Header, len(in.Headers))
+		}
+		out.Headers[name] = c.header(pointer(path, "headers", name), h)
+	}
+	return out
+}
+
+func (c *v3Converter) header(path string, in spec.Header) *spec3.Header {
+	schema := c.simpleSchema(path, in.SimpleSchema, in.CommonValidations)
+	out := &spec3.Header{
+		HeaderProps: spec3.HeaderProps{
+			Description: in.Description,
+			Schema:      schema,
+			Example:     schema.Example,
+		},
+		VendorExtensible: in.VendorExtensible,
+	}
+	schema.Example = nil
+	if in.Type == "array" {
+		out.Style, out.Explode = c.style(path, "header", in.CollectionFormat)
+	}
+	return out
+}
+
+// oauthFlowsV3 are the names of the OAuth2 flows of OpenAPI v3 by their
+// Swagger 2.0 names.
+var oauthFlowsV3 = map[string]string{
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "clientCredentials",
+	"accessCode":  "authorizationCode",
+}
+
+func (c *v3Converter) securityScheme(path string, in *spec.SecurityScheme) *spec3.SecurityScheme {
+	out := &spec3.SecurityScheme{
+		SecuritySchemeProps: spec3.SecuritySchemeProps{
+			Type:        in.Type,
+			Description: in.Description,
+		},
+		VendorExtensible: in.VendorExtensible,
+	}
+	switch in.Type {
+	case "basic":
+		out.Type = "http"
+		out.Scheme = "basic"
+	case "apiKey":
+		out.Name = in.Name
+		out.In = in.In
+	case "oauth2":
+		flow, ok := oauthFlowsV3[in.Flow]
+		if !ok {
+			c.losses.dropped(path+"/flow", in.Flow)
+			break
+		}
+		out.Flows = map[string]*spec3.OAuthFlow{flow: {OAuthFlowProps: spec3.OAuthFlowProps{
+			AuthorizationUrl: in.AuthorizationURL,
+			TokenUrl:         in.TokenURL,
+			Scopes:           in.Scopes,
+		}}}
+	}
+	return out
+}
+
+func externalDocsV3(in *spec.ExternalDocumentation) *spec3.ExternalDocumentation {
+	if in == nil {
+		return nil
+	}
+	return &spec3.ExternalDocumentation{ExternalDocumentationProps: spec3.ExternalDocumentationProps{
+		Description: in.Description,
+		URL:         in.URL,
+	}}
+}
+
+func securityV3(in []map[string][]string) []*spec3.SecurityRequirement {
+	if in == nil {
+		return nil
+	}
+	out := make([]*spec3.SecurityRequirement, len(in))
+	for i, r := range in {
+		out[i] = &spec3.SecurityRequirement{SecurityRequirementProps: r}
+	}
+	return out
+}
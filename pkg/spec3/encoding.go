@@ -28,7 +28,7 @@ type Encoding struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Encoding as JSON
-func (e *Encoding) MarshalJSON() ([]byte, error) {
+func (e Encoding) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(e.EncodingProps)
 	if err != nil {
 		return nil, err
@@ -58,7 +58,7 @@ type EncodingProps struct {
 	// Describes how a specific property value will be serialized depending on its type
 	Style string `json:"style,omitempty"`
 	// When this is true, property values of type array or object generate separate parameters for each value of the array, or key-value-pair of the map. For other types of properties this property has no effect
-	Explode string `json:"explode,omitempty"`
+	Explode bool `json:"explode,omitempty"`
 	// AllowReserved determines whether the parameter value SHOULD allow reserved characters, as defined by RFC3986
 	AllowReserved bool `json:"allowReserved,omitempty"`
 }
@@ -32,7 +32,7 @@ type Example struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode RequestBody as JSON
-func (e *Example) MarshalJSON() ([]byte, error) {
+func (e Example) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(e.Refable)
 	if err != nil {
 		return nil, err
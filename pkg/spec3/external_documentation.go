@@ -35,7 +35,7 @@ type ExternalDocumentationProps struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Responses as JSON
-func (e *ExternalDocumentation) MarshalJSON() ([]byte, error) {
+func (e ExternalDocumentation) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(e.ExternalDocumentationProps)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+// FuzzOpenAPIRoundTrip checks that unmarshaling arbitrary input, e.g.
+// aggregated specs of other servers, does not panic, and that the JSON
+// representation of whatever is accepted is stable from the first round
+// trip on.
+func FuzzOpenAPIRoundTrip(f *testing.F) {
+	f.Add([]byte(`{"openapi": "3.0.0", "info": {"title": "t", "version": "v1"}, "paths": {"/a": {"get": {"responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/a"}}}}}}}}}`))
+	f.Add([]byte(`{"openapi": "3.1.0", "paths": {"/a/{b}": {"parameters": [{"name": "b", "in": "path", "required": true, "schema": {"type": "string"}}], "post": {"requestBody": {"$ref": "#/components/requestBodies/b"}, "callbacks": {"c": {"{$url}": {"get": {}}}}}}}}`))
+	f.Add([]byte(`{"components": {"schemas": {"a": {"type": "object"}}, "securitySchemes": {"k": {"type": "oauth2", "flows": {"implicit": {"authorizationUrl": "u", "scopes": {}}}}}, "x-a": 1}, "servers": [{"url": "https://{h}", "variables": {"h": {"default": "example.com"}}}]}`))
+	f.Add([]byte(`{"webhooks": {"w": {"post": {"responses": {"default": {"description": "d", "links": {"l": {"operationId": "o"}}}}}}}, "security": [{"k": []}], "tags": [{"name": "t"}]}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var openAPI spec3.OpenAPI
+		if err := json.Unmarshal(data, &openAPI); err != nil {
+			return
+		}
+		first, err := json.Marshal(&openAPI)
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %v", data, err)
+		}
+		openAPI = spec3.OpenAPI{}
+		if err := json.Unmarshal(first, &openAPI); err != nil {
+			t.Fatalf("failed to unmarshal %s, marshaled from %s: %v", first, data, err)
+		}
+		second, err := json.Marshal(&openAPI)
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %v", first, err)
+		}
+		if !bytes.Equal(first, second) {
+			t.Fatalf("unstable round trip of %s:\n%s\n%s", data, first, second)
+		}
+	})
+}
@@ -33,7 +33,7 @@ type Header struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Header as JSON
-func (h *Header) MarshalJSON() ([]byte, error) {
+func (h Header) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(h.Refable)
 	if err != nil {
 		return nil, err
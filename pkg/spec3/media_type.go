@@ -31,7 +31,7 @@ type MediaType struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode MediaType as JSON
-func (m *MediaType) MarshalJSON() ([]byte, error) {
+func (m MediaType) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(m.MediaTypeProps)
 	if err != nil {
 		return nil, err
@@ -32,7 +32,7 @@ type Operation struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Operation as JSON
-func (o *Operation) MarshalJSON() ([]byte, error) {
+func (o Operation) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(o.OperationProps)
 	if err != nil {
 		return nil, err
@@ -70,6 +70,8 @@ type OperationProps struct {
 	RequestBody *RequestBody `json:"requestBody,omitempty"`
 	// Responses holds the list of possible responses as they are returned from executing this operation
 	Responses *Responses `json:"responses,omitempty"`
+	// Callbacks holds the possible out-of-band callbacks related to this operation, by name
+	Callbacks map[string]*Callback `json:"callbacks,omitempty"`
 	// Deprecated declares this operation to be deprecated
 	Deprecated bool `json:"deprecated,omitempty"`
 	// SecurityRequirement holds a declaration of which security mechanisms can be used for this operation
@@ -77,3 +79,22 @@ type OperationProps struct {
 	// Servers contains an alternative server array to service this operation
 	Servers []*Server `json:"servers,omitempty"`
 }
+
+// MarshalJSON takes care of serializing operation properties to JSON
+//
+// We use a custom marshaller here to preserve a zero length SecurityRequirement,
+// which removes the security of the document from the operation, while
+// omitting the field when the value is nil/unset.
+func (o OperationProps) MarshalJSON() ([]byte, error) {
+	type Alias OperationProps
+	if o.SecurityRequirement == nil {
+		return json.Marshal(Alias(o))
+	}
+	return json.Marshal(&struct {
+		SecurityRequirement []*SecurityRequirement `json:"security"`
+		*Alias
+	}{
+		SecurityRequirement: o.SecurityRequirement,
+		Alias:               (*Alias)(&o),
+	})
+}
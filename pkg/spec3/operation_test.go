@@ -115,3 +115,29 @@ func TestOperationJSONSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestOperationEmptySecurity(t *testing.T) {
+	for _, tc := range []struct {
+		security []*spec3.SecurityRequirement
+		expected string
+	}{
+		{nil, `{"operationId":"getPublic"}`},
+		{[]*spec3.SecurityRequirement{}, `{"security":[],"operationId":"getPublic"}`},
+	} {
+		op := spec3.Operation{OperationProps: spec3.OperationProps{OperationId: "getPublic", SecurityRequirement: tc.security}}
+		b, err := json.Marshal(op)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, b)
+		}
+		var roundTripped spec3.Operation
+		if err := json.Unmarshal(b, &roundTripped); err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(op, roundTripped) {
+			t.Errorf("unexpected round trip: %s", cmp.Diff(op, roundTripped))
+		}
+	}
+}
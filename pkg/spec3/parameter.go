@@ -33,7 +33,7 @@ type Parameter struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Parameter as JSON
-func (p *Parameter) MarshalJSON() ([]byte, error) {
+func (p Parameter) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(p.Refable)
 	if err != nil {
 		return nil, err
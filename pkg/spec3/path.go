@@ -31,7 +31,7 @@ type Paths struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Paths as JSON
-func (p *Paths) MarshalJSON() ([]byte, error) {
+func (p Paths) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(p.Paths)
 	if err != nil {
 		return nil, err
@@ -84,7 +84,7 @@ type Path struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Path as JSON
-func (p *Path) MarshalJSON() ([]byte, error) {
+func (p Path) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(p.Refable)
 	if err != nil {
 		return nil, err
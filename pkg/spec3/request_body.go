@@ -33,7 +33,7 @@ type RequestBody struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode RequestBody as JSON
-func (r *RequestBody) MarshalJSON() ([]byte, error) {
+func (r RequestBody) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(r.Refable)
 	if err != nil {
 		return nil, err
@@ -33,7 +33,7 @@ type Responses struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Responses as JSON
-func (r *Responses) MarshalJSON() ([]byte, error) {
+func (r Responses) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(r.ResponsesProps)
 	if err != nil {
 		return nil, err
@@ -107,7 +107,7 @@ type Response struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Response as JSON
-func (r *Response) MarshalJSON() ([]byte, error) {
+func (r Response) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(r.Refable)
 	if err != nil {
 		return nil, err
@@ -158,7 +158,7 @@ type Link struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Link as JSON
-func (r *Link) MarshalJSON() ([]byte, error) {
+func (r Link) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(r.Refable)
 	if err != nil {
 		return nil, err
@@ -190,6 +190,8 @@ func (r *Link) UnmarshalJSON(data []byte) error {
 
 // LinkProps describes a single response from an API Operation, more at https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#responseObject
 type LinkProps struct {
+	// OperationRef is a relative or absolute URI reference to an OAS operation, mutually exclusive with OperationId
+	OperationRef string `json:"operationRef,omitempty"`
 	// OperationId is the name of an existing, resolvable OAS operation
 	OperationId string `json:"operationId,omitempty"`
 	// Parameters is a map representing parameters to pass to an operation as specified with operationId or identified via operationRef
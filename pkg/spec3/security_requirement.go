@@ -32,7 +32,7 @@ type SecurityRequirement struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode SecurityRequirement as JSON
-func (s *SecurityRequirement) MarshalJSON() ([]byte, error) {
+func (s SecurityRequirement) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(s.SecurityRequirementProps)
 	if err != nil {
 		return nil, err
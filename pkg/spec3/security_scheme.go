@@ -31,7 +31,7 @@ type SecurityScheme struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode SecurityScheme as JSON
-func (s *SecurityScheme) MarshalJSON() ([]byte, error) {
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(s.SecuritySchemeProps)
 	if err != nil {
 		return nil, err
@@ -85,7 +85,7 @@ type OAuthFlow struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode OAuthFlow as JSON
-func (o *OAuthFlow) MarshalJSON() ([]byte, error) {
+func (o OAuthFlow) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(o.OAuthFlowProps)
 	if err != nil {
 		return nil, err
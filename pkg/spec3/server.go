@@ -38,7 +38,7 @@ type ServerProps struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Responses as JSON
-func (s *Server) MarshalJSON() ([]byte, error) {
+func (s Server) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(s.ServerProps)
 	if err != nil {
 		return nil, err
@@ -75,7 +75,7 @@ type ServerVariableProps struct {
 }
 
 // MarshalJSON is a custom marshal function that knows how to encode Responses as JSON
-func (s *ServerVariable) MarshalJSON() ([]byte, error) {
+func (s ServerVariable) MarshalJSON() ([]byte, error) {
 	b1, err := json.Marshal(s.ServerVariableProps)
 	if err != nil {
 		return nil, err
@@ -17,6 +17,9 @@ limitations under the License.
 package spec3
 
 import (
+	"encoding/json"
+
+	"github.com/go-openapi/swag"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
@@ -34,4 +37,42 @@ type OpenAPI struct {
 	Components *Components `json:"components,omitempty"`
 	// ExternalDocs holds additional external documentation
 	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty"`
+	// SecurityRequirement holds a declaration of which security mechanisms can be used across the API
+	SecurityRequirement []*SecurityRequirement `json:"security,omitempty"`
+	// Tags holds a list of tags used by the specification with additional metadata
+	Tags []spec.Tag `json:"tags,omitempty"`
+	// Webhooks holds the incoming webhooks that may be received as part of this API, by name, added in OpenAPI 3.1
+	Webhooks map[string]*Path `json:"webhooks,omitempty"`
+	// JSONSchemaDialect is the default value for the $schema keyword within the schemas of the document, added in OpenAPI 3.1
+	JSONSchemaDialect string `json:"jsonSchemaDialect,omitempty"`
+	// Extensions holds the vendor extensions of the document
+	Extensions spec.Extensions `json:"-"`
+}
+
+// MarshalJSON is a custom marshal function that knows how to encode OpenAPI as JSON
+func (o OpenAPI) MarshalJSON() ([]byte, error) {
+	type props OpenAPI
+	b1, err := json.Marshal(props(o))
+	if err != nil {
+		return nil, err
+	}
+	b2, err := json.Marshal(spec.VendorExtensible{Extensions: o.Extensions})
+	if err != nil {
+		return nil, err
+	}
+	return swag.ConcatJSON(b1, b2), nil
+}
+
+// UnmarshalJSON hydrates this items instance with the data from JSON
+func (o *OpenAPI) UnmarshalJSON(data []byte) error {
+	type props OpenAPI
+	if err := json.Unmarshal(data, (*props)(o)); err != nil {
+		return err
+	}
+	var ext spec.VendorExtensible
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return err
+	}
+	o.Extensions = ext.Extensions
+	return nil
 }
@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestOpenAPI31SchemaRoundTrip(t *testing.T) {
+	input := `{
+		"openapi": "3.1.0",
+		"info": {"title": "pets", "version": "v1"},
+		"paths": {},
+		"components": {
+			"schemas": {
+				"Pet": {
+					"type": "object",
+					"properties": {
+						"name": {"type": ["string", "null"], "examples": ["rex", null]},
+						"kind": {"const": "dog"},
+						"age": {"type": "integer", "exclusiveMinimum": 0, "exclusiveMaximum": 30},
+						"weight": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+						"position": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "maxItems": 2}
+					}
+				}
+			}
+		}
+	}`
+	var openAPI spec3.OpenAPI
+	if err := json.Unmarshal([]byte(input), &openAPI); err != nil {
+		t.Fatal(err)
+	}
+
+	pet := openAPI.Components.Schemas["Pet"]
+	if name := pet.Properties["name"]; !name.Type.Contains("null") || len(name.Examples) != 2 {
+		t.Errorf("unexpected name %#v", name.SchemaProps)
+	}
+	if kind := pet.Properties["kind"]; kind.Const != "dog" {
+		t.Errorf("unexpected const %#v", kind.Const)
+	}
+	if age := pet.Properties["age"]; age.ExclusiveMinimumValue == nil || *age.ExclusiveMinimumValue != 0 || age.ExclusiveMinimum {
+		t.Errorf("unexpected exclusiveMinimum %v, %v", age.ExclusiveMinimumValue, age.ExclusiveMinimum)
+	}
+	if position := pet.Properties["position"]; len(position.PrefixItems) != 2 {
+		t.Errorf("unexpected prefixItems %#v", position.PrefixItems)
+	}
+
+	b, err := json.Marshal(&openAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, actual interface{}
+	if err := json.Unmarshal([]byte(input), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("round trip changed the document:\n%s", b)
+	}
+}
+
+func TestOpenAPIRoundTrip(t *testing.T) {
+	input := `{
+		"openapi": "3.1.0",
+		"info": {"title": "pets", "version": "v1"},
+		"jsonSchemaDialect": "https://spec.openapis.org/oas/3.1/dialect/base",
+		"security": [{"apiKey": []}],
+		"tags": [{"name": "pets", "description": "Everything about pets"}],
+		"x-api-id": "pets",
+		"paths": {
+			"/pets": {
+				"post": {
+					"operationId": "createPet",
+					"requestBody": {
+						"content": {
+							"multipart/form-data": {
+								"schema": {"type": "object"},
+								"encoding": {
+									"photo": {
+										"contentType": "image/png",
+										"style": "form",
+										"explode": true,
+										"headers": {"X-Rate-Limit": {"schema": {"type": "integer"}}}
+									}
+								}
+							}
+						}
+					},
+					"callbacks": {
+						"onCreated": {"$ref": "#/components/callbacks/onCreated"}
+					},
+					"responses": {
+						"201": {
+							"description": "created",
+							"links": {
+								"GetPet": {"operationRef": "#/paths/~1pets~1{id}/get", "parameters": {"id": "$response.body#/id"}}
+							}
+						}
+					}
+				}
+			}
+		},
+		"webhooks": {
+			"newPet": {"post": {"responses": {"200": {"description": "received"}}}}
+		},
+		"components": {
+			"callbacks": {
+				"onCreated": {
+					"{$request.body#/callbackUrl}": {"post": {"responses": {"200": {"description": "received"}}}},
+					"x-internal": true
+				}
+			},
+			"pathItems": {
+				"ping": {"get": {"responses": {"200": {"description": "pong"}}}}
+			},
+			"securitySchemes": {"apiKey": {"type": "apiKey", "name": "X-API-Key", "in": "header"}},
+			"x-components-version": 2
+		}
+	}`
+	var openAPI spec3.OpenAPI
+	if err := json.Unmarshal([]byte(input), &openAPI); err != nil {
+		t.Fatal(err)
+	}
+	if openAPI.Webhooks["newPet"].Post == nil {
+		t.Errorf("missing webhook operation")
+	}
+	if callback := openAPI.Components.Callbacks["onCreated"]; callback.CallbackProps["{$request.body#/callbackUrl}"].Post == nil {
+		t.Errorf("missing callback operation")
+	}
+	if ext := openAPI.Extensions["x-api-id"]; ext != "pets" {
+		t.Errorf("unexpected extension %v", ext)
+	}
+
+	b, err := json.Marshal(&openAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, actual interface{}
+	if err := json.Unmarshal([]byte(input), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("round trip changed the document:\n%s", b)
+	}
+}
+
+func TestOpenAPIMarshalCanonical(t *testing.T) {
+	ext := spec.VendorExtensible{Extensions: spec.Extensions{"x-b": "b", "x-a": "a", "x-c": "c"}}
+	op := spec3.Operation{
+		OperationProps: spec3.OperationProps{
+			OperationId: "listPets",
+			Parameters:  []*spec3.Parameter{{ParameterProps: spec3.ParameterProps{Name: "limit", In: "query"}}},
+			Responses: &spec3.Responses{
+				ResponsesProps: spec3.ResponsesProps{
+					Default:             &spec3.Response{ResponseProps: spec3.ResponseProps{Description: "error"}},
+					StatusCodeResponses: map[int]*spec3.Response{404: {ResponseProps: spec3.ResponseProps{Description: "not found"}}, 200: {ResponseProps: spec3.ResponseProps{Description: "ok"}}},
+				},
+			},
+		},
+		VendorExtensible: ext,
+	}
+	openAPI := spec3.OpenAPI{
+		Version:    "3.0.0",
+		Info:       &spec.Info{InfoProps: spec.InfoProps{Title: "pets", Version: "v1"}},
+		Paths:      &spec3.Paths{Paths: map[string]*spec3.Path{"/pets": {PathProps: spec3.PathProps{Get: &op}}}, VendorExtensible: ext},
+		Components: &spec3.Components{},
+		Extensions: ext.Extensions,
+	}
+	expected := `{"openapi":"3.0.0","info":{"title":"pets","version":"v1"},"paths":{"/pets":{"get":{` +
+		`"operationId":"listPets","parameters":[{"name":"limit","in":"query"}],` +
+		`"responses":{"200":{"description":"ok"},"404":{"description":"not found"},"default":{"description":"error"}},` +
+		`"x-a":"a","x-b":"b","x-c":"c"}},"x-a":"a","x-b":"b","x-c":"c"},"components":{},"x-a":"a","x-b":"b","x-c":"c"}`
+	for i := 0; i < 10; i++ {
+		b, err := json.Marshal(openAPI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected {
+			t.Fatalf("unexpected JSON:\n%s\nexpected:\n%s", b, expected)
+		}
+	}
+
+	// values and pointers marshal the same
+	for _, v := range []interface{}{
+		op,
+		*op.Parameters[0],
+		*op.Responses,
+		*openAPI.Paths,
+		spec3.Server{ServerProps: spec3.ServerProps{URL: "https://example.com"}, VendorExtensible: ext},
+		spec3.Callback{CallbackProps: spec3.CallbackProps{"{$url}": {}}, VendorExtensible: ext},
+		spec3.SecurityRequirement{SecurityRequirementProps: spec3.SecurityRequirementProps{"apiKey": []string{}}},
+	} {
+		b1, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ptr := reflect.New(reflect.TypeOf(v))
+		ptr.Elem().Set(reflect.ValueOf(v))
+		b2, err := json.Marshal(ptr.Interface())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b1) != string(b2) {
+			t.Errorf("%T marshals to %s, its pointer to %s", v, b1, b2)
+		}
+	}
+}
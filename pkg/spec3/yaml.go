@@ -0,0 +1,42 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3
+
+import (
+	"encoding/json"
+
+	"k8s.io/kube-openapi/pkg/internal"
+)
+
+// UnmarshalYAML hydrates the spec from YAML, as from JSON.
+func (o *OpenAPI) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := internal.YAMLToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, o)
+}
+
+// MarshalYAML returns the YAML form of the spec, with the keys of its JSON
+// form.
+func (o OpenAPI) MarshalYAML() (interface{}, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return internal.JSONToYAML(data)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec3_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/kube-openapi/pkg/spec3"
+)
+
+func TestOpenAPIYAML(t *testing.T) {
+	input := `openapi: 3.0.0
+info:
+  title: pets
+  version: v1
+paths:
+  /pets:
+    get:
+      responses:
+        200:
+          description: the pets
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      additionalProperties: false
+`
+	var openAPI spec3.OpenAPI
+	if err := yaml.Unmarshal([]byte(input), &openAPI); err != nil {
+		t.Fatal(err)
+	}
+	response := openAPI.Paths.Paths["/pets"].Get.Responses.StatusCodeResponses[200]
+	if response == nil || response.Description != "the pets" {
+		t.Fatalf("unexpected response %#v", response)
+	}
+	if ref := response.Content["application/json"].Schema.Ref.String(); ref != "#/components/schemas/Pet" {
+		t.Errorf("unexpected ref %q", ref)
+	}
+	if pet := openAPI.Components.Schemas["Pet"]; pet.AdditionalProperties == nil || pet.AdditionalProperties.Allows {
+		t.Errorf("unexpected additionalProperties %#v", pet.AdditionalProperties)
+	}
+
+	out, err := yaml.Marshal(openAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped spec3.OpenAPI
+	if err := yaml.Unmarshal(out, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(openAPI, roundTripped) {
+		t.Errorf("unexpected round trip of:\n%s", out)
+	}
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compat converts between the types of github.com/go-openapi/spec
+// and those of k8s.io/kube-openapi/pkg/validation/spec, to ease migrating
+// from go-openapi/spec and go-openapi/validate.
+//
+// Both share the JSON representation of OpenAPI v2 documents, so conversions
+// go through JSON and accept any type marshaling to it, without depending on
+// go-openapi/spec:
+//
+//	var legacy gospec.Schema
+//	schema, losses, err := compat.ToSchema(&legacy)
+//	...
+//	losses, err = compat.FromSchema(schema, &legacy)
+//
+// Losses list what the target types drop or do not interpret.
+//
+// Validation errors have the same codes as those of go-openapi/errors, but
+// are of the types of k8s.io/kube-openapi/pkg/validation/errors.
+package compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// Loss is a part of a document which a conversion does not carry over faithfully.
+type Loss struct {
+	// Path is the JSON pointer to the value in the converted document.
+	Path string
+	// Value is the value in the converted document.
+	Value interface{}
+	// Uninterpreted is set if the value is kept, but not understood by the
+	// target types, e.g. the xml keyword of schemas, which are ignored
+	// by validation. Otherwise the value is dropped or changed.
+	Uninterpreted bool
+}
+
+func (l Loss) String() string {
+	if l.Uninterpreted {
+		return fmt.Sprintf("%s is not interpreted", l.Path)
+	}
+	return fmt.Sprintf("%s is dropped", l.Path)
+}
+
+// ToSchema converts in, e.g. a *github.com/go-openapi/spec.Schema, to a schema.
+func ToSchema(in interface{}) (*spec.Schema, []Loss, error) {
+	s := &spec.Schema{}
+	losses, err := convert(in, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	walkSchema("", s, func(path string, s *spec.Schema) {
+		losses = append(losses, extraPropsLosses(path, s.ExtraProps)...)
+	})
+	return s, sortLosses(losses), nil
+}
+
+// FromSchema converts s to out, e.g. a *github.com/go-openapi/spec.Schema.
+// The nullable keyword is reported as uninterpreted, as go-openapi
+// does not support it.
+func FromSchema(s *spec.Schema, out interface{}) ([]Loss, error) {
+	losses, err := convert(s, out)
+	if err != nil {
+		return nil, err
+	}
+	walkSchema("", s, nullableLosses(&losses))
+	return sortLosses(losses), nil
+}
+
+// ToSwagger converts in, e.g. a *github.com/go-openapi/spec.Swagger, to a swagger document.
+func ToSwagger(in interface{}) (*spec.Swagger, []Loss, error) {
+	sp := &spec.Swagger{}
+	losses, err := convert(in, sp)
+	if err != nil {
+		return nil, nil, err
+	}
+	walkSwagger(sp, func(path string, s *spec.Schema) {
+		losses = append(losses, extraPropsLosses(path, s.ExtraProps)...)
+	})
+	return sp, sortLosses(losses), nil
+}
+
+// FromSwagger converts sp to out, e.g. a *github.com/go-openapi/spec.Swagger,
+// reporting losses as FromSchema.
+func FromSwagger(sp *spec.Swagger, out interface{}) ([]Loss, error) {
+	losses, err := convert(sp, out)
+	if err != nil {
+		return nil, err
+	}
+	walkSwagger(sp, nullableLosses(&losses))
+	return sortLosses(losses), nil
+}
+
+// AgainstSchema validates data against schema, e.g. a *github.com/go-openapi/spec.Schema,
+// as validate.AgainstSchema. Losses of the conversion are ignored, use ToSchema
+// to check them first.
+func AgainstSchema(schema interface{}, data interface{}, formats strfmt.Registry, options ...validate.Option) error {
+	s, _, err := ToSchema(schema)
+	if err != nil {
+		return err
+	}
+	return validate.AgainstSchema(s, data, formats, options...)
+}
+
+// convert converts in to out through JSON, and returns the values of in
+// which do not survive the round trip through out.
+func convert(in, out interface{}) ([]Loss, error) {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %v", in, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, fmt.Errorf("failed to convert %T to %T: %v", in, out, err)
+	}
+	back, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %v", out, err)
+	}
+
+	var before, after interface{}
+	if err := json.Unmarshal(data, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(back, &after); err != nil {
+		return nil, err
+	}
+	var losses []Loss
+	diff("", before, after, &losses)
+	return losses, nil
+}
+
+// diff appends the values of before missing from or different in after.
+func diff(path string, before, after interface{}, losses *[]Loss) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			for k, v := range b {
+				diff(path+"/"+common.EscapeJsonPointer(k), v, a[k], losses)
+			}
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok && len(a) == len(b) {
+			for i := range b {
+				diff(path+"/"+strconv.Itoa(i), b[i], a[i], losses)
+			}
+			return
+		}
+	default:
+		if before == after {
+			return
+		}
+	}
+	*losses = append(*losses, Loss{Path: path, Value: before})
+}
+
+// nullableLosses returns a walk function appending the nullable keywords
+// to losses, unless they are dropped already.
+func nullableLosses(losses *[]Loss) func(path string, s *spec.Schema) {
+	dropped := map[string]bool{}
+	for _, l := range *losses {
+		dropped[l.Path] = true
+	}
+	return func(path string, s *spec.Schema) {
+		if s.Nullable && !dropped[path+"/nullable"] {
+			*losses = append(*losses, Loss{Path: path + "/nullable", Value: true, Uninterpreted: true})
+		}
+	}
+}
+
+func extraPropsLosses(path string, props map[string]interface{}) []Loss {
+	var losses []Loss
+	for k, v := range props {
+		losses = append(losses, Loss{Path: path + "/" + common.EscapeJsonPointer(k), Value: v, Uninterpreted: true})
+	}
+	return losses
+}
+
+func sortLosses(losses []Loss) []Loss {
+	sort.Slice(losses, func(i, j int) bool { return losses[i].Path < losses[j].Path })
+	return losses
+}
+
+// walkSwagger calls fn for the schemas of sp, with their JSON pointers.
+func walkSwagger(sp *spec.Swagger, fn func(path string, s *spec.Schema)) {
+	for name, def := range sp.Definitions {
+		def := def
+		walkSchema("/definitions/"+common.EscapeJsonPointer(name), &def, fn)
+	}
+	for name, p := range sp.Parameters {
+		walkSchema("/parameters/"+common.EscapeJsonPointer(name)+"/schema", p.Schema, fn)
+	}
+	for name, r := range sp.Responses {
+		walkSchema("/responses/"+common.EscapeJsonPointer(name)+"/schema", r.Schema, fn)
+	}
+	if sp.Paths == nil {
+		return
+	}
+	for path, item := range sp.Paths.Paths {
+		itemPath := "/paths/" + common.EscapeJsonPointer(path)
+		for i := range item.Parameters {
+			walkSchema(itemPath+"/parameters/"+strconv.Itoa(i)+"/schema", item.Parameters[i].Schema, fn)
+		}
+		for method, op := range map[string]*spec.Operation{
+			"get": item.Get, "put": item.Put, "post": item.Post, "delete": item.Delete,
+			"options": item.Options, "head": item.Head, "patch": item.Patch,
+		} {
+			if op == nil {
+				continue
+			}
+			opPath := itemPath + "/" + method
+			for i := range op.Parameters {
+				walkSchema(opPath+"/parameters/"+strconv.Itoa(i)+"/schema", op.Parameters[i].Schema, fn)
+			}
+			if op.Responses == nil {
+				continue
+			}
+			if op.Responses.Default != nil {
+				walkSchema(opPath+"/responses/default/schema", op.Responses.Default.Schema, fn)
+			}
+			for code, r := range op.Responses.StatusCodeResponses {
+				walkSchema(opPath+"/responses/"+strconv.Itoa(code)+"/schema", r.Schema, fn)
+			}
+		}
+	}
+}
+
+// walkSchema calls fn for s and its nested schemas, with their JSON pointers.
+func walkSchema(path string, s *spec.Schema, fn func(path string, s *spec.Schema)) {
+	if s == nil {
+		return
+	}
+	fn(path, s)
+	for keyword, m := range map[string]map[string]spec.Schema{
+		"properties": s.Properties, "patternProperties": s.PatternProperties, "dependentSchemas": s.DependentSchemas,
+		"definitions": s.Definitions,
+	} {
+		for name, v := range m {
+			v := v
+			walkSchema(path+"/"+keyword+"/"+common.EscapeJsonPointer(name), &v, fn)
+		}
+	}
+	for keyword, l := range map[string][]spec.Schema{"allOf": s.AllOf, "anyOf": s.AnyOf, "oneOf": s.OneOf, "prefixItems": s.PrefixItems} {
+		for i := range l {
+			walkSchema(path+"/"+keyword+"/"+strconv.Itoa(i), &l[i], fn)
+		}
+	}
+	walkSchema(path+"/not", s.Not, fn)
+	walkSchema(path+"/contentSchema", s.ContentSchema, fn)
+	walkSchema(path+"/if", s.If, fn)
+	walkSchema(path+"/then", s.Then, fn)
+	walkSchema(path+"/else", s.Else, fn)
+	if s.Items != nil {
+		walkSchema(path+"/items", s.Items.Schema, fn)
+		for i := range s.Items.Schemas {
+			walkSchema(path+"/items/"+strconv.Itoa(i), &s.Items.Schemas[i], fn)
+		}
+	}
+	if s.AdditionalProperties != nil {
+		walkSchema(path+"/additionalProperties", s.AdditionalProperties.Schema, fn)
+	}
+	if s.AdditionalItems != nil {
+		walkSchema(path+"/additionalItems", s.AdditionalItems.Schema, fn)
+	}
+	if s.UnevaluatedProperties != nil {
+		walkSchema(path+"/unevaluatedProperties", s.UnevaluatedProperties.Schema, fn)
+	}
+	if s.UnevaluatedItems != nil {
+		walkSchema(path+"/unevaluatedItems", s.UnevaluatedItems.Schema, fn)
+	}
+	for name, d := range s.Dependencies {
+		walkSchema(path+"/dependencies/"+common.EscapeJsonPointer(name), d.Schema, fn)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// legacySchema stands for a schema type of another library, which only
+// supports some keywords.
+type legacySchema struct {
+	Type       string                  `json:"type,omitempty"`
+	MaxLength  *int64                  `json:"maxLength,omitempty"`
+	Properties map[string]legacySchema `json:"properties,omitempty"`
+}
+
+func TestToSchema(t *testing.T) {
+	in := json.RawMessage(`{
+		"type": "object",
+		"xml": {"name": "pod"},
+		"properties": {
+			"name": {"type": "string", "maxLength": 10, "x-kubernetes-foo": true},
+			"ports": {"type": "array", "items": {"type": "integer", "xml": {"wrapped": true}}}
+		}
+	}`)
+
+	s, losses, err := ToSchema(in)
+	require.NoError(t, err)
+	assert.Equal(t, spec.StringOrArray{"object"}, s.Type)
+	assert.Equal(t, int64(10), *s.Properties["name"].MaxLength)
+	assert.Equal(t, true, s.Properties["name"].Extensions["x-kubernetes-foo"])
+	assert.Equal(t, []Loss{
+		{Path: "/properties/ports/items/xml", Value: map[string]interface{}{"wrapped": true}, Uninterpreted: true},
+		{Path: "/xml", Value: map[string]interface{}{"name": "pod"}, Uninterpreted: true},
+	}, losses)
+	assert.Equal(t, "/xml is not interpreted", losses[1].String())
+
+	_, _, err = ToSchema(json.RawMessage(`[]`))
+	assert.Error(t, err)
+}
+
+func TestFromSchema(t *testing.T) {
+	s := &spec.Schema{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "maxLength": 10, "pattern": "^[a-z]+$", "nullable": true}
+		}
+	}`), s))
+
+	var out legacySchema
+	losses, err := FromSchema(s, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "object", out.Type)
+	assert.Equal(t, int64(10), *out.Properties["name"].MaxLength)
+	assert.Equal(t, []Loss{
+		{Path: "/properties/name/nullable", Value: true},
+		{Path: "/properties/name/pattern", Value: "^[a-z]+$"},
+	}, losses)
+	assert.Equal(t, "/properties/name/pattern is dropped", losses[1].String())
+
+	// converting back is lossless
+	back, losses, err := ToSchema(&out)
+	require.NoError(t, err)
+	assert.Empty(t, losses)
+	assert.Equal(t, int64(10), *back.Properties["name"].MaxLength)
+}
+
+func TestSwagger(t *testing.T) {
+	in := json.RawMessage(`{
+		"swagger": "2.0",
+		"info": {"title": "test", "version": "v1"},
+		"paths": {
+			"/pods": {"post": {
+				"parameters": [{"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Pod", "xml": {"name": "pod"}}}],
+				"responses": {"200": {"description": "OK", "schema": {"type": "string", "nullable": true}}}
+			}}
+		},
+		"definitions": {
+			"Pod": {"type": "object", "properties": {"name": {"type": "string", "xml": {"attribute": true}}}}
+		}
+	}`)
+
+	sp, losses, err := ToSwagger(in)
+	require.NoError(t, err)
+	assert.Contains(t, sp.Definitions, "Pod")
+	var paths []string
+	for _, l := range losses {
+		assert.True(t, l.Uninterpreted)
+		paths = append(paths, l.Path)
+	}
+	assert.Equal(t, []string{"/definitions/Pod/properties/name/xml", "/paths/~1pods/post/parameters/0/schema/xml"}, paths)
+
+	var out map[string]interface{}
+	losses, err = FromSwagger(sp, &out)
+	require.NoError(t, err)
+	assert.Equal(t, []Loss{{Path: "/paths/~1pods/post/responses/200/schema/nullable", Value: true, Uninterpreted: true}}, losses)
+	assert.Equal(t, "2.0", out["swagger"])
+}
+
+func TestAgainstSchema(t *testing.T) {
+	schema := legacySchema{Type: "object", Properties: map[string]legacySchema{"name": {Type: "string"}}}
+
+	assert.NoError(t, AgainstSchema(&schema, map[string]interface{}{"name": "a"}, strfmt.Default))
+
+	err := AgainstSchema(&schema, map[string]interface{}{"name": 1}, strfmt.Default)
+	require.Error(t, err)
+	composite, ok := err.(*errors.CompositeError)
+	require.True(t, ok)
+	require.Len(t, composite.Errors, 1)
+	assert.EqualValues(t, errors.InvalidTypeCode, composite.Errors[0].(*errors.Validation).Code())
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaulting applies the defaults of schemas to unstructured objects,
+// i.e. trees of map[string]interface{}, []interface{} and JSON values, as done
+// for custom resources before they are validated.
+package defaulting
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/internal"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Default sets the defaults of schema in obj, in place, and returns the
+// sorted paths of the defaulted values, named as in validation errors,
+// e.g. "spec.ports.0.protocol".
+//
+// A property is defaulted if it is missing, or null while its schema is not
+// nullable. Defaults are applied in properties, items and additionalProperties,
+// and in defaulted values. The schema is expected to be expanded and
+// structural: $refs and allOf, anyOf, oneOf and not are ignored.
+func Default(obj interface{}, schema *spec.Schema) []string {
+	var paths []string
+	walk(obj, schema, "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func walk(obj interface{}, s *spec.Schema, path string, paths *[]string) {
+	if s == nil {
+		return
+	}
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for name, prop := range s.Properties {
+			prop := prop
+			propPath := internal.JoinPath(path, name)
+			if value, found := v[name]; prop.Default != nil && (!found || value == nil && !prop.Nullable) {
+				v[name] = copyJSON(prop.Default)
+				*paths = append(*paths, propPath)
+			}
+			if value, found := v[name]; found {
+				walk(value, &prop, propPath, paths)
+			}
+		}
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			for name, value := range v {
+				if _, declared := s.Properties[name]; !declared {
+					walk(value, s.AdditionalProperties.Schema, internal.JoinPath(path, name), paths)
+				}
+			}
+		}
+	case []interface{}:
+		if s.Items == nil || s.Items.Schema == nil {
+			return
+		}
+		for i, item := range v {
+			itemPath := internal.JoinPath(path, strconv.Itoa(i))
+			if item == nil && s.Items.Schema.Default != nil && !s.Items.Schema.Nullable {
+				v[i] = copyJSON(s.Items.Schema.Default)
+				*paths = append(*paths, itemPath)
+			}
+			walk(v[i], s.Items.Schema, itemPath, paths)
+		}
+	}
+}
+
+// copyJSON deep copies a JSON value, so that defaulted objects do not share
+// the default of the schema.
+func copyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			ret[k] = copyJSON(e)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, e := range v {
+			ret[i] = copyJSON(e)
+		}
+		return ret
+	case string, bool, float64, int64, json.Number, nil:
+		return v
+	}
+	// e.g. a default set from Go, convert it to a JSON value
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var ret interface{}
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return v
+	}
+	return ret
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaulting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const schemaJSON = `{
+	"type": "object",
+	"properties": {
+		"spec": {
+			"type": "object",
+			"properties": {
+				"replicas": {"type": "integer", "default": 1},
+				"paused": {"type": "boolean", "default": false, "nullable": true},
+				"strategy": {
+					"type": "object",
+					"default": {},
+					"properties": {"type": {"type": "string", "default": "RollingUpdate"}}
+				},
+				"ports": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"default": {"port": 80},
+						"properties": {
+							"port": {"type": "integer"},
+							"protocol": {"type": "string", "default": "TCP"}
+						}
+					}
+				},
+				"selectors": {
+					"type": "object",
+					"additionalProperties": {
+						"type": "object",
+						"properties": {"operator": {"type": "string", "default": "In"}}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestDefault(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), schema))
+
+	var obj interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"spec": {
+			"replicas": null,
+			"paused": null,
+			"ports": [{"port": 443}, null, {"port": 53, "protocol": "UDP"}],
+			"selectors": {"app": {}, "tier": {"operator": "NotIn"}}
+		}
+	}`), &obj))
+
+	paths := Default(obj, schema)
+	assert.Equal(t, []string{
+		"spec.ports.0.protocol",
+		"spec.ports.1",
+		"spec.ports.1.protocol",
+		"spec.replicas",
+		"spec.selectors.app.operator",
+		"spec.strategy",
+		"spec.strategy.type",
+	}, paths)
+
+	var expected interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"spec": {
+			"replicas": 1,
+			"paused": null,
+			"strategy": {"type": "RollingUpdate"},
+			"ports": [{"port": 443, "protocol": "TCP"}, {"port": 80, "protocol": "TCP"}, {"port": 53, "protocol": "UDP"}],
+			"selectors": {"app": {"operator": "In"}, "tier": {"operator": "NotIn"}}
+		}
+	}`), &expected))
+	assert.Equal(t, expected, obj)
+
+	// defaults are copied
+	assert.Equal(t, map[string]interface{}{"port": float64(80)}, schema.Properties["spec"].Properties["ports"].Items.Schema.Default)
+	assert.Empty(t, schema.Properties["spec"].Properties["strategy"].Default)
+
+	// defaulting is idempotent
+	assert.Empty(t, Default(obj, schema))
+}
+
+func TestDefaultGoValues(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{
+		"labels": {SchemaProps: spec.SchemaProps{Default: map[string]string{"app": "x"}}},
+	}}}
+	obj := map[string]interface{}{}
+	assert.Equal(t, []string{"labels"}, Default(obj, schema))
+	assert.Equal(t, map[string]interface{}{"labels": map[string]interface{}{"app": "x"}}, obj)
+
+	// other values are left alone
+	assert.Empty(t, Default("a", schema))
+	assert.Empty(t, Default(obj, nil))
+}
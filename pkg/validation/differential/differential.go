@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package differential runs the same schemas and objects through two
+// validator configurations, e.g. with and without a new keyword, and reports
+// where their outcomes diverge, to roll out validation changes safely.
+package differential
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// Validator is a validator configuration.
+type Validator struct {
+	// Name identifies the configuration in reports.
+	Name string
+	// Validate validates obj against schema. It should return early once ctx is done.
+	Validate func(ctx context.Context, schema *spec.Schema, obj interface{}) *validate.Result
+}
+
+// SchemaValidator validates with a validate.SchemaValidator created with options.
+func SchemaValidator(name string, formats strfmt.Registry, options ...validate.Option) Validator {
+	return Validator{
+		Name: name,
+		Validate: func(ctx context.Context, schema *spec.Schema, obj interface{}) *validate.Result {
+			return validate.NewSchemaValidator(schema, nil, "", formats, options...).ValidateWithContext(ctx, obj)
+		},
+	}
+}
+
+// Case is a schema and an object to validate against it.
+type Case struct {
+	Name   string
+	Schema *spec.Schema
+	Object interface{}
+}
+
+// Options configures a comparison.
+type Options struct {
+	// CompareMessages reports cases with the same validity, but different
+	// error messages. Otherwise only validity is compared.
+	CompareMessages bool
+	// CompareWarnings reports cases with different warnings.
+	CompareWarnings bool
+}
+
+// Outcome is the outcome of the validation of a case.
+type Outcome struct {
+	Valid bool
+	// Errors and Warnings are the sorted messages of the result.
+	Errors   []string
+	Warnings []string
+	// Panic is the value recovered from a panicking validator, formatted.
+	// Panicking validators are invalid.
+	Panic string
+}
+
+// Divergence is a case whose outcomes differ.
+type Divergence struct {
+	Case     string
+	Old, New Outcome
+}
+
+// Report is the outcome of a comparison.
+type Report struct {
+	// Old and New are the names of the compared validators.
+	Old, New string
+	// Cases is the number of cases compared.
+	Cases int
+	// Divergences are the cases whose outcomes differ, in order.
+	Divergences []Divergence
+}
+
+// Compare validates every case with old and new, and reports the cases whose
+// outcomes differ, until all cases are compared or ctx is done.
+func Compare(ctx context.Context, old, new Validator, cases []Case, opts Options) *Report {
+	report := &Report{Old: old.Name, New: new.Name}
+	for _, c := range cases {
+		if ctx.Err() != nil {
+			break
+		}
+		o := run(ctx, old, c)
+		n := run(ctx, new, c)
+		if ctx.Err() != nil {
+			// outcomes of interrupted validations are not comparable
+			break
+		}
+		report.Cases++
+		if o.diverges(n, opts) {
+			report.Divergences = append(report.Divergences, Divergence{Case: c.Name, Old: o, New: n})
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, v Validator, c Case) (outcome Outcome) {
+	defer func() {
+		if r := recover(); r != nil {
+			outcome = Outcome{Panic: fmt.Sprint(r)}
+		}
+	}()
+	res := v.Validate(ctx, c.Schema, c.Object)
+	if res == nil {
+		return Outcome{Valid: true}
+	}
+	return Outcome{
+		Valid:    res.IsValid(),
+		Errors:   messages(res.Errors),
+		Warnings: messages(res.Warnings),
+	}
+}
+
+func messages(errs []error) []string {
+	if len(errs) == 0 {
+		return nil
+	}
+	ret := make([]string, len(errs))
+	for i, err := range errs {
+		ret[i] = err.Error()
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (o Outcome) diverges(other Outcome, opts Options) bool {
+	switch {
+	case o.Valid != other.Valid, o.Panic != other.Panic:
+		return true
+	case opts.CompareMessages && !equal(o.Errors, other.Errors):
+		return true
+	case opts.CompareWarnings && !equal(o.Warnings, other.Warnings):
+		return true
+	}
+	return false
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
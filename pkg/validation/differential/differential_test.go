@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package differential
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/fixtures"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+func testCases() []Case {
+	var cases []Case
+	for _, f := range fixtures.All() {
+		cases = append(cases, Case{Name: f.Name, Schema: f.Schema, Object: f.Object})
+	}
+	closed := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:                 spec.StringOrArray{"object"},
+		Properties:           map[string]spec.Schema{"replicas": *spec.Int32Property()},
+		AdditionalProperties: &spec.SchemaOrBool{Allows: false},
+	}}
+	return append(cases,
+		Case{Name: "typo", Schema: closed, Object: map[string]interface{}{"replics": 1}},
+		Case{Name: "huge", Schema: closed, Object: map[string]interface{}{"replicas": 1000}},
+	)
+}
+
+func TestCompare(t *testing.T) {
+	old := SchemaValidator("default", strfmt.Default)
+	suggestions := SchemaValidator("suggestions", strfmt.Default, validate.EnablePropertySuggestions())
+
+	report := Compare(context.Background(), old, suggestions, testCases(), Options{})
+	assert.Equal(t, len(testCases()), report.Cases)
+	assert.Empty(t, report.Divergences)
+
+	report = Compare(context.Background(), old, suggestions, testCases(), Options{CompareMessages: true})
+	assert.Equal(t, "default", report.Old)
+	assert.Equal(t, "suggestions", report.New)
+	assert.Equal(t, []Divergence{{
+		Case: "typo",
+		Old:  Outcome{Errors: []string{".replics in body is a forbidden property"}},
+		New:  Outcome{Errors: []string{`.replics in body is a forbidden property, did you mean "replicas"?`}},
+	}}, report.Divergences)
+}
+
+func TestCompareValidity(t *testing.T) {
+	old := SchemaValidator("default", strfmt.Default)
+	// a stricter validator, limiting replicas
+	strict := Validator{
+		Name: "strict",
+		Validate: func(ctx context.Context, schema *spec.Schema, obj interface{}) *validate.Result {
+			res := old.Validate(ctx, schema, obj)
+			if replicas, ok := obj.(map[string]interface{})["replicas"].(int); ok && replicas > 100 {
+				res.AddErrors(errors.New(errors.MaxFailCode, "replicas should be less than or equal to 100"))
+			}
+			return res
+		},
+	}
+	panicking := Validator{
+		Name: "panicking",
+		Validate: func(ctx context.Context, schema *spec.Schema, obj interface{}) *validate.Result {
+			panic("boom")
+		},
+	}
+
+	report := Compare(context.Background(), old, strict, testCases(), Options{})
+	if assert.Len(t, report.Divergences, 1) {
+		d := report.Divergences[0]
+		assert.Equal(t, "huge", d.Case)
+		assert.True(t, d.Old.Valid)
+		assert.Equal(t, Outcome{Errors: []string{"replicas should be less than or equal to 100"}}, d.New)
+	}
+
+	report = Compare(context.Background(), old, panicking, testCases(), Options{})
+	assert.Len(t, report.Divergences, len(testCases()))
+	assert.Equal(t, Outcome{Panic: "boom"}, report.Divergences[0].New)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report = Compare(ctx, old, strict, testCases(), Options{})
+	assert.Zero(t, report.Cases)
+}
+
+func TestCompareWarnings(t *testing.T) {
+	old := SchemaValidator("default", strfmt.Default)
+	warnings := SchemaValidator("warnings", strfmt.Default, validate.EnableWarnings())
+	cases := []Case{{
+		Name:   "unknown field",
+		Schema: &spec.Schema{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{"a": *spec.StringProperty()}}},
+		Object: map[string]interface{}{"b": "c"},
+	}}
+
+	assert.Empty(t, Compare(context.Background(), old, warnings, cases, Options{CompareMessages: true}).Divergences)
+	report := Compare(context.Background(), old, warnings, cases, Options{CompareWarnings: true})
+	if assert.Len(t, report.Divergences, 1) {
+		assert.Equal(t, []string{".b in body is an unknown field"}, report.Divergences[0].New.Warnings)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Params are the named parameters of the message of a validation error.
+type Params map[string]interface{}
+
+// MessageCatalog rewrites the messages of validation errors, e.g. to
+// translate them, from their code, template and parameters.
+type MessageCatalog interface {
+	// Message returns the message of err, or false to keep its message.
+	Message(err *Validation) (string, bool)
+}
+
+// MessageCatalogFunc is a function implementing MessageCatalog.
+type MessageCatalogFunc func(err *Validation) (string, bool)
+
+// Message implements MessageCatalog.
+func (f MessageCatalogFunc) Message(err *Validation) (string, bool) {
+	return f(err)
+}
+
+// TemplateCatalog is a MessageCatalog mapping the English templates of
+// messages to other templates, e.g. translations, with the same placeholders:
+//
+//	errors.TemplateCatalog{
+//	    "{name} in {in} is required": "{name} in {in} ist erforderlich",
+//	}
+type TemplateCatalog map[string]string
+
+// Message implements MessageCatalog.
+func (c TemplateCatalog) Message(err *Validation) (string, bool) {
+	template, ok := c[err.Template]
+	if !ok {
+		return "", false
+	}
+	return FormatTemplate(template, err.Params), true
+}
+
+// FormatTemplate replaces the placeholders of template by params: "{key}"
+// by the value of key, as formatted by fmt.Sprint, and "{key:q}" by the
+// value quoted, as formatted by %q. Unknown placeholders are kept.
+func FormatTemplate(template string, params Params) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		key, quote := template[start+1:end], false
+		if strings.HasSuffix(key, ":q") {
+			key, quote = strings.TrimSuffix(key, ":q"), true
+		}
+		b.WriteString(template[:start])
+		if v, ok := params[key]; !ok {
+			b.WriteString(template[start : end+1])
+		} else if quote {
+			fmt.Fprintf(&b, "%q", v)
+		} else {
+			fmt.Fprint(&b, v)
+		}
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+// Localize returns err with its message rewritten by catalog, if it is a
+// validation error or a composite error of them. err is not modified.
+func Localize(err error, catalog MessageCatalog) error {
+	switch e := err.(type) {
+	case *Validation:
+		msg, ok := catalog.Message(e)
+		if !ok {
+			return e
+		}
+		localized := *e
+		localized.message = msg
+		return &localized
+	case *CompositeError:
+		localized := *e
+		localized.Errors = make([]error, 0, len(e.Errors))
+		for _, nested := range e.Errors {
+			localized.Errors = append(localized.Errors, Localize(nested, catalog))
+		}
+		return &localized
+	}
+	return err
+}
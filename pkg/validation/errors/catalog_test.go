@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	params := Params{"name": "spec.name", "values": []interface{}{"a", "b"}, "value": `say "hi"`}
+	assert.Equal(t, "spec.name should be one of [a b]", FormatTemplate("{name} should be one of {values}", params))
+	assert.Equal(t, `spec.name is "say \"hi\""`, FormatTemplate("{name} is {value:q}", params))
+	assert.Equal(t, "spec.name is {unknown} {", FormatTemplate("{name} is {unknown} {", params))
+}
+
+func TestTemplateAndParams(t *testing.T) {
+	err := TooLong("spec.name", "body", 5, "abcdef")
+	assert.Equal(t, tooLongMessage, err.Template)
+	assert.Equal(t, Params{"name": "spec.name", "in": "body", "max": int64(5)}, err.Params)
+
+	err = TooLong("spec.name", "", 5, "abcdef")
+	assert.Equal(t, tooLongMessageNoIn, err.Template)
+	assert.Equal(t, Params{"name": "spec.name", "max": int64(5)}, err.Params)
+
+	err = Required("", "body").ValidateName("spec.name")
+	assert.Equal(t, "spec.name in body is required", err.Error())
+	assert.Equal(t, "spec.name", err.Params["name"])
+}
+
+func TestLocalize(t *testing.T) {
+	catalog := TemplateCatalog{requiredFail: "{name} ({in}) est obligatoire"}
+
+	err := Required("spec.name", "body")
+	localized := Localize(err, catalog)
+	assert.Equal(t, "spec.name (body) est obligatoire", localized.Error())
+	assert.Equal(t, "spec.name in body is required", err.Error())
+	assert.EqualValues(t, RequiredFailCode, localized.(*Validation).Code())
+
+	// messages missing from the catalog are kept
+	tooLong := TooLong("spec.name", "body", 5, "abcdef")
+	assert.Same(t, tooLong, Localize(tooLong, catalog))
+
+	composite := CompositeValidationError(err, tooLong)
+	assert.Equal(t, "validation failure list:\nspec.name (body) est obligatoire\nspec.name in body should be at most 5 chars long",
+		Localize(composite, catalog).Error())
+
+	codes := MessageCatalogFunc(func(err *Validation) (string, bool) {
+		return fmt.Sprintf("%s: error %d", err.Params["name"], err.Code()), true
+	})
+	assert.Equal(t, "spec.name: error 602", Localize(err, codes).Error())
+}
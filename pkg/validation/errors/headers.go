@@ -22,6 +22,16 @@ type Validation struct {
 	Value   interface{}
 	message string
 	Values  []interface{}
+	// Template is the English template of the message, whose placeholders,
+	// e.g. "{name}", are replaced by Params. Templates are stable, so that
+	// they identify messages, e.g. to translate them, see MessageCatalog.
+	Template string
+	// Params are the parameters of the message, named after the
+	// placeholders of Template, e.g. "name", "in" or "max".
+	Params Params
+	// Pointer is the RFC 6901 JSON pointer to the invalid value, when the
+	// validator is asked to compute it. Name is the dotted path.
+	Pointer string
 }
 
 func (e *Validation) Error() string {
@@ -38,6 +48,9 @@ func (e *Validation) ValidateName(name string) *Validation {
 	if e.Name == "" && name != "" {
 		e.Name = name
 		e.message = name + e.message
+		if e.Params != nil {
+			e.Params["name"] = name
+		}
 	}
 	return e
 }
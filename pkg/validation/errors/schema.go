@@ -15,55 +15,89 @@
 package errors
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
 )
 
 const (
-	invalidType               = "%s is an invalid type name"
-	typeFail                  = "%s in %s must be of type %s"
-	typeFailWithData          = "%s in %s must be of type %s: %q"
-	typeFailWithError         = "%s in %s must be of type %s, because: %s"
-	requiredFail              = "%s in %s is required"
-	tooLongMessage            = "%s in %s should be at most %d chars long"
-	tooShortMessage           = "%s in %s should be at least %d chars long"
-	patternFail               = "%s in %s should match '%s'"
-	enumFail                  = "%s in %s should be one of %v"
-	multipleOfFail            = "%s in %s should be a multiple of %v"
-	maxIncFail                = "%s in %s should be less than or equal to %v"
-	maxExcFail                = "%s in %s should be less than %v"
-	minIncFail                = "%s in %s should be greater than or equal to %v"
-	minExcFail                = "%s in %s should be greater than %v"
-	uniqueFail                = "%s in %s shouldn't contain duplicates"
-	maxItemsFail              = "%s in %s should have at most %d items"
-	minItemsFail              = "%s in %s should have at least %d items"
-	typeFailNoIn              = "%s must be of type %s"
-	typeFailWithDataNoIn      = "%s must be of type %s: %q"
-	typeFailWithErrorNoIn     = "%s must be of type %s, because: %s"
-	requiredFailNoIn          = "%s is required"
-	tooLongMessageNoIn        = "%s should be at most %d chars long"
-	tooShortMessageNoIn       = "%s should be at least %d chars long"
-	patternFailNoIn           = "%s should match '%s'"
-	enumFailNoIn              = "%s should be one of %v"
-	multipleOfFailNoIn        = "%s should be a multiple of %v"
-	maxIncFailNoIn            = "%s should be less than or equal to %v"
-	maxExcFailNoIn            = "%s should be less than %v"
-	minIncFailNoIn            = "%s should be greater than or equal to %v"
-	minExcFailNoIn            = "%s should be greater than %v"
-	uniqueFailNoIn            = "%s shouldn't contain duplicates"
-	maxItemsFailNoIn          = "%s should have at most %d items"
-	minItemsFailNoIn          = "%s should have at least %d items"
-	noAdditionalItems         = "%s in %s can't have additional items"
-	noAdditionalItemsNoIn     = "%s can't have additional items"
-	tooFewProperties          = "%s in %s should have at least %d properties"
-	tooFewPropertiesNoIn      = "%s should have at least %d properties"
-	tooManyProperties         = "%s in %s should have at most %d properties"
-	tooManyPropertiesNoIn     = "%s should have at most %d properties"
-	unallowedProperty         = "%s.%s in %s is a forbidden property"
-	unallowedPropertyNoIn     = "%s.%s is a forbidden property"
-	failedAllPatternProps     = "%s.%s in %s failed all pattern properties"
-	failedAllPatternPropsNoIn = "%s.%s failed all pattern properties"
-	multipleOfMustBePositive  = "factor MultipleOf declared for %s must be positive: %v"
+	invalidType               = "{type} is an invalid type name"
+	typeFail                  = "{name} in {in} must be of type {type}"
+	typeFailWithData          = "{name} in {in} must be of type {type}: {value:q}"
+	typeFailWithError         = "{name} in {in} must be of type {type}, because: {value}"
+	requiredFail              = "{name} in {in} is required"
+	tooLongMessage            = "{name} in {in} should be at most {max} chars long"
+	tooShortMessage           = "{name} in {in} should be at least {min} chars long"
+	patternFail               = "{name} in {in} should match '{pattern}'"
+	enumFail                  = "{name} in {in} should be one of {values}"
+	multipleOfFail            = "{name} in {in} should be a multiple of {multiple}"
+	maxIncFail                = "{name} in {in} should be less than or equal to {max}"
+	maxExcFail                = "{name} in {in} should be less than {max}"
+	minIncFail                = "{name} in {in} should be greater than or equal to {min}"
+	minExcFail                = "{name} in {in} should be greater than {min}"
+	uniqueFail                = "{name} in {in} shouldn't contain duplicates"
+	maxItemsFail              = "{name} in {in} should have at most {max} items"
+	minItemsFail              = "{name} in {in} should have at least {min} items"
+	typeFailNoIn              = "{name} must be of type {type}"
+	typeFailWithDataNoIn      = "{name} must be of type {type}: {value:q}"
+	typeFailWithErrorNoIn     = "{name} must be of type {type}, because: {value}"
+	requiredFailNoIn          = "{name} is required"
+	tooLongMessageNoIn        = "{name} should be at most {max} chars long"
+	tooShortMessageNoIn       = "{name} should be at least {min} chars long"
+	patternFailNoIn           = "{name} should match '{pattern}'"
+	enumFailNoIn              = "{name} should be one of {values}"
+	multipleOfFailNoIn        = "{name} should be a multiple of {multiple}"
+	maxIncFailNoIn            = "{name} should be less than or equal to {max}"
+	maxExcFailNoIn            = "{name} should be less than {max}"
+	minIncFailNoIn            = "{name} should be greater than or equal to {min}"
+	minExcFailNoIn            = "{name} should be greater than {min}"
+	uniqueFailNoIn            = "{name} shouldn't contain duplicates"
+	maxItemsFailNoIn          = "{name} should have at most {max} items"
+	minItemsFailNoIn          = "{name} should have at least {min} items"
+	noAdditionalItems         = "{name} in {in} can't have additional items"
+	noAdditionalItemsNoIn     = "{name} can't have additional items"
+	tooFewProperties          = "{name} in {in} should have at least {min} properties"
+	tooFewPropertiesNoIn      = "{name} should have at least {min} properties"
+	tooManyProperties         = "{name} in {in} should have at most {max} properties"
+	tooManyPropertiesNoIn     = "{name} should have at most {max} properties"
+	unallowedProperty         = "{name}.{key} in {in} is a forbidden property"
+	unallowedPropertyNoIn     = "{name}.{key} is a forbidden property"
+	failedAllPatternProps     = "{name}.{key} in {in} failed all pattern properties"
+	failedAllPatternPropsNoIn = "{name}.{key} failed all pattern properties"
+	multipleOfMustBePositive  = "factor MultipleOf declared for {name} must be positive: {factor}"
+	invalidCollectionFormat   = "the collection format {format:q} is not supported for the {in} param {name:q}"
+)
+
+const (
+	unallowedPropertySuggest     = "{name}.{key} in {in} is a forbidden property, did you mean {suggestions}?"
+	unallowedPropertySuggestNoIn = "{name}.{key} is a forbidden property, did you mean {suggestions}?"
+	duplicateField               = "{name} in {in} must be unique, it duplicates {previous}"
+	duplicateFieldNoIn           = "{name} must be unique, it duplicates {previous}"
+	unknownField                 = "{name}.{key} in {in} is an unknown field"
+	unknownFieldNoIn             = "{name}.{key} is an unknown field"
+	unknownFormat                = "{name} in {in} has unknown format {format:q}"
+	unknownFormatNoIn            = "{name} has unknown format {format:q}"
+	notStructural                = "{name} in {in} is not structural: {reason}"
+	notStructuralNoIn            = "{name} is not structural: {reason}"
+	readOnlyField                = "{name}.{key} in {in} is read-only and must not be set in requests"
+	readOnlyFieldNoIn            = "{name}.{key} is read-only and must not be set in requests"
+	writeOnlyField               = "{name}.{key} in {in} is write-only and must not be set in responses"
+	writeOnlyFieldNoIn           = "{name}.{key} is write-only and must not be set in responses"
+	dependentRequired            = "{name}.{required} in {in} is required when {name}.{key} is set"
+	dependentRequiredNoIn        = "{name}.{required} is required when {name}.{key} is set"
+	unevaluatedProperty          = "{name}.{key} in {in} is an unevaluated property"
+	unevaluatedPropertyNoIn      = "{name}.{key} is an unevaluated property"
+	unevaluatedItems             = "{name} in {in} can't have unevaluated items"
+	unevaluatedItemsNoIn         = "{name} can't have unevaluated items"
+	unknownDiscriminator         = "{name} in {in} has an unknown discriminator value {value:q}"
+	unknownDiscriminatorNoIn     = "{name} has an unknown discriminator value {value:q}"
+	contentEncoding              = "{name} in {in} must be {encoding} encoded: {reason}"
+	contentEncodingNoIn          = "{name} must be {encoding} encoded: {reason}"
+	contentMediaType             = "{name} in {in} must contain a valid {mediaType} document: {reason}"
+	contentMediaTypeNoIn         = "{name} must contain a valid {mediaType} document: {reason}"
+	invalidSpec                  = "{name} in {in} is invalid: {reason}"
+	invalidSpecNoIn              = "{name} is invalid: {reason}"
+	constFail                    = "{name} in {in} should be equal to {const}"
+	constFailNoIn                = "{name} should be equal to {const}"
 )
 
 // All code responses can be used to differentiate errors for different handling
@@ -91,6 +125,19 @@ const (
 	UnallowedPropertyCode
 	FailedAllPatternPropsCode
 	MultipleOfMustBePositiveCode
+	UnknownFieldCode
+	UnknownFormatCode
+	NotStructuralCode
+	ReadOnlyFieldCode
+	WriteOnlyFieldCode
+	DependentRequiredFailCode
+	UnevaluatedPropertyCode
+	UnevaluatedItemsCode
+	UnknownDiscriminatorCode
+	ContentEncodingFailCode
+	ContentMediaTypeFailCode
+	InvalidSpecCode
+	ConstFailCode
 )
 
 // CompositeError is an error that groups several errors together
@@ -127,441 +174,288 @@ func CompositeValidationError(errors ...error) *CompositeError {
 
 // FailedAllPatternProperties an error for when the property doesn't match a pattern
 func FailedAllPatternProperties(name, in, key string) *Validation {
-	msg := fmt.Sprintf(failedAllPatternProps, name, key, in)
-	if in == "" {
-		msg = fmt.Sprintf(failedAllPatternPropsNoIn, name, key)
-	}
-	return &Validation{
-		code:    FailedAllPatternPropsCode,
-		Name:    name,
-		In:      in,
-		Value:   key,
-		message: msg,
-	}
+	return newValidation(FailedAllPatternPropsCode, name, in, key, failedAllPatternProps, failedAllPatternPropsNoIn, Params{"key": key})
 }
 
 // PropertyNotAllowed an error for when the property doesn't match a pattern
 func PropertyNotAllowed(name, in, key string) *Validation {
-	msg := fmt.Sprintf(unallowedProperty, name, key, in)
-	if in == "" {
-		msg = fmt.Sprintf(unallowedPropertyNoIn, name, key)
-	}
-	return &Validation{
-		code:    UnallowedPropertyCode,
-		Name:    name,
-		In:      in,
-		Value:   key,
-		message: msg,
-	}
+	return newValidation(UnallowedPropertyCode, name, in, key, unallowedProperty, unallowedPropertyNoIn, Params{"key": key})
+}
+
+// PropertyNotAllowedWithSuggestions an error for when the property doesn't match a pattern,
+// suggesting allowed property names, e.g. the closest matches of a misspelled one
+func PropertyNotAllowedWithSuggestions(name, in, key string, suggestions []string) *Validation {
+	if len(suggestions) == 0 {
+		return PropertyNotAllowed(name, in, key)
+	}
+	quoted := make([]string, 0, len(suggestions))
+	values := make([]interface{}, 0, len(suggestions))
+	for _, s := range suggestions {
+		quoted = append(quoted, strconv.Quote(s))
+		values = append(values, s)
+	}
+	err := newValidation(UnallowedPropertyCode, name, in, key, unallowedPropertySuggest, unallowedPropertySuggestNoIn,
+		Params{"key": key, "suggestions": strings.Join(quoted, " or ")})
+	err.Values = values
+	return err
 }
 
 // TooFewProperties an error for an object with too few properties
 func TooFewProperties(name, in string, n int64) *Validation {
-	msg := fmt.Sprintf(tooFewProperties, name, in, n)
-	if in == "" {
-		msg = fmt.Sprintf(tooFewPropertiesNoIn, name, n)
-	}
-	return &Validation{
-		code:    TooFewPropertiesCode,
-		Name:    name,
-		In:      in,
-		Value:   n,
-		message: msg,
-	}
+	return newValidation(TooFewPropertiesCode, name, in, n, tooFewProperties, tooFewPropertiesNoIn, Params{"min": n})
 }
 
 // TooManyProperties an error for an object with too many properties
 func TooManyProperties(name, in string, n int64) *Validation {
-	msg := fmt.Sprintf(tooManyProperties, name, in, n)
-	if in == "" {
-		msg = fmt.Sprintf(tooManyPropertiesNoIn, name, n)
-	}
-	return &Validation{
-		code:    TooManyPropertiesCode,
-		Name:    name,
-		In:      in,
-		Value:   n,
-		message: msg,
-	}
+	return newValidation(TooManyPropertiesCode, name, in, n, tooManyProperties, tooManyPropertiesNoIn, Params{"max": n})
 }
 
 // AdditionalItemsNotAllowed an error for invalid additional items
 func AdditionalItemsNotAllowed(name, in string) *Validation {
-	msg := fmt.Sprintf(noAdditionalItems, name, in)
-	if in == "" {
-		msg = fmt.Sprintf(noAdditionalItemsNoIn, name)
-	}
-	return &Validation{
-		code:    NoAdditionalItemsCode,
-		Name:    name,
-		In:      in,
-		message: msg,
-	}
+	return newValidation(NoAdditionalItemsCode, name, in, nil, noAdditionalItems, noAdditionalItemsNoIn, nil)
 }
 
 // InvalidCollectionFormat another flavor of invalid type error
 func InvalidCollectionFormat(name, in, format string) *Validation {
+	params := Params{"name": name, "in": in, "format": format}
 	return &Validation{
-		code:    InvalidTypeCode,
-		Name:    name,
-		In:      in,
-		Value:   format,
-		message: fmt.Sprintf("the collection format %q is not supported for the %s param %q", format, in, name),
+		code:     InvalidTypeCode,
+		Name:     name,
+		In:       in,
+		Value:    format,
+		Template: invalidCollectionFormat,
+		Params:   params,
+		message:  FormatTemplate(invalidCollectionFormat, params),
 	}
 }
 
 // InvalidTypeName an error for when the type is invalid
 func InvalidTypeName(typeName string) *Validation {
+	params := Params{"type": typeName}
 	return &Validation{
-		code:    InvalidTypeCode,
-		Value:   typeName,
-		message: fmt.Sprintf(invalidType, typeName),
+		code:     InvalidTypeCode,
+		Value:    typeName,
+		Template: invalidType,
+		Params:   params,
+		message:  FormatTemplate(invalidType, params),
 	}
 }
 
 // InvalidType creates an error for when the type is invalid
 func InvalidType(name, in, typeName string, value interface{}) *Validation {
-	var message string
-
-	if in != "" {
-		switch value.(type) {
-		case string:
-			message = fmt.Sprintf(typeFailWithData, name, in, typeName, value)
-		case error:
-			message = fmt.Sprintf(typeFailWithError, name, in, typeName, value)
-		default:
-			message = fmt.Sprintf(typeFail, name, in, typeName)
-		}
-	} else {
-		switch value.(type) {
-		case string:
-			message = fmt.Sprintf(typeFailWithDataNoIn, name, typeName, value)
-		case error:
-			message = fmt.Sprintf(typeFailWithErrorNoIn, name, typeName, value)
-		default:
-			message = fmt.Sprintf(typeFailNoIn, name, typeName)
-		}
-	}
-
-	return &Validation{
-		code:    InvalidTypeCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: message,
-	}
-
+	template, templateNoIn := typeFail, typeFailNoIn
+	params := Params{"type": typeName}
+	switch value.(type) {
+	case string:
+		template, templateNoIn = typeFailWithData, typeFailWithDataNoIn
+		params["value"] = value
+	case error:
+		template, templateNoIn = typeFailWithError, typeFailWithErrorNoIn
+		params["value"] = value
+	}
+	return newValidation(InvalidTypeCode, name, in, value, template, templateNoIn, params)
 }
 
 // DuplicateItems error for when an array contains duplicates
 func DuplicateItems(name, in string) *Validation {
-	msg := fmt.Sprintf(uniqueFail, name, in)
-	if in == "" {
-		msg = fmt.Sprintf(uniqueFailNoIn, name)
-	}
-	return &Validation{
-		code:    UniqueFailCode,
-		Name:    name,
-		In:      in,
-		message: msg,
-	}
+	return newValidation(UniqueFailCode, name, in, nil, uniqueFail, uniqueFailNoIn, nil)
+}
+
+// UnknownField a warning for when a property is neither declared nor
+// forbidden, e.g. a misspelled field that would be pruned
+func UnknownField(name, in, key string) *Validation {
+	return newValidation(UnknownFieldCode, name, in, key, unknownField, unknownFieldNoIn, Params{"key": key})
+}
+
+// UnknownFormat a warning for when a value is declared with a format
+// that is not in the registry, and thus is not checked
+func UnknownFormat(name, in, format string, value interface{}) *Validation {
+	return newValidation(UnknownFormatCode, name, in, value, unknownFormat, unknownFormatNoIn, Params{"format": format})
+}
+
+// NotStructural error for when a schema keyword violates the constraints of
+// Kubernetes structural schemas, e.g. a missing type
+func NotStructural(name, in, reason string) *Validation {
+	return newValidation(NotStructuralCode, name, in, nil, notStructural, notStructuralNoIn, Params{"reason": reason})
+}
+
+// ReadOnlyField error for when a property declared readOnly is set in a request
+func ReadOnlyField(name, in, key string) *Validation {
+	return newValidation(ReadOnlyFieldCode, name, in, key, readOnlyField, readOnlyFieldNoIn, Params{"key": key})
+}
+
+// WriteOnlyField error for when a property declared writeOnly is set in a response
+func WriteOnlyField(name, in, key string) *Validation {
+	return newValidation(WriteOnlyFieldCode, name, in, key, writeOnlyField, writeOnlyFieldNoIn, Params{"key": key})
+}
+
+// DependentRequired error for when a property is missing, while it is
+// required by dependentRequired because the property key is set
+func DependentRequired(name, in, key, required string) *Validation {
+	return newValidation(DependentRequiredFailCode, name, in, required, dependentRequired, dependentRequiredNoIn, Params{"key": key, "required": required})
+}
+
+// UnevaluatedPropertyNotAllowed error for a property not evaluated by any
+// keyword, when unevaluatedProperties is false
+func UnevaluatedPropertyNotAllowed(name, in, key string) *Validation {
+	return newValidation(UnevaluatedPropertyCode, name, in, key, unevaluatedProperty, unevaluatedPropertyNoIn, Params{"key": key})
+}
+
+// UnevaluatedItemsNotAllowed error for items not evaluated by any keyword,
+// when unevaluatedItems is false
+func UnevaluatedItemsNotAllowed(name, in string) *Validation {
+	return newValidation(UnevaluatedItemsCode, name, in, nil, unevaluatedItems, unevaluatedItemsNoIn, nil)
+}
+
+// UnknownDiscriminatorValue error for when the discriminator property of an
+// object has a value selecting none of the schemas
+func UnknownDiscriminatorValue(name, in, value string) *Validation {
+	return newValidation(UnknownDiscriminatorCode, name, in, value, unknownDiscriminator, unknownDiscriminatorNoIn, Params{"value": value})
+}
+
+// InvalidContentEncoding error for when a string can't be decoded as
+// declared by contentEncoding
+func InvalidContentEncoding(name, in, encoding string, reason error) *Validation {
+	return newValidation(ContentEncodingFailCode, name, in, reason, contentEncoding, contentEncodingNoIn, Params{"encoding": encoding, "reason": reason})
+}
+
+// InvalidContentMediaType error for when the content of a string isn't a
+// document of the media type declared by contentMediaType
+func InvalidContentMediaType(name, in, mediaType string, reason error) *Validation {
+	return newValidation(ContentMediaTypeFailCode, name, in, reason, contentMediaType, contentMediaTypeNoIn, Params{"mediaType": mediaType, "reason": reason})
+}
+
+// InvalidSpec error for when a part of an OpenAPI document violates the
+// specification, e.g. a duplicate operation ID or an unresolved reference
+func InvalidSpec(name, in, reason string) *Validation {
+	return newValidation(InvalidSpecCode, name, in, nil, invalidSpec, invalidSpecNoIn, Params{"reason": reason})
+}
+
+// DuplicateField error for when a field of an array item, declared unique,
+// has the same value as the field of a previous item
+func DuplicateField(name, in, previous string, value interface{}) *Validation {
+	return newValidation(UniqueFailCode, name, in, value, duplicateField, duplicateFieldNoIn, Params{"previous": previous})
 }
 
 // TooManyItems error for when an array contains too many items
 func TooManyItems(name, in string, max int64, value interface{}) *Validation {
-	msg := fmt.Sprintf(maxItemsFail, name, in, max)
-	if in == "" {
-		msg = fmt.Sprintf(maxItemsFailNoIn, name, max)
-	}
-
-	return &Validation{
-		code:    MaxItemsFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: msg,
-	}
+	return newValidation(MaxItemsFailCode, name, in, value, maxItemsFail, maxItemsFailNoIn, Params{"max": max})
 }
 
 // TooFewItems error for when an array contains too few items
 func TooFewItems(name, in string, min int64, value interface{}) *Validation {
-	msg := fmt.Sprintf(minItemsFail, name, in, min)
-	if in == "" {
-		msg = fmt.Sprintf(minItemsFailNoIn, name, min)
-	}
-	return &Validation{
-		code:    MinItemsFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: msg,
-	}
+	return newValidation(MinItemsFailCode, name, in, value, minItemsFail, minItemsFailNoIn, Params{"min": min})
 }
 
 // ExceedsMaximumInt error for when maxinum validation fails
 func ExceedsMaximumInt(name, in string, max int64, exclusive bool, value interface{}) *Validation {
-	var message string
-	if in == "" {
-		m := maxIncFailNoIn
-		if exclusive {
-			m = maxExcFailNoIn
-		}
-		message = fmt.Sprintf(m, name, max)
-	} else {
-		m := maxIncFail
-		if exclusive {
-			m = maxExcFail
-		}
-		message = fmt.Sprintf(m, name, in, max)
-	}
-	return &Validation{
-		code:    MaxFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: message,
-	}
+	return exceedsMaximum(name, in, max, exclusive, value)
 }
 
 // ExceedsMaximumUint error for when maxinum validation fails
 func ExceedsMaximumUint(name, in string, max uint64, exclusive bool, value interface{}) *Validation {
-	var message string
-	if in == "" {
-		m := maxIncFailNoIn
-		if exclusive {
-			m = maxExcFailNoIn
-		}
-		message = fmt.Sprintf(m, name, max)
-	} else {
-		m := maxIncFail
-		if exclusive {
-			m = maxExcFail
-		}
-		message = fmt.Sprintf(m, name, in, max)
-	}
-	return &Validation{
-		code:    MaxFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: message,
-	}
+	return exceedsMaximum(name, in, max, exclusive, value)
 }
 
 // ExceedsMaximum error for when maxinum validation fails
 func ExceedsMaximum(name, in string, max float64, exclusive bool, value interface{}) *Validation {
-	var message string
-	if in == "" {
-		m := maxIncFailNoIn
-		if exclusive {
-			m = maxExcFailNoIn
-		}
-		message = fmt.Sprintf(m, name, max)
-	} else {
-		m := maxIncFail
-		if exclusive {
-			m = maxExcFail
-		}
-		message = fmt.Sprintf(m, name, in, max)
-	}
-	return &Validation{
-		code:    MaxFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: message,
+	return exceedsMaximum(name, in, max, exclusive, value)
+}
+
+func exceedsMaximum(name, in string, max interface{}, exclusive bool, value interface{}) *Validation {
+	template, templateNoIn := maxIncFail, maxIncFailNoIn
+	if exclusive {
+		template, templateNoIn = maxExcFail, maxExcFailNoIn
 	}
+	return newValidation(MaxFailCode, name, in, value, template, templateNoIn, Params{"max": max})
 }
 
 // ExceedsMinimumInt error for when maxinum validation fails
 func ExceedsMinimumInt(name, in string, min int64, exclusive bool, value interface{}) *Validation {
-	var message string
-	if in == "" {
-		m := minIncFailNoIn
-		if exclusive {
-			m = minExcFailNoIn
-		}
-		message = fmt.Sprintf(m, name, min)
-	} else {
-		m := minIncFail
-		if exclusive {
-			m = minExcFail
-		}
-		message = fmt.Sprintf(m, name, in, min)
-	}
-	return &Validation{
-		code:    MinFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: message,
-	}
+	return exceedsMinimum(name, in, min, exclusive, value)
 }
 
 // ExceedsMinimumUint error for when maxinum validation fails
 func ExceedsMinimumUint(name, in string, min uint64, exclusive bool, value interface{}) *Validation {
-	var message string
-	if in == "" {
-		m := minIncFailNoIn
-		if exclusive {
-			m = minExcFailNoIn
-		}
-		message = fmt.Sprintf(m, name, min)
-	} else {
-		m := minIncFail
-		if exclusive {
-			m = minExcFail
-		}
-		message = fmt.Sprintf(m, name, in, min)
-	}
-	return &Validation{
-		code:    MinFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: message,
-	}
+	return exceedsMinimum(name, in, min, exclusive, value)
 }
 
 // ExceedsMinimum error for when maxinum validation fails
 func ExceedsMinimum(name, in string, min float64, exclusive bool, value interface{}) *Validation {
-	var message string
-	if in == "" {
-		m := minIncFailNoIn
-		if exclusive {
-			m = minExcFailNoIn
-		}
-		message = fmt.Sprintf(m, name, min)
-	} else {
-		m := minIncFail
-		if exclusive {
-			m = minExcFail
-		}
-		message = fmt.Sprintf(m, name, in, min)
-	}
-	return &Validation{
-		code:    MinFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: message,
+	return exceedsMinimum(name, in, min, exclusive, value)
+}
+
+func exceedsMinimum(name, in string, min interface{}, exclusive bool, value interface{}) *Validation {
+	template, templateNoIn := minIncFail, minIncFailNoIn
+	if exclusive {
+		template, templateNoIn = minExcFail, minExcFailNoIn
 	}
+	return newValidation(MinFailCode, name, in, value, template, templateNoIn, Params{"min": min})
 }
 
 // NotMultipleOf error for when multiple of validation fails
 func NotMultipleOf(name, in string, multiple, value interface{}) *Validation {
-	var msg string
-	if in == "" {
-		msg = fmt.Sprintf(multipleOfFailNoIn, name, multiple)
-	} else {
-		msg = fmt.Sprintf(multipleOfFail, name, in, multiple)
-	}
-	return &Validation{
-		code:    MultipleOfFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: msg,
-	}
+	return newValidation(MultipleOfFailCode, name, in, value, multipleOfFail, multipleOfFailNoIn, Params{"multiple": multiple})
 }
 
 // EnumFail error for when an enum validation fails
 func EnumFail(name, in string, value interface{}, values []interface{}) *Validation {
-	var msg string
-	if in == "" {
-		msg = fmt.Sprintf(enumFailNoIn, name, values)
-	} else {
-		msg = fmt.Sprintf(enumFail, name, in, values)
-	}
+	err := newValidation(EnumFailCode, name, in, value, enumFail, enumFailNoIn, Params{"values": values})
+	err.Values = values
+	return err
+}
 
-	return &Validation{
-		code:    EnumFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		Values:  values,
-		message: msg,
-	}
+// ConstFail error for when a value is not equal to the const value
+func ConstFail(name, in string, value interface{}, constValue interface{}) *Validation {
+	return newValidation(ConstFailCode, name, in, value, constFail, constFailNoIn, Params{"const": constValue})
 }
 
 // Required error for when a value is missing
 func Required(name, in string) *Validation {
-	var msg string
-	if in == "" {
-		msg = fmt.Sprintf(requiredFailNoIn, name)
-	} else {
-		msg = fmt.Sprintf(requiredFail, name, in)
-	}
-	return &Validation{
-		code:    RequiredFailCode,
-		Name:    name,
-		In:      in,
-		message: msg,
-	}
+	return newValidation(RequiredFailCode, name, in, nil, requiredFail, requiredFailNoIn, nil)
 }
 
 // TooLong error for when a string is too long
 func TooLong(name, in string, max int64, value interface{}) *Validation {
-	var msg string
-	if in == "" {
-		msg = fmt.Sprintf(tooLongMessageNoIn, name, max)
-	} else {
-		msg = fmt.Sprintf(tooLongMessage, name, in, max)
-	}
-	return &Validation{
-		code:    TooLongFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: msg,
-	}
+	return newValidation(TooLongFailCode, name, in, value, tooLongMessage, tooLongMessageNoIn, Params{"max": max})
 }
 
 // TooShort error for when a string is too short
 func TooShort(name, in string, min int64, value interface{}) *Validation {
-	var msg string
-	if in == "" {
-		msg = fmt.Sprintf(tooShortMessageNoIn, name, min)
-	} else {
-		msg = fmt.Sprintf(tooShortMessage, name, in, min)
-	}
-
-	return &Validation{
-		code:    TooShortFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: msg,
-	}
+	return newValidation(TooShortFailCode, name, in, value, tooShortMessage, tooShortMessageNoIn, Params{"min": min})
 }
 
 // FailedPattern error for when a string fails a regex pattern match
 // the pattern that is returned is the ECMA syntax version of the pattern not the golang version.
 func FailedPattern(name, in, pattern string, value interface{}) *Validation {
-	var msg string
-	if in == "" {
-		msg = fmt.Sprintf(patternFailNoIn, name, pattern)
-	} else {
-		msg = fmt.Sprintf(patternFail, name, in, pattern)
-	}
-
-	return &Validation{
-		code:    PatternFailCode,
-		Name:    name,
-		In:      in,
-		Value:   value,
-		message: msg,
-	}
+	return newValidation(PatternFailCode, name, in, value, patternFail, patternFailNoIn, Params{"pattern": pattern})
 }
 
 // MultipleOfMustBePositive error for when a
 // multipleOf factor is negative
 func MultipleOfMustBePositive(name, in string, factor interface{}) *Validation {
+	return newValidation(MultipleOfMustBePositiveCode, name, in, factor, multipleOfMustBePositive, multipleOfMustBePositive, Params{"factor": factor})
+}
+
+// newValidation creates a validation error, whose message follows template,
+// or templateNoIn if in is empty, with params along with name and in.
+func newValidation(code int32, name, in string, value interface{}, template, templateNoIn string, params Params) *Validation {
+	if params == nil {
+		params = Params{}
+	}
+	params["name"] = name
+	if in == "" {
+		template = templateNoIn
+	} else {
+		params["in"] = in
+	}
 	return &Validation{
-		code:    MultipleOfMustBePositiveCode,
-		Name:    name,
-		In:      in,
-		Value:   factor,
-		message: fmt.Sprintf(multipleOfMustBePositive, name, factor),
+		code:     code,
+		Name:     name,
+		In:       in,
+		Value:    value,
+		Template: template,
+		Params:   params,
+		message:  FormatTemplate(template, params),
 	}
 }
@@ -261,6 +261,16 @@ func TestSchemaErrors(t *testing.T) {
 	assert.Equal(t, "something should be one of [hello world]", err.Error())
 	assert.Equal(t, "yada", err.Value)
 
+	// func ConstFail(name, in string, value interface{}, constValue interface{}) *Validation {
+	err = ConstFail("something", "query", "yada", "hello")
+	assert.Error(t, err)
+	assert.EqualValues(t, ConstFailCode, err.Code())
+	assert.Equal(t, "something in query should be equal to hello", err.Error())
+	assert.Equal(t, "yada", err.Value)
+
+	err = ConstFail("something", "", "yada", "hello")
+	assert.Equal(t, "something should be equal to hello", err.Error())
+
 	err = Required("something", "query")
 	assert.Error(t, err)
 	assert.EqualValues(t, RequiredFailCode, err.Code())
@@ -363,6 +373,139 @@ func TestSchemaErrors(t *testing.T) {
 	//unallowedPropertyNoIn     = "%s.%s is a forbidden property"
 	assert.Equal(t, "path.key is a forbidden property", err.Error())
 
+	// func PropertyNotAllowedWithSuggestions(name, in, key string, suggestions []string) *Validation {
+	err = PropertyNotAllowedWithSuggestions("path", "body", "kye", []string{"key", "kyes"})
+	assert.Error(t, err)
+	assert.EqualValues(t, UnallowedPropertyCode, err.Code())
+	assert.Equal(t, `path.kye in body is a forbidden property, did you mean "key" or "kyes"?`, err.Error())
+	assert.Equal(t, []interface{}{"key", "kyes"}, err.Values)
+
+	err = PropertyNotAllowedWithSuggestions("path", "", "kye", []string{"key"})
+	assert.Equal(t, `path.kye is a forbidden property, did you mean "key"?`, err.Error())
+
+	err = PropertyNotAllowedWithSuggestions("path", "body", "kye", nil)
+	assert.Equal(t, "path.kye in body is a forbidden property", err.Error())
+
+	// func DuplicateField(name, in, previous string, value interface{}) *Validation {
+	err = DuplicateField("path.1.key", "body", "path.0.key", "a")
+	assert.Error(t, err)
+	assert.EqualValues(t, UniqueFailCode, err.Code())
+	assert.Equal(t, "path.1.key in body must be unique, it duplicates path.0.key", err.Error())
+	assert.Equal(t, "a", err.Value)
+
+	err = DuplicateField("path.1.key", "", "path.0.key", "a")
+	assert.Equal(t, "path.1.key must be unique, it duplicates path.0.key", err.Error())
+
+	// func UnknownField(name, in, key string) *Validation {
+	err = UnknownField("spec", "body", "replics")
+	assert.Error(t, err)
+	assert.EqualValues(t, UnknownFieldCode, err.Code())
+	assert.Equal(t, "spec.replics in body is an unknown field", err.Error())
+	assert.Equal(t, "replics", err.Value)
+
+	err = UnknownField("spec", "", "replics")
+	assert.Equal(t, "spec.replics is an unknown field", err.Error())
+
+	// func ReadOnlyField(name, in, key string) *Validation {
+	err = ReadOnlyField("metadata", "body", "uid")
+	assert.Error(t, err)
+	assert.EqualValues(t, ReadOnlyFieldCode, err.Code())
+	assert.Equal(t, "metadata.uid in body is read-only and must not be set in requests", err.Error())
+	assert.Equal(t, "uid", err.Value)
+
+	err = ReadOnlyField("metadata", "", "uid")
+	assert.Equal(t, "metadata.uid is read-only and must not be set in requests", err.Error())
+
+	// func WriteOnlyField(name, in, key string) *Validation {
+	err = WriteOnlyField("spec", "body", "password")
+	assert.Error(t, err)
+	assert.EqualValues(t, WriteOnlyFieldCode, err.Code())
+	assert.Equal(t, "spec.password in body is write-only and must not be set in responses", err.Error())
+
+	err = WriteOnlyField("spec", "", "password")
+	assert.Equal(t, "spec.password is write-only and must not be set in responses", err.Error())
+
+	// func DependentRequired(name, in, key, required string) *Validation {
+	err = DependentRequired("spec", "body", "port", "host")
+	assert.Error(t, err)
+	assert.EqualValues(t, DependentRequiredFailCode, err.Code())
+	assert.Equal(t, "spec.host in body is required when spec.port is set", err.Error())
+	assert.Equal(t, "host", err.Value)
+
+	err = DependentRequired("spec", "", "port", "host")
+	assert.Equal(t, "spec.host is required when spec.port is set", err.Error())
+
+	// func UnevaluatedPropertyNotAllowed(name, in, key string) *Validation {
+	err = UnevaluatedPropertyNotAllowed("spec", "body", "extra")
+	assert.Error(t, err)
+	assert.EqualValues(t, UnevaluatedPropertyCode, err.Code())
+	assert.Equal(t, "spec.extra in body is an unevaluated property", err.Error())
+	assert.Equal(t, "extra", err.Value)
+
+	err = UnevaluatedPropertyNotAllowed("spec", "", "extra")
+	assert.Equal(t, "spec.extra is an unevaluated property", err.Error())
+
+	// func UnevaluatedItemsNotAllowed(name, in string) *Validation {
+	err = UnevaluatedItemsNotAllowed("spec.ports", "body")
+	assert.Error(t, err)
+	assert.EqualValues(t, UnevaluatedItemsCode, err.Code())
+	assert.Equal(t, "spec.ports in body can't have unevaluated items", err.Error())
+
+	err = UnevaluatedItemsNotAllowed("spec.ports", "")
+	assert.Equal(t, "spec.ports can't have unevaluated items", err.Error())
+
+	// func UnknownDiscriminatorValue(name, in, value string) *Validation {
+	err = UnknownDiscriminatorValue("spec.petType", "body", "fish")
+	assert.Error(t, err)
+	assert.EqualValues(t, UnknownDiscriminatorCode, err.Code())
+	assert.Equal(t, `spec.petType in body has an unknown discriminator value "fish"`, err.Error())
+
+	err = UnknownDiscriminatorValue("spec.petType", "", "fish")
+	assert.Equal(t, `spec.petType has an unknown discriminator value "fish"`, err.Error())
+
+	// func InvalidContentEncoding(name, in, encoding string, reason error) *Validation {
+	err = InvalidContentEncoding("spec.data", "body", "base64", fmt.Errorf("illegal base64 data at input byte 4"))
+	assert.Error(t, err)
+	assert.EqualValues(t, ContentEncodingFailCode, err.Code())
+	assert.Equal(t, "spec.data in body must be base64 encoded: illegal base64 data at input byte 4", err.Error())
+
+	err = InvalidContentEncoding("spec.data", "", "base64", fmt.Errorf("illegal base64 data at input byte 4"))
+	assert.Equal(t, "spec.data must be base64 encoded: illegal base64 data at input byte 4", err.Error())
+
+	// func InvalidContentMediaType(name, in, mediaType string, reason error) *Validation {
+	err = InvalidContentMediaType("spec.config", "body", "application/json", fmt.Errorf("unexpected end of JSON input"))
+	assert.Error(t, err)
+	assert.EqualValues(t, ContentMediaTypeFailCode, err.Code())
+	assert.Equal(t, "spec.config in body must contain a valid application/json document: unexpected end of JSON input", err.Error())
+
+	err = InvalidContentMediaType("spec.config", "", "application/json", fmt.Errorf("unexpected end of JSON input"))
+	assert.Equal(t, "spec.config must contain a valid application/json document: unexpected end of JSON input", err.Error())
+
+	// func InvalidSpec(name, in, reason string) *Validation {
+	err = InvalidSpec("paths[/pets].get.operationId", "", `duplicate operation ID "listPets"`)
+	assert.Error(t, err)
+	assert.EqualValues(t, InvalidSpecCode, err.Code())
+	assert.Equal(t, `paths[/pets].get.operationId is invalid: duplicate operation ID "listPets"`, err.Error())
+
+	// func NotStructural(name, in, reason string) *Validation {
+	err = NotStructural("properties[spec].type", "schema", "must not be empty")
+	assert.Error(t, err)
+	assert.EqualValues(t, NotStructuralCode, err.Code())
+	assert.Equal(t, "properties[spec].type in schema is not structural: must not be empty", err.Error())
+
+	err = NotStructural("properties[spec].type", "", "must not be empty")
+	assert.Equal(t, "properties[spec].type is not structural: must not be empty", err.Error())
+
+	// func UnknownFormat(name, in, format string, value interface{}) *Validation {
+	err = UnknownFormat("spec.id", "body", "ulid", "01F8")
+	assert.Error(t, err)
+	assert.EqualValues(t, UnknownFormatCode, err.Code())
+	assert.Equal(t, `spec.id in body has unknown format "ulid"`, err.Error())
+	assert.Equal(t, "01F8", err.Value)
+
+	err = UnknownFormat("spec.id", "", "ulid", "01F8")
+	assert.Equal(t, `spec.id has unknown format "ulid"`, err.Error())
+
 	//func TooManyProperties(name, in string, n int64) *Validation {
 	err = TooManyProperties("path", "body", 10)
 	assert.Error(t, err)
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Fixture is a schema along with an object valid against it.
+type Fixture struct {
+	Name   string
+	Schema *spec.Schema
+	Object interface{}
+}
+
+// All returns all the fixtures, with the nested status one at depth 10.
+func All() []Fixture {
+	return []Fixture{
+		{Name: "deployment", Schema: DeploymentSchema(), Object: DeploymentObject()},
+		{Name: "crd", Schema: CRDSchema(), Object: CRDObject()},
+		{Name: "nested-status", Schema: NestedStatusSchema(10), Object: NestedStatusObject(10)},
+	}
+}
+
+// DeploymentSchema returns a structural schema shaped like an apps/v1
+// Deployment, with the sub-types inlined.
+func DeploymentSchema() *spec.Schema {
+	return mustSchema(deploymentSchema)
+}
+
+// DeploymentObject returns a Deployment with two containers, valid against
+// DeploymentSchema.
+func DeploymentObject() map[string]interface{} {
+	return mustObject(deploymentObject)
+}
+
+// CRDSchema returns the schema of a custom resource using all the
+// x-kubernetes-* extensions: list types and map keys, map types,
+// int-or-string, embedded resources, preserved unknown fields, unique fields
+// and validation rules.
+func CRDSchema() *spec.Schema {
+	return mustSchema(crdSchema)
+}
+
+// CRDObject returns a custom resource valid against CRDSchema.
+func CRDObject() map[string]interface{} {
+	return mustObject(crdObject)
+}
+
+// NestedStatusSchema returns the schema of an object whose status nests
+// depth levels of conditions-carrying sub-statuses, as found in composite
+// controllers' resources.
+func NestedStatusSchema(depth int) *spec.Schema {
+	return &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"apiVersion": *spec.StringProperty(),
+				"kind":       *spec.StringProperty(),
+				"metadata":   *objectMetaSchema(),
+				"status":     *nestedStatusSchema(depth),
+			},
+		},
+	}
+}
+
+// NestedStatusObject returns an object valid against NestedStatusSchema with
+// the same depth.
+func NestedStatusObject(depth int) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Composite",
+		"metadata":   map[string]interface{}{"name": "composite", "namespace": "default"},
+		"status":     nestedStatusObject(depth),
+	}
+}
+
+func nestedStatusSchema(depth int) *spec.Schema {
+	s := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"observedGeneration": *spec.Int64Property(),
+				"phase":              {SchemaProps: spec.SchemaProps{Type: []string{"string"}, Enum: []interface{}{"Pending", "Ready", "Failed"}}},
+				"conditions":         *conditionsSchema(),
+			},
+		},
+	}
+	if depth > 1 {
+		s.Properties["child"] = *nestedStatusSchema(depth - 1)
+	}
+	return s
+}
+
+func nestedStatusObject(depth int) map[string]interface{} {
+	o := map[string]interface{}{
+		"observedGeneration": int64(depth),
+		"phase":              "Ready",
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True", "lastTransitionTime": "2021-06-01T10:00:00Z", "reason": "AllGood"},
+			map[string]interface{}{"type": "Progressing", "status": "False", "lastTransitionTime": "2021-06-01T10:00:00Z"},
+		},
+	}
+	if depth > 1 {
+		o["child"] = nestedStatusObject(depth - 1)
+	}
+	return o
+}
+
+func objectMetaSchema() *spec.Schema {
+	return mustSchema(objectMetaSchemaJSON)
+}
+
+func conditionsSchema() *spec.Schema {
+	return mustSchema(conditionsSchemaJSON)
+}
+
+func mustSchema(s string) *spec.Schema {
+	ret := &spec.Schema{}
+	if err := json.Unmarshal([]byte(s), ret); err != nil {
+		panic(fmt.Sprintf("invalid fixture schema: %v", err))
+	}
+	return ret
+}
+
+func mustObject(s string) map[string]interface{} {
+	var ret map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &ret); err != nil {
+		panic(fmt.Sprintf("invalid fixture object: %v", err))
+	}
+	return ret
+}
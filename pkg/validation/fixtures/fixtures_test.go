@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fixtures_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/fixtures"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+func TestFixturesAreValid(t *testing.T) {
+	for _, f := range fixtures.All() {
+		t.Run(f.Name, func(t *testing.T) {
+			assert.NoError(t, validate.AgainstSchema(f.Schema, f.Object, strfmt.Default))
+		})
+	}
+}
+
+func TestFixturesAreCopies(t *testing.T) {
+	s := fixtures.DeploymentSchema()
+	delete(s.Properties, "spec")
+	assert.Contains(t, fixtures.DeploymentSchema().Properties, "spec")
+
+	o := fixtures.CRDObject()
+	o["spec"].(map[string]interface{})["maxReplicas"] = 0
+	assert.Error(t, validate.AgainstSchema(fixtures.CRDSchema(), o, strfmt.Default))
+	assert.EqualValues(t, 10, fixtures.CRDObject()["spec"].(map[string]interface{})["maxReplicas"])
+}
+
+func TestNestedStatus(t *testing.T) {
+	s := fixtures.NestedStatusSchema(3)
+	depth := 0
+	for status, ok := s.Properties["status"], true; ok; status, ok = status.Properties["child"] {
+		depth++
+	}
+	assert.Equal(t, 3, depth)
+
+	o := fixtures.NestedStatusObject(3)
+	o["status"].(map[string]interface{})["child"].(map[string]interface{})["phase"] = "Unknown"
+	require.Error(t, validate.AgainstSchema(s, o, strfmt.Default))
+}
+
+func BenchmarkValidateFixtures(b *testing.B) {
+	for _, f := range fixtures.All() {
+		f := f
+		b.Run(f.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := validate.AgainstSchema(f.Schema, f.Object, strfmt.Default); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fixtures
+
+const objectMetaSchemaJSON = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "maxLength": 253},
+		"namespace": {"type": "string", "maxLength": 63},
+		"generateName": {"type": "string"},
+		"uid": {"type": "string"},
+		"resourceVersion": {"type": "string"},
+		"generation": {"type": "integer", "format": "int64"},
+		"creationTimestamp": {"type": "string", "format": "date-time"},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"annotations": {"type": "object", "additionalProperties": {"type": "string"}},
+		"finalizers": {"type": "array", "x-kubernetes-list-type": "set", "items": {"type": "string"}}
+	}
+}`
+
+const conditionsSchemaJSON = `{
+	"type": "array",
+	"x-kubernetes-list-type": "map",
+	"x-kubernetes-list-map-keys": ["type"],
+	"items": {
+		"type": "object",
+		"required": ["type", "status"],
+		"properties": {
+			"type": {"type": "string", "maxLength": 316},
+			"status": {"type": "string", "enum": ["True", "False", "Unknown"]},
+			"observedGeneration": {"type": "integer", "format": "int64", "minimum": 0},
+			"lastTransitionTime": {"type": "string", "format": "date-time"},
+			"reason": {"type": "string", "maxLength": 1024, "pattern": "^([A-Za-z]([A-Za-z0-9_,:]*[A-Za-z0-9_])?)?$"},
+			"message": {"type": "string", "maxLength": 32768}
+		}
+	}
+}`
+
+const quantitySchemaJSON = `{
+	"x-kubernetes-int-or-string": true,
+	"anyOf": [{"type": "integer"}, {"type": "string"}],
+	"pattern": "^(\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\\+|-)?(([0-9]+(\\.[0-9]*)?)|(\\.[0-9]+))))?$"
+}`
+
+const containerSchemaJSON = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "maxLength": 63, "pattern": "^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"},
+		"image": {"type": "string"},
+		"imagePullPolicy": {"type": "string", "enum": ["Always", "Never", "IfNotPresent"]},
+		"command": {"type": "array", "x-kubernetes-list-type": "atomic", "items": {"type": "string"}},
+		"args": {"type": "array", "x-kubernetes-list-type": "atomic", "items": {"type": "string"}},
+		"ports": {
+			"type": "array",
+			"x-kubernetes-list-type": "map",
+			"x-kubernetes-list-map-keys": ["containerPort", "protocol"],
+			"items": {
+				"type": "object",
+				"required": ["containerPort"],
+				"properties": {
+					"name": {"type": "string", "maxLength": 15},
+					"containerPort": {"type": "integer", "format": "int32", "minimum": 1, "maximum": 65535},
+					"protocol": {"type": "string", "default": "TCP", "enum": ["TCP", "UDP", "SCTP"]}
+				}
+			}
+		},
+		"env": {
+			"type": "array",
+			"x-kubernetes-list-type": "map",
+			"x-kubernetes-list-map-keys": ["name"],
+			"items": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {
+					"name": {"type": "string"},
+					"value": {"type": "string"},
+					"valueFrom": {
+						"type": "object",
+						"properties": {
+							"configMapKeyRef": {
+								"type": "object",
+								"required": ["key"],
+								"properties": {"name": {"type": "string"}, "key": {"type": "string"}, "optional": {"type": "boolean"}},
+								"x-kubernetes-map-type": "atomic"
+							}
+						}
+					}
+				}
+			}
+		},
+		"resources": {
+			"type": "object",
+			"properties": {
+				"limits": {"type": "object", "additionalProperties": ` + quantitySchemaJSON + `},
+				"requests": {"type": "object", "additionalProperties": ` + quantitySchemaJSON + `}
+			}
+		}
+	}
+}`
+
+const deploymentSchema = `{
+	"description": "Deployment enables declarative updates for Pods and ReplicaSets.",
+	"type": "object",
+	"properties": {
+		"apiVersion": {"type": "string"},
+		"kind": {"type": "string"},
+		"metadata": ` + objectMetaSchemaJSON + `,
+		"spec": {
+			"type": "object",
+			"required": ["selector", "template"],
+			"properties": {
+				"replicas": {"type": "integer", "format": "int32", "minimum": 0},
+				"minReadySeconds": {"type": "integer", "format": "int32", "minimum": 0},
+				"revisionHistoryLimit": {"type": "integer", "format": "int32"},
+				"paused": {"type": "boolean"},
+				"selector": {
+					"type": "object",
+					"x-kubernetes-map-type": "atomic",
+					"properties": {
+						"matchLabels": {"type": "object", "additionalProperties": {"type": "string"}},
+						"matchExpressions": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"required": ["key", "operator"],
+								"properties": {
+									"key": {"type": "string"},
+									"operator": {"type": "string", "enum": ["In", "NotIn", "Exists", "DoesNotExist"]},
+									"values": {"type": "array", "items": {"type": "string"}}
+								}
+							}
+						}
+					}
+				},
+				"strategy": {
+					"type": "object",
+					"properties": {
+						"type": {"type": "string", "enum": ["Recreate", "RollingUpdate"]},
+						"rollingUpdate": {
+							"type": "object",
+							"properties": {
+								"maxUnavailable": {"x-kubernetes-int-or-string": true, "anyOf": [{"type": "integer"}, {"type": "string"}]},
+								"maxSurge": {"x-kubernetes-int-or-string": true, "anyOf": [{"type": "integer"}, {"type": "string"}]}
+							}
+						}
+					}
+				},
+				"template": {
+					"type": "object",
+					"properties": {
+						"metadata": ` + objectMetaSchemaJSON + `,
+						"spec": {
+							"type": "object",
+							"required": ["containers"],
+							"properties": {
+								"serviceAccountName": {"type": "string"},
+								"nodeSelector": {"type": "object", "additionalProperties": {"type": "string"}, "x-kubernetes-map-type": "atomic"},
+								"restartPolicy": {"type": "string", "enum": ["Always", "OnFailure", "Never"]},
+								"containers": {
+									"type": "array",
+									"minItems": 1,
+									"x-kubernetes-list-type": "map",
+									"x-kubernetes-list-map-keys": ["name"],
+									"items": ` + containerSchemaJSON + `
+								},
+								"initContainers": {
+									"type": "array",
+									"x-kubernetes-list-type": "map",
+									"x-kubernetes-list-map-keys": ["name"],
+									"items": ` + containerSchemaJSON + `
+								}
+							}
+						}
+					}
+				}
+			}
+		},
+		"status": {
+			"type": "object",
+			"properties": {
+				"observedGeneration": {"type": "integer", "format": "int64"},
+				"replicas": {"type": "integer", "format": "int32"},
+				"updatedReplicas": {"type": "integer", "format": "int32"},
+				"readyReplicas": {"type": "integer", "format": "int32"},
+				"availableReplicas": {"type": "integer", "format": "int32"},
+				"conditions": ` + conditionsSchemaJSON + `
+			}
+		}
+	}
+}`
+
+const deploymentObject = `{
+	"apiVersion": "apps/v1",
+	"kind": "Deployment",
+	"metadata": {
+		"name": "frontend",
+		"namespace": "default",
+		"generation": 3,
+		"creationTimestamp": "2021-06-01T09:00:00Z",
+		"labels": {"app": "frontend", "tier": "web"},
+		"annotations": {"deployment.kubernetes.io/revision": "3"}
+	},
+	"spec": {
+		"replicas": 3,
+		"revisionHistoryLimit": 10,
+		"selector": {"matchLabels": {"app": "frontend"}},
+		"strategy": {"type": "RollingUpdate", "rollingUpdate": {"maxUnavailable": "25%", "maxSurge": 1}},
+		"template": {
+			"metadata": {"labels": {"app": "frontend", "tier": "web"}},
+			"spec": {
+				"serviceAccountName": "frontend",
+				"restartPolicy": "Always",
+				"initContainers": [
+					{"name": "migrate", "image": "example.com/frontend:v3", "command": ["/bin/migrate"], "args": ["--wait"]}
+				],
+				"containers": [
+					{
+						"name": "app",
+						"image": "example.com/frontend:v3",
+						"imagePullPolicy": "IfNotPresent",
+						"ports": [{"name": "http", "containerPort": 8080, "protocol": "TCP"}, {"name": "metrics", "containerPort": 9090}],
+						"env": [
+							{"name": "LOG_LEVEL", "value": "info"},
+							{"name": "BACKEND", "valueFrom": {"configMapKeyRef": {"name": "frontend-config", "key": "backend"}}}
+						],
+						"resources": {"limits": {"cpu": "500m", "memory": "256Mi"}, "requests": {"cpu": "250m", "memory": "128Mi"}}
+					},
+					{
+						"name": "proxy",
+						"image": "example.com/proxy:v1",
+						"ports": [{"containerPort": 8443, "protocol": "TCP"}],
+						"resources": {"limits": {"cpu": 1, "memory": "64Mi"}}
+					}
+				]
+			}
+		}
+	},
+	"status": {
+		"observedGeneration": 3,
+		"replicas": 3,
+		"updatedReplicas": 3,
+		"readyReplicas": 3,
+		"availableReplicas": 3,
+		"conditions": [
+			{"type": "Available", "status": "True", "lastTransitionTime": "2021-06-01T09:05:00Z", "reason": "MinimumReplicasAvailable"},
+			{"type": "Progressing", "status": "True", "lastTransitionTime": "2021-06-01T09:01:00Z", "reason": "NewReplicaSetAvailable"}
+		]
+	}
+}`
+
+const crdSchema = `{
+	"type": "object",
+	"properties": {
+		"apiVersion": {"type": "string"},
+		"kind": {"type": "string"},
+		"metadata": {"type": "object"},
+		"spec": {
+			"type": "object",
+			"required": ["minReplicas", "maxReplicas"],
+			"x-kubernetes-validations": [
+				{"rule": "self.minReplicas <= self.maxReplicas", "message": "minReplicas must not exceed maxReplicas"}
+			],
+			"properties": {
+				"minReplicas": {"type": "integer", "format": "int32", "minimum": 0},
+				"maxReplicas": {"type": "integer", "format": "int32", "minimum": 1},
+				"targetUtilization": {"x-kubernetes-int-or-string": true, "anyOf": [{"type": "integer"}, {"type": "string"}]},
+				"tags": {"type": "array", "x-kubernetes-list-type": "set", "items": {"type": "string"}},
+				"hosts": {"type": "array", "x-kubernetes-list-type": "atomic", "items": {"type": "string", "format": "hostname"}},
+				"routes": {
+					"type": "array",
+					"x-kubernetes-list-type": "map",
+					"x-kubernetes-list-map-keys": ["path", "method"],
+					"x-kubernetes-unique-fields": ["name"],
+					"items": {
+						"type": "object",
+						"required": ["path", "method"],
+						"properties": {
+							"name": {"type": "string"},
+							"path": {"type": "string", "pattern": "^/"},
+							"method": {"type": "string", "enum": ["GET", "POST", "PUT", "DELETE"]},
+							"weight": {"type": "integer", "minimum": 0, "maximum": 100, "default": 100}
+						},
+						"x-kubernetes-validations": [
+							{"rule": "self.method != 'DELETE' || self.path != '/'", "message": "cannot delete the root"}
+						]
+					}
+				},
+				"selector": {
+					"type": "object",
+					"x-kubernetes-map-type": "atomic",
+					"additionalProperties": {"type": "string"}
+				},
+				"limits": {
+					"type": "object",
+					"x-kubernetes-map-type": "granular",
+					"properties": {
+						"cpu": ` + quantitySchemaJSON + `,
+						"memory": ` + quantitySchemaJSON + `
+					}
+				},
+				"template": {
+					"type": "object",
+					"x-kubernetes-embedded-resource": true,
+					"x-kubernetes-preserve-unknown-fields": true,
+					"properties": {
+						"apiVersion": {"type": "string"},
+						"kind": {"type": "string"},
+						"metadata": {"type": "object"}
+					}
+				},
+				"config": {
+					"type": "object",
+					"x-kubernetes-preserve-unknown-fields": true
+				}
+			}
+		},
+		"status": {
+			"type": "object",
+			"properties": {
+				"observedGeneration": {"type": "integer", "format": "int64"},
+				"currentReplicas": {"type": "integer", "format": "int32"},
+				"conditions": ` + conditionsSchemaJSON + `
+			}
+		}
+	}
+}`
+
+const crdObject = `{
+	"apiVersion": "example.com/v1",
+	"kind": "Scaler",
+	"metadata": {"name": "web", "namespace": "default"},
+	"spec": {
+		"minReplicas": 2,
+		"maxReplicas": 10,
+		"targetUtilization": "80%",
+		"tags": ["web", "public"],
+		"hosts": ["web.example.com", "www.example.com"],
+		"routes": [
+			{"name": "index", "path": "/", "method": "GET"},
+			{"name": "submit", "path": "/submit", "method": "POST", "weight": 50}
+		],
+		"selector": {"app": "web"},
+		"limits": {"cpu": "2", "memory": "1Gi"},
+		"template": {
+			"apiVersion": "v1",
+			"kind": "ConfigMap",
+			"metadata": {"name": "web-config"},
+			"data": {"mode": "fast"}
+		},
+		"config": {"anything": {"goes": [1, "two", true]}}
+	},
+	"status": {
+		"observedGeneration": 1,
+		"currentReplicas": 2,
+		"conditions": [
+			{"type": "Ready", "status": "True", "lastTransitionTime": "2021-06-01T10:00:00Z", "reason": "Scaled"}
+		]
+	}
+}`
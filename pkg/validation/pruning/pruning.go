@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pruning removes the fields of unstructured objects, i.e. trees of
+// map[string]interface{}, []interface{} and JSON values, which are not
+// declared in their schema, as done for custom resources.
+package pruning
+
+import (
+	"sort"
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/internal"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Prune removes the fields of obj not declared in schema, in place, and
+// returns the sorted paths of the removed fields, named as in validation
+// errors, e.g. "spec.template.foo".
+//
+// Fields are kept if they are declared in properties or additionalProperties,
+// or if their object is x-kubernetes-preserve-unknown-fields or allows any
+// additionalProperties. The apiVersion, kind and metadata fields are kept
+// in resources, i.e. in obj if isResourceRoot is set and in
+// x-kubernetes-embedded-resource objects. The schema is expected to be
+// expanded and structural: $refs and allOf, anyOf, oneOf and not are ignored.
+func Prune(obj interface{}, schema *spec.Schema, isResourceRoot bool) []string {
+	var paths []string
+	prune(obj, schema, "", isResourceRoot, &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func prune(obj interface{}, s *spec.Schema, path string, isResource bool, paths *[]string) {
+	if s == nil {
+		return
+	}
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		preserve, _ := s.PreserveUnknownFields()
+		for name, value := range v {
+			if isResource && (name == "apiVersion" || name == "kind" || name == "metadata") {
+				continue
+			}
+			if prop, declared := s.Properties[name]; declared {
+				prune(value, &prop, internal.JoinPath(path, name), isEmbeddedResource(&prop), paths)
+				continue
+			}
+			if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+				prune(value, s.AdditionalProperties.Schema, internal.JoinPath(path, name), isEmbeddedResource(s.AdditionalProperties.Schema), paths)
+				continue
+			}
+			if preserve || s.AdditionalProperties != nil && s.AdditionalProperties.Allows {
+				continue
+			}
+			delete(v, name)
+			*paths = append(*paths, internal.JoinPath(path, name))
+		}
+	case []interface{}:
+		if s.Items == nil || s.Items.Schema == nil {
+			return
+		}
+		for i, item := range v {
+			prune(item, s.Items.Schema, internal.JoinPath(path, strconv.Itoa(i)), isEmbeddedResource(s.Items.Schema), paths)
+		}
+	}
+}
+
+func isEmbeddedResource(s *spec.Schema) bool {
+	embedded, _ := s.Extensions.GetBool("x-kubernetes-embedded-resource")
+	return embedded
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pruning
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const schemaJSON = `{
+	"type": "object",
+	"properties": {
+		"spec": {
+			"type": "object",
+			"properties": {
+				"replicas": {"type": "integer"},
+				"ports": {"type": "array", "items": {"type": "object", "properties": {"port": {"type": "integer"}}}},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"config": {"type": "object", "x-kubernetes-preserve-unknown-fields": true, "properties": {"nested": {"type": "object", "properties": {"a": {"type": "string"}}}}},
+				"any": {"type": "object", "additionalProperties": true},
+				"template": {"type": "object", "x-kubernetes-embedded-resource": true, "properties": {"spec": {"type": "object", "properties": {"image": {"type": "string"}}}}}
+			}
+		}
+	}
+}`
+
+func TestPrune(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), schema))
+
+	var obj interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"apiVersion": "example.com/v1",
+		"kind": "Foo",
+		"metadata": {"name": "foo"},
+		"status": {"ready": true},
+		"spec": {
+			"replicas": 1,
+			"replics": 2,
+			"ports": [{"port": 80, "protocol": "TCP"}, {"port": 443}],
+			"labels": {"app": "x"},
+			"config": {"anything": {"goes": 1}, "nested": {"a": "b", "c": "d"}},
+			"any": {"x": 1},
+			"template": {"apiVersion": "v1", "kind": "Pod", "metadata": {"labels": {"a": "b"}}, "spec": {"image": "nginx", "foo": 1}, "bar": 2}
+		}
+	}`), &obj))
+
+	paths := Prune(obj, schema, true)
+	assert.Equal(t, []string{
+		"spec.config.nested.c",
+		"spec.ports.0.protocol",
+		"spec.replics",
+		"spec.template.bar",
+		"spec.template.spec.foo",
+		"status",
+	}, paths)
+
+	var expected interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"apiVersion": "example.com/v1",
+		"kind": "Foo",
+		"metadata": {"name": "foo"},
+		"spec": {
+			"replicas": 1,
+			"ports": [{"port": 80}, {"port": 443}],
+			"labels": {"app": "x"},
+			"config": {"anything": {"goes": 1}, "nested": {"a": "b"}},
+			"any": {"x": 1},
+			"template": {"apiVersion": "v1", "kind": "Pod", "metadata": {"labels": {"a": "b"}}, "spec": {"image": "nginx"}}
+		}
+	}`), &expected))
+	assert.Equal(t, expected, obj)
+}
+
+func TestPruneNotResourceRoot(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{"a": *spec.StringProperty()}}}
+	obj := map[string]interface{}{"a": "x", "kind": "Foo"}
+	assert.Equal(t, []string{"kind"}, Prune(obj, schema, false))
+	assert.Equal(t, map[string]interface{}{"a": "x"}, obj)
+
+	assert.Empty(t, Prune("a", schema, false))
+	assert.Empty(t, Prune(obj, nil, false))
+}
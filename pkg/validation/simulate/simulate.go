@@ -0,0 +1,250 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulate runs objects through an admission-like validation
+// pipeline and reports how long it takes, for capacity planning and
+// performance regression detection in CI.
+package simulate
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/validation/defaulting"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// Stage is one step of the validation pipeline.
+type Stage struct {
+	// Name identifies the stage in the report.
+	Name string
+	// Run validates obj. It should return early once ctx is done.
+	Run func(ctx context.Context, obj interface{}) *validate.Result
+}
+
+// SchemaStage validates objects against schema, stopping once the deadline
+// of the object is exceeded.
+func SchemaStage(schema *spec.Schema, formats strfmt.Registry, options ...validate.Option) Stage {
+	return Stage{
+		Name: "schema",
+		Run: func(ctx context.Context, obj interface{}) *validate.Result {
+			return validate.NewSchemaValidator(schema, nil, "", formats, options...).ValidateWithContext(ctx, obj)
+		},
+	}
+}
+
+// StructuralStage validates objects against schema as SchemaStage, also
+// rejecting them if schema is not a Kubernetes structural schema, as the
+// API server requires for custom resources. It replaces SchemaStage in the
+// pipelines of custom resources.
+func StructuralStage(schema *spec.Schema, formats strfmt.Registry, options ...validate.Option) Stage {
+	options = append(options[:len(options):len(options)], validate.RequireStructuralSchema())
+	return Stage{
+		Name: "structural",
+		Run: func(ctx context.Context, obj interface{}) *validate.Result {
+			return validate.NewSchemaValidator(schema, nil, "", formats, options...).ValidateWithContext(ctx, obj)
+		},
+	}
+}
+
+// DefaultingStage sets the defaults of schema in objects, in place, as the
+// API server does before validation. It never rejects objects.
+func DefaultingStage(schema *spec.Schema) Stage {
+	return Stage{
+		Name: "defaulting",
+		Run: func(ctx context.Context, obj interface{}) *validate.Result {
+			if ctx.Err() == nil {
+				defaulting.Default(obj, schema)
+			}
+			return nil
+		},
+	}
+}
+
+// Options configures a simulation.
+type Options struct {
+	// Stages are run in order for every object.
+	Stages []Stage
+	// Deadline is the time allowed to each object to go through all stages.
+	// Zero means no deadline.
+	Deadline time.Duration
+}
+
+// Timings summarizes a set of durations.
+type Timings struct {
+	P50, P90, P99, Max time.Duration
+	Total              time.Duration
+}
+
+// Report is the outcome of a simulation.
+type Report struct {
+	// Objects is the number of objects processed.
+	Objects int
+	// Invalid is the number of objects rejected by a stage.
+	Invalid int
+	// TimedOut is the number of objects that exceeded the deadline.
+	TimedOut int
+	// Aborted is the number of objects interrupted because the context of
+	// the simulation was done, not counted as timed out.
+	Aborted int
+	// Timings are the per-object durations through the whole pipeline.
+	Timings Timings
+	// StageTimings are the per-object durations of each stage, by stage name.
+	// Stages not reached because of a rejection or a timeout are not counted.
+	StageTimings map[string]Timings
+}
+
+type objectOutcome struct {
+	invalid bool
+	stages  []time.Duration
+}
+
+// objectStatus is how the processing of an object ended.
+type objectStatus int
+
+const (
+	completed objectStatus = iota
+	timedOut
+	aborted
+)
+
+// Run sends every object read from objects through the stages and reports
+// timings, until objects is closed or ctx is done.
+//
+// Stages that ignore their context cannot be interrupted: when an object times
+// out the simulation moves on, but the stage keeps running in the background
+// until it returns.
+func Run(ctx context.Context, objects <-chan interface{}, opts Options) *Report {
+	report := &Report{StageTimings: map[string]Timings{}}
+	var total []time.Duration
+	perStage := make([][]time.Duration, len(opts.Stages))
+
+	for {
+		var obj interface{}
+		select {
+		case <-ctx.Done():
+			return report.finish(total, perStage, opts.Stages)
+		case o, ok := <-objects:
+			if !ok {
+				return report.finish(total, perStage, opts.Stages)
+			}
+			obj = o
+		}
+
+		report.Objects++
+		start := time.Now()
+		outcome, status := runObject(ctx, obj, opts)
+		elapsed := time.Since(start)
+
+		if status == aborted {
+			report.Aborted++
+			continue
+		}
+		total = append(total, elapsed)
+		if status == timedOut {
+			report.TimedOut++
+			continue
+		}
+		if outcome.invalid {
+			report.Invalid++
+		}
+		for i, d := range outcome.stages {
+			perStage[i] = append(perStage[i], d)
+		}
+	}
+}
+
+func runObject(ctx context.Context, obj interface{}, opts Options) (objectOutcome, objectStatus) {
+	objCtx := ctx
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		objCtx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	done := make(chan objectOutcome, 1)
+	go func() {
+		var outcome objectOutcome
+		for _, stage := range opts.Stages {
+			start := time.Now()
+			res := stage.Run(objCtx, obj)
+			outcome.stages = append(outcome.stages, time.Since(start))
+			if res != nil && !res.IsValid() {
+				outcome.invalid = true
+				break
+			}
+			if objCtx.Err() != nil {
+				break
+			}
+		}
+		done <- outcome
+	}()
+
+	select {
+	case outcome := <-done:
+		if ctx.Err() == nil && objCtx.Err() == context.DeadlineExceeded {
+			return outcome, timedOut
+		}
+		return outcome, completed
+	case <-objCtx.Done():
+		// The deadline of ctx, or its cancellation, aborts the object
+		// rather than timing it out.
+		if ctx.Err() != nil {
+			return objectOutcome{}, aborted
+		}
+		return objectOutcome{}, timedOut
+	}
+}
+
+func (r *Report) finish(total []time.Duration, perStage [][]time.Duration, stages []Stage) *Report {
+	r.Timings = summarize(total)
+	for i, durations := range perStage {
+		if len(durations) > 0 {
+			r.StageTimings[stages[i].Name] = summarize(durations)
+		}
+	}
+	return r
+}
+
+func summarize(durations []time.Duration) Timings {
+	if len(durations) == 0 {
+		return Timings{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var t Timings
+	for _, d := range sorted {
+		t.Total += d
+	}
+	t.P50 = percentile(sorted, 50)
+	t.P90 = percentile(sorted, 90)
+	t.P99 = percentile(sorted, 99)
+	t.Max = sorted[len(sorted)-1]
+	return t
+}
+
+// percentile uses the nearest-rank method on sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+func feed(objects ...interface{}) <-chan interface{} {
+	ch := make(chan interface{}, len(objects))
+	for _, o := range objects {
+		ch <- o
+	}
+	close(ch)
+	return ch
+}
+
+func TestRun(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:     []string{"object"},
+		Required: []string{"name"},
+		Properties: map[string]spec.Schema{
+			"name": *spec.StringProperty(),
+		},
+	}}
+	slow := Stage{
+		Name: "slow",
+		Run: func(ctx context.Context, obj interface{}) *validate.Result {
+			if obj.(map[string]interface{})["slow"] == true {
+				<-ctx.Done()
+			}
+			return nil
+		},
+	}
+
+	report := Run(context.Background(), feed(
+		map[string]interface{}{"name": "a"},
+		map[string]interface{}{"name": "b"},
+		map[string]interface{}{"name": 1},
+		map[string]interface{}{"name": "c", "slow": true},
+	), Options{
+		Stages:   []Stage{SchemaStage(schema, strfmt.Default), slow},
+		Deadline: 50 * time.Millisecond,
+	})
+
+	assert.Equal(t, 4, report.Objects)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, 1, report.TimedOut)
+	assert.True(t, report.Timings.Max >= 50*time.Millisecond)
+	assert.True(t, report.Timings.P50 <= report.Timings.P90)
+	assert.Contains(t, report.StageTimings, "schema")
+	assert.Contains(t, report.StageTimings, "slow")
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i > 0; i-- {
+		durations = append(durations, time.Duration(i))
+	}
+	timings := summarize(durations)
+	assert.Equal(t, Timings{P50: 50, P90: 90, P99: 99, Max: 100, Total: 5050}, timings)
+	assert.Equal(t, Timings{P50: 7, P90: 7, P99: 7, Max: 7, Total: 7}, summarize([]time.Duration{7}))
+}
+
+func TestRunAborted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocking := Stage{
+		Name: "blocking",
+		Run: func(ctx context.Context, obj interface{}) *validate.Result {
+			cancel()
+			<-ctx.Done()
+			return nil
+		},
+	}
+
+	report := Run(ctx, feed(map[string]interface{}{}), Options{
+		Stages:   []Stage{blocking},
+		Deadline: time.Minute,
+	})
+
+	assert.Equal(t, 1, report.Objects)
+	assert.Equal(t, 1, report.Aborted)
+	assert.Equal(t, 0, report.TimedOut)
+}
+
+func TestSchemaStageContext(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{"object"},
+		Properties: map[string]spec.Schema{"name": *spec.StringProperty()},
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res := SchemaStage(schema, strfmt.Default).Run(ctx, map[string]interface{}{"name": 1})
+	assert.False(t, res.IsValid())
+	assert.Contains(t, res.Errors[0].Error(), context.Canceled.Error())
+}
+
+func TestStructuralStage(t *testing.T) {
+	nonStructural := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{"object"},
+		Properties: map[string]spec.Schema{"name": {}},
+	}}
+	structural := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{"object"},
+		Properties: map[string]spec.Schema{"name": *spec.StringProperty()},
+	}}
+	obj := map[string]interface{}{"name": "a"}
+
+	assert.True(t, SchemaStage(nonStructural, strfmt.Default).Run(context.Background(), obj).IsValid())
+	assert.False(t, StructuralStage(nonStructural, strfmt.Default).Run(context.Background(), obj).IsValid())
+	assert.True(t, StructuralStage(structural, strfmt.Default).Run(context.Background(), obj).IsValid())
+}
+
+func TestDefaultingStage(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:     []string{"object"},
+		Required: []string{"replicas"},
+		Properties: map[string]spec.Schema{
+			"replicas": {SchemaProps: spec.SchemaProps{Type: []string{"integer"}, Default: int64(1)}},
+		},
+	}}
+
+	report := Run(context.Background(), feed(
+		map[string]interface{}{},
+		map[string]interface{}{"replicas": int64(3)},
+	), Options{
+		Stages: []Stage{DefaultingStage(schema), SchemaStage(schema, strfmt.Default)},
+	})
+
+	assert.Equal(t, 2, report.Objects)
+	assert.Equal(t, 0, report.Invalid)
+	assert.Contains(t, report.StageTimings, "defaulting")
+}
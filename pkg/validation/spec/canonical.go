@@ -0,0 +1,222 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// OrderExtension is the vendor extension giving the position of a property
+// in the properties of its schema, when marshaled by MarshalCanonical with
+// CanonicalOptions.OrderProperties.
+const OrderExtension = "x-order"
+
+// CanonicalOptions configures MarshalCanonical.
+type CanonicalOptions struct {
+	// OrderProperties orders the properties of schemas by the number of their
+	// OrderExtension, ascending, before the properties without it. It applies
+	// to Swagger, Schema and Definitions values.
+	OrderProperties bool
+}
+
+// MarshalCanonical returns the compact JSON encoding of v with the keys of
+// every object sorted, e.g. those of properties, definitions and extensions,
+// so that equal specs always have the same encoding. The encoding of
+// MarshalJSON is deterministic too, but depends on how the keys are split
+// among the embedded structs.
+func MarshalCanonical(v interface{}, opts CanonicalOptions) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	kind := otherKind
+	if opts.OrderProperties {
+		switch v.(type) {
+		case Swagger, *Swagger:
+			kind = swaggerKind
+		case Schema, *Schema:
+			kind = schemaKind
+		case Definitions, *Definitions:
+			kind = schemaMapKind
+		}
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, doc, kind); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// canonicalKind is the kind of object of a JSON value in a spec, to find the
+// properties of schemas.
+type canonicalKind int
+
+const (
+	otherKind canonicalKind = iota
+	swaggerKind
+	pathMapKind
+	pathItemKind
+	operationKind
+	parameterKind
+	parameterMapKind
+	responseKind
+	responseMapKind
+	schemaKind
+	schemaMapKind
+	// propertiesKind is the properties of a schema.
+	propertiesKind
+)
+
+// childKind returns the kind of the value at key in an object of kind.
+func (kind canonicalKind) childKind(key string) canonicalKind {
+	switch kind {
+	case swaggerKind:
+		switch key {
+		case "definitions":
+			return schemaMapKind
+		case "parameters":
+			return parameterMapKind
+		case "responses":
+			return responseMapKind
+		case "paths":
+			return pathMapKind
+		}
+	case pathMapKind:
+		return pathItemKind
+	case pathItemKind:
+		switch key {
+		case "parameters":
+			return parameterKind
+		case "get", "put", "post", "delete", "options", "head", "patch":
+			return operationKind
+		}
+	case operationKind:
+		switch key {
+		case "parameters":
+			return parameterKind
+		case "responses":
+			return responseMapKind
+		}
+	case parameterKind, responseKind:
+		if key == "schema" {
+			return schemaKind
+		}
+	case parameterMapKind:
+		return parameterKind
+	case responseMapKind:
+		return responseKind
+	case schemaKind:
+		switch key {
+		case "properties":
+			return propertiesKind
+		case "patternProperties", "definitions", "dependentSchemas", "dependencies":
+			return schemaMapKind
+		case "items", "allOf", "anyOf", "oneOf", "not", "if", "then", "else", "contentSchema",
+			"additionalProperties", "additionalItems", "unevaluatedProperties", "unevaluatedItems":
+			return schemaKind
+		}
+	case schemaMapKind, propertiesKind:
+		return schemaKind
+	}
+	return otherKind
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}, kind canonicalKind) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		if kind == propertiesKind {
+			sortByOrder(keys, v)
+		} else {
+			sort.Strings(keys)
+		}
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, k, otherKind); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k], kind.childKind(k)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		// lists hold values of the kind of the list, e.g. allOf schemas,
+		// tuple items or operation parameters.
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, e, kind); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
+
+// sortByOrder sorts the names of properties by their OrderExtension, then
+// by name.
+func sortByOrder(names []string, properties map[string]interface{}) {
+	order := func(name string) (float64, bool) {
+		schema, ok := properties[name].(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		n, ok := schema[OrderExtension].(json.Number)
+		if !ok {
+			return 0, false
+		}
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	sort.Slice(names, func(i, j int) bool {
+		oi, iok := order(names[i])
+		oj, jok := order(names[j])
+		switch {
+		case iok && jok && oi != oj:
+			return oi < oj
+		case iok != jok:
+			return iok
+		}
+		return names[i] < names[j]
+	})
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	schema := Schema{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"x-b": 1,
+		"type": "object",
+		"x-a": {"z": 1, "y": 123},
+		"properties": {
+			"name": {"type": "string", "x-order": 2},
+			"kind": {"type": "string", "x-order": 1},
+			"b": {"type": "object", "properties": {"z": {"x-order": 1}, "y": {}}},
+			"a": {"type": "integer"}
+		}
+	}`), &schema))
+
+	b, err := MarshalCanonical(&schema, CanonicalOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"properties":{"a":{"type":"integer"},"b":{"properties":{"y":{},"z":{"x-order":1}},"type":"object"},`+
+		`"kind":{"type":"string","x-order":1},"name":{"type":"string","x-order":2}},"type":"object","x-a":{"y":123,"z":1},"x-b":1}`, string(b))
+
+	b, err = MarshalCanonical(&schema, CanonicalOptions{OrderProperties: true})
+	require.NoError(t, err)
+	assert.Equal(t, `{"properties":{"kind":{"type":"string","x-order":1},"name":{"type":"string","x-order":2},`+
+		`"a":{"type":"integer"},"b":{"properties":{"z":{"x-order":1},"y":{}},"type":"object"}},"type":"object","x-a":{"y":123,"z":1},"x-b":1}`, string(b))
+
+	swagger := &Swagger{SwaggerProps: SwaggerProps{
+		Definitions: Definitions{"Pod": schema},
+		Paths: &Paths{Paths: map[string]PathItem{"/pods": {PathItemProps: PathItemProps{Get: &Operation{OperationProps: OperationProps{
+			Parameters: []Parameter{{ParamProps: ParamProps{Name: "body", In: "body", Schema: &schema}}},
+		}}}}}},
+	}}
+	b, err = MarshalCanonical(swagger, CanonicalOptions{OrderProperties: true})
+	require.NoError(t, err)
+	var got struct {
+		Definitions map[string]json.RawMessage
+		Paths       map[string]map[string]struct {
+			Parameters []struct {
+				Schema json.RawMessage
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(b, &got))
+	ordered, err := MarshalCanonical(&schema, CanonicalOptions{OrderProperties: true})
+	require.NoError(t, err)
+	assert.Equal(t, string(ordered), string(got.Definitions["Pod"]))
+	assert.Equal(t, string(ordered), string(got.Paths["/pods"]["get"].Parameters[0].Schema))
+}
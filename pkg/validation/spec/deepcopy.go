@@ -0,0 +1,221 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+// The DeepCopyInto methods of this file are written by hand, because
+// deepcopy-gen does not support interface{} values, e.g. defaults, examples,
+// enums and extensions. The others are in zz_generated.deepcopy.go, which is
+// regenerated with:
+//
+//	deepcopy-gen -i k8s.io/kube-openapi/pkg/validation/spec -O zz_generated.deepcopy -h boilerplate/boilerplate.go.txt
+
+// DeepCopyInto copies the receiver into out. A Ref is never modified in place,
+// so the parsed reference is shared.
+func (in *Ref) DeepCopyInto(out *Ref) {
+	*out = *in
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in Extensions) DeepCopyInto(out *Extensions) {
+	*out = deepCopyJSONMap(in)
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in *SimpleSchema) DeepCopyInto(out *SimpleSchema) {
+	*out = *in
+	if in.Items != nil {
+		out.Items = in.Items.DeepCopy()
+	}
+	out.Default = deepCopyJSONValue(in.Default)
+	out.Example = deepCopyJSONValue(in.Example)
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in *CommonValidations) DeepCopyInto(out *CommonValidations) {
+	*out = *in
+	out.Maximum = copyFloat64(in.Maximum)
+	out.Minimum = copyFloat64(in.Minimum)
+	out.MaxLength = copyInt64(in.MaxLength)
+	out.MinLength = copyInt64(in.MinLength)
+	out.MaxItems = copyInt64(in.MaxItems)
+	out.MinItems = copyInt64(in.MinItems)
+	out.MultipleOf = copyFloat64(in.MultipleOf)
+	out.Enum = deepCopyJSONSlice(in.Enum)
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in *SchemaProps) DeepCopyInto(out *SchemaProps) {
+	*out = *in
+	in.Ref.DeepCopyInto(&out.Ref)
+	if in.Type != nil {
+		out.Type = make(StringOrArray, len(in.Type))
+		copy(out.Type, in.Type)
+	}
+	out.Default = deepCopyJSONValue(in.Default)
+	out.Maximum = copyFloat64(in.Maximum)
+	out.Minimum = copyFloat64(in.Minimum)
+	out.MaxLength = copyInt64(in.MaxLength)
+	out.MinLength = copyInt64(in.MinLength)
+	out.ContentSchema = in.ContentSchema.DeepCopy()
+	out.MaxItems = copyInt64(in.MaxItems)
+	out.MinItems = copyInt64(in.MinItems)
+	out.MultipleOf = copyFloat64(in.MultipleOf)
+	out.Enum = deepCopyJSONSlice(in.Enum)
+	out.MaxProperties = copyInt64(in.MaxProperties)
+	out.MinProperties = copyInt64(in.MinProperties)
+	if in.Required != nil {
+		out.Required = make([]string, len(in.Required))
+		copy(out.Required, in.Required)
+	}
+	out.Items = in.Items.DeepCopy()
+	out.AllOf = deepCopySchemas(in.AllOf)
+	out.OneOf = deepCopySchemas(in.OneOf)
+	out.AnyOf = deepCopySchemas(in.AnyOf)
+	out.Not = in.Not.DeepCopy()
+	out.If = in.If.DeepCopy()
+	out.Then = in.Then.DeepCopy()
+	out.Else = in.Else.DeepCopy()
+	out.Properties = deepCopySchemaMap(in.Properties)
+	out.AdditionalProperties = in.AdditionalProperties.DeepCopy()
+	out.PatternProperties = deepCopySchemaMap(in.PatternProperties)
+	if in.Dependencies != nil {
+		in.Dependencies.DeepCopyInto(&out.Dependencies)
+	}
+	if in.DependentRequired != nil {
+		in.DependentRequired.DeepCopyInto(&out.DependentRequired)
+	}
+	out.DependentSchemas = deepCopySchemaMap(in.DependentSchemas)
+	out.AdditionalItems = in.AdditionalItems.DeepCopy()
+	if in.Definitions != nil {
+		in.Definitions.DeepCopyInto(&out.Definitions)
+	}
+	out.UnevaluatedProperties = in.UnevaluatedProperties.DeepCopy()
+	out.UnevaluatedItems = in.UnevaluatedItems.DeepCopy()
+	out.Const = deepCopyJSONValue(in.Const)
+	out.Examples = deepCopyJSONSlice(in.Examples)
+	out.PrefixItems = deepCopySchemas(in.PrefixItems)
+	out.ExclusiveMaximumValue = copyFloat64(in.ExclusiveMaximumValue)
+	out.ExclusiveMinimumValue = copyFloat64(in.ExclusiveMinimumValue)
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in *SwaggerSchemaProps) DeepCopyInto(out *SwaggerSchemaProps) {
+	*out = *in
+	out.ExternalDocs = in.ExternalDocs.DeepCopy()
+	out.Example = deepCopyJSONValue(in.Example)
+	if in.DiscriminatorMapping != nil {
+		out.DiscriminatorMapping = make(map[string]string, len(in.DiscriminatorMapping))
+		for k, v := range in.DiscriminatorMapping {
+			out.DiscriminatorMapping[k] = v
+		}
+	}
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in *Schema) DeepCopyInto(out *Schema) {
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.SchemaProps.DeepCopyInto(&out.SchemaProps)
+	in.SwaggerSchemaProps.DeepCopyInto(&out.SwaggerSchemaProps)
+	out.ExtraProps = deepCopyJSONMap(in.ExtraProps)
+}
+
+// DeepCopyInto copies the receiver into out. in must be non-nil.
+func (in *ResponseProps) DeepCopyInto(out *ResponseProps) {
+	*out = *in
+	out.Schema = in.Schema.DeepCopy()
+	if in.Headers != nil {
+		out.Headers = make(map[string]Header, len(in.Headers))
+		for k, v := range in.Headers {
+			out.Headers[k] = *v.DeepCopy()
+		}
+	}
+	out.Examples = deepCopyJSONMap(in.Examples)
+}
+
+func deepCopySchemas(in []Schema) []Schema {
+	if in == nil {
+		return nil
+	}
+	out := make([]Schema, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func deepCopySchemaMap(in map[string]Schema) map[string]Schema {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]Schema, len(in))
+	for k, v := range in {
+		out[k] = *v.DeepCopy()
+	}
+	return out
+}
+
+func copyFloat64(in *float64) *float64 {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+func copyInt64(in *int64) *int64 {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+// deepCopyJSONValue copies the maps and slices of v, a value decoded from JSON
+// or YAML. Other values, e.g. scalars or structs set by callers, are
+// returned as is.
+func deepCopyJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return deepCopyJSONMap(v)
+	case []interface{}:
+		return deepCopyJSONSlice(v)
+	default:
+		return v
+	}
+}
+
+func deepCopyJSONMap(in map[string]interface{}) map[string]interface{} {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = deepCopyJSONValue(v)
+	}
+	return out
+}
+
+func deepCopyJSONSlice(in []interface{}) []interface{} {
+	if in == nil {
+		return nil
+	}
+	out := make([]interface{}, len(in))
+	for i, v := range in {
+		out[i] = deepCopyJSONValue(v)
+	}
+	return out
+}
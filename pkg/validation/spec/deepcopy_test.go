@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepCopyFuzz(t *testing.T) {
+	f := fuzz.New().NilChance(0.3).NumElements(0, 2).MaxDepth(8).Funcs(
+		func(v *interface{}, c fuzz.Continue) {
+			switch c.Intn(4) {
+			case 0:
+				*v = c.RandString()
+			case 1:
+				*v = float64(c.Int31())
+			case 2:
+				*v = map[string]interface{}{c.RandString(): []interface{}{c.RandBool(), nil}}
+			}
+		},
+		func(r *Ref, c fuzz.Continue) {
+			if c.RandBool() {
+				*r = MustCreateRef("#/definitions/Def" + strconv.Itoa(c.Intn(10)))
+			}
+		},
+	)
+	for i := 0; i < 50; i++ {
+		var in Swagger
+		f.Fuzz(&in)
+		out := in.DeepCopy()
+		require.Equal(t, &in, out)
+
+		var schema Schema
+		f.Fuzz(&schema)
+		require.Equal(t, &schema, schema.DeepCopy())
+	}
+}
+
+func TestSchemaDeepCopyIsIndependent(t *testing.T) {
+	var in Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"default": {"spec": {"replicas": [1]}},
+		"enum": [{"a": "b"}],
+		"x-kubernetes-list-map-keys": ["name"],
+		"properties": {"name": {"type": "string", "maxLength": 3}},
+		"allOf": [{"required": ["name"]}]
+	}`), &in))
+	orig, err := json.Marshal(in)
+	require.NoError(t, err)
+
+	out := in.DeepCopy()
+	require.Equal(t, &in, out)
+	out.Type[0] = "array"
+	out.Default.(map[string]interface{})["spec"].(map[string]interface{})["replicas"].([]interface{})[0] = 2
+	out.Enum[0].(map[string]interface{})["a"] = "c"
+	out.Extensions["x-kubernetes-list-map-keys"].([]interface{})[0] = "id"
+	*out.Properties["name"].MaxLength = 5
+	out.Properties["other"] = Schema{}
+	out.AllOf[0].Required[0] = "other"
+
+	after, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(orig), string(after))
+
+	var nilSchema *Schema
+	assert.Nil(t, nilSchema.DeepCopy())
+}
+
+// TestDeepCopySharesNothing populates every field of the types with
+// hand-written or generated DeepCopy methods, and fails if the copy shares a
+// pointer, slice or map with the original. Refs are immutable and shared on
+// purpose.
+func TestDeepCopySharesNothing(t *testing.T) {
+	for _, in := range []interface{}{&Schema{}, &Swagger{}} {
+		v := reflect.ValueOf(in)
+		populate(v.Elem(), map[reflect.Type]int{})
+		out := v.MethodByName("DeepCopy").Call(nil)[0]
+		require.Equal(t, in, out.Interface())
+		assertNotShared(t, v.Elem().Type().Name(), v.Elem(), out.Elem())
+	}
+}
+
+var refType = reflect.TypeOf(Ref{})
+
+// populate sets every field of v to a non-zero value, nesting each type at
+// most twice so that recursive types are populated below their top level too.
+func populate(v reflect.Value, nesting map[reflect.Type]int) {
+	if v.Type() == refType {
+		v.Set(reflect.ValueOf(MustCreateRef("#/definitions/Def")))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.String:
+		v.SetString("x-value")
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(map[string]interface{}{"key": []interface{}{"value"}}))
+	case reflect.Ptr:
+		if nesting[v.Type().Elem()] >= 2 {
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		populate(v.Elem(), nesting)
+	case reflect.Slice:
+		if nesting[v.Type().Elem()] >= 2 {
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		populate(v.Index(0), nesting)
+	case reflect.Map:
+		if nesting[v.Type().Elem()] >= 2 {
+			return
+		}
+		key, elem := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		populate(key, nesting)
+		populate(elem, nesting)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		nesting[v.Type()]++
+		defer func() { nesting[v.Type()]-- }()
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				populate(v.Field(i), nesting)
+			}
+		}
+	}
+}
+
+func assertNotShared(t *testing.T, path string, in, out reflect.Value) {
+	if in.Type() == refType {
+		return
+	}
+	switch in.Kind() {
+	case reflect.Interface:
+		if !in.IsNil() {
+			assertNotShared(t, path, in.Elem(), out.Elem())
+		}
+	case reflect.Ptr:
+		if in.IsNil() {
+			return
+		}
+		if in.Pointer() == out.Pointer() {
+			t.Errorf("%s: pointer shared by the copy", path)
+			return
+		}
+		assertNotShared(t, path, in.Elem(), out.Elem())
+	case reflect.Slice:
+		if in.Len() == 0 {
+			return
+		}
+		if in.Pointer() == out.Pointer() {
+			t.Errorf("%s: slice shared by the copy", path)
+			return
+		}
+		for i := 0; i < in.Len(); i++ {
+			assertNotShared(t, path+"["+strconv.Itoa(i)+"]", in.Index(i), out.Index(i))
+		}
+	case reflect.Map:
+		if in.Len() == 0 {
+			return
+		}
+		if in.Pointer() == out.Pointer() {
+			t.Errorf("%s: map shared by the copy", path)
+			return
+		}
+		for _, k := range in.MapKeys() {
+			assertNotShared(t, path+"["+k.String()+"]", in.MapIndex(k), out.MapIndex(k))
+		}
+	case reflect.Struct:
+		for i := 0; i < in.NumField(); i++ {
+			if f := in.Type().Field(i); f.PkgPath == "" {
+				assertNotShared(t, path+"."+f.Name, in.Field(i), out.Field(i))
+			}
+		}
+	}
+}
@@ -0,0 +1,484 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// ChangeKind is the kind of a SchemaChange.
+type ChangeKind string
+
+const (
+	// PropertyAdded is a property added to the properties of a schema. The
+	// keyword of the change is the name of the property.
+	PropertyAdded ChangeKind = "PropertyAdded"
+	// PropertyRemoved is a property removed from the properties of a schema.
+	// The keyword of the change is the name of the property.
+	PropertyRemoved ChangeKind = "PropertyRemoved"
+	// TypeChanged is a change of the type, format or nullable keywords.
+	TypeChanged ChangeKind = "TypeChanged"
+	// ConstraintChanged is a change of a validation keyword, e.g. maximum,
+	// pattern, enum, required or additionalProperties, or a subschema added
+	// or removed.
+	ConstraintChanged ChangeKind = "ConstraintChanged"
+	// ExtensionChanged is a vendor extension added, removed or changed. The
+	// keyword of the change is the name of the extension.
+	ExtensionChanged ChangeKind = "ExtensionChanged"
+	// KeywordChanged is a change of any other keyword, e.g. description,
+	// default or $ref.
+	KeywordChanged ChangeKind = "KeywordChanged"
+)
+
+// SchemaChange is a difference between two schemas.
+type SchemaChange struct {
+	// Path is the JSON pointer to the changed schema, e.g. "/properties/spec".
+	Path string
+	// Keyword is the changed keyword, e.g. "maxLength", or the name of the
+	// property or extension for PropertyAdded, PropertyRemoved and
+	// ExtensionChanged.
+	Keyword string
+	Kind    ChangeKind
+	// Old and New are the values of the keyword, nil when not set. Numeric
+	// constraints are dereferenced, e.g. Old is a float64 for maximum.
+	Old, New interface{}
+	// Tightening is set if the change makes the schema reject values it used
+	// to accept, e.g. a lower maximum or a new required property. Only the
+	// type, format, nullable and constraint changes can be tightenings.
+	Tightening bool
+}
+
+func (c SchemaChange) String() string {
+	path := c.Path
+	if path == "" {
+		path = "/"
+	}
+	var msg string
+	switch {
+	case c.Kind == PropertyAdded:
+		msg = fmt.Sprintf("property %q added", c.Keyword)
+	case c.Kind == PropertyRemoved:
+		msg = fmt.Sprintf("property %q removed", c.Keyword)
+	case c.Old == nil:
+		msg = fmt.Sprintf("%s added: %v", c.Keyword, c.New)
+	case c.New == nil:
+		msg = fmt.Sprintf("%s removed", c.Keyword)
+	default:
+		msg = fmt.Sprintf("%s changed from %v to %v", c.Keyword, c.Old, c.New)
+	}
+	if c.Tightening {
+		msg += " (tightening)"
+	}
+	return path + ": " + msg
+}
+
+// SchemaDiff is the report of DiffSchemas.
+type SchemaDiff struct {
+	// Changes are sorted by path and keyword.
+	Changes []SchemaChange
+}
+
+// Empty returns true if the schemas are equivalent.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Tightenings returns the changes making the schema reject values it used to
+// accept.
+func (d *SchemaDiff) Tightenings() []SchemaChange {
+	var ret []SchemaChange
+	for _, c := range d.Changes {
+		if c.Tightening {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// DiffSchemas returns the changes from oldSchema to newSchema, walking the
+// subschemas present in both. References are compared, not resolved.
+func DiffSchemas(oldSchema, newSchema *Schema) *SchemaDiff {
+	d := &schemaDiffer{}
+	d.diff("", oldSchema, newSchema)
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		if d.changes[i].Path != d.changes[j].Path {
+			return d.changes[i].Path < d.changes[j].Path
+		}
+		return d.changes[i].Keyword < d.changes[j].Keyword
+	})
+	return &SchemaDiff{Changes: d.changes}
+}
+
+type schemaDiffer struct {
+	changes []SchemaChange
+}
+
+func (d *schemaDiffer) add(path, keyword string, kind ChangeKind, o, n interface{}, tightening bool) {
+	d.changes = append(d.changes, SchemaChange{Path: path, Keyword: keyword, Kind: kind, Old: o, New: n, Tightening: tightening})
+}
+
+func (d *schemaDiffer) diff(path string, o, n *Schema) {
+	if o == nil {
+		o = &Schema{}
+	}
+	if n == nil {
+		n = &Schema{}
+	}
+
+	if o.Ref.String() != n.Ref.String() {
+		d.add(path, "$ref", KeywordChanged, emptyToNil(o.Ref.String()), emptyToNil(n.Ref.String()), false)
+	}
+	d.diffTypes(path, o, n)
+
+	d.diffMaximum(path, "maximum", o.Maximum, n.Maximum, func(o, n float64) bool { return n < o })
+	d.diffMaximum(path, "minimum", o.Minimum, n.Minimum, func(o, n float64) bool { return n > o })
+	d.diffFlag(path, "exclusiveMaximum", o.ExclusiveMaximum, n.ExclusiveMaximum)
+	d.diffFlag(path, "exclusiveMinimum", o.ExclusiveMinimum, n.ExclusiveMinimum)
+	d.diffMaximum(path, "exclusiveMaximum", o.ExclusiveMaximumValue, n.ExclusiveMaximumValue, func(o, n float64) bool { return n < o })
+	d.diffMaximum(path, "exclusiveMinimum", o.ExclusiveMinimumValue, n.ExclusiveMinimumValue, func(o, n float64) bool { return n > o })
+	d.diffLimit(path, "maxLength", o.MaxLength, n.MaxLength, true)
+	d.diffLimit(path, "minLength", o.MinLength, n.MinLength, false)
+	d.diffLimit(path, "maxItems", o.MaxItems, n.MaxItems, true)
+	d.diffLimit(path, "minItems", o.MinItems, n.MinItems, false)
+	d.diffLimit(path, "maxProperties", o.MaxProperties, n.MaxProperties, true)
+	d.diffLimit(path, "minProperties", o.MinProperties, n.MinProperties, false)
+	d.diffFlag(path, "uniqueItems", o.UniqueItems, n.UniqueItems)
+	if o.Pattern != n.Pattern {
+		d.add(path, "pattern", ConstraintChanged, emptyToNil(o.Pattern), emptyToNil(n.Pattern), n.Pattern != "")
+	}
+	d.diffMaximum(path, "multipleOf", o.MultipleOf, n.MultipleOf, func(o, n float64) bool {
+		// values multiple of o are multiple of n if o is a multiple of n
+		q := o / n
+		return math.Abs(q-math.Round(q)) > 1e-9
+	})
+	d.diffEnum(path, o.Enum, n.Enum)
+	if !reflect.DeepEqual(o.Const, n.Const) {
+		d.add(path, "const", ConstraintChanged, o.Const, n.Const, n.Const != nil)
+	}
+	d.diffRequired(path, o.Required, n.Required)
+
+	d.diffProperties(path, o.Properties, n.Properties)
+	d.diffSchemaMap(path, "patternProperties", o.PatternProperties, n.PatternProperties)
+	d.diffSchemaOrBool(path, "additionalProperties", o.AdditionalProperties, n.AdditionalProperties)
+	d.diffItems(path, o.Items, n.Items)
+	d.diffSchemaOrBool(path, "additionalItems", o.AdditionalItems, n.AdditionalItems)
+	d.diffSchemaList(path, "prefixItems", o.PrefixItems, n.PrefixItems, true)
+	d.diffSchemaList(path, "allOf", o.AllOf, n.AllOf, true)
+	d.diffSchemaList(path, "anyOf", o.AnyOf, n.AnyOf, false)
+	d.diffSchemaList(path, "oneOf", o.OneOf, n.OneOf, false)
+	for _, s := range []struct {
+		keyword string
+		o, n    *Schema
+	}{{"not", o.Not, n.Not}, {"if", o.If, n.If}, {"then", o.Then, n.Then}, {"else", o.Else, n.Else}} {
+		d.diffSubSchema(path, s.keyword, s.o, s.n)
+	}
+
+	for _, k := range []struct {
+		keyword string
+		o, n    interface{}
+	}{
+		{"id", o.ID, n.ID},
+		{"$schema", string(o.Schema), string(n.Schema)},
+		{"title", o.Title, n.Title},
+		{"description", o.Description, n.Description},
+		{"default", o.Default, n.Default},
+		{"example", o.Example, n.Example},
+		{"examples", o.Examples, n.Examples},
+		{"readOnly", o.ReadOnly, n.ReadOnly},
+		{"writeOnly", o.WriteOnly, n.WriteOnly},
+		{"discriminator", o.Discriminator, n.Discriminator},
+		{"externalDocs", o.ExternalDocs, n.ExternalDocs},
+		{"dependencies", o.Dependencies, n.Dependencies},
+		{"dependentRequired", o.DependentRequired, n.DependentRequired},
+		{"dependentSchemas", o.DependentSchemas, n.DependentSchemas},
+		{"unevaluatedProperties", o.UnevaluatedProperties, n.UnevaluatedProperties},
+		{"unevaluatedItems", o.UnevaluatedItems, n.UnevaluatedItems},
+		{"contentEncoding", o.ContentEncoding, n.ContentEncoding},
+		{"contentMediaType", o.ContentMediaType, n.ContentMediaType},
+		{"contentSchema", o.ContentSchema, n.ContentSchema},
+		{"definitions", o.Definitions, n.Definitions},
+	} {
+		if !reflect.DeepEqual(k.o, k.n) {
+			d.add(path, k.keyword, KeywordChanged, zeroToNil(k.o), zeroToNil(k.n), false)
+		}
+	}
+
+	for name, ov := range o.Extensions {
+		nv, ok := n.Extensions[name]
+		if !ok {
+			d.add(path, name, ExtensionChanged, ov, nil, false)
+		} else if !reflect.DeepEqual(ov, nv) {
+			d.add(path, name, ExtensionChanged, ov, nv, false)
+		}
+	}
+	for name, nv := range n.Extensions {
+		if _, ok := o.Extensions[name]; !ok {
+			d.add(path, name, ExtensionChanged, nil, nv, false)
+		}
+	}
+}
+
+func (d *schemaDiffer) diffTypes(path string, o, n *Schema) {
+	if !sameStrings(o.Type, n.Type) {
+		// a type is narrowed if one of the old types is not accepted anymore
+		tightening := len(n.Type) > 0
+		if len(o.Type) > 0 {
+			tightening = false
+			for _, t := range o.Type {
+				if !n.Type.Contains(t) && !(t == "integer" && n.Type.Contains("number")) {
+					tightening = true
+				}
+			}
+		}
+		d.add(path, "type", TypeChanged, zeroToNil([]string(o.Type)), zeroToNil([]string(n.Type)), tightening)
+	}
+	if o.Format != n.Format {
+		d.add(path, "format", TypeChanged, emptyToNil(o.Format), emptyToNil(n.Format), n.Format != "")
+	}
+	if o.Nullable != n.Nullable {
+		d.add(path, "nullable", TypeChanged, o.Nullable, n.Nullable, o.Nullable)
+	}
+}
+
+// diffMaximum compares float bounds. tighter returns true if n is tighter
+// than o when both are set. Adding a bound is a tightening.
+func (d *schemaDiffer) diffMaximum(path, keyword string, o, n *float64, tighter func(o, n float64) bool) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.add(path, keyword, ConstraintChanged, nil, *n, true)
+	case n == nil:
+		d.add(path, keyword, ConstraintChanged, *o, nil, false)
+	case *o != *n:
+		d.add(path, keyword, ConstraintChanged, *o, *n, tighter(*o, *n))
+	}
+}
+
+// diffLimit compares integer limits, upper ones if max, lower otherwise.
+func (d *schemaDiffer) diffLimit(path, keyword string, o, n *int64, max bool) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.add(path, keyword, ConstraintChanged, nil, *n, true)
+	case n == nil:
+		d.add(path, keyword, ConstraintChanged, *o, nil, false)
+	case *o != *n:
+		d.add(path, keyword, ConstraintChanged, *o, *n, (*n < *o) == max)
+	}
+}
+
+// diffFlag compares boolean constraints, restricting values when set.
+func (d *schemaDiffer) diffFlag(path, keyword string, o, n bool) {
+	if o != n {
+		d.add(path, keyword, ConstraintChanged, o, n, n)
+	}
+}
+
+func (d *schemaDiffer) diffEnum(path string, o, n []interface{}) {
+	if reflect.DeepEqual(o, n) {
+		return
+	}
+	tightening := len(n) > 0 && len(o) == 0
+	for _, ov := range o {
+		if len(n) > 0 && !containsValue(n, ov) {
+			tightening = true
+		}
+	}
+	if !tightening && len(o) == len(n) && len(o) > 0 {
+		// same values in a different order
+		return
+	}
+	d.add(path, "enum", ConstraintChanged, zeroToNil(o), zeroToNil(n), tightening)
+}
+
+func (d *schemaDiffer) diffRequired(path string, o, n []string) {
+	if sameStrings(o, n) {
+		return
+	}
+	tightening := false
+	for _, r := range n {
+		if !StringOrArray(o).Contains(r) {
+			tightening = true
+		}
+	}
+	d.add(path, "required", ConstraintChanged, zeroToNil(o), zeroToNil(n), tightening)
+}
+
+func (d *schemaDiffer) diffProperties(path string, o, n map[string]Schema) {
+	for name, ov := range o {
+		ov := ov
+		if nv, ok := n[name]; ok {
+			d.diff(path+"/properties/"+jsonpointer.Escape(name), &ov, &nv)
+		} else {
+			d.add(path, name, PropertyRemoved, &ov, nil, false)
+		}
+	}
+	for name, nv := range n {
+		nv := nv
+		if _, ok := o[name]; !ok {
+			d.add(path, name, PropertyAdded, nil, &nv, false)
+		}
+	}
+}
+
+func (d *schemaDiffer) diffSchemaMap(path, keyword string, o, n map[string]Schema) {
+	for k, ov := range o {
+		ov := ov
+		if nv, ok := n[k]; ok {
+			d.diff(path+"/"+keyword+"/"+jsonpointer.Escape(k), &ov, &nv)
+		} else {
+			d.add(path, keyword+"/"+k, ConstraintChanged, &ov, nil, false)
+		}
+	}
+	for k, nv := range n {
+		nv := nv
+		if _, ok := o[k]; !ok {
+			d.add(path, keyword+"/"+k, ConstraintChanged, nil, &nv, true)
+		}
+	}
+}
+
+func (d *schemaDiffer) diffSchemaOrBool(path, keyword string, o, n *SchemaOrBool) {
+	if o != nil && n != nil && o.Schema != nil && n.Schema != nil {
+		d.diff(path+"/"+keyword, o.Schema, n.Schema)
+		return
+	}
+	ov, nv := schemaOrBoolValue(o), schemaOrBoolValue(n)
+	if reflect.DeepEqual(ov, nv) {
+		return
+	}
+	// from the loosest to the tightest: absent or true, a schema, false
+	rank := func(v interface{}) int {
+		switch v := v.(type) {
+		case nil:
+			return 0
+		case bool:
+			if v {
+				return 0
+			}
+			return 2
+		}
+		return 1
+	}
+	d.add(path, keyword, ConstraintChanged, ov, nv, rank(nv) > rank(ov))
+}
+
+func schemaOrBoolValue(s *SchemaOrBool) interface{} {
+	switch {
+	case s == nil:
+		return nil
+	case s.Schema != nil:
+		return s.Schema
+	}
+	return s.Allows
+}
+
+func (d *schemaDiffer) diffItems(path string, o, n *SchemaOrArray) {
+	switch {
+	case o == nil && n == nil:
+	case o != nil && n != nil && o.Schema != nil && n.Schema != nil:
+		d.diff(path+"/items", o.Schema, n.Schema)
+	case o != nil && n != nil && o.Schema == nil && n.Schema == nil:
+		d.diffSchemaList(path, "items", o.Schemas, n.Schemas, true)
+	case o == nil:
+		d.add(path, "items", ConstraintChanged, nil, n, true)
+	case n == nil:
+		d.add(path, "items", ConstraintChanged, o, nil, false)
+	default:
+		// between a single schema and tuple schemas
+		d.add(path, "items", ConstraintChanged, o, n, true)
+	}
+}
+
+// diffSchemaList compares the subschemas of a list by index. Adding schemas is
+// a tightening if additive, e.g. for allOf, removing them otherwise.
+func (d *schemaDiffer) diffSchemaList(path, keyword string, o, n []Schema, additive bool) {
+	for i := 0; i < len(o) || i < len(n); i++ {
+		key := keyword + "/" + strconv.Itoa(i)
+		switch {
+		case i >= len(o):
+			d.add(path, key, ConstraintChanged, nil, &n[i], additive)
+		case i >= len(n):
+			d.add(path, key, ConstraintChanged, &o[i], nil, !additive)
+		default:
+			d.diff(path+"/"+key, &o[i], &n[i])
+		}
+	}
+}
+
+func (d *schemaDiffer) diffSubSchema(path, keyword string, o, n *Schema) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.add(path, keyword, ConstraintChanged, nil, n, true)
+	case n == nil:
+		d.add(path, keyword, ConstraintChanged, o, nil, false)
+	default:
+		d.diff(path+"/"+keyword, o, n)
+	}
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, s := range a {
+		if !StringOrArray(b).Contains(s) {
+			return false
+		}
+	}
+	for _, s := range b {
+		if !StringOrArray(a).Contains(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, e := range values {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func emptyToNil(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// zeroToNil returns nil for the zero values of keywords, i.e. for keywords
+// not set.
+func zeroToNil(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.IsZero() || ((rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0) {
+		return nil
+	}
+	return v
+}
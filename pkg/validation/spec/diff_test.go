@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	parse := func(s string) *Schema {
+		schema := &Schema{}
+		require.NoError(t, json.Unmarshal([]byte(s), schema))
+		return schema
+	}
+	old := parse(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "maxLength": 63, "description": "the name"},
+			"replicas": {"type": "integer", "minimum": 0},
+			"mode": {"type": "string", "enum": ["a", "b"]},
+			"legacy": {"type": "string"},
+			"ports": {"type": "array", "items": {"type": "integer", "multipleOf": 2}}
+		},
+		"x-kubernetes-preserve-unknown-fields": true
+	}`)
+	updated := parse(`{
+		"type": "object",
+		"required": ["name", "replicas"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "maxLength": 253, "description": "the object name", "pattern": "^[a-z]+$"},
+			"replicas": {"type": "number", "minimum": 1},
+			"mode": {"type": "string", "enum": ["b", "a"]},
+			"ports": {"type": "array", "items": {"type": "integer", "multipleOf": 4}},
+			"labels": {"type": "object"}
+		},
+		"x-kubernetes-map-type": "atomic"
+	}`)
+
+	diff := DiffSchemas(old, updated)
+	var changes []string
+	for _, c := range diff.Changes {
+		changes = append(changes, c.String())
+	}
+	assert.Equal(t, []string{
+		"/: additionalProperties added: false (tightening)",
+		`/: property "labels" added`,
+		`/: property "legacy" removed`,
+		"/: required changed from [name] to [name replicas] (tightening)",
+		"/: x-kubernetes-map-type added: atomic",
+		"/: x-kubernetes-preserve-unknown-fields removed",
+		"/properties/name: description changed from the name to the object name",
+		"/properties/name: maxLength changed from 63 to 253",
+		"/properties/name: pattern added: ^[a-z]+$ (tightening)",
+		"/properties/ports/items: multipleOf changed from 2 to 4 (tightening)",
+		"/properties/replicas: minimum changed from 0 to 1 (tightening)",
+		"/properties/replicas: type changed from [integer] to [number]",
+	}, changes)
+
+	assert.Len(t, diff.Tightenings(), 5)
+	assert.Equal(t, SchemaChange{Path: "/properties/replicas", Keyword: "minimum", Kind: ConstraintChanged, Old: float64(0), New: float64(1), Tightening: true}, diff.Changes[10])
+	assert.Equal(t, TypeChanged, diff.Changes[11].Kind)
+
+	reverse := DiffSchemas(updated, old)
+	assert.Len(t, reverse.Tightenings(), 2, "type narrowed to integer and smaller maxLength")
+	assert.True(t, DiffSchemas(old, old).Empty())
+}
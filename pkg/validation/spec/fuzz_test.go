@@ -0,0 +1,74 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// The fuzz targets check that unmarshaling arbitrary input, e.g. aggregated
+// specs of other servers, does not panic, and that the JSON representation
+// of whatever is accepted is stable from the first round trip on.
+
+func FuzzSchemaRoundTrip(f *testing.F) {
+	f.Add([]byte(`{"type": "object", "properties": {"name": {"type": "string", "maxLength": 10}}, "required": ["name"]}`))
+	f.Add([]byte(`{"items": [{"type": "integer"}, {"$ref": "#/definitions/x"}], "additionalItems": false}`))
+	f.Add([]byte(`{"additionalProperties": {"type": "string"}, "dependencies": {"a": ["b"], "c": {"required": ["d"]}}}`))
+	f.Add([]byte(`{"items": false, "not": {"items": []}}`))
+	f.Add([]byte(`{"exclusiveMinimum": 1, "const": null, "prefixItems": [true], "x-kubernetes-list-type": "map"}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkRoundTrip(t, data, func() interface{} { return &Schema{} })
+	})
+}
+
+func FuzzSwaggerRoundTrip(f *testing.F) {
+	f.Add([]byte(`{"swagger": "2.0", "info": {"title": "t", "version": "v1"}, "paths": {"/a": {"get": {"responses": {"200": {"description": "OK"}, "default": {"$ref": "#/responses/e"}}}}}}`))
+	f.Add([]byte(`{"swagger": "2.0", "paths": {"/a/{b}": {"parameters": [{"name": "b", "in": "path", "type": "string", "required": true}], "x-a": 1}}}`))
+	f.Add([]byte(`{"definitions": {"a": {"type": "object"}}, "securityDefinitions": {"k": {"type": "apiKey", "name": "k", "in": "header"}}}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkRoundTrip(t, data, func() interface{} { return &Swagger{} })
+	})
+}
+
+// checkRoundTrip unmarshals data into newValue() and checks that marshaling
+// the result and unmarshaling it again gives the same JSON.
+func checkRoundTrip(t *testing.T, data []byte, newValue func() interface{}) {
+	v := newValue()
+	if err := json.Unmarshal(data, v); err != nil {
+		return
+	}
+	first, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal %s: %v", data, err)
+	}
+	v = newValue()
+	if err := json.Unmarshal(first, v); err != nil {
+		t.Fatalf("failed to unmarshal %s, marshaled from %s: %v", first, data, err)
+	}
+	second, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal %s: %v", first, err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("unstable round trip of %s:\n%s\n%s", data, first, second)
+	}
+}
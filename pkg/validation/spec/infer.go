@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	byteSliceType = reflect.TypeOf([]byte(nil))
+)
+
+// InferSchema builds a schema describing the Go value v using reflection.
+//
+// It is meant for tests and prototyping, where running openapi-gen is not
+// practical. The resulting schema is inlined (it has no $ref) and follows
+// encoding/json conventions:
+//   - field names come from the json struct tag, fields tagged "-" are skipped
+//     and anonymous struct fields without a json name are flattened;
+//   - non-pointer fields without the omitempty option are required;
+//   - pointer, slice and map fields without the omitempty option are
+//     nullable, as their nil values are encoded as null;
+//   - a `format:"..."` struct tag sets the format of the field;
+//   - time.Time is a date-time string and []byte is a byte (base64) string;
+//   - recursive types are cut at the first repetition with an empty schema.
+//
+// A nil v yields an empty schema, which accepts any value.
+func InferSchema(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return inferSchemaForType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func inferSchemaForType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return DateTimeProperty()
+	case byteSliceType:
+		return StrFmtProperty("byte")
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return BooleanProperty()
+	case reflect.String:
+		return StringProperty()
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return Int32Property()
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return Int64Property()
+	case reflect.Float32:
+		return Float32Property()
+	case reflect.Float64:
+		return Float64Property()
+	case reflect.Slice, reflect.Array:
+		return ArrayProperty(inferSchemaForType(t.Elem(), visiting))
+	case reflect.Map:
+		return MapProperty(inferSchemaForType(t.Elem(), visiting))
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{SchemaProps: SchemaProps{Type: []string{"object"}}}
+		inferStructFields(s, t, visiting)
+		return s
+	}
+	// interface{}, channels, funcs and the like accept anything.
+	return &Schema{}
+}
+
+func inferStructFields(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// unexported
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && ft != timeType {
+			if visiting[ft] {
+				continue
+			}
+			visiting[ft] = true
+			inferStructFields(s, ft, visiting)
+			delete(visiting, ft)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := inferSchemaForType(f.Type, visiting)
+		if format := f.Tag.Get("format"); format != "" {
+			prop.Format = format
+		}
+
+		if !hasTagOption(opts, "omitempty") {
+			switch f.Type.Kind() {
+			case reflect.Ptr:
+				prop.Nullable = true
+			case reflect.Slice, reflect.Map:
+				prop.Nullable = true
+				s.AddRequired(name)
+			default:
+				s.AddRequired(name)
+			}
+		}
+		s.SetProperty(name, *prop)
+	}
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var next string
+		if idx := strings.Index(opts, ","); idx >= 0 {
+			opts, next = opts[:idx], opts[idx+1:]
+		}
+		if opts == option {
+			return true
+		}
+		opts = next
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inferMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type inferNode struct {
+	Value    int          `json:"value"`
+	Children []*inferNode `json:"children,omitempty"`
+}
+
+type inferSample struct {
+	inferMeta `json:",inline"`
+
+	Replicas *int32    `json:"replicas"`
+	Email    string    `json:"email" format:"email"`
+	Created  time.Time `json:"created"`
+	Data     []byte    `json:"data,omitempty"`
+	Ratio    float64   `json:"ratio,omitempty"`
+	Enabled  bool      `json:"enabled"`
+	Tree     inferNode `json:"tree"`
+	Tags     []string  `json:"tags"`
+	Notes    map[string]string
+	Any      interface{}
+	Ignored  string `json:"-"`
+	private  string
+}
+
+func TestInferSchema(t *testing.T) {
+	actual := InferSchema(&inferSample{})
+
+	var expected Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["name", "email", "created", "enabled", "tree", "tags", "Notes", "Any"],
+		"properties": {
+			"name": {"type": "string"},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"replicas": {"type": "integer", "format": "int32", "nullable": true},
+			"email": {"type": "string", "format": "email"},
+			"created": {"type": "string", "format": "date-time"},
+			"data": {"type": "string", "format": "byte"},
+			"ratio": {"type": "number", "format": "double"},
+			"enabled": {"type": "boolean"},
+			"tree": {
+				"type": "object",
+				"required": ["value"],
+				"properties": {
+					"value": {"type": "integer", "format": "int64"},
+					"children": {"type": "array", "items": {}}
+				}
+			},
+			"tags": {"type": "array", "items": {"type": "string"}, "nullable": true},
+			"Notes": {"type": "object", "additionalProperties": {"type": "string"}, "nullable": true},
+			"Any": {}
+		}
+	}`), &expected))
+
+	assert.ElementsMatch(t, expected.Required, actual.Required)
+	expected.Required, actual.Required = nil, nil
+	assert.Equal(t, expected, *actual)
+}
+
+func TestInferSchemaScalars(t *testing.T) {
+	assert.Equal(t, &Schema{}, InferSchema(nil))
+	assert.Equal(t, StringProperty(), InferSchema("x"))
+	assert.Equal(t, ArrayProperty(Int64Property()), InferSchema([]int{1}))
+	assert.Equal(t, MapProperty(BooleanProperty()), InferSchema(map[string]bool{}))
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The well-known Kubernetes extensions of schemas.
+const (
+	ListTypeExtension              = "x-kubernetes-list-type"
+	ListMapKeysExtension           = "x-kubernetes-list-map-keys"
+	MapTypeExtension               = "x-kubernetes-map-type"
+	PreserveUnknownFieldsExtension = "x-kubernetes-preserve-unknown-fields"
+	ValidationsExtension           = "x-kubernetes-validations"
+)
+
+// ListType is the value of the x-kubernetes-list-type extension.
+type ListType string
+
+const (
+	ListTypeAtomic ListType = "atomic"
+	ListTypeSet    ListType = "set"
+	ListTypeMap    ListType = "map"
+)
+
+// MapType is the value of the x-kubernetes-map-type extension.
+type MapType string
+
+const (
+	MapTypeAtomic   MapType = "atomic"
+	MapTypeGranular MapType = "granular"
+)
+
+// ValidationRule is a rule of the x-kubernetes-validations extension.
+type ValidationRule struct {
+	// Rule is the CEL expression validating the values of the schema.
+	Rule string `json:"rule"`
+	// Message is the error message when the rule fails.
+	Message string `json:"message,omitempty"`
+}
+
+// ListType returns the x-kubernetes-list-type extension of the schema, or
+// "" if not set. It fails if the extension is not one of the list types.
+func (s *Schema) ListType() (ListType, error) {
+	v, ok, err := s.stringExtension(ListTypeExtension)
+	if !ok || err != nil {
+		return "", err
+	}
+	switch t := ListType(v); t {
+	case ListTypeAtomic, ListTypeSet, ListTypeMap:
+		return t, nil
+	}
+	return "", fmt.Errorf("%s: unknown list type %q", ListTypeExtension, v)
+}
+
+// SetListType sets the x-kubernetes-list-type extension, or removes it if t is "".
+func (s *Schema) SetListType(t ListType) {
+	s.setExtension(ListTypeExtension, string(t), t == "")
+}
+
+// ListMapKeys returns the x-kubernetes-list-map-keys extension of the schema,
+// or nil if not set. It fails if the extension is not a list of strings.
+func (s *Schema) ListMapKeys() ([]string, error) {
+	v, ok := s.Extensions[ListMapKeysExtension]
+	if !ok {
+		return nil, nil
+	}
+	keys, ok := s.Extensions.GetStringSlice(ListMapKeysExtension)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a list of strings, got %T", ListMapKeysExtension, v)
+	}
+	return keys, nil
+}
+
+// SetListMapKeys sets the x-kubernetes-list-map-keys extension, or removes it
+// if keys is empty.
+func (s *Schema) SetListMapKeys(keys []string) {
+	value := make([]interface{}, len(keys))
+	for i, k := range keys {
+		value[i] = k
+	}
+	s.setExtension(ListMapKeysExtension, value, len(keys) == 0)
+}
+
+// MapType returns the x-kubernetes-map-type extension of the schema, or ""
+// if not set. It fails if the extension is not one of the map types.
+func (s *Schema) MapType() (MapType, error) {
+	v, ok, err := s.stringExtension(MapTypeExtension)
+	if !ok || err != nil {
+		return "", err
+	}
+	switch t := MapType(v); t {
+	case MapTypeAtomic, MapTypeGranular:
+		return t, nil
+	}
+	return "", fmt.Errorf("%s: unknown map type %q", MapTypeExtension, v)
+}
+
+// SetMapType sets the x-kubernetes-map-type extension, or removes it if t is "".
+func (s *Schema) SetMapType(t MapType) {
+	s.setExtension(MapTypeExtension, string(t), t == "")
+}
+
+// PreserveUnknownFields returns the x-kubernetes-preserve-unknown-fields
+// extension of the schema, false if not set. It fails if the extension is
+// not a boolean.
+func (s *Schema) PreserveUnknownFields() (bool, error) {
+	v, ok := s.Extensions[PreserveUnknownFieldsExtension]
+	if !ok {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s: must be a boolean, got %T", PreserveUnknownFieldsExtension, v)
+	}
+	return b, nil
+}
+
+// SetPreserveUnknownFields sets the x-kubernetes-preserve-unknown-fields
+// extension, or removes it if preserve is false.
+func (s *Schema) SetPreserveUnknownFields(preserve bool) {
+	s.setExtension(PreserveUnknownFieldsExtension, true, !preserve)
+}
+
+// CELRules returns the rules of the x-kubernetes-validations extension of the
+// schema. It fails if the extension is not a list of rules with a non-empty
+// rule.
+func (s *Schema) CELRules() ([]ValidationRule, error) {
+	v, ok := s.Extensions[ValidationsExtension]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a list of rules, got %T", ValidationsExtension, v)
+	}
+	rules := make([]ValidationRule, 0, len(list))
+	for i, e := range list {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s[%d]: must be an object, got %T", ValidationsExtension, i, e)
+		}
+		rule, _ := m["rule"].(string)
+		if rule == "" {
+			return nil, fmt.Errorf("%s[%d].rule: must be a non-empty string", ValidationsExtension, i)
+		}
+		message, ok := m["message"].(string)
+		if _, found := m["message"]; found && !ok {
+			return nil, fmt.Errorf("%s[%d].message: must be a string", ValidationsExtension, i)
+		}
+		rules = append(rules, ValidationRule{Rule: rule, Message: message})
+	}
+	return rules, nil
+}
+
+// SetCELRules sets the x-kubernetes-validations extension, or removes it if
+// rules is empty.
+func (s *Schema) SetCELRules(rules []ValidationRule) {
+	value := make([]interface{}, len(rules))
+	for i, r := range rules {
+		rule := map[string]interface{}{"rule": r.Rule}
+		if r.Message != "" {
+			rule["message"] = r.Message
+		}
+		value[i] = rule
+	}
+	s.setExtension(ValidationsExtension, value, len(rules) == 0)
+}
+
+// stringExtension returns the value of the extension name, if set, failing
+// if it is not a string.
+func (s *Schema) stringExtension(name string) (string, bool, error) {
+	v, ok := s.Extensions[name]
+	if !ok {
+		return "", false, nil
+	}
+	str, ok := v.(string)
+	if !ok {
+		return "", false, fmt.Errorf("%s: must be a string, got %T", name, v)
+	}
+	return str, true, nil
+}
+
+func (s *Schema) setExtension(name string, value interface{}, remove bool) {
+	if remove {
+		delete(s.Extensions, strings.ToLower(name))
+		return
+	}
+	s.AddExtension(name, value)
+}
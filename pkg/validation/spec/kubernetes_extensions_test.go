@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesExtensions(t *testing.T) {
+	var s Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "array",
+		"x-kubernetes-list-type": "map",
+		"x-kubernetes-list-map-keys": ["name", "protocol"],
+		"x-kubernetes-map-type": "atomic",
+		"x-kubernetes-preserve-unknown-fields": true,
+		"x-kubernetes-validations": [{"rule": "size(self) < 10", "message": "too many"}, {"rule": "true"}]
+	}`), &s))
+
+	listType, err := s.ListType()
+	require.NoError(t, err)
+	assert.Equal(t, ListTypeMap, listType)
+	keys, err := s.ListMapKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "protocol"}, keys)
+	mapType, err := s.MapType()
+	require.NoError(t, err)
+	assert.Equal(t, MapTypeAtomic, mapType)
+	preserve, err := s.PreserveUnknownFields()
+	require.NoError(t, err)
+	assert.True(t, preserve)
+	rules, err := s.CELRules()
+	require.NoError(t, err)
+	assert.Equal(t, []ValidationRule{{Rule: "size(self) < 10", Message: "too many"}, {Rule: "true"}}, rules)
+
+	// setters produce the JSON form of the extensions
+	var built Schema
+	built.Type = StringOrArray{"array"}
+	built.SetListType(ListTypeMap)
+	built.SetListMapKeys([]string{"name", "protocol"})
+	built.SetMapType(MapTypeAtomic)
+	built.SetPreserveUnknownFields(true)
+	built.SetCELRules(rules)
+	assert.Equal(t, s, built)
+
+	built.SetListType("")
+	built.SetListMapKeys(nil)
+	built.SetMapType("")
+	built.SetPreserveUnknownFields(false)
+	built.SetCELRules(nil)
+	assert.Empty(t, built.Extensions)
+	listType, err = built.ListType()
+	assert.NoError(t, err)
+	assert.Equal(t, ListType(""), listType)
+	rules, err = built.CELRules()
+	assert.NoError(t, err)
+	assert.Nil(t, rules)
+}
+
+func TestKubernetesExtensionsErrors(t *testing.T) {
+	var s Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"x-kubernetes-list-type": "bag",
+		"x-kubernetes-list-map-keys": "name",
+		"x-kubernetes-map-type": 1,
+		"x-kubernetes-preserve-unknown-fields": "true",
+		"x-kubernetes-validations": [{"message": "no rule"}]
+	}`), &s))
+
+	_, err := s.ListType()
+	assert.EqualError(t, err, `x-kubernetes-list-type: unknown list type "bag"`)
+	_, err = s.ListMapKeys()
+	assert.EqualError(t, err, "x-kubernetes-list-map-keys: must be a list of strings, got string")
+	_, err = s.MapType()
+	assert.EqualError(t, err, "x-kubernetes-map-type: must be a string, got float64")
+	_, err = s.PreserveUnknownFields()
+	assert.EqualError(t, err, "x-kubernetes-preserve-unknown-fields: must be a boolean, got string")
+	_, err = s.CELRules()
+	assert.EqualError(t, err, "x-kubernetes-validations[0].rule: must be a non-empty string")
+}
@@ -19,7 +19,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/go-openapi/jsonpointer"
 	"github.com/go-openapi/jsonreference"
 )
 
@@ -110,6 +112,16 @@ func (r *Ref) Inherits(child Ref) (*Ref, error) {
 	return &Ref{Ref: *ref}, nil
 }
 
+// LocalName returns the unescaped name of the object the ref points to, if
+// the ref starts with prefix, e.g. "#/definitions/".
+func (r *Ref) LocalName(prefix string) (string, bool) {
+	s := r.String()
+	if !strings.HasPrefix(s, prefix) {
+		return "", false
+	}
+	return jsonpointer.Unescape(strings.TrimPrefix(s, prefix)), true
+}
+
 // NewRef creates a new instance of a ref object
 // returns an error when the reference uri is an invalid uri
 func NewRef(refURI string) (Ref, error) {
@@ -39,3 +39,13 @@ func TestCloneRef(t *testing.T) {
 
 	assert.Equal(t, `{"$ref":"#/definitions/test"}`, string(jazon))
 }
+
+func TestRefLocalName(t *testing.T) {
+	ref := MustCreateRef("#/definitions/a~1b~0c")
+	name, ok := ref.LocalName("#/definitions/")
+	assert.True(t, ok)
+	assert.Equal(t, "a/b~c", name)
+
+	_, ok = ref.LocalName("#/parameters/")
+	assert.False(t, ok)
+}
@@ -173,6 +173,9 @@ type SchemaProps struct {
 	MaxLength            *int64            `json:"maxLength,omitempty"`
 	MinLength            *int64            `json:"minLength,omitempty"`
 	Pattern              string            `json:"pattern,omitempty"`
+	ContentEncoding      string            `json:"contentEncoding,omitempty"`
+	ContentMediaType     string            `json:"contentMediaType,omitempty"`
+	ContentSchema        *Schema           `json:"contentSchema,omitempty"`
 	MaxItems             *int64            `json:"maxItems,omitempty"`
 	MinItems             *int64            `json:"minItems,omitempty"`
 	UniqueItems          bool              `json:"uniqueItems,omitempty"`
@@ -186,20 +189,96 @@ type SchemaProps struct {
 	OneOf                []Schema          `json:"oneOf,omitempty"`
 	AnyOf                []Schema          `json:"anyOf,omitempty"`
 	Not                  *Schema           `json:"not,omitempty"`
+	If                   *Schema           `json:"if,omitempty"`
+	Then                 *Schema           `json:"then,omitempty"`
+	Else                 *Schema           `json:"else,omitempty"`
 	Properties           map[string]Schema `json:"properties,omitempty"`
 	AdditionalProperties *SchemaOrBool     `json:"additionalProperties,omitempty"`
 	PatternProperties    map[string]Schema `json:"patternProperties,omitempty"`
 	Dependencies         Dependencies      `json:"dependencies,omitempty"`
+	DependentRequired    DependentRequired `json:"dependentRequired,omitempty"`
+	DependentSchemas     map[string]Schema `json:"dependentSchemas,omitempty"`
 	AdditionalItems      *SchemaOrBool     `json:"additionalItems,omitempty"`
 	Definitions          Definitions       `json:"definitions,omitempty"`
+
+	// UnevaluatedProperties and UnevaluatedItems apply to the properties and
+	// items not evaluated by the other keywords, including those of allOf,
+	// anyOf, oneOf, if/then/else and dependentSchemas subschemas.
+	UnevaluatedProperties *SchemaOrBool `json:"unevaluatedProperties,omitempty"`
+	UnevaluatedItems      *SchemaOrBool `json:"unevaluatedItems,omitempty"`
+
+	// Const, Examples and PrefixItems are keywords of JSON Schema 2020-12, the
+	// dialect of OpenAPI 3.1. PrefixItems are the schemas of the first items,
+	// Items then applies to the following items.
+	Const       interface{}   `json:"const,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"`
+	PrefixItems []Schema      `json:"prefixItems,omitempty"`
+
+	// ExclusiveMaximumValue and ExclusiveMinimumValue are the numeric
+	// exclusiveMaximum and exclusiveMinimum of JSON Schema 2020-12, making
+	// them marshal as numbers when set.
+	ExclusiveMaximumValue *float64 `json:"-"`
+	ExclusiveMinimumValue *float64 `json:"-"`
+}
+
+// exclusiveBound is the JSON form of exclusiveMaximum and exclusiveMinimum: a
+// boolean in JSON Schema draft 4 and OpenAPI 3.0, the bound in JSON Schema
+// 2020-12.
+type exclusiveBound struct {
+	Exclusive bool
+	Value     *float64
+}
+
+func newExclusiveBound(exclusive bool, value *float64) *exclusiveBound {
+	if !exclusive && value == nil {
+		return nil
+	}
+	return &exclusiveBound{Exclusive: exclusive, Value: value}
+}
+
+func (b exclusiveBound) MarshalJSON() ([]byte, error) {
+	if b.Value != nil {
+		return json.Marshal(*b.Value)
+	}
+	return json.Marshal(b.Exclusive)
+}
+
+func (b *exclusiveBound) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.Exclusive); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &b.Value)
 }
 
 // SwaggerSchemaProps are additional properties supported by swagger schemas, but not JSON-schema (draft 4)
 type SwaggerSchemaProps struct {
 	Discriminator string                 `json:"discriminator,omitempty"`
 	ReadOnly      bool                   `json:"readOnly,omitempty"`
+	WriteOnly     bool                   `json:"writeOnly,omitempty"`
 	ExternalDocs  *ExternalDocumentation `json:"externalDocs,omitempty"`
 	Example       interface{}            `json:"example,omitempty"`
+
+	// DiscriminatorMapping maps values of the discriminator property to the
+	// schemas they select, by name or reference, e.g. "#/definitions/Cat". It
+	// is the mapping of OpenAPI v3 discriminator objects, and makes
+	// Discriminator marshal as such an object when set.
+	DiscriminatorMapping map[string]string `json:"-"`
+}
+
+// discriminator is the JSON form of a discriminator: the name of the
+// discriminator property in Swagger 2.0, or an object with the property name
+// and a mapping in OpenAPI v3.
+type discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
+func (d *discriminator) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.PropertyName); err == nil {
+		return nil
+	}
+	type plain discriminator
+	return json.Unmarshal(data, (*plain)(d))
 }
 
 // Schema the schema object allows the definition of input and output data types.
@@ -406,6 +485,18 @@ func (s *Schema) AsWritable() *Schema {
 	return s
 }
 
+// AsWriteOnly flags this schema as write-only
+func (s *Schema) AsWriteOnly() *Schema {
+	s.WriteOnly = true
+	return s
+}
+
+// AsReadable flags this schema as readable (not write-only)
+func (s *Schema) AsReadable() *Schema {
+	s.WriteOnly = false
+	return s
+}
+
 // WithExample sets the example for this schema
 func (s *Schema) WithExample(example interface{}) *Schema {
 	s.Example = example
@@ -432,7 +523,21 @@ func (s *Schema) WithExternalDocs(description, url string) *Schema {
 
 // MarshalJSON marshal this to JSON
 func (s Schema) MarshalJSON() ([]byte, error) {
-	b1, err := json.Marshal(s.SchemaProps)
+	var b1 []byte
+	var err error
+	if s.ExclusiveMaximumValue != nil || s.ExclusiveMinimumValue != nil {
+		b1, err = json.Marshal(struct {
+			SchemaProps
+			ExclusiveMaximum *exclusiveBound `json:"exclusiveMaximum,omitempty"`
+			ExclusiveMinimum *exclusiveBound `json:"exclusiveMinimum,omitempty"`
+		}{
+			s.SchemaProps,
+			newExclusiveBound(s.ExclusiveMaximum, s.ExclusiveMaximumValue),
+			newExclusiveBound(s.ExclusiveMinimum, s.ExclusiveMinimumValue),
+		})
+	} else {
+		b1, err = json.Marshal(s.SchemaProps)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("schema props %v", err)
 	}
@@ -448,7 +553,15 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("schema prop %v", err)
 	}
-	b5, err := json.Marshal(s.SwaggerSchemaProps)
+	var b5 []byte
+	if len(s.DiscriminatorMapping) > 0 {
+		b5, err = json.Marshal(struct {
+			SwaggerSchemaProps
+			Discriminator discriminator `json:"discriminator"`
+		}{s.SwaggerSchemaProps, discriminator{PropertyName: s.Discriminator, Mapping: s.DiscriminatorMapping}})
+	} else {
+		b5, err = json.Marshal(s.SwaggerSchemaProps)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("common validations %v", err)
 	}
@@ -463,11 +576,25 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 	return swag.ConcatJSON(b1, b2, b3, b4, b5, b6), nil
 }
 
+// schemaJSONNames are the keywords decoded into the fields of Schema.
+var schemaJSONNames = func() map[string]bool {
+	names := map[string]bool{}
+	for _, n := range swag.DefaultJSONNameProvider.GetJSONNames(&Schema{}) {
+		names[n] = true
+	}
+	return names
+}()
+
 // UnmarshalJSON marshal this from JSON
 func (s *Schema) UnmarshalJSON(data []byte) error {
 	props := struct {
 		SchemaProps
 		SwaggerSchemaProps
+		// shadows SwaggerSchemaProps.Discriminator to accept discriminator objects
+		Discriminator discriminator `json:"discriminator,omitempty"`
+		// shadow SchemaProps.ExclusiveMaximum and ExclusiveMinimum to accept numbers
+		ExclusiveMaximum exclusiveBound `json:"exclusiveMaximum,omitempty"`
+		ExclusiveMinimum exclusiveBound `json:"exclusiveMinimum,omitempty"`
 	}{}
 	if err := json.Unmarshal(data, &props); err != nil {
 		return err
@@ -477,22 +604,40 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 		SchemaProps:        props.SchemaProps,
 		SwaggerSchemaProps: props.SwaggerSchemaProps,
 	}
-
-	var d map[string]interface{}
+	sch.Discriminator = props.Discriminator.PropertyName
+	sch.DiscriminatorMapping = props.Discriminator.Mapping
+	sch.ExclusiveMaximum, sch.ExclusiveMaximumValue = props.ExclusiveMaximum.Exclusive, props.ExclusiveMaximum.Value
+	sch.ExclusiveMinimum, sch.ExclusiveMinimumValue = props.ExclusiveMinimum.Exclusive, props.ExclusiveMinimum.Value
+
+	// only the keys are decoded, the values of $ref, $schema, extensions and
+	// unknown keywords are decoded below, not the whole schema again
+	var d map[string]json.RawMessage
 	if err := json.Unmarshal(data, &d); err != nil {
 		return err
 	}
 
-	_ = sch.Ref.fromMap(d)
-	_ = sch.Schema.fromMap(d)
-
-	delete(d, "$ref")
-	delete(d, "$schema")
-	for _, pn := range swag.DefaultJSONNameProvider.GetJSONNames(s) {
-		delete(d, pn)
+	refs := make(map[string]interface{}, 2)
+	for _, k := range []string{"$ref", "$schema"} {
+		if raw, ok := d[k]; ok {
+			var v interface{}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			refs[k] = v
+			delete(d, k)
+		}
 	}
+	_ = sch.Ref.fromMap(refs)
+	_ = sch.Schema.fromMap(refs)
 
-	for k, vv := range d {
+	for k, raw := range d {
+		if schemaJSONNames[k] {
+			continue
+		}
+		var vv interface{}
+		if err := json.Unmarshal(raw, &vv); err != nil {
+			return err
+		}
 		lk := strings.ToLower(k)
 		if strings.HasPrefix(lk, "x-") {
 			if sch.Extensions == nil {
@@ -16,9 +16,14 @@ package spec
 
 import (
 	"encoding/json"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/go-openapi/swag"
+	fuzz "github.com/google/gofuzz"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var schema = Schema{
@@ -195,9 +200,152 @@ func TestSchema(t *testing.T) {
 
 }
 
+func TestSchemaDiscriminatorMapping(t *testing.T) {
+	var actual Schema
+	if assert.NoError(t, json.Unmarshal([]byte(`{"discriminator": {"propertyName": "petType", "mapping": {"cat": "#/definitions/Cat"}}}`), &actual)) {
+		assert.Equal(t, "petType", actual.Discriminator)
+		assert.Equal(t, map[string]string{"cat": "#/definitions/Cat"}, actual.DiscriminatorMapping)
+		assert.Empty(t, actual.ExtraProps)
+	}
+	b, err := json.Marshal(actual)
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"discriminator": {"propertyName": "petType", "mapping": {"cat": "#/definitions/Cat"}}}`, string(b))
+	}
+
+	// without mapping, discriminators keep their Swagger 2.0 form
+	actual = Schema{}
+	if assert.NoError(t, json.Unmarshal([]byte(`{"discriminator": {"propertyName": "petType"}}`), &actual)) {
+		assert.Equal(t, "petType", actual.Discriminator)
+		assert.Nil(t, actual.DiscriminatorMapping)
+	}
+	b, err = json.Marshal(actual)
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"discriminator": "petType"}`, string(b))
+	}
+}
+
 func BenchmarkSchemaUnmarshal(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		sch := &Schema{}
 		_ = sch.UnmarshalJSON([]byte(schemaJSON))
 	}
 }
+
+func BenchmarkSchemaUnmarshalNested(b *testing.B) {
+	data := []byte(schemaJSON)
+	for i := 0; i < 5; i++ {
+		data = []byte(`{"type": "object", "x-depth": ` + strconv.Itoa(i) + `, "properties": {"a": ` + string(data) + `, "b": ` + string(data) + `}}`)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sch := &Schema{}
+		_ = sch.UnmarshalJSON(data)
+	}
+}
+
+// referenceSchema decodes extensions and unknown keywords from a generic
+// decoding of the whole schema, as Schema.UnmarshalJSON used to.
+type referenceSchema Schema
+
+func (s *referenceSchema) UnmarshalJSON(data []byte) error {
+	props := struct {
+		SchemaProps
+		SwaggerSchemaProps
+		Discriminator    discriminator  `json:"discriminator,omitempty"`
+		ExclusiveMaximum exclusiveBound `json:"exclusiveMaximum,omitempty"`
+		ExclusiveMinimum exclusiveBound `json:"exclusiveMinimum,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &props); err != nil {
+		return err
+	}
+	sch := Schema{
+		SchemaProps:        props.SchemaProps,
+		SwaggerSchemaProps: props.SwaggerSchemaProps,
+	}
+	sch.Discriminator = props.Discriminator.PropertyName
+	sch.DiscriminatorMapping = props.Discriminator.Mapping
+	sch.ExclusiveMaximum, sch.ExclusiveMaximumValue = props.ExclusiveMaximum.Exclusive, props.ExclusiveMaximum.Value
+	sch.ExclusiveMinimum, sch.ExclusiveMinimumValue = props.ExclusiveMinimum.Exclusive, props.ExclusiveMinimum.Value
+
+	var d map[string]interface{}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	_ = sch.Ref.fromMap(d)
+	_ = sch.Schema.fromMap(d)
+	delete(d, "$ref")
+	delete(d, "$schema")
+	for _, pn := range swag.DefaultJSONNameProvider.GetJSONNames(&sch) {
+		delete(d, pn)
+	}
+	for k, vv := range d {
+		if strings.HasPrefix(strings.ToLower(k), "x-") {
+			if sch.Extensions == nil {
+				sch.Extensions = map[string]interface{}{}
+			}
+			sch.Extensions[k] = vv
+			continue
+		}
+		if sch.ExtraProps == nil {
+			sch.ExtraProps = map[string]interface{}{}
+		}
+		sch.ExtraProps[k] = vv
+	}
+	*s = referenceSchema(sch)
+	return nil
+}
+
+func TestSchemaUnmarshalEquivalence(t *testing.T) {
+	documents := []string{
+		schemaJSON,
+		`null`,
+		`{}`,
+		`{"$ref": 1, "$schema": "http://json-schema.org/schema#", "X-Upper": [1, {"a": null}], "Type": "string", "unknown": {"type": "string"}}`,
+		`{"properties": {"a": {"$ref": "#/definitions/a", "x-a": true}}, "allOf": [{"discriminator": {"propertyName": "kind"}, "extra": 1.5}]}`,
+	}
+	f := fuzz.New().NilChance(0.3).NumElements(0, 2).MaxDepth(6).Funcs(
+		func(v *interface{}, c fuzz.Continue) {
+			switch c.Intn(3) {
+			case 0:
+				*v = c.RandString()
+			case 1:
+				*v = map[string]interface{}{"x": []interface{}{float64(c.Int31()), c.RandBool()}}
+			}
+		},
+		func(r *Ref, c fuzz.Continue) {
+			if c.RandBool() {
+				*r = MustCreateRef("#/definitions/Def" + strconv.Itoa(c.Intn(10)))
+			}
+		},
+		func(u *SchemaURL, c fuzz.Continue) {
+			if c.RandBool() {
+				*u = "http://json-schema.org/schema#"
+			}
+		},
+		func(e *Extensions, c fuzz.Continue) {
+			if c.RandBool() {
+				*e = Extensions{"x-" + strconv.Itoa(c.Intn(5)): c.RandString()}
+			}
+		},
+		func(m *map[string]interface{}, c fuzz.Continue) {
+			if c.RandBool() {
+				*m = map[string]interface{}{"extra" + strconv.Itoa(c.Intn(5)): float64(c.Int31())}
+			}
+		},
+	)
+	for i := 0; i < 200; i++ {
+		var in Schema
+		f.Fuzz(&in)
+		b, err := json.Marshal(in)
+		require.NoError(t, err)
+		documents = append(documents, string(b))
+	}
+
+	for _, doc := range documents {
+		var expected referenceSchema
+		var actual Schema
+		require.NoError(t, json.Unmarshal([]byte(doc), &expected))
+		require.NoError(t, json.Unmarshal([]byte(doc), &actual))
+		require.Equal(t, Schema(expected), actual, doc)
+	}
+}
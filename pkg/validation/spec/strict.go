@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// StrictOptions configures the detection of unknown keywords in schemas.
+//
+// +k8s:deepcopy-gen=false
+type StrictOptions struct {
+	// AllowedExtensions lists the vendor extensions (x-* keywords) that are
+	// accepted. An entry ending with "*" matches every extension with that
+	// prefix, e.g. "x-kubernetes-*". Matching is case insensitive.
+	// A nil list accepts every extension.
+	AllowedExtensions []string
+}
+
+// UnknownKeyword is a keyword found in a schema that is neither part of
+// JSON schema draft 4 nor of the Swagger additions, or an extension that is
+// not allowed.
+//
+// +k8s:deepcopy-gen=false
+type UnknownKeyword struct {
+	// Path is the JSON pointer to the schema holding the keyword, e.g. "/properties/foo".
+	Path string
+	// Keyword is the unknown keyword as written in the document.
+	Keyword string
+}
+
+func (k UnknownKeyword) String() string {
+	path := k.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: unknown keyword %q", path, k.Keyword)
+}
+
+// UnknownKeywordsError is returned by UnmarshalStrict when a schema has unknown keywords.
+//
+// +k8s:deepcopy-gen=false
+type UnknownKeywordsError struct {
+	Keywords []UnknownKeyword
+}
+
+func (e *UnknownKeywordsError) Error() string {
+	msgs := make([]string, 0, len(e.Keywords))
+	for _, k := range e.Keywords {
+		msgs = append(msgs, k.String())
+	}
+	return "schema has unknown keywords: " + strings.Join(msgs, ", ")
+}
+
+// UnmarshalStrict unmarshals data into s and fails with an *UnknownKeywordsError
+// if the schema, or any of its subschemas, has unknown keywords.
+//
+// Plain json.Unmarshal keeps unknown keywords in ExtraProps or Extensions, where
+// they are silently ignored by validation.
+func UnmarshalStrict(data []byte, s *Schema, opts StrictOptions) error {
+	if err := json.Unmarshal(data, s); err != nil {
+		return err
+	}
+	if unknown := s.UnknownKeywords(opts); len(unknown) > 0 {
+		return &UnknownKeywordsError{Keywords: unknown}
+	}
+	return nil
+}
+
+// UnknownKeywords returns the unknown keywords of the schema and all its
+// subschemas, sorted by path and keyword.
+func (s *Schema) UnknownKeywords(opts StrictOptions) []UnknownKeyword {
+	var ret []UnknownKeyword
+	collectUnknownKeywords(s, "", opts, &ret)
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Path != ret[j].Path {
+			return ret[i].Path < ret[j].Path
+		}
+		return ret[i].Keyword < ret[j].Keyword
+	})
+	return ret
+}
+
+func (o StrictOptions) allowsExtension(name string) bool {
+	if o.AllowedExtensions == nil {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, allowed := range o.AllowedExtensions {
+		allowed = strings.ToLower(allowed)
+		if strings.HasSuffix(allowed, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+		} else if name == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func collectUnknownKeywords(s *Schema, path string, opts StrictOptions, ret *[]UnknownKeyword) {
+	if s == nil {
+		return
+	}
+	for k := range s.ExtraProps {
+		*ret = append(*ret, UnknownKeyword{Path: path, Keyword: k})
+	}
+	for k := range s.Extensions {
+		if !opts.allowsExtension(k) {
+			*ret = append(*ret, UnknownKeyword{Path: path, Keyword: k})
+		}
+	}
+
+	if s.Items != nil {
+		collectUnknownKeywords(s.Items.Schema, path+"/items", opts, ret)
+		for i := range s.Items.Schemas {
+			collectUnknownKeywords(&s.Items.Schemas[i], path+"/items/"+strconv.Itoa(i), opts, ret)
+		}
+	}
+	for i := range s.PrefixItems {
+		collectUnknownKeywords(&s.PrefixItems[i], path+"/prefixItems/"+strconv.Itoa(i), opts, ret)
+	}
+	for i := range s.AllOf {
+		collectUnknownKeywords(&s.AllOf[i], path+"/allOf/"+strconv.Itoa(i), opts, ret)
+	}
+	for i := range s.OneOf {
+		collectUnknownKeywords(&s.OneOf[i], path+"/oneOf/"+strconv.Itoa(i), opts, ret)
+	}
+	for i := range s.AnyOf {
+		collectUnknownKeywords(&s.AnyOf[i], path+"/anyOf/"+strconv.Itoa(i), opts, ret)
+	}
+	collectUnknownKeywords(s.Not, path+"/not", opts, ret)
+	collectUnknownKeywords(s.ContentSchema, path+"/contentSchema", opts, ret)
+	collectUnknownKeywords(s.If, path+"/if", opts, ret)
+	collectUnknownKeywords(s.Then, path+"/then", opts, ret)
+	collectUnknownKeywords(s.Else, path+"/else", opts, ret)
+	for k, v := range s.Properties {
+		v := v
+		collectUnknownKeywords(&v, path+"/properties/"+jsonpointer.Escape(k), opts, ret)
+	}
+	if s.AdditionalProperties != nil {
+		collectUnknownKeywords(s.AdditionalProperties.Schema, path+"/additionalProperties", opts, ret)
+	}
+	for k, v := range s.PatternProperties {
+		v := v
+		collectUnknownKeywords(&v, path+"/patternProperties/"+jsonpointer.Escape(k), opts, ret)
+	}
+	for k, v := range s.Dependencies {
+		collectUnknownKeywords(v.Schema, path+"/dependencies/"+jsonpointer.Escape(k), opts, ret)
+	}
+	for k, v := range s.DependentSchemas {
+		v := v
+		collectUnknownKeywords(&v, path+"/dependentSchemas/"+jsonpointer.Escape(k), opts, ret)
+	}
+	if s.AdditionalItems != nil {
+		collectUnknownKeywords(s.AdditionalItems.Schema, path+"/additionalItems", opts, ret)
+	}
+	if s.UnevaluatedProperties != nil {
+		collectUnknownKeywords(s.UnevaluatedProperties.Schema, path+"/unevaluatedProperties", opts, ret)
+	}
+	if s.UnevaluatedItems != nil {
+		collectUnknownKeywords(s.UnevaluatedItems.Schema, path+"/unevaluatedItems", opts, ret)
+	}
+	for k, v := range s.Definitions {
+		v := v
+		collectUnknownKeywords(&v, path+"/definitions/"+jsonpointer.Escape(k), opts, ret)
+	}
+}
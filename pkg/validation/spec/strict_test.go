@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const strictSchemaJSON = `{
+	"type": "object",
+	"nullable": true,
+	"x-kubernetes-preserve-unknown-fields": true,
+	"x-custom": "value",
+	"properties": {
+		"name": {"type": "string", "maxLenght": 5},
+		"a/b": {"type": "array", "items": {"type": "integer", "exclusiveMin": 0}}
+	},
+	"additionalProperties": {"type": "string", "x-kubernetes-int-or-string": true},
+	"definitions": {
+		"Foo": {"type": "object", "requried": ["bar"]}
+	}
+}`
+
+func TestUnknownKeywords(t *testing.T) {
+	var s Schema
+	err := UnmarshalStrict([]byte(strictSchemaJSON), &s, StrictOptions{})
+	require.Error(t, err)
+	unknownErr, ok := err.(*UnknownKeywordsError)
+	require.True(t, ok)
+	assert.Equal(t, []UnknownKeyword{
+		{Path: "/definitions/Foo", Keyword: "requried"},
+		{Path: "/properties/a~1b/items", Keyword: "exclusiveMin"},
+		{Path: "/properties/name", Keyword: "maxLenght"},
+	}, unknownErr.Keywords)
+	assert.Contains(t, err.Error(), `/properties/name: unknown keyword "maxLenght"`)
+
+	// the schema is still unmarshaled
+	assert.Equal(t, StringOrArray{"object"}, s.Type)
+
+	unknown := s.UnknownKeywords(StrictOptions{AllowedExtensions: []string{"x-kubernetes-*"}})
+	assert.Contains(t, unknown, UnknownKeyword{Path: "", Keyword: "x-custom"})
+	assert.Len(t, unknown, 4)
+
+	unknown = s.UnknownKeywords(StrictOptions{AllowedExtensions: []string{}})
+	assert.Contains(t, unknown, UnknownKeyword{Path: "/additionalProperties", Keyword: "x-kubernetes-int-or-string"})
+	assert.Len(t, unknown, 6)
+}
+
+func TestUnmarshalStrictValid(t *testing.T) {
+	var s Schema
+	require.NoError(t, UnmarshalStrict([]byte(`{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"id": "foo",
+		"type": "object",
+		"discriminator": "kind",
+		"properties": {"kind": {"type": "string", "x-KUBERNETES-foo": 1}}
+	}`), &s, StrictOptions{AllowedExtensions: []string{"x-kubernetes-*"}}))
+}
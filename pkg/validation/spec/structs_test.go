@@ -73,6 +73,7 @@ func TestSerialization_SerializeJSON(t *testing.T) {
 			{SchemaProps: SchemaProps{Type: []string{"string"}}},
 		}}, "[{\"type\":\"string\"},{\"type\":\"string\"}]")
 	assertSerializeJSON(t, SchemaOrArray{}, "null")
+	assertSerializeJSON(t, SchemaOrArray{Schemas: []Schema{}}, "[]")
 }
 
 func TestSerialization_DeserializeJSON(t *testing.T) {
@@ -94,4 +95,7 @@ func TestSerialization_DeserializeJSON(t *testing.T) {
 		},
 	})
 	assertParsesJSON(t, "null", SchemaOrArray{})
+	assertParsesJSON(t, "[]", SchemaOrArray{Schemas: []Schema{}})
+	var s SchemaOrArray
+	assert.EqualError(t, json.Unmarshal([]byte("false"), &s), "only schema or array is allowed, not bool")
 }
@@ -85,6 +85,10 @@ type SwaggerProps struct {
 // Dependencies represent a dependencies property
 type Dependencies map[string]SchemaOrStringArray
 
+// DependentRequired represent a dependentRequired property: the properties
+// required when the property they are keyed by is present
+type DependentRequired map[string][]string
+
 // SchemaOrBool represents a schema or boolean value, is biased towards true for the boolean property
 type SchemaOrBool struct {
 	Allows bool
@@ -256,7 +260,7 @@ func (s *SchemaOrArray) ContainsType(name string) bool {
 
 // MarshalJSON converts this schema object or array into JSON structure
 func (s SchemaOrArray) MarshalJSON() ([]byte, error) {
-	if len(s.Schemas) > 0 {
+	if s.Schema == nil && s.Schemas != nil {
 		return json.Marshal(s.Schemas)
 	}
 	return json.Marshal(s.Schema)
@@ -266,20 +270,28 @@ func (s SchemaOrArray) MarshalJSON() ([]byte, error) {
 func (s *SchemaOrArray) UnmarshalJSON(data []byte) error {
 	var nw SchemaOrArray
 	var first byte
-	if len(data) > 1 {
+	if len(data) > 0 {
 		first = data[0]
 	}
-	if first == '{' {
+	switch first {
+	case '{':
 		var sch Schema
 		if err := json.Unmarshal(data, &sch); err != nil {
 			return err
 		}
 		nw.Schema = &sch
-	}
-	if first == '[' {
+	case '[':
 		if err := json.Unmarshal(data, &nw.Schemas); err != nil {
 			return err
 		}
+	default:
+		var single interface{}
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		if single != nil {
+			return fmt.Errorf("only schema or array is allowed, not %T", single)
+		}
 	}
 	*s = nw
 	return nil
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"encoding/json"
+
+	"k8s.io/kube-openapi/pkg/internal"
+)
+
+// UnmarshalYAML hydrates the spec from YAML, as from JSON.
+func (s *Swagger) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := internal.YAMLToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, s)
+}
+
+// MarshalYAML returns the YAML form of the spec, with the keys of its JSON
+// form.
+func (s Swagger) MarshalYAML() (interface{}, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return internal.JSONToYAML(data)
+}
+
+// UnmarshalYAML hydrates the schema from YAML, as from JSON.
+func (s *Schema) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	data, err := internal.YAMLToJSON(unmarshal)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, s)
+}
+
+// MarshalYAML returns the YAML form of the schema, with the keys of its JSON
+// form.
+func (s Schema) MarshalYAML() (interface{}, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return internal.JSONToYAML(data)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const swaggerYAML = `swagger: "2.0"
+info:
+  title: pets
+  version: v1
+  x-api-id: 42
+paths:
+  /pets:
+    get:
+      responses:
+        200:
+          description: the pets
+          schema:
+            type: array
+            items:
+              $ref: '#/definitions/Pet'
+definitions:
+  Pet:
+    type: object
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+        maxLength: 63
+    x-kubernetes-preserve-unknown-fields: true
+`
+
+func TestSwaggerYAML(t *testing.T) {
+	var swagger Swagger
+	require.NoError(t, yaml.Unmarshal([]byte(swaggerYAML), &swagger))
+
+	assert.Equal(t, float64(42), swagger.Info.Extensions["x-api-id"])
+	response := swagger.Paths.Paths["/pets"].Get.Responses.StatusCodeResponses[200]
+	assert.Equal(t, "the pets", response.Description)
+	assert.Equal(t, "#/definitions/Pet", response.Schema.Items.Schema.Ref.String())
+	pet := swagger.Definitions["Pet"]
+	require.NotNil(t, pet.AdditionalProperties)
+	assert.False(t, pet.AdditionalProperties.Allows)
+	assert.Equal(t, int64(63), *pet.Properties["name"].MaxLength)
+	assert.Equal(t, true, pet.Extensions["x-kubernetes-preserve-unknown-fields"])
+
+	out, err := yaml.Marshal(swagger)
+	require.NoError(t, err)
+	var roundTripped Swagger
+	require.NoError(t, yaml.Unmarshal(out, &roundTripped))
+	assert.Equal(t, swagger, roundTripped)
+	assert.Contains(t, string(out), "x-api-id: 42\n")
+	assert.Contains(t, string(out), "\"200\":\n")
+}
+
+func TestSchemaYAML(t *testing.T) {
+	var schema Schema
+	require.NoError(t, yaml.Unmarshal([]byte("type: object\nadditionalProperties:\n  type: integer\n"), &schema))
+	assert.Equal(t, StringOrArray{"object"}, schema.Type)
+	require.NotNil(t, schema.AdditionalProperties.Schema)
+	assert.Equal(t, StringOrArray{"integer"}, schema.AdditionalProperties.Schema.Type)
+
+	out, err := yaml.Marshal(&schema)
+	require.NoError(t, err)
+	assert.Equal(t, "additionalProperties:\n  type: integer\ntype: object\n", string(out))
+
+	assert.Error(t, yaml.Unmarshal([]byte("type: [object\n"), &schema))
+}
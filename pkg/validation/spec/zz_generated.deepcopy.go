@@ -0,0 +1,987 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package spec
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonValidations.
+func (in *CommonValidations) DeepCopy() *CommonValidations {
+	if in == nil {
+		return nil
+	}
+	out := new(CommonValidations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContactInfo) DeepCopyInto(out *ContactInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContactInfo.
+func (in *ContactInfo) DeepCopy() *ContactInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ContactInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in Definitions) DeepCopyInto(out *Definitions) {
+	{
+		in := &in
+		*out = make(Definitions, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Definitions.
+func (in Definitions) DeepCopy() Definitions {
+	if in == nil {
+		return nil
+	}
+	out := new(Definitions)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in Dependencies) DeepCopyInto(out *Dependencies) {
+	{
+		in := &in
+		*out = make(Dependencies, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependencies.
+func (in Dependencies) DeepCopy() Dependencies {
+	if in == nil {
+		return nil
+	}
+	out := new(Dependencies)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in DependentRequired) DeepCopyInto(out *DependentRequired) {
+	{
+		in := &in
+		*out = make(DependentRequired, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependentRequired.
+func (in DependentRequired) DeepCopy() DependentRequired {
+	if in == nil {
+		return nil
+	}
+	out := new(DependentRequired)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Extensions.
+func (in Extensions) DeepCopy() Extensions {
+	if in == nil {
+		return nil
+	}
+	out := new(Extensions)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDocumentation) DeepCopyInto(out *ExternalDocumentation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDocumentation.
+func (in *ExternalDocumentation) DeepCopy() *ExternalDocumentation {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDocumentation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Header) DeepCopyInto(out *Header) {
+	*out = *in
+	in.CommonValidations.DeepCopyInto(&out.CommonValidations)
+	in.SimpleSchema.DeepCopyInto(&out.SimpleSchema)
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	out.HeaderProps = in.HeaderProps
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Header.
+func (in *Header) DeepCopy() *Header {
+	if in == nil {
+		return nil
+	}
+	out := new(Header)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderProps) DeepCopyInto(out *HeaderProps) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderProps.
+func (in *HeaderProps) DeepCopy() *HeaderProps {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Info) DeepCopyInto(out *Info) {
+	*out = *in
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.InfoProps.DeepCopyInto(&out.InfoProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Info.
+func (in *Info) DeepCopy() *Info {
+	if in == nil {
+		return nil
+	}
+	out := new(Info)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfoProps) DeepCopyInto(out *InfoProps) {
+	*out = *in
+	if in.Contact != nil {
+		in, out := &in.Contact, &out.Contact
+		*out = new(ContactInfo)
+		**out = **in
+	}
+	if in.License != nil {
+		in, out := &in.License, &out.License
+		*out = new(License)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfoProps.
+func (in *InfoProps) DeepCopy() *InfoProps {
+	if in == nil {
+		return nil
+	}
+	out := new(InfoProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Items) DeepCopyInto(out *Items) {
+	*out = *in
+	in.Refable.DeepCopyInto(&out.Refable)
+	in.CommonValidations.DeepCopyInto(&out.CommonValidations)
+	in.SimpleSchema.DeepCopyInto(&out.SimpleSchema)
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Items.
+func (in *Items) DeepCopy() *Items {
+	if in == nil {
+		return nil
+	}
+	out := new(Items)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *License) DeepCopyInto(out *License) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new License.
+func (in *License) DeepCopy() *License {
+	if in == nil {
+		return nil
+	}
+	out := new(License)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Operation) DeepCopyInto(out *Operation) {
+	*out = *in
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.OperationProps.DeepCopyInto(&out.OperationProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Operation.
+func (in *Operation) DeepCopy() *Operation {
+	if in == nil {
+		return nil
+	}
+	out := new(Operation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationProps) DeepCopyInto(out *OperationProps) {
+	*out = *in
+	if in.Consumes != nil {
+		in, out := &in.Consumes, &out.Consumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Produces != nil {
+		in, out := &in.Produces, &out.Produces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schemes != nil {
+		in, out := &in.Schemes, &out.Schemes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalDocs != nil {
+		in, out := &in.ExternalDocs, &out.ExternalDocs
+		*out = new(ExternalDocumentation)
+		**out = **in
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = make([]map[string][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string][]string, len(*in))
+				for key, val := range *in {
+					var outVal []string
+					if val == nil {
+						(*out)[key] = nil
+					} else {
+						in, out := &val, &outVal
+						*out = make([]string, len(*in))
+						copy(*out, *in)
+					}
+					(*out)[key] = outVal
+				}
+			}
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]Parameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Responses != nil {
+		in, out := &in.Responses, &out.Responses
+		*out = new(Responses)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationProps.
+func (in *OperationProps) DeepCopy() *OperationProps {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParamProps) DeepCopyInto(out *ParamProps) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParamProps.
+func (in *ParamProps) DeepCopy() *ParamProps {
+	if in == nil {
+		return nil
+	}
+	out := new(ParamProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Parameter) DeepCopyInto(out *Parameter) {
+	*out = *in
+	in.Refable.DeepCopyInto(&out.Refable)
+	in.CommonValidations.DeepCopyInto(&out.CommonValidations)
+	in.SimpleSchema.DeepCopyInto(&out.SimpleSchema)
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.ParamProps.DeepCopyInto(&out.ParamProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Parameter.
+func (in *Parameter) DeepCopy() *Parameter {
+	if in == nil {
+		return nil
+	}
+	out := new(Parameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathItem) DeepCopyInto(out *PathItem) {
+	*out = *in
+	in.Refable.DeepCopyInto(&out.Refable)
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.PathItemProps.DeepCopyInto(&out.PathItemProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathItem.
+func (in *PathItem) DeepCopy() *PathItem {
+	if in == nil {
+		return nil
+	}
+	out := new(PathItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathItemProps) DeepCopyInto(out *PathItemProps) {
+	*out = *in
+	if in.Get != nil {
+		in, out := &in.Get, &out.Get
+		*out = new(Operation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Put != nil {
+		in, out := &in.Put, &out.Put
+		*out = new(Operation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = new(Operation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Delete != nil {
+		in, out := &in.Delete, &out.Delete
+		*out = new(Operation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = new(Operation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Head != nil {
+		in, out := &in.Head, &out.Head
+		*out = new(Operation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Patch != nil {
+		in, out := &in.Patch, &out.Patch
+		*out = new(Operation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]Parameter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathItemProps.
+func (in *PathItemProps) DeepCopy() *PathItemProps {
+	if in == nil {
+		return nil
+	}
+	out := new(PathItemProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Paths) DeepCopyInto(out *Paths) {
+	*out = *in
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make(map[string]PathItem, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Paths.
+func (in *Paths) DeepCopy() *Paths {
+	if in == nil {
+		return nil
+	}
+	out := new(Paths)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ref.
+func (in *Ref) DeepCopy() *Ref {
+	if in == nil {
+		return nil
+	}
+	out := new(Ref)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Refable) DeepCopyInto(out *Refable) {
+	*out = *in
+	in.Ref.DeepCopyInto(&out.Ref)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Refable.
+func (in *Refable) DeepCopy() *Refable {
+	if in == nil {
+		return nil
+	}
+	out := new(Refable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Response) DeepCopyInto(out *Response) {
+	*out = *in
+	in.Refable.DeepCopyInto(&out.Refable)
+	in.ResponseProps.DeepCopyInto(&out.ResponseProps)
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Response.
+func (in *Response) DeepCopy() *Response {
+	if in == nil {
+		return nil
+	}
+	out := new(Response)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseProps.
+func (in *ResponseProps) DeepCopy() *ResponseProps {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Responses) DeepCopyInto(out *Responses) {
+	*out = *in
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.ResponsesProps.DeepCopyInto(&out.ResponsesProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Responses.
+func (in *Responses) DeepCopy() *Responses {
+	if in == nil {
+		return nil
+	}
+	out := new(Responses)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponsesProps) DeepCopyInto(out *ResponsesProps) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(Response)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatusCodeResponses != nil {
+		in, out := &in.StatusCodeResponses, &out.StatusCodeResponses
+		*out = make(map[int]Response, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponsesProps.
+func (in *ResponsesProps) DeepCopy() *ResponsesProps {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponsesProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schema.
+func (in *Schema) DeepCopy() *Schema {
+	if in == nil {
+		return nil
+	}
+	out := new(Schema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaOrArray) DeepCopyInto(out *SchemaOrArray) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = (*in).DeepCopy()
+	}
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]Schema, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaOrArray.
+func (in *SchemaOrArray) DeepCopy() *SchemaOrArray {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaOrArray)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaOrBool) DeepCopyInto(out *SchemaOrBool) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaOrBool.
+func (in *SchemaOrBool) DeepCopy() *SchemaOrBool {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaOrBool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaOrStringArray) DeepCopyInto(out *SchemaOrStringArray) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = (*in).DeepCopy()
+	}
+	if in.Property != nil {
+		in, out := &in.Property, &out.Property
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaOrStringArray.
+func (in *SchemaOrStringArray) DeepCopy() *SchemaOrStringArray {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaOrStringArray)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaProps.
+func (in *SchemaProps) DeepCopy() *SchemaProps {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in SecurityDefinitions) DeepCopyInto(out *SecurityDefinitions) {
+	{
+		in := &in
+		*out = make(SecurityDefinitions, len(*in))
+		for key, val := range *in {
+			var outVal *SecurityScheme
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(SecurityScheme)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityDefinitions.
+func (in SecurityDefinitions) DeepCopy() SecurityDefinitions {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityDefinitions)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityScheme) DeepCopyInto(out *SecurityScheme) {
+	*out = *in
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.SecuritySchemeProps.DeepCopyInto(&out.SecuritySchemeProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityScheme.
+func (in *SecurityScheme) DeepCopy() *SecurityScheme {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityScheme)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecuritySchemeProps) DeepCopyInto(out *SecuritySchemeProps) {
+	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecuritySchemeProps.
+func (in *SecuritySchemeProps) DeepCopy() *SecuritySchemeProps {
+	if in == nil {
+		return nil
+	}
+	out := new(SecuritySchemeProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SimpleSchema.
+func (in *SimpleSchema) DeepCopy() *SimpleSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(SimpleSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in StringOrArray) DeepCopyInto(out *StringOrArray) {
+	{
+		in := &in
+		*out = make(StringOrArray, len(*in))
+		copy(*out, *in)
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StringOrArray.
+func (in StringOrArray) DeepCopy() StringOrArray {
+	if in == nil {
+		return nil
+	}
+	out := new(StringOrArray)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Swagger) DeepCopyInto(out *Swagger) {
+	*out = *in
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.SwaggerProps.DeepCopyInto(&out.SwaggerProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Swagger.
+func (in *Swagger) DeepCopy() *Swagger {
+	if in == nil {
+		return nil
+	}
+	out := new(Swagger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SwaggerProps) DeepCopyInto(out *SwaggerProps) {
+	*out = *in
+	if in.Consumes != nil {
+		in, out := &in.Consumes, &out.Consumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Produces != nil {
+		in, out := &in.Produces, &out.Produces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schemes != nil {
+		in, out := &in.Schemes, &out.Schemes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = new(Info)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = new(Paths)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Definitions != nil {
+		in, out := &in.Definitions, &out.Definitions
+		*out = make(Definitions, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]Parameter, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Responses != nil {
+		in, out := &in.Responses, &out.Responses
+		*out = make(map[string]Response, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.SecurityDefinitions != nil {
+		in, out := &in.SecurityDefinitions, &out.SecurityDefinitions
+		*out = make(SecurityDefinitions, len(*in))
+		for key, val := range *in {
+			var outVal *SecurityScheme
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(SecurityScheme)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = make([]map[string][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string][]string, len(*in))
+				for key, val := range *in {
+					var outVal []string
+					if val == nil {
+						(*out)[key] = nil
+					} else {
+						in, out := &val, &outVal
+						*out = make([]string, len(*in))
+						copy(*out, *in)
+					}
+					(*out)[key] = outVal
+				}
+			}
+		}
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]Tag, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExternalDocs != nil {
+		in, out := &in.ExternalDocs, &out.ExternalDocs
+		*out = new(ExternalDocumentation)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwaggerProps.
+func (in *SwaggerProps) DeepCopy() *SwaggerProps {
+	if in == nil {
+		return nil
+	}
+	out := new(SwaggerProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SwaggerSchemaProps.
+func (in *SwaggerSchemaProps) DeepCopy() *SwaggerSchemaProps {
+	if in == nil {
+		return nil
+	}
+	out := new(SwaggerSchemaProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Tag) DeepCopyInto(out *Tag) {
+	*out = *in
+	in.VendorExtensible.DeepCopyInto(&out.VendorExtensible)
+	in.TagProps.DeepCopyInto(&out.TagProps)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Tag.
+func (in *Tag) DeepCopy() *Tag {
+	if in == nil {
+		return nil
+	}
+	out := new(Tag)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagProps) DeepCopyInto(out *TagProps) {
+	*out = *in
+	if in.ExternalDocs != nil {
+		in, out := &in.ExternalDocs, &out.ExternalDocs
+		*out = new(ExternalDocumentation)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagProps.
+func (in *TagProps) DeepCopy() *TagProps {
+	if in == nil {
+		return nil
+	}
+	out := new(TagProps)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VendorExtensible) DeepCopyInto(out *VendorExtensible) {
+	*out = *in
+	out.Extensions = in.Extensions.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VendorExtensible.
+func (in *VendorExtensible) DeepCopy() *VendorExtensible {
+	if in == nil {
+		return nil
+	}
+	out := new(VendorExtensible)
+	in.DeepCopyInto(out)
+	return out
+}
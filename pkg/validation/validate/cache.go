@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"sync"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ValidatorCache shares what schema validators derive from schemas between
+// the SchemaValidators of the same schemas, to cut the cost of creating
+// validators in hot paths: the validators of vendor extensions registered
+// with RegisterExtensionValidator, and enum sets.
+//
+// Schemas are identified by the pointer to the schema given to
+// NewSchemaValidator, and by the location of sub-schemas in it. Cached
+// schemas must thus be long-lived and must not be modified. The validators
+// of vendor extensions are shared between concurrent validations, and must
+// be safe for concurrent use.
+//
+// A ValidatorCache is safe for concurrent use.
+type ValidatorCache struct {
+	lock  sync.Mutex
+	roots map[*spec.Schema]*compiledSchema
+}
+
+// NewValidatorCache creates an empty cache.
+func NewValidatorCache() *ValidatorCache {
+	return &ValidatorCache{roots: map[*spec.Schema]*compiledSchema{}}
+}
+
+// WithValidatorCache makes schema validators share what they derive from
+// schemas through cache.
+func WithValidatorCache(cache *ValidatorCache) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.cache = cache
+	}
+}
+
+// Len returns the number of cached root schemas.
+func (c *ValidatorCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.roots)
+}
+
+func (c *ValidatorCache) root(schema *spec.Schema) *compiledSchema {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	compiled, ok := c.roots[schema]
+	if !ok {
+		compiled = &compiledSchema{}
+		c.roots[schema] = compiled
+	}
+	return compiled
+}
+
+// compiledSchema is what validators derive from a schema, computed once.
+// Methods accept a nil receiver, which computes everything again.
+type compiledSchema struct {
+	extensionsOnce sync.Once
+	extensions     []ExtensionValidator
+
+	enumOnce sync.Once
+	enumSet  map[string]struct{}
+
+	lock     sync.Mutex
+	children map[string]*compiledSchema
+}
+
+// child returns the compiled sub-schema at key, e.g. "properties/name".
+func (c *compiledSchema) child(key string) *compiledSchema {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.children == nil {
+		c.children = map[string]*compiledSchema{}
+	}
+	child, ok := c.children[key]
+	if !ok {
+		child = &compiledSchema{}
+		c.children[key] = child
+	}
+	return child
+}
+
+func (c *compiledSchema) extensionValidators(schema *spec.Schema) []ExtensionValidator {
+	if c == nil {
+		return newExtensionValidators(schema)
+	}
+	c.extensionsOnce.Do(func() {
+		c.extensions = newExtensionValidators(schema)
+	})
+	return c.extensions
+}
+
+// stringEnumSet returns the set of the values of enum if they are all
+// strings, or nil.
+func (c *compiledSchema) stringEnumSet(enum []interface{}) map[string]struct{} {
+	if c == nil {
+		// not worth it for a single validation
+		return nil
+	}
+	c.enumOnce.Do(func() {
+		set := make(map[string]struct{}, len(enum))
+		for _, v := range enum {
+			s, ok := v.(string)
+			if !ok {
+				return
+			}
+			set[s] = struct{}{}
+		}
+		c.enumSet = set
+	})
+	return c.enumSet
+}
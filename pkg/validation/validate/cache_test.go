@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// compilations counts the validators created for x-test-counted.
+var compilations int64
+
+func init() {
+	RegisterExtensionValidator("x-test-counted", func(value interface{}, schema *spec.Schema) ExtensionValidator {
+		atomic.AddInt64(&compilations, 1)
+		return ExtensionValidatorFunc(func(path, in string, data interface{}) *Result { return nil })
+	})
+}
+
+func TestValidatorCache(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"x-test-counted": true,
+		"properties": {
+			"names": {"type": "array", "items": {"type": "string", "x-test-counted": true}},
+			"mode": {"type": "string", "enum": ["a", "b"], "allOf": [{"x-test-counted": true}]}
+		}
+	}`), schema))
+	var input interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"names": ["a", "b", "c"], "mode": "b"}`), &input))
+
+	validate := func(options ...Option) *Result {
+		return NewSchemaValidator(schema, nil, "", strfmt.Default, options...).Validate(input)
+	}
+
+	atomic.StoreInt64(&compilations, 0)
+	for i := 0; i < 3; i++ {
+		assert.True(t, validate().IsValid())
+	}
+	assert.Equal(t, int64(9), atomic.LoadInt64(&compilations))
+
+	cache := NewValidatorCache()
+	atomic.StoreInt64(&compilations, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.True(t, validate(WithValidatorCache(cache)).IsValid())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(3), atomic.LoadInt64(&compilations))
+	assert.Equal(t, 1, cache.Len())
+
+	// the cached enum set validates as the enum
+	require.NoError(t, json.Unmarshal([]byte(`{"mode": "c"}`), &input))
+	assert.Equal(t, errorStrings(validate()), errorStrings(validate(WithValidatorCache(cache))))
+	assert.Equal(t, []string{"mode in body should be one of [a b]"}, errorStrings(validate(WithValidatorCache(cache))))
+}
+
+func TestValidatorCacheEnum(t *testing.T) {
+	cache := NewValidatorCache()
+	mixed := &spec.Schema{SchemaProps: spec.SchemaProps{Enum: []interface{}{"a", int64(1)}}}
+	strings := &spec.Schema{SchemaProps: spec.SchemaProps{Enum: []interface{}{"a", "b"}}}
+	for _, schema := range []*spec.Schema{mixed, strings} {
+		for _, data := range []interface{}{"a", "b", []byte("b"), int64(1), 1, 2} {
+			expected := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(data)
+			actual := NewSchemaValidator(schema, nil, "spec", strfmt.Default, WithValidatorCache(cache)).Validate(data)
+			assert.Equal(t, errorStrings(expected), errorStrings(actual), "%v in %v", data, schema.Enum)
+		}
+	}
+	assert.Equal(t, 2, cache.Len())
+}
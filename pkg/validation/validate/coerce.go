@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"math"
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// coerce returns data with the strings whose schema does not allow strings
+// converted to the integer, number or boolean type of the schema, when they
+// can be. Objects and arrays are coerced in place.
+//
+// The schemas of values are found by properties, patternProperties,
+// additionalProperties, items and additionalItems, including in allOf
+// subschemas. anyOf, oneOf and if/then/else subschemas are not used, as
+// they may disagree.
+func coerce(schema *spec.Schema, data interface{}) interface{} {
+	if schema == nil {
+		return data
+	}
+	for i := range schema.AllOf {
+		data = coerce(&schema.AllOf[i], data)
+	}
+
+	switch v := data.(type) {
+	case string:
+		return coerceString(schema.Type, v)
+	case map[string]interface{}:
+		for k, value := range v {
+			s, matched := schema.Properties[k]
+			if matched {
+				value = coerce(&s, value)
+			}
+			for pattern, s := range schema.PatternProperties {
+				if r, err := compileRegexp(pattern); err == nil && r.MatchString(k) {
+					s := s
+					value = coerce(&s, value)
+					matched = true
+				}
+			}
+			if !matched && schema.AdditionalProperties != nil {
+				value = coerce(schema.AdditionalProperties.Schema, value)
+			}
+			v[k] = value
+		}
+	case []interface{}:
+		for i := range v {
+			switch {
+			case schema.Items != nil && schema.Items.Schema != nil:
+				v[i] = coerce(schema.Items.Schema, v[i])
+			case schema.Items != nil && i < len(schema.Items.Schemas):
+				v[i] = coerce(&schema.Items.Schemas[i], v[i])
+			case schema.AdditionalItems != nil:
+				v[i] = coerce(schema.AdditionalItems.Schema, v[i])
+			}
+		}
+	}
+	return data
+}
+
+// coerceString converts s to the first of types it is a valid value of,
+// unless strings are allowed.
+func coerceString(types spec.StringOrArray, s string) interface{} {
+	if len(types) == 0 || types.Contains(stringType) {
+		return s
+	}
+	for _, t := range types {
+		switch t {
+		case integerType:
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i
+			}
+		case numberType:
+			if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+				return f
+			}
+		case booleanType:
+			// as JSON, unlike strconv.ParseBool
+			if s == "true" || s == "false" {
+				return s == "true"
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestCoercion(t *testing.T) {
+	// scalars, e.g. query parameters
+	res := NewSchemaValidator(spec.Int64Property().WithMaximum(10, false), nil, "limit", strfmt.Default, EnableCoercion()).Validate("5")
+	assert.True(t, res.IsValid())
+	assert.Equal(t, int64(5), res.Value)
+
+	res = NewSchemaValidator(spec.Int64Property().WithMaximum(10, false), nil, "limit", strfmt.Default, EnableCoercion()).Validate("50")
+	assert.Equal(t, []string{"limit in body should be less than or equal to 10"}, errorStrings(res))
+
+	res = NewSchemaValidator(spec.Int64Property(), nil, "limit", strfmt.Default, EnableCoercion()).Validate("five")
+	assert.Equal(t, []string{`limit in body must be of type integer: "string"`}, errorStrings(res))
+	assert.Equal(t, "five", res.Value)
+
+	res = NewSchemaValidator(spec.Int64Property(), nil, "limit", strfmt.Default).Validate("5")
+	assert.False(t, res.IsValid())
+	assert.Nil(t, res.Value)
+
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"watch": {"type": "boolean"},
+			"ratio": {"type": ["number", "boolean"]},
+			"name": {"type": "string"},
+			"ports": {"type": "array", "items": {"type": "integer"}}
+		},
+		"allOf": [{"properties": {"replicas": {"type": "integer"}}}],
+		"additionalProperties": {"type": "number"}
+	}`), schema))
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"watch": "true",
+		"ratio": "0.5",
+		"name": "5",
+		"ports": ["80", "443"],
+		"replicas": "3",
+		"weight": "1e3"
+	}`), &data))
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, EnableCoercion()).Validate(data)
+	assert.Empty(t, errorStrings(res))
+	expected := map[string]interface{}{
+		"watch":    true,
+		"ratio":    0.5,
+		"name":     "5",
+		"ports":    []interface{}{int64(80), int64(443)},
+		"replicas": int64(3),
+		"weight":   float64(1000),
+	}
+	assert.Equal(t, expected, res.Value)
+	// in place
+	assert.Equal(t, expected, data)
+
+	// only JSON booleans
+	require.NoError(t, json.Unmarshal([]byte(`{"watch": "1"}`), &data))
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, EnableCoercion()).Validate(data)
+	assert.Equal(t, []string{`watch in body must be of type boolean: "string"`}, errorStrings(res))
+}
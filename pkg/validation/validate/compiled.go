@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// CompiledSchema validates data against a schema compiled once for all
+// validations: its tree of sub-schemas is walked when it is created, compiling
+// the regular expressions of patterns and patternProperties, enum sets and the
+// validators of vendor extensions registered with RegisterExtensionValidator.
+// The schema validators themselves are still created for every validation,
+// sharing these compiled parts.
+//
+// The schema must not be modified afterwards. A CompiledSchema is immutable
+// and safe for concurrent use, provided the validators of vendor extensions
+// are.
+type CompiledSchema struct {
+	validator *SchemaValidator
+}
+
+// NewCompiledSchema compiles schema for validations with formats and options.
+// It fails if schema has references, which are not supported, or invalid
+// regular expressions.
+func NewCompiledSchema(schema *spec.Schema, formats strfmt.Registry, options ...Option) (*CompiledSchema, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("schema is nil")
+	}
+	cache := NewValidatorCache()
+	if err := compileSchema(cache.root(schema), schema, ""); err != nil {
+		return nil, err
+	}
+	options = append(options[:len(options):len(options)], WithValidatorCache(cache))
+	return &CompiledSchema{validator: NewSchemaValidator(schema, nil, "", formats, options...)}, nil
+}
+
+// Validate validates data against the schema, as SchemaValidator.Validate.
+func (c *CompiledSchema) Validate(data interface{}) *Result {
+	return c.validator.Validate(data)
+}
+
+// ValidateWithContext validates data against the schema, as
+// SchemaValidator.ValidateWithContext. The validators are created anew to
+// carry ctx.
+func (c *CompiledSchema) ValidateWithContext(ctx context.Context, data interface{}) *Result {
+	return c.validator.ValidateWithContext(ctx, data)
+}
+
+// compileSchema computes compiled, the compiled schema of schema, and of all
+// its sub-schemas. path is the location of schema, for errors.
+func compileSchema(compiled *compiledSchema, schema *spec.Schema, path string) error {
+	if ref := schema.Ref.String(); ref != "" {
+		return fmt.Errorf("%s: schema references not supported: %s", pathOrRoot(path), ref)
+	}
+	if schema.Pattern != "" {
+		if _, err := compileRegexp(schema.Pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %v", pathOrRoot(path), schema.Pattern, err)
+		}
+	}
+	compiled.extensionValidators(schema)
+	if len(schema.Enum) > 0 {
+		compiled.stringEnumSet(schema.Enum)
+	}
+
+	compile := func(key string, s *spec.Schema) error {
+		if s == nil {
+			return nil
+		}
+		return compileSchema(compiled.child(key), s, path+"/"+key)
+	}
+	for name, s := range schema.Properties {
+		s := s
+		if err := compile("properties/"+name, &s); err != nil {
+			return err
+		}
+	}
+	for pattern, s := range schema.PatternProperties {
+		if _, err := compileRegexp(pattern); err != nil {
+			return fmt.Errorf("%s: invalid pattern property %q: %v", pathOrRoot(path), pattern, err)
+		}
+		s := s
+		if err := compile("patternProperties/"+pattern, &s); err != nil {
+			return err
+		}
+	}
+	for key, s := range schema.DependentSchemas {
+		s := s
+		if err := compile("dependentSchemas/"+key, &s); err != nil {
+			return err
+		}
+	}
+	for key, dep := range schema.Dependencies {
+		if err := compile("dependencies/"+key, dep.Schema); err != nil {
+			return err
+		}
+	}
+	for _, list := range []struct {
+		key     string
+		schemas []spec.Schema
+	}{{"allOf", schema.AllOf}, {"anyOf", schema.AnyOf}, {"oneOf", schema.OneOf}, {"prefixItems", schema.PrefixItems}} {
+		for i := range list.schemas {
+			if err := compile(list.key+"/"+strconv.Itoa(i), &list.schemas[i]); err != nil {
+				return err
+			}
+		}
+	}
+	if schema.Items != nil {
+		if err := compile("items", schema.Items.Schema); err != nil {
+			return err
+		}
+		for i := range schema.Items.Schemas {
+			if err := compile("items/"+strconv.Itoa(i), &schema.Items.Schemas[i]); err != nil {
+				return err
+			}
+		}
+	}
+	for key, s := range map[string]*spec.SchemaOrBool{
+		"additionalProperties":  schema.AdditionalProperties,
+		"additionalItems":       schema.AdditionalItems,
+		"unevaluatedProperties": schema.UnevaluatedProperties,
+		"unevaluatedItems":      schema.UnevaluatedItems,
+	} {
+		if s != nil {
+			if err := compile(key, s.Schema); err != nil {
+				return err
+			}
+		}
+	}
+	for key, s := range map[string]*spec.Schema{
+		"not":           schema.Not,
+		"if":            schema.If,
+		"then":          schema.Then,
+		"else":          schema.Else,
+		"contentSchema": schema.ContentSchema,
+	} {
+		if err := compile(key, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestCompiledSchema(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"x-test-counted": true,
+		"properties": {
+			"names": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$", "x-test-counted": true}},
+			"mode": {"type": "string", "enum": ["a", "b"], "allOf": [{"x-test-counted": true}]}
+		},
+		"patternProperties": {"^x-": {"x-test-counted": true}}
+	}`), schema))
+
+	atomic.StoreInt64(&compilations, 0)
+	compiled, err := NewCompiledSchema(schema, strfmt.Default, WithMaxErrors(10))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), atomic.LoadInt64(&compilations))
+
+	var input interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"names": ["a", "B"], "mode": "c", "x-a": 1}`), &input))
+	expected := errorStrings(NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input))
+	require.Len(t, expected, 2)
+
+	atomic.StoreInt64(&compilations, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.ElementsMatch(t, expected, errorStrings(compiled.Validate(input)))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(0), atomic.LoadInt64(&compilations))
+}
+
+func TestCompiledSchemaErrors(t *testing.T) {
+	_, err := NewCompiledSchema(nil, strfmt.Default)
+	assert.Error(t, err)
+
+	schema := spec.ArrayProperty(spec.StringProperty().WithPattern("^[a-z"))
+	_, err = NewCompiledSchema(schema, strfmt.Default)
+	assert.EqualError(t, err, "/items: invalid pattern \"^[a-z\": error parsing regexp: missing closing ]: `[a-z`")
+
+	schema = spec.MapProperty(spec.RefSchema("#/definitions/Pet"))
+	_, err = NewCompiledSchema(schema, strfmt.Default)
+	assert.EqualError(t, err, "/additionalProperties: schema references not supported: #/definitions/Pet")
+}
+
+func BenchmarkCompiledSchema(b *testing.B) {
+	schema := new(spec.Schema)
+	require.NoError(b, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"names": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}},
+			"mode": {"type": "string", "enum": ["a", "b", "c", "d"]},
+			"labels": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 63}}
+		},
+		"patternProperties": {"^x-": {"type": "integer"}}
+	}`), schema))
+	var input interface{}
+	require.NoError(b, json.Unmarshal([]byte(`{
+		"names": ["a", "b", "c", "d"],
+		"mode": "c",
+		"labels": {"app": "web", "tier": "frontend"},
+		"x-a": 1
+	}`), &input))
+
+	b.Run("NewSchemaValidator", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input)
+		}
+	})
+	b.Run("CompiledSchema", func(b *testing.B) {
+		compiled, err := NewCompiledSchema(schema, strfmt.Default)
+		require.NoError(b, err)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			compiled.Validate(input)
+		}
+	})
+	b.Run("CompiledSchemaWithContext", func(b *testing.B) {
+		compiled, err := NewCompiledSchema(schema, strfmt.Default)
+		require.NoError(b, err)
+		ctx := context.Background()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			compiled.ValidateWithContext(ctx, input)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"reflect"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// contentValidator validates strings holding an encoded document, as declared
+// by contentEncoding and contentMediaType.
+//
+// The base64 encoding, and the JSON media types, i.e. application/json and the
+// ones with a +json suffix, are checked. Others are not. JSON documents are
+// validated against ContentSchema when set.
+type contentValidator struct {
+	Path             string
+	In               string
+	ContentEncoding  string
+	ContentMediaType string
+	ContentSchema    *spec.Schema
+	Root             interface{}
+	KnownFormats     strfmt.Registry
+	Options          SchemaValidatorOptions
+}
+
+func (c *contentValidator) setPointer(pointer string) {
+	c.Options.pointer = pointer
+}
+
+func (c *contentValidator) SetPath(path string) {
+	c.Path = path
+}
+
+func (c *contentValidator) Applies(source interface{}, kind reflect.Kind) bool {
+	if _, ok := source.(*spec.Schema); !ok {
+		return false
+	}
+	return kind == reflect.String && (c.ContentEncoding != "" || c.ContentMediaType != "")
+}
+
+func (c *contentValidator) Validate(data interface{}) *Result {
+	result := new(Result)
+	str := reflect.ValueOf(data).String()
+
+	content := []byte(str)
+	switch strings.ToLower(c.ContentEncoding) {
+	case "":
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			result.AddErrors(errors.InvalidContentEncoding(c.Path, c.In, c.ContentEncoding, err))
+			return result
+		}
+		content = decoded
+	default:
+		// the content can't be decoded
+		return result
+	}
+
+	if !isJSONMediaType(c.ContentMediaType) {
+		return result
+	}
+	var doc interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		result.AddErrors(errors.InvalidContentMediaType(c.Path, c.In, c.ContentMediaType, err))
+		return result
+	}
+	if c.ContentSchema != nil {
+		result.Merge(NewSchemaValidator(c.ContentSchema, c.Root, c.Path, c.KnownFormats, c.Options.childOptions("contentSchema")...).Validate(doc))
+	}
+	result.Inc()
+	return result
+}
+
+func isJSONMediaType(mediaType string) bool {
+	t, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	return t == "application/json" || strings.HasSuffix(t, "+json")
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestContentValidator(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"raw": {"type": "string", "contentEncoding": "base64"},
+			"config": {
+				"type": "string",
+				"contentMediaType": "application/json",
+				"contentSchema": {"type": "object", "required": ["port"], "properties": {"port": {"type": "integer"}}}
+			},
+			"encoded": {
+				"type": "string",
+				"contentEncoding": "base64",
+				"contentMediaType": "application/merge-patch+json; charset=utf-8",
+				"contentSchema": {"type": "array"}
+			},
+			"other": {"type": "string", "contentEncoding": "quoted-printable", "contentMediaType": "text/plain"}
+		}
+	}`), schema))
+	require.NotNil(t, schema.Properties["config"].ContentSchema)
+	assert.Empty(t, schema.Properties["config"].ExtraProps)
+
+	validate := func(obj map[string]interface{}) []string {
+		return errorStrings(NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(obj))
+	}
+	assert.Empty(t, validate(map[string]interface{}{
+		"raw":     base64.StdEncoding.EncodeToString([]byte{0xff, 0}),
+		"config":  `{"port": 80}`,
+		"encoded": base64.StdEncoding.EncodeToString([]byte(`[1, 2]`)),
+		"other":   "=E2=82=AC",
+	}))
+	assert.Equal(t, []string{
+		"spec.raw in body must be base64 encoded: illegal base64 data at input byte 3",
+	}, validate(map[string]interface{}{"raw": "abc!"}))
+	assert.Equal(t, []string{
+		"spec.config in body must contain a valid application/json document: unexpected end of JSON input",
+	}, validate(map[string]interface{}{"config": `{"port": 80`}))
+	assert.Equal(t, []string{
+		"spec.config.port in body is required",
+	}, validate(map[string]interface{}{"config": `{}`}))
+	assert.Equal(t, []string{
+		`spec.config.port in body must be of type integer: "string"`,
+	}, validate(map[string]interface{}{"config": `{"port": "80"}`}))
+	assert.Equal(t, []string{
+		`spec.encoded in body must be of type array: "object"`,
+	}, validate(map[string]interface{}{"encoded": base64.StdEncoding.EncodeToString([]byte(`{}`))}))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// validateDiscriminated validates obj against the one of schemas selected by
+// the value of the discriminator property, instead of against all of them.
+// validators are the validators of schemas.
+func (s *schemaPropsValidator) validateDiscriminated(obj map[string]interface{}, schemas []spec.Schema, validators []SchemaValidator) *Result {
+	result := new(Result)
+	name := s.Discriminator
+	if s.Path != "" {
+		name = s.Path + "." + s.Discriminator
+	}
+
+	v, ok := obj[s.Discriminator]
+	if !ok {
+		result.AddErrors(errors.Required(name, s.In))
+		return result
+	}
+	value, ok := v.(string)
+	if !ok {
+		result.AddErrors(errors.InvalidType(name, s.In, stringType, v))
+		return result
+	}
+	i := discriminatedSchema(schemas, s.Discriminator, value, s.DiscriminatorMapping)
+	if i < 0 {
+		result.AddErrors(errors.UnknownDiscriminatorValue(name, s.In, value))
+		return result
+	}
+	return validators[i].Validate(obj)
+}
+
+// discriminatedSchema returns the index of the schema selected by value, the
+// value of the discriminator property, or -1.
+//
+// The schema is selected by its title: the name of the schema value is mapped
+// to, i.e. the last segment of a reference like "#/definitions/Cat", or value
+// itself without mapping. Otherwise, it is the schema restricting the
+// discriminator property to value by enum.
+func discriminatedSchema(schemas []spec.Schema, property, value string, mapping map[string]string) int {
+	name := value
+	if ref, ok := mapping[value]; ok {
+		name = ref[strings.LastIndex(ref, "/")+1:]
+	}
+	for i := range schemas {
+		if schemas[i].Title == name {
+			return i
+		}
+	}
+	for i := range schemas {
+		for _, e := range schemas[i].Properties[property].Enum {
+			if e == value {
+				return i
+			}
+		}
+	}
+	return -1
+}
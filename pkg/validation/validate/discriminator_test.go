@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestDiscriminator(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"discriminator": {"propertyName": "petType", "mapping": {"kitten": "#/definitions/Cat"}},
+		"oneOf": [
+			{"title": "Cat", "required": ["lives"], "properties": {"lives": {"type": "integer"}}},
+			{"title": "Dog", "properties": {"bark": {"type": "string"}}},
+			{"properties": {"petType": {"enum": ["fish"]}, "fins": {"type": "integer"}}}
+		]
+	}`), schema))
+
+	validate := func(input string) []string {
+		var data interface{}
+		require.NoError(t, json.Unmarshal([]byte(input), &data))
+		return errorStrings(NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(data))
+	}
+	// Dog validates any cat, but only the selected schema is evaluated
+	assert.Empty(t, validate(`{"petType": "Cat", "lives": 9}`))
+	assert.Empty(t, validate(`{"petType": "kitten", "lives": 9}`))
+	assert.Empty(t, validate(`{"petType": "fish", "fins": 2}`))
+	assert.Equal(t, []string{"spec.lives in body is required"}, validate(`{"petType": "Cat"}`))
+	assert.Equal(t, []string{`spec.bark in body must be of type string: "number"`}, validate(`{"petType": "Dog", "bark": 1}`))
+	assert.Equal(t, []string{`spec.petType in body has an unknown discriminator value "Bird"`}, validate(`{"petType": "Bird"}`))
+	assert.Equal(t, []string{"spec.petType in body is required"}, validate(`{"lives": 9}`))
+	assert.Equal(t, []string{"spec.petType in body must be of type string"}, validate(`{"petType": 1}`))
+
+	// without discriminator, all oneOf schemas are evaluated
+	schema.Discriminator = ""
+	assert.Equal(t, []string{`"spec" must validate one and only one schema (oneOf). Found 2 valid alternatives`}, validate(`{"petType": "Cat", "lives": 9}`))
+}
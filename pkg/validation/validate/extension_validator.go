@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// ExtensionValidator validates data against the vendor extension of a schema.
+type ExtensionValidator interface {
+	// Validate validates data located at path, e.g. "spec.containers.0".
+	// Errors should be named after path, and the result may be nil.
+	Validate(path, in string, data interface{}) *Result
+}
+
+// ContextExtensionValidator is an ExtensionValidator which may take long,
+// e.g. evaluating expressions, and thus should stop once ctx is done. Its
+// ValidateWithContext method is called instead of Validate when the schema
+// is validated with a context.
+type ContextExtensionValidator interface {
+	ExtensionValidator
+	ValidateWithContext(ctx context.Context, path, in string, data interface{}) *Result
+}
+
+// ExtensionValidatorFunc is a function implementing ExtensionValidator.
+type ExtensionValidatorFunc func(path, in string, data interface{}) *Result
+
+// Validate calls f.
+func (f ExtensionValidatorFunc) Validate(path, in string, data interface{}) *Result {
+	return f(path, in, data)
+}
+
+// ExtensionValidatorFactory returns the validator for the value of a vendor
+// extension of schema, or nil if the extension does not need validation.
+// It is called once per schema validator, not per validated value.
+type ExtensionValidatorFactory func(value interface{}, schema *spec.Schema) ExtensionValidator
+
+var extensionValidators = struct {
+	sync.RWMutex
+	factories map[string]ExtensionValidatorFactory
+}{factories: map[string]ExtensionValidatorFactory{}}
+
+// RegisterExtensionValidator registers the factory of the validators of the
+// vendor extension name, e.g. "x-my-keyword", which then run for every
+// schema with this extension, along with the standard validators, and whose
+// results are merged into the result of the schema validator.
+//
+// It is meant to be called from init functions, and panics if name is not
+// a vendor extension or already has a registered factory.
+func RegisterExtensionValidator(name string, factory ExtensionValidatorFactory) {
+	if !strings.HasPrefix(strings.ToLower(name), "x-") {
+		panic(fmt.Sprintf("%q is not a vendor extension", name))
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("nil factory for vendor extension %q", name))
+	}
+	extensionValidators.Lock()
+	defer extensionValidators.Unlock()
+	if _, found := extensionValidators.factories[name]; found {
+		panic(fmt.Sprintf("vendor extension %q already has a validator", name))
+	}
+	extensionValidators.factories[name] = factory
+}
+
+// extensionsValidator runs the validators of the registered vendor extensions of a schema.
+type extensionsValidator struct {
+	Path       string
+	In         string
+	Validators []ExtensionValidator
+	Ctx        context.Context
+}
+
+func newExtensionsValidator(ctx context.Context, validators []ExtensionValidator, path, in string) *extensionsValidator {
+	return &extensionsValidator{Path: path, In: in, Validators: validators, Ctx: ctx}
+}
+
+// newExtensionValidators creates the validators of the registered vendor extensions of schema.
+func newExtensionValidators(schema *spec.Schema) []ExtensionValidator {
+	if len(schema.Extensions) == 0 {
+		return nil
+	}
+
+	extensionValidators.RLock()
+	defer extensionValidators.RUnlock()
+	if len(extensionValidators.factories) == 0 {
+		return nil
+	}
+	// run validators in a stable order
+	names := make([]string, 0, len(schema.Extensions))
+	for name := range schema.Extensions {
+		if _, found := extensionValidators.factories[name]; found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var validators []ExtensionValidator
+	for _, name := range names {
+		if validator := extensionValidators.factories[name](schema.Extensions[name], schema); validator != nil {
+			validators = append(validators, validator)
+		}
+	}
+	return validators
+}
+
+func (e *extensionsValidator) SetPath(path string) {
+	e.Path = path
+}
+
+func (e *extensionsValidator) Applies(source interface{}, kind reflect.Kind) bool {
+	_, ok := source.(*spec.Schema)
+	return ok && len(e.Validators) > 0
+}
+
+func (e *extensionsValidator) Validate(data interface{}) *Result {
+	result := new(Result)
+	for _, v := range e.Validators {
+		if cv, ok := v.(ContextExtensionValidator); ok && e.Ctx != nil {
+			result.Merge(cv.ValidateWithContext(e.Ctx, e.Path, e.In, data))
+			continue
+		}
+		result.Merge(v.Validate(e.Path, e.In, data))
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// contextValidator reports whether it is called with a context.
+type contextValidator struct{}
+
+func (contextValidator) Validate(path, in string, data interface{}) *Result {
+	return &Result{Warnings: []error{errors.New(0, "%s validated without context", path)}}
+}
+
+func (contextValidator) ValidateWithContext(ctx context.Context, path, in string, data interface{}) *Result {
+	return &Result{Warnings: []error{errors.New(0, "%s validated with context", path)}}
+}
+
+func init() {
+	RegisterExtensionValidator("x-test-context", func(value interface{}, schema *spec.Schema) ExtensionValidator {
+		return contextValidator{}
+	})
+
+	// x-test-multiple-of-length requires strings whose length is a multiple of the extension value
+	RegisterExtensionValidator("x-test-multiple-of-length", func(value interface{}, schema *spec.Schema) ExtensionValidator {
+		n, ok := value.(float64)
+		if !ok || n <= 0 {
+			return nil
+		}
+		return ExtensionValidatorFunc(func(path, in string, data interface{}) *Result {
+			s, ok := data.(string)
+			if !ok || len(s)%int(n) == 0 {
+				return nil
+			}
+			return errorHelp.sErr(errors.New(errors.MultipleOfFailCode, "%s in %s length should be a multiple of %v", path, in, n))
+		})
+	})
+}
+
+func TestExtensionValidator(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"codes": {"type": "array", "items": {"type": "string", "x-test-multiple-of-length": 2}},
+			"ignored": {"type": "string", "x-test-multiple-of-length": "two"}
+		}
+	}`), schema))
+
+	var input interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"codes": ["ab", "abc", "abcd"], "ignored": "abc"}`), &input))
+
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(input)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "spec.codes.1 in body length should be a multiple of 2", res.Errors[0].Error())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"codes": ["ab"]}`), &input))
+	assert.True(t, NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(input).IsValid())
+}
+
+func TestContextExtensionValidator(t *testing.T) {
+	schema := &spec.Schema{VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{"x-test-context": true}}}
+
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate("a")
+	assert.Equal(t, "spec validated without context", res.Warnings[0].Error())
+
+	res = NewSchemaValidator(schema, nil, "spec", strfmt.Default).ValidateWithContext(context.Background(), "a")
+	assert.Equal(t, "spec validated with context", res.Warnings[0].Error())
+}
+
+func TestRegisterExtensionValidator(t *testing.T) {
+	factory := func(value interface{}, schema *spec.Schema) ExtensionValidator { return nil }
+
+	assert.Panics(t, func() { RegisterExtensionValidator("my-keyword", factory) })
+	assert.Panics(t, func() { RegisterExtensionValidator("x-my-keyword", nil) })
+	assert.Panics(t, func() { RegisterExtensionValidator("x-test-multiple-of-length", factory) })
+}
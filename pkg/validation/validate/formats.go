@@ -17,6 +17,7 @@ package validate
 import (
 	"reflect"
 
+	"k8s.io/kube-openapi/pkg/validation/errors"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
@@ -26,6 +27,8 @@ type formatValidator struct {
 	Path         string
 	In           string
 	KnownFormats strfmt.Registry
+	// WarnUnknown warns about formats missing from KnownFormats instead of ignoring them
+	WarnUnknown bool
 }
 
 func (f *formatValidator) SetPath(path string) {
@@ -39,7 +42,7 @@ func (f *formatValidator) Applies(source interface{}, kind reflect.Kind) bool {
 		}
 		switch source := source.(type) {
 		case *spec.Schema:
-			return kind == reflect.String && f.KnownFormats.ContainsName(source.Format)
+			return kind == reflect.String && (f.KnownFormats.ContainsName(source.Format) || f.WarnUnknown && source.Format != "")
 		}
 		return false
 	}
@@ -52,6 +55,11 @@ func (f *formatValidator) Validate(val interface{}) *Result {
 	result := new(Result)
 	debugLog("validating \"%v\" against format: %s", val, f.Format)
 
+	if !f.KnownFormats.ContainsName(f.Format) {
+		result.AddWarnings(errors.UnknownFormat(f.Path, f.In, f.Format, val))
+		return result
+	}
+
 	if err := FormatOf(f.Path, f.In, f.Format, val.(string), f.KnownFormats); err != nil {
 		result.AddErrors(err)
 	}
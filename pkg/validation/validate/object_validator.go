@@ -17,7 +17,9 @@ package validate
 import (
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"k8s.io/kube-openapi/pkg/validation/errors"
 	"k8s.io/kube-openapi/pkg/validation/spec"
@@ -33,15 +35,25 @@ type objectValidator struct {
 	Properties           map[string]spec.Schema
 	AdditionalProperties *spec.SchemaOrBool
 	PatternProperties    map[string]spec.Schema
-	Root                 interface{}
-	KnownFormats         strfmt.Registry
-	Options              SchemaValidatorOptions
+	DependentRequired    spec.DependentRequired
+	DependentSchemas     map[string]spec.Schema
+	// UnknownFields reports undeclared properties when AdditionalProperties is not set.
+	// The implicit properties of embedded resources are in EmbeddedResourceFields.
+	UnknownFields          UnknownFieldStrictness
+	EmbeddedResourceFields bool
+	Root                   interface{}
+	KnownFormats           strfmt.Registry
+	Options                SchemaValidatorOptions
 }
 
 func (o *objectValidator) SetPath(path string) {
 	o.Path = path
 }
 
+func (o *objectValidator) setPointer(pointer string) {
+	o.Options.pointer = pointer
+}
+
 func (o *objectValidator) Applies(source interface{}, kind reflect.Kind) bool {
 	// TODO: this should also work for structs
 	// there is a problem in the type validator where it will be unhappy about null values
@@ -69,21 +81,27 @@ func (o *objectValidator) isExample() bool {
 func (o *objectValidator) Validate(data interface{}) *Result {
 	val := data.(map[string]interface{})
 	// TODO: guard against nil data
+	res := new(Result)
+	if o.Options.direction != DirectionNone {
+		// before counting properties, as stripped properties do not count
+		o.validateDirection(val, res)
+	}
 	numKeys := int64(len(val))
 
 	if o.MinProperties != nil && numKeys < *o.MinProperties {
-		return errorHelp.sErr(errors.TooFewProperties(o.Path, o.In, *o.MinProperties))
+		return res.Merge(errorHelp.sErr(errors.TooFewProperties(o.Path, o.In, *o.MinProperties)))
 	}
 	if o.MaxProperties != nil && numKeys > *o.MaxProperties {
-		return errorHelp.sErr(errors.TooManyProperties(o.Path, o.In, *o.MaxProperties))
+		return res.Merge(errorHelp.sErr(errors.TooManyProperties(o.Path, o.In, *o.MaxProperties)))
 	}
 
-	res := new(Result)
-
 	// check validity of field names
 	if o.AdditionalProperties != nil && !o.AdditionalProperties.Allows {
 		// Case: additionalProperties: false
 		for k := range val {
+			if res.reachedMaxErrors(o.Options.maxErrors) {
+				break
+			}
 			_, regularProperty := o.Properties[k]
 			matched := false
 
@@ -96,12 +114,19 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 
 			if !regularProperty && !matched {
 				// Special properties "$schema" and "id" are ignored
-				res.AddErrors(errors.PropertyNotAllowed(o.Path, o.In, k))
+				if o.Options.propertySuggestions {
+					res.AddErrors(errors.PropertyNotAllowedWithSuggestions(o.Path, o.In, k, suggestPropertyNames(k, o.Properties)))
+				} else {
+					res.AddErrors(errors.PropertyNotAllowed(o.Path, o.In, k))
+				}
 			}
 		}
 	} else {
 		// Cases: no additionalProperties (implying: true), or additionalProperties: true, or additionalProperties: { <<schema>> }
 		for key, value := range val {
+			if res.reachedMaxErrors(o.Options.maxErrors) {
+				break
+			}
 			_, regularProperty := o.Properties[key]
 
 			// Validates property against "patternProperties" if applicable
@@ -115,10 +140,16 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 				// Cases: properties which are not regular properties and have not been matched by the PatternProperties validator
 				if o.AdditionalProperties != nil && o.AdditionalProperties.Schema != nil {
 					// AdditionalProperties as Schema
-					res.Merge(NewSchemaValidator(o.AdditionalProperties.Schema, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.Options()...).Validate(value))
+					res.Merge(NewSchemaValidator(o.AdditionalProperties.Schema, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.childValueOptions("additionalProperties", key)...).Validate(value))
 				} else if regularProperty && !(matched || succeededOnce) {
 					// TODO: this is dead code since regularProperty=false here
 					res.AddErrors(errors.FailedAllPatternProperties(o.Path, o.In, key))
+				} else if o.UnknownFields != UnknownFieldsIgnore && o.AdditionalProperties == nil && !(o.EmbeddedResourceFields && isEmbeddedResourceField(key)) {
+					if o.UnknownFields == UnknownFieldsError {
+						res.AddErrors(errors.UnknownField(o.Path, o.In, key))
+					} else {
+						res.AddWarnings(errors.UnknownField(o.Path, o.In, key))
+					}
 				}
 			}
 		}
@@ -129,16 +160,17 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 
 	// Property types:
 	// - regular Property
-	for pName, pSchema := range o.Properties {
-		rName := pName
-		if o.Path != "" {
-			rName = o.Path + "." + pName
-		}
-
-		// Recursively validates each property against its schema
-		if v, ok := val[pName]; ok {
-			r := NewSchemaValidator(&pSchema, o.Root, rName, o.KnownFormats, o.Options.Options()...).Validate(v)
-			res.Merge(r)
+	if o.Options.propertyWorkers > 1 && !o.Options.nested {
+		res.Merge(o.validatePropertiesConcurrently(val))
+	} else {
+		for pName, pSchema := range o.Properties {
+			if res.reachedMaxErrors(o.Options.maxErrors) {
+				return res
+			}
+			// Recursively validates each property against its schema
+			if v, ok := val[pName]; ok {
+				res.Merge(o.validateProperty(pName, pSchema, v))
+			}
 		}
 	}
 
@@ -152,15 +184,37 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 		}
 	}
 
+	// Check dependentRequired and dependentSchemas
+	for key, required := range o.DependentRequired {
+		if _, ok := val[key]; !ok {
+			continue
+		}
+		for _, r := range required {
+			if _, ok := val[r]; !ok {
+				res.AddErrors(errors.DependentRequired(o.Path, o.In, key, r))
+			}
+		}
+	}
+	for key, schema := range o.DependentSchemas {
+		if _, ok := val[key]; !ok {
+			continue
+		}
+		schema := schema
+		res.Merge(NewSchemaValidator(&schema, o.Root, o.Path, o.KnownFormats, o.Options.childOptions("dependentSchemas/"+key)...).Validate(val))
+	}
+
 	// Check patternProperties
 	// TODO: it looks like we have done that twice in many cases
 	for key, value := range val {
+		if res.reachedMaxErrors(o.Options.maxErrors) {
+			break
+		}
 		_, regularProperty := o.Properties[key]
 		matched, _ /*succeededOnce*/, patterns := o.validatePatternProperty(key, value, res)
 		if !regularProperty && (matched /*|| succeededOnce*/) {
 			for _, pName := range patterns {
 				if v, ok := o.PatternProperties[pName]; ok {
-					res.Merge(NewSchemaValidator(&v, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.Options()...).Validate(value))
+					res.Merge(NewSchemaValidator(&v, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.childValueOptions("patternProperties/"+pName, key)...).Validate(value))
 				}
 			}
 		}
@@ -168,6 +222,85 @@ func (o *objectValidator) Validate(data interface{}) *Result {
 	return res
 }
 
+func (o *objectValidator) validateProperty(name string, schema spec.Schema, value interface{}) *Result {
+	path := name
+	if o.Path != "" {
+		path = o.Path + "." + name
+	}
+	return NewSchemaValidator(&schema, o.Root, path, o.KnownFormats, o.Options.childValueOptions("properties/"+name, name)...).Validate(value)
+}
+
+// validatePropertiesConcurrently validates the properties of val declared in
+// Properties with up to o.Options.propertyWorkers goroutines. Results are
+// merged in the order of the property names.
+func (o *objectValidator) validatePropertiesConcurrently(val map[string]interface{}) *Result {
+	names := make([]string, 0, len(o.Properties))
+	for name := range o.Properties {
+		if _, ok := val[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	results := make([]*Result, len(names))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.Options.propertyWorkers && w < len(names); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = o.validateProperty(names[i], o.Properties[names[i]], val[names[i]])
+			}
+		}()
+	}
+	for i := range names {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	res := new(Result)
+	for _, r := range results {
+		if res.reachedMaxErrors(o.Options.maxErrors) {
+			break
+		}
+		res.Merge(r)
+	}
+	return res
+}
+
+// validateDirection rejects, or strips, the readOnly properties of requests
+// and the writeOnly properties of responses.
+func (o *objectValidator) validateDirection(val map[string]interface{}, res *Result) {
+	for name, schema := range o.Properties {
+		if _, ok := val[name]; !ok {
+			continue
+		}
+		var err *errors.Validation
+		switch {
+		case o.Options.direction == DirectionRequest && schema.ReadOnly:
+			err = errors.ReadOnlyField(o.Path, o.In, name)
+		case o.Options.direction == DirectionResponse && schema.WriteOnly:
+			err = errors.WriteOnlyField(o.Path, o.In, name)
+		default:
+			continue
+		}
+		if o.Options.stripByDirection {
+			delete(val, name)
+			res.AddWarnings(err)
+		} else {
+			res.AddErrors(err)
+		}
+	}
+}
+
+// isEmbeddedResourceField returns true for the properties every
+// x-kubernetes-embedded-resource object has, declared or not.
+func isEmbeddedResourceField(key string) bool {
+	return key == "apiVersion" || key == "kind" || key == "metadata"
+}
+
 // TODO: succeededOnce is not used anywhere
 func (o *objectValidator) validatePatternProperty(key string, value interface{}, result *Result) (bool, bool, []string) {
 	matched := false
@@ -179,7 +312,7 @@ func (o *objectValidator) validatePatternProperty(key string, value interface{},
 		if match, _ := regexp.MatchString(k, key); match {
 			patterns = append(patterns, k)
 			matched = true
-			validator := NewSchemaValidator(&sch, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.Options()...)
+			validator := NewSchemaValidator(&sch, o.Root, o.Path+"."+key, o.KnownFormats, o.Options.childValueOptions("patternProperties/"+k, key)...)
 
 			res := validator.Validate(value)
 			result.Merge(res)
@@ -194,3 +327,67 @@ func (o *objectValidator) validatePatternProperty(key string, value interface{},
 
 	return matched, succeededOnce, patterns
 }
+
+// maxPropertySuggestions is the maximum number of suggestions for a forbidden property.
+const maxPropertySuggestions = 3
+
+// suggestPropertyNames returns the declared properties closest to key, by edit
+// distance ignoring case. Properties more than a third of key's length away are
+// not considered to be typos of key.
+func suggestPropertyNames(key string, properties map[string]spec.Schema) []string {
+	maxDistance := len(key) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	lowerKey := strings.ToLower(key)
+
+	best := maxDistance + 1
+	var suggestions []string
+	for name := range properties {
+		d := editDistance(lowerKey, strings.ToLower(name))
+		switch {
+		case d < best:
+			best = d
+			suggestions = []string{name}
+		case d == best:
+			suggestions = append(suggestions, name)
+		}
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > maxPropertySuggestions {
+		suggestions = suggestions[:maxPropertySuggestions]
+	}
+	return suggestions
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
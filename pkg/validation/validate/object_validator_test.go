@@ -15,9 +15,17 @@
 package validate
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
 
 func itemsFixture() map[string]interface{} {
@@ -80,3 +88,113 @@ func TestObjectValidator_EdgeCases(t *testing.T) {
 	s.SetPath("path")
 	assert.Equal(t, "path", s.Path)
 }
+
+func TestObjectValidator_PropertySuggestions(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:                 []string{"object"},
+		AdditionalProperties: &spec.SchemaOrBool{Allows: false},
+		Properties: map[string]spec.Schema{
+			"replicas": *spec.Int64Property(),
+			"selector": *spec.StringProperty(),
+			"template": *spec.StringProperty(),
+		},
+	}}
+	input := map[string]interface{}{"replcias": 1, "Selector": "a", "zzz": "b"}
+
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(input)
+	var msgs []string
+	for _, err := range res.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		"spec.replcias in body is a forbidden property",
+		"spec.Selector in body is a forbidden property",
+		"spec.zzz in body is a forbidden property",
+	}, msgs)
+
+	res = NewSchemaValidator(schema, nil, "spec", strfmt.Default, EnablePropertySuggestions()).Validate(input)
+	msgs = nil
+	for _, err := range res.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		`spec.replcias in body is a forbidden property, did you mean "replicas"?`,
+		`spec.Selector in body is a forbidden property, did you mean "selector"?`,
+		"spec.zzz in body is a forbidden property",
+	}, msgs)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("", ""))
+	assert.Equal(t, 3, editDistance("abc", ""))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 2, editDistance("replcias", "replicas"))
+}
+
+func TestObjectValidator_Dependents(t *testing.T) {
+	schema := new(spec.Schema)
+	if err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {"kind": {"type": "string"}, "port": {"type": "integer"}, "host": {"type": "string"}},
+		"dependentRequired": {"port": ["host", "kind"]},
+		"dependentSchemas": {"kind": {"properties": {"port": {"minimum": 1024}}}}
+	}`), schema); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, spec.DependentRequired{"port": {"host", "kind"}}, schema.DependentRequired)
+	assert.Empty(t, schema.ExtraProps)
+
+	validate := func(data map[string]interface{}) []string {
+		return errorStrings(NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(data))
+	}
+	assert.Empty(t, validate(map[string]interface{}{"host": "a"}))
+	assert.Empty(t, validate(map[string]interface{}{"host": "a", "kind": "tcp", "port": 8080}))
+	assert.Equal(t, []string{"spec.host in body is required when spec.port is set"},
+		validate(map[string]interface{}{"kind": "tcp", "port": 8080}))
+	assert.Equal(t, []string{"spec.port in body should be greater than or equal to 1024"},
+		validate(map[string]interface{}{"host": "a", "kind": "tcp", "port": 80}))
+	// without kind, the dependent schema does not apply
+	assert.Equal(t, []string{"spec.kind in body is required when spec.port is set"},
+		validate(map[string]interface{}{"host": "a", "port": 80}))
+
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(map[string]interface{}{"port": 8080})
+	if assert.Len(t, res.Errors, 2) {
+		for _, err := range res.Errors {
+			assert.EqualValues(t, errors.DependentRequiredFailCode, err.(*errors.Validation).Code())
+		}
+	}
+}
+
+func TestObjectValidator_ParallelProperties(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       spec.StringOrArray{"object"},
+		Properties: map[string]spec.Schema{},
+	}}
+	input := map[string]interface{}{}
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("p%03d", i)
+		schema.Properties[name] = *spec.ArrayProperty(spec.StringProperty().WithMaxLength(2))
+		input[name] = []interface{}{"ok", "too long"}
+	}
+
+	sequential := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(input)
+	require.Len(t, sequential.Errors, 200)
+	for _, workers := range []int{2, 8, 500} {
+		res := NewSchemaValidator(schema, nil, "spec", strfmt.Default, WithParallelProperties(workers)).Validate(input)
+		expected := errorStrings(sequential)
+		sort.Strings(expected)
+		// merged in the order of the property names
+		assert.Equal(t, expected, errorStrings(res), "workers: %d", workers)
+		assert.Equal(t, sequential.MatchCount, res.MatchCount)
+	}
+
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default, WithParallelProperties(8), WithMaxErrors(5)).Validate(input)
+	assert.Equal(t, []string{
+		"spec.p000.1 in body should be at most 2 chars long",
+		"spec.p001.1 in body should be at most 2 chars long",
+		"spec.p002.1 in body should be at most 2 chars long",
+		"spec.p003.1 in body should be at most 2 chars long",
+		"spec.p004.1 in body should be at most 2 chars long",
+	}, errorStrings(res))
+	assert.True(t, res.Truncated)
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// PatchValidator checks whether a patch could violate a schema, without
+// knowing the object the patch is applied to.
+//
+// It reports the violations that are certain whatever the patched object is:
+// values of the wrong type or failing their schema, removal of required fields
+// and additions of fields that are not allowed. Constraints depending on the
+// rest of the object (e.g. minItems after removing an item) are not checked,
+// so a patch passing this validator still needs the patched object to be validated.
+//
+// The schema is expected to be expanded: $refs are not followed and accept anything.
+type PatchValidator struct {
+	Path         string
+	Schema       *spec.Schema
+	Root         interface{}
+	KnownFormats strfmt.Registry
+	options      []Option
+}
+
+// NewPatchValidator creates a validator for patches of objects described by schema.
+func NewPatchValidator(schema *spec.Schema, rootSchema interface{}, root string, formats strfmt.Registry, options ...Option) *PatchValidator {
+	if rootSchema == nil {
+		rootSchema = schema
+	}
+	return &PatchValidator{
+		Path:         root,
+		Schema:       schema,
+		Root:         rootSchema,
+		KnownFormats: formats,
+		options:      options,
+	}
+}
+
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from"`
+	Value interface{} `json:"value"`
+}
+
+// ValidateJSONPatch validates a JSON patch (RFC 6902).
+func (p *PatchValidator) ValidateJSONPatch(patch []byte) *Result {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return errorHelp.sErr(errors.New(http.StatusBadRequest, "invalid JSON patch: %v", err))
+	}
+
+	res := new(Result)
+	for _, op := range ops {
+		path, err := splitJSONPointer(op.Path)
+		if err != nil {
+			res.AddErrors(err)
+			continue
+		}
+		switch op.Op {
+		case "add", "replace":
+			res.Merge(p.validateValue(path, op.Value))
+		case "remove":
+			res.Merge(p.validateRemoval(path))
+		case "move", "copy":
+			from, err := splitJSONPointer(op.From)
+			if err != nil {
+				res.AddErrors(err)
+				continue
+			}
+			if op.Op == "move" {
+				res.Merge(p.validateRemoval(from))
+			}
+			if _, r := p.lookup(path); r != nil {
+				res.Merge(r)
+			}
+		case "test":
+		default:
+			res.AddErrors(errors.New(http.StatusBadRequest, "invalid JSON patch: unknown operation %q", op.Op))
+		}
+	}
+	return res
+}
+
+// ValidateJSONPatchWithContext validates a JSON patch as ValidateJSONPatch,
+// but stops once ctx is done, as SchemaValidator.ValidateWithContext.
+func (p *PatchValidator) ValidateJSONPatchWithContext(ctx context.Context, patch []byte) *Result {
+	return p.withContext(ctx).ValidateJSONPatch(patch)
+}
+
+// ValidateMergePatchWithContext validates a JSON merge patch as ValidateMergePatch,
+// but stops once ctx is done, as SchemaValidator.ValidateWithContext.
+func (p *PatchValidator) ValidateMergePatchWithContext(ctx context.Context, patch []byte) *Result {
+	return p.withContext(ctx).ValidateMergePatch(patch)
+}
+
+func (p *PatchValidator) withContext(ctx context.Context) *PatchValidator {
+	v := *p
+	v.options = append(append([]Option(nil), p.options...), withContext(ctx))
+	return &v
+}
+
+// ValidateMergePatch validates a JSON merge patch (RFC 7386).
+func (p *PatchValidator) ValidateMergePatch(patch []byte) *Result {
+	var data interface{}
+	if err := json.Unmarshal(patch, &data); err != nil {
+		return errorHelp.sErr(errors.New(http.StatusBadRequest, "invalid merge patch: %v", err))
+	}
+	return p.validateMerge(nil, data)
+}
+
+func (p *PatchValidator) validateMerge(path []string, data interface{}) *Result {
+	m, isMap := data.(map[string]interface{})
+	if !isMap {
+		return p.validateValue(path, data)
+	}
+	s, r := p.lookup(path)
+	if r != nil {
+		return r
+	}
+	if s == nil || !isObjectSchema(s) {
+		// the patch replaces the value entirely
+		return p.validateValue(path, data)
+	}
+
+	res := new(Result)
+	for k, v := range m {
+		child := append(path[:len(path):len(path)], k)
+		if v == nil {
+			res.Merge(p.validateRemoval(child))
+			continue
+		}
+		res.Merge(p.validateMerge(child, v))
+	}
+	return res
+}
+
+func (p *PatchValidator) validateValue(path []string, value interface{}) *Result {
+	s, r := p.lookup(path)
+	if r != nil || s == nil {
+		return r
+	}
+	return NewSchemaValidator(s, p.Root, p.fieldPath(path), p.KnownFormats, append(p.options, withoutValidatorCache())...).Validate(value)
+}
+
+func (p *PatchValidator) validateRemoval(path []string) *Result {
+	if len(path) == 0 {
+		return nil
+	}
+	parentPath, name := path[:len(path)-1], path[len(path)-1]
+	parent, r := p.lookup(parentPath)
+	if r != nil || parent == nil {
+		return r
+	}
+	for _, required := range parent.Required {
+		if required == name {
+			return errorHelp.sErr(errors.Required(p.fieldPath(path), "body"))
+		}
+	}
+	return nil
+}
+
+// lookup returns the schema of the value at path, nil if any value is accepted,
+// or a result with an error if the path is not allowed by the schema.
+func (p *PatchValidator) lookup(path []string) (*spec.Schema, *Result) {
+	s := p.Schema
+	for i, token := range path {
+		if s == nil {
+			return nil, nil
+		}
+		next, allowed := childSchema(s, token)
+		if !allowed {
+			return nil, errorHelp.sErr(errors.PropertyNotAllowed(p.fieldPath(path[:i]), "body", token))
+		}
+		s = next
+	}
+	if s != nil && s.Ref.String() != "" {
+		return nil, nil
+	}
+	return s, nil
+}
+
+func (p *PatchValidator) fieldPath(path []string) string {
+	if p.Path == "" {
+		return strings.Join(path, ".")
+	}
+	if len(path) == 0 {
+		return p.Path
+	}
+	return p.Path + "." + strings.Join(path, ".")
+}
+
+// childSchema returns the schema of the child token of a value described by s,
+// nil if any value is accepted, and false if the child is not allowed.
+func childSchema(s *spec.Schema, token string) (*spec.Schema, bool) {
+	if s.Ref.String() != "" {
+		return nil, true
+	}
+
+	if s.Items != nil && (token == "-" || isArrayIndex(token)) {
+		if s.Items.Schema != nil {
+			return s.Items.Schema, true
+		}
+		if i, err := strconv.Atoi(token); err == nil && i < len(s.Items.Schemas) {
+			return &s.Items.Schemas[i], true
+		}
+		if s.AdditionalItems != nil {
+			return s.AdditionalItems.Schema, s.AdditionalItems.Allows || s.AdditionalItems.Schema != nil
+		}
+		return nil, true
+	}
+
+	if prop, ok := s.Properties[token]; ok {
+		return &prop, true
+	}
+	for pattern, prop := range s.PatternProperties {
+		if matches, _ := regexp.MatchString(pattern, token); matches {
+			prop := prop
+			return &prop, true
+		}
+	}
+	if s.AdditionalProperties != nil {
+		if s.AdditionalProperties.Schema != nil {
+			return s.AdditionalProperties.Schema, true
+		}
+		return nil, s.AdditionalProperties.Allows
+	}
+	return nil, true
+}
+
+func isObjectSchema(s *spec.Schema) bool {
+	return s.Type.Contains("object") || len(s.Properties) > 0 || s.AdditionalProperties != nil || len(s.PatternProperties) > 0
+}
+
+func isArrayIndex(token string) bool {
+	_, err := strconv.Atoi(token)
+	return err == nil
+}
+
+// splitJSONPointer splits a JSON pointer (RFC 6901) into its unescaped tokens.
+func splitJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New(http.StatusBadRequest, "invalid JSON patch: path %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = jsonpointer.Unescape(t)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+const patchSchemaJSON = `{
+	"type": "object",
+	"properties": {
+		"spec": {
+			"type": "object",
+			"required": ["replicas"],
+			"additionalProperties": false,
+			"properties": {
+				"replicas": {"type": "integer", "minimum": 0},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"ports": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {"port": {"type": "integer"}}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func patchSchema(t *testing.T) *spec.Schema {
+	s := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(patchSchemaJSON), s))
+	return s
+}
+
+func errorStrings(res *Result) []string {
+	var ret []string
+	for _, err := range res.Errors {
+		ret = append(ret, err.Error())
+	}
+	return ret
+}
+
+func TestValidateJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected []string
+	}{
+		{
+			name:  "valid",
+			patch: `[{"op": "replace", "path": "/spec/replicas", "value": 3}, {"op": "add", "path": "/spec/ports/-", "value": {"port": 80}}, {"op": "remove", "path": "/spec/labels/app"}]`,
+		},
+		{
+			name:     "type change",
+			patch:    `[{"op": "replace", "path": "/spec/replicas", "value": "three"}]`,
+			expected: []string{"spec.replicas in body must be of type integer: \"string\""},
+		},
+		{
+			name:     "constraint violation in nested item",
+			patch:    `[{"op": "add", "path": "/spec/ports/0", "value": {"port": "http"}}]`,
+			expected: []string{"spec.ports.0.port in body must be of type integer: \"string\""},
+		},
+		{
+			name:     "required field removed",
+			patch:    `[{"op": "remove", "path": "/spec/replicas"}]`,
+			expected: []string{"spec.replicas in body is required"},
+		},
+		{
+			name:     "required field moved",
+			patch:    `[{"op": "move", "from": "/spec/replicas", "path": "/spec/labels/replicas"}]`,
+			expected: []string{"spec.replicas in body is required"},
+		},
+		{
+			name:     "unknown field",
+			patch:    `[{"op": "add", "path": "/spec/foo", "value": 1}]`,
+			expected: []string{"spec.foo in body is a forbidden property"},
+		},
+		{
+			name:  "unknown field below an open object",
+			patch: `[{"op": "add", "path": "/metadata/foo", "value": 1}]`,
+		},
+		{
+			name:     "unknown operation",
+			patch:    `[{"op": "frobnicate", "path": "/spec"}]`,
+			expected: []string{`invalid JSON patch: unknown operation "frobnicate"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewPatchValidator(patchSchema(t), nil, "", strfmt.Default).ValidateJSONPatch([]byte(tt.patch))
+			assert.ElementsMatch(t, tt.expected, errorStrings(res))
+		})
+	}
+}
+
+func TestValidateMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected []string
+	}{
+		{
+			name:  "valid",
+			patch: `{"spec": {"replicas": 2, "labels": {"app": "x", "old": null}}}`,
+		},
+		{
+			name:     "required field removed",
+			patch:    `{"spec": {"replicas": null}}`,
+			expected: []string{"spec.replicas in body is required"},
+		},
+		{
+			name:     "invalid values",
+			patch:    `{"spec": {"replicas": -1, "labels": {"app": 1}, "bar": true}}`,
+			expected: []string{"spec.replicas in body should be greater than or equal to 0", "spec.labels.app in body must be of type string: \"number\"", "spec.bar in body is a forbidden property"},
+		},
+		{
+			name:     "arrays are replaced",
+			patch:    `{"spec": {"ports": [{"port": true}]}}`,
+			expected: []string{"spec.ports.0.port in body must be of type integer: \"boolean\""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := NewPatchValidator(patchSchema(t), nil, "", strfmt.Default).ValidateMergePatch([]byte(tt.patch))
+			assert.ElementsMatch(t, tt.expected, errorStrings(res))
+		})
+	}
+}
+
+func TestValidatePatchWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	v := NewPatchValidator(patchSchema(t), nil, "", strfmt.Default)
+
+	patch := []byte(`[{"op": "replace", "path": "/spec/replicas", "value": "three"}]`)
+	assert.Equal(t, []string{"spec.replicas in body must be of type integer: \"string\""}, errorStrings(v.ValidateJSONPatchWithContext(ctx, patch)))
+	merge := []byte(`{"spec": {"replicas": "three"}}`)
+	assert.Equal(t, []string{"spec.replicas in body must be of type integer: \"string\""}, errorStrings(v.ValidateMergePatchWithContext(ctx, merge)))
+
+	cancel()
+	assert.Equal(t, []error{context.Canceled}, v.ValidateJSONPatchWithContext(ctx, patch).Errors)
+	assert.Equal(t, []error{context.Canceled}, v.ValidateMergePatchWithContext(ctx, merge).Errors)
+	// the validator is not changed
+	assert.Len(t, v.ValidateJSONPatch(patch).Errors, 1)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/validation/errors"
+)
+
+// pointerSetter is implemented by the validators of keywords validating the
+// value, or its properties and items, against subschemas, which need the JSON
+// pointer of the value for the validators of the subschemas.
+type pointerSetter interface {
+	setPointer(pointer string)
+}
+
+// childPointer returns the JSON pointer of the property or item token of the
+// value at pointer.
+func childPointer(pointer, token string) string {
+	return pointer + "/" + jsonpointer.Escape(token)
+}
+
+// setJSONPointers sets the Pointer of the validation errors of result
+// reported for the value at path, whose JSON pointer is pointer: the errors
+// named path, and those named after a property of the value, e.g. missing
+// required properties. The errors of the subschemas of properties and items
+// have their pointers set by their own validators.
+func setJSONPointers(result *Result, path, pointer string) {
+	for _, errs := range [][]error{result.Errors, result.Warnings} {
+		for _, err := range errs {
+			setJSONPointer(err, path, pointer)
+		}
+	}
+}
+
+func setJSONPointer(err error, path, pointer string) {
+	switch e := err.(type) {
+	case *errors.Validation:
+		if e.Pointer != "" {
+			return
+		}
+		switch {
+		case e.Name == path:
+			e.Pointer = pointer
+		case path == "":
+			// properties of the root are named with a leading dot, e.g. ".kind"
+			e.Pointer = childPointer(pointer, strings.TrimPrefix(e.Name, "."))
+		case strings.HasPrefix(e.Name, path+"."):
+			e.Pointer = childPointer(pointer, e.Name[len(path)+1:])
+		}
+	case *errors.CompositeError:
+		for _, nested := range e.Errors {
+			setJSONPointer(nested, path, pointer)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestSchemaValidator_JSONPointers(t *testing.T) {
+	schema := &spec.Schema{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["kind"],
+		"properties": {
+			"labels": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 3}},
+			"items": {
+				"type": "array",
+				"x-kubernetes-list-type": "map",
+				"x-kubernetes-list-map-keys": ["name"],
+				"items": {"type": "object", "properties": {"name": {"type": "string"}, "size": {"type": "integer"}}}
+			},
+			"choice": {"anyOf": [{"type": "integer"}, {"type": "string", "minLength": 2}]}
+		}
+	}`), schema))
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"labels": {"example.com/owner": "somebody"},
+		"items": [{"name": "a", "size": 1}, {"name": "b", "size": "big"}],
+		"choice": "c"
+	}`), &data))
+
+	pointers := func(res *Result) []string {
+		var ret []string
+		var collect func(err error)
+		collect = func(err error) {
+			switch e := err.(type) {
+			case *errors.Validation:
+				ret = append(ret, e.Pointer)
+			case *errors.CompositeError:
+				for _, nested := range e.Errors {
+					collect(nested)
+				}
+			}
+		}
+		for _, err := range res.Errors {
+			collect(err)
+		}
+		sort.Strings(ret)
+		return ret
+	}
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default, EnableJSONPointers(), EnableListMapKeyPaths()).Validate(data)
+	assert.Equal(t, []string{"/choice", "/items/1/size", "/kind", "/labels/example.com~1owner"}, pointers(res))
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(data)
+	for _, p := range pointers(res) {
+		assert.Empty(t, p, "pointers are only set when enabled")
+	}
+}
+
+// jsonPointers returns the sorted pointers of the validation errors of res.
+func jsonPointers(res *Result) []string {
+	var ret []string
+	var collect func(err error)
+	collect = func(err error) {
+		switch e := err.(type) {
+		case *errors.Validation:
+			ret = append(ret, e.Pointer)
+		case *errors.CompositeError:
+			for _, nested := range e.Errors {
+				collect(nested)
+			}
+		}
+	}
+	for _, err := range res.Errors {
+		collect(err)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestSchemaValidator_JSONPointersTypedData(t *testing.T) {
+	schema := &spec.Schema{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"c": {"type": "array", "items": {"type": "object", "properties": {"i": {"type": "integer"}}}},
+			"l": {"type": "array", "items": {"type": "string", "maxLength": 1}},
+			"m": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 1}}
+		}
+	}`), schema))
+	data := map[string]interface{}{
+		"c": []map[string]interface{}{{"i": 1}, {"i": "x"}},
+		"l": []string{"a", "bc"},
+		"m": map[string]string{"a.b": "cd", "e[0]": "f"},
+	}
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default, EnableJSONPointers()).Validate(data)
+	assert.Equal(t, []string{"/c/1/i", "/l/1", "/m/a.b"}, jsonPointers(res))
+}
+
+func TestSchemaValidator_JSONPointersAmbiguousNames(t *testing.T) {
+	schema := &spec.Schema{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["missing.name"],
+		"properties": {
+			"a.b": {"type": "object", "properties": {"c": {"type": "integer"}}},
+			"a": {"type": "object", "properties": {"b.c": {"type": "string"}}},
+			"x[0]": {"type": "integer"},
+			"x": {"type": "array", "items": {"type": "integer"}},
+			"p/q~r": {"type": "integer"},
+			"items": {
+				"type": "array",
+				"x-kubernetes-list-type": "map",
+				"x-kubernetes-list-map-keys": ["name"],
+				"items": {"type": "object", "required": ["size"], "properties": {"name": {"type": "string"}, "size": {"type": "integer"}}}
+			}
+		}
+	}`), schema))
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"a.b": {"c": "1"},
+		"a": {"b.c": 2},
+		"x[0]": "3",
+		"x": ["4"],
+		"p/q~r": "5",
+		"items": [{"name": "a]b.c", "size": 1}, {"name": "d=e,f"}]
+	}`), &data))
+
+	// the errors of "a.b"/"c" and "a"/"b.c", and of "x[0]" and "x"/0, have the same dotted paths
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default, EnableJSONPointers(), EnableListMapKeyPaths()).Validate(data)
+	assert.Equal(t, []string{"/a.b/c", "/a/b.c", "/items/1/size", "/missing.name", "/p~1q~0r", "/x/0", "/x[0]"}, jsonPointers(res))
+}
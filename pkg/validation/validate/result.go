@@ -16,6 +16,7 @@ package validate
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"k8s.io/kube-openapi/pkg/validation/errors"
@@ -37,6 +38,14 @@ type Result struct {
 	Errors     []error
 	Warnings   []error
 	MatchCount int
+
+	// Truncated is set when validation stopped at the error budget of
+	// WithMaxErrors, so that errors may be missing.
+	Truncated bool
+
+	// Value is the validated data after coercion, set by
+	// SchemaValidator.Validate with EnableCoercion.
+	Value interface{}
 }
 
 // Merge merges this result with the other one(s), preserving match counts etc.
@@ -46,6 +55,7 @@ func (r *Result) Merge(others ...*Result) *Result {
 			r.AddErrors(other.Errors...)
 			r.AddWarnings(other.Warnings...)
 			r.MatchCount += other.MatchCount
+			r.Truncated = r.Truncated || other.Truncated
 		}
 	}
 	return r
@@ -60,6 +70,7 @@ func (r *Result) MergeAsErrors(others ...*Result) *Result {
 			r.AddErrors(other.Errors...)
 			r.AddErrors(other.Warnings...)
 			r.MatchCount += other.MatchCount
+			r.Truncated = r.Truncated || other.Truncated
 		}
 	}
 	return r
@@ -74,6 +85,7 @@ func (r *Result) MergeAsWarnings(others ...*Result) *Result {
 			r.AddWarnings(other.Errors...)
 			r.AddWarnings(other.Warnings...)
 			r.MatchCount += other.MatchCount
+			r.Truncated = r.Truncated || other.Truncated
 		}
 	}
 	return r
@@ -119,6 +131,38 @@ func (r *Result) AddWarnings(warnings ...error) {
 	}
 }
 
+// reachedMaxErrors returns true, and sets Truncated, if r has at least max
+// errors, when max is not zero. Validators check it to stop validating,
+// see WithMaxErrors.
+func (r *Result) reachedMaxErrors(max int) bool {
+	if max <= 0 || len(r.Errors) < max {
+		return false
+	}
+	r.Truncated = true
+	return true
+}
+
+// truncate limits the errors of r to max, when max is not zero.
+func (r *Result) truncate(max int) {
+	if max > 0 && len(r.Errors) > max {
+		r.Errors = r.Errors[:max]
+		r.Truncated = true
+	}
+}
+
+// localize rewrites the messages of the errors and warnings of r with
+// catalog, if not nil.
+func (r *Result) localize(catalog errors.MessageCatalog) {
+	if catalog == nil {
+		return
+	}
+	for _, errs := range [][]error{r.Errors, r.Warnings} {
+		for i, err := range errs {
+			errs[i] = errors.Localize(err, catalog)
+		}
+	}
+}
+
 func (r *Result) keepRelevantErrors() *Result {
 	// TODO: this one is going to disapear...
 	// keepRelevantErrors strips a result from standard errors and keeps
@@ -170,6 +214,29 @@ func (r *Result) HasErrors() bool {
 	return !r.IsValid()
 }
 
+// UnknownFields returns the sorted paths of the unknown fields reported as
+// errors or warnings, see WithUnknownFields.
+func (r *Result) UnknownFields() []string {
+	if r == nil {
+		return nil
+	}
+	var paths []string
+	for _, errs := range [][]error{r.Errors, r.Warnings} {
+		for _, err := range errs {
+			if e, ok := err.(*errors.Validation); ok && e.Code() == errors.UnknownFieldCode {
+				key, _ := e.Value.(string)
+				if e.Name == "" {
+					paths = append(paths, key)
+				} else {
+					paths = append(paths, e.Name+"."+key)
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // HasWarnings returns true when this result contains warnings.
 //
 // Returns false on a nil *Result.
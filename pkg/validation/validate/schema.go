@@ -15,6 +15,7 @@
 package validate
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -39,6 +40,10 @@ type SchemaValidator struct {
 	Root         interface{}
 	KnownFormats strfmt.Registry
 	Options      SchemaValidatorOptions
+
+	// structuralErrors are the violations of the structural schema
+	// constraints by Schema, if required by Options.
+	structuralErrors []error
 }
 
 // AgainstSchema validates the specified data against the provided schema, using a registry of supported formats.
@@ -52,6 +57,12 @@ func AgainstSchema(schema *spec.Schema, data interface{}, formats strfmt.Registr
 	return nil
 }
 
+// AgainstSchemaWithContext validates data as AgainstSchema, but stops once ctx
+// is done, returning an error including ctx.Err().
+func AgainstSchemaWithContext(ctx context.Context, schema *spec.Schema, data interface{}, formats strfmt.Registry, options ...Option) error {
+	return AgainstSchema(schema, data, formats, append(options, withContext(ctx))...)
+}
+
 // NewSchemaValidator creates a new schema validator.
 //
 // Panics if the provided schema is invalid.
@@ -78,6 +89,9 @@ func NewSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string
 	for _, o := range options {
 		o(&s.Options)
 	}
+	if s.Options.cache != nil && s.Options.compiled == nil && !s.Options.nested {
+		s.Options.compiled = s.Options.cache.root(schema)
+	}
 	s.validators = []valueValidator{
 		s.typeValidator(),
 		s.schemaPropsValidator(),
@@ -87,13 +101,35 @@ func NewSchemaValidator(schema *spec.Schema, rootSchema interface{}, root string
 		s.sliceValidator(),
 		s.commonValidator(),
 		s.objectValidator(),
+		newExtensionsValidator(s.Options.ctx, s.Options.compiled.extensionValidators(schema), s.Path, s.in),
+		s.unevaluatedValidator(),
+		s.contentValidator(),
+	}
+	if s.Options.structural && !s.Options.nested {
+		s.structuralErrors = ValidateStructuralSchema(schema, "").Errors
 	}
 	return &s
 }
 
-// SetPath sets the path for this schema validator
+// SetPath sets the path for this schema validator and the validators of its
+// keywords, so that a validator reused for several values, e.g. the items of
+// an array, reports errors at the path of each value.
 func (s *SchemaValidator) SetPath(path string) {
 	s.Path = path
+	for _, v := range s.validators {
+		v.SetPath(path)
+	}
+}
+
+// setPointer sets the JSON pointer of the validated value, as SetPath the
+// path, for JSON pointers to be set on errors.
+func (s *SchemaValidator) setPointer(pointer string) {
+	s.Options.pointer = pointer
+	for _, v := range s.validators {
+		if ps, ok := v.(pointerSetter); ok {
+			ps.setPointer(pointer)
+		}
+	}
 }
 
 // Applies returns true when this schema validator applies
@@ -104,11 +140,62 @@ func (s *SchemaValidator) Applies(source interface{}, kind reflect.Kind) bool {
 
 // Validate validates the data against the schema
 func (s *SchemaValidator) Validate(data interface{}) *Result {
+	if s != nil && s.Options.tracer != nil {
+		s.Options.tracer.EnterSchema(s.Path, s.Schema)
+	}
+	if s != nil && s.Options.coercion && !s.Options.nested {
+		data = coerce(s.Schema, data)
+	}
+	result := s.validate(data)
+	if s != nil && s.Options.jsonPointers {
+		setJSONPointers(result, s.Path, s.Options.pointer)
+	}
+	if s != nil {
+		// not named after data paths, so added after JSON pointers are set
+		result.AddErrors(s.structuralErrors...)
+		if !s.Options.nested {
+			result.truncate(s.Options.maxErrors)
+			result.localize(s.Options.catalog)
+			if s.Options.coercion {
+				result.Value = data
+			}
+		}
+		if s.Options.tracer != nil {
+			s.Options.tracer.ExitSchema(s.Path, result)
+		}
+	}
+	return result
+}
+
+// ValidateWithContext validates data as Validate, but stops once ctx is done.
+// The values left unvalidated are then reported by a single ctx.Err() error.
+// Context is checked before validating each value, so a single value taking
+// long to validate, e.g. a long string with a pattern, is not interrupted.
+func (s *SchemaValidator) ValidateWithContext(ctx context.Context, data interface{}) *Result {
+	if s == nil {
+		return new(Result)
+	}
+	v := NewSchemaValidator(s.Schema, s.Root, s.Path, s.KnownFormats, func(o *SchemaValidatorOptions) {
+		*o = s.Options
+		o.ctx = ctx
+	})
+	v.in = s.in
+	return v.Validate(data)
+}
+
+func (s *SchemaValidator) validate(data interface{}) *Result {
 	result := new(Result)
 	if s == nil {
 		return result
 	}
 
+	if s.Options.ctx != nil {
+		if err := s.Options.ctx.Err(); err != nil {
+			result.AddErrors(err)
+			return result
+		}
+	}
+
 	if data == nil {
 		result.Merge(s.validators[0].Validate(data)) // type validator
 		result.Merge(s.validators[6].Validate(data)) // common validator
@@ -123,11 +210,11 @@ func (s *SchemaValidator) Validate(data interface{}) *Result {
 	}
 	d := data
 
-	if kind == reflect.Struct {
+	if _, ok := data.(map[string]interface{}); kind == reflect.Struct || (kind == reflect.Map && !ok) {
 		// NOTE: since reflect retrieves the true nature of types
 		// this means that all strfmt types passed here (e.g. strfmt.Datetime, etc..)
 		// are converted here to strings, and structs are systematically converted
-		// to map[string]interface{}.
+		// to map[string]interface{}, as are maps of other types.
 		d = swag.ToDynamicJSON(data)
 	}
 
@@ -158,6 +245,9 @@ func (s *SchemaValidator) Validate(data interface{}) *Result {
 	}
 
 	for _, v := range s.validators {
+		if result.reachedMaxErrors(s.Options.maxErrors) {
+			break
+		}
 		if !v.Applies(s.Schema, kind) {
 			debugLog("%T does not apply for %v", v, kind)
 			continue
@@ -177,13 +267,22 @@ func (s *SchemaValidator) typeValidator() valueValidator {
 
 func (s *SchemaValidator) commonValidator() valueValidator {
 	return &basicCommonValidator{
-		Path: s.Path,
-		In:   s.in,
-		Enum: s.Schema.Enum,
+		Path:    s.Path,
+		In:      s.in,
+		Enum:    s.Schema.Enum,
+		Const:   s.Schema.Const,
+		enumSet: s.Options.compiled.stringEnumSet(s.Schema.Enum),
 	}
 }
 
 func (s *SchemaValidator) sliceValidator() valueValidator {
+	var listMapKeys []string
+	if s.Options.listMapKeyPaths {
+		if listType, _ := s.Schema.ListType(); listType == spec.ListTypeMap {
+			listMapKeys, _ = s.Schema.ListMapKeys()
+		}
+	}
+	uniqueFields, _ := s.Schema.Extensions.GetStringSlice("x-kubernetes-unique-fields")
 	return &schemaSliceValidator{
 		Path:            s.Path,
 		In:              s.in,
@@ -192,6 +291,9 @@ func (s *SchemaValidator) sliceValidator() valueValidator {
 		UniqueItems:     s.Schema.UniqueItems,
 		AdditionalItems: s.Schema.AdditionalItems,
 		Items:           s.Schema.Items,
+		PrefixItems:     s.Schema.PrefixItems,
+		ListMapKeys:     listMapKeys,
+		UniqueFields:    uniqueFields,
 		Root:            s.Root,
 		KnownFormats:    s.KnownFormats,
 		Options:         s.Options,
@@ -204,10 +306,14 @@ func (s *SchemaValidator) numberValidator() valueValidator {
 		In:               s.in,
 		Default:          s.Schema.Default,
 		MultipleOf:       s.Schema.MultipleOf,
+		ExactMultipleOf:  s.Options.exactMultipleOf,
 		Maximum:          s.Schema.Maximum,
 		ExclusiveMaximum: s.Schema.ExclusiveMaximum,
 		Minimum:          s.Schema.Minimum,
 		ExclusiveMinimum: s.Schema.ExclusiveMinimum,
+
+		ExclusiveMaximumValue: s.Schema.ExclusiveMaximumValue,
+		ExclusiveMinimumValue: s.Schema.ExclusiveMinimumValue,
 	}
 }
 
@@ -227,26 +333,70 @@ func (s *SchemaValidator) formatValidator() valueValidator {
 		In:           s.in,
 		Format:       s.Schema.Format,
 		KnownFormats: s.KnownFormats,
+		WarnUnknown:  s.Options.warnings,
 	}
 }
 
 func (s *SchemaValidator) schemaPropsValidator() valueValidator {
 	sch := s.Schema
-	return newSchemaPropsValidator(s.Path, s.in, sch.AllOf, sch.OneOf, sch.AnyOf, sch.Not, sch.Dependencies, s.Root, s.KnownFormats, s.Options.Options()...)
+	v := newSchemaPropsValidator(s.Path, s.in, sch.AllOf, sch.OneOf, sch.AnyOf, sch.Not, sch.If, sch.Then, sch.Else, sch.Dependencies, s.Root, s.KnownFormats, s.Options.childOptions("")...)
+	v.Discriminator = sch.Discriminator
+	v.DiscriminatorMapping = sch.DiscriminatorMapping
+	return v
 }
 
 func (s *SchemaValidator) objectValidator() valueValidator {
+	unknownFields := s.Options.unknownFields
+	if !s.Options.unknownFieldsSet && s.Options.warnings {
+		unknownFields = UnknownFieldsWarn
+	}
+	if len(s.Schema.Properties) == 0 || len(s.Schema.PatternProperties) > 0 {
+		unknownFields = UnknownFieldsIgnore
+	} else if preserve, _ := s.Schema.PreserveUnknownFields(); preserve {
+		unknownFields = UnknownFieldsIgnore
+	}
+	embedded, _ := s.Schema.Extensions.GetBool("x-kubernetes-embedded-resource")
 	return &objectValidator{
-		Path:                 s.Path,
-		In:                   s.in,
-		MaxProperties:        s.Schema.MaxProperties,
-		MinProperties:        s.Schema.MinProperties,
-		Required:             s.Schema.Required,
-		Properties:           s.Schema.Properties,
-		AdditionalProperties: s.Schema.AdditionalProperties,
-		PatternProperties:    s.Schema.PatternProperties,
-		Root:                 s.Root,
-		KnownFormats:         s.KnownFormats,
-		Options:              s.Options,
+		Path:                   s.Path,
+		In:                     s.in,
+		MaxProperties:          s.Schema.MaxProperties,
+		MinProperties:          s.Schema.MinProperties,
+		Required:               s.Schema.Required,
+		Properties:             s.Schema.Properties,
+		AdditionalProperties:   s.Schema.AdditionalProperties,
+		PatternProperties:      s.Schema.PatternProperties,
+		DependentRequired:      s.Schema.DependentRequired,
+		DependentSchemas:       s.Schema.DependentSchemas,
+		UnknownFields:          unknownFields,
+		EmbeddedResourceFields: embedded,
+		Root:                   s.Root,
+		KnownFormats:           s.KnownFormats,
+		Options:                s.Options,
+	}
+}
+
+func (s *SchemaValidator) unevaluatedValidator() valueValidator {
+	return &unevaluatedValidator{
+		Path:                  s.Path,
+		In:                    s.in,
+		Schema:                s.Schema,
+		UnevaluatedProperties: s.Schema.UnevaluatedProperties,
+		UnevaluatedItems:      s.Schema.UnevaluatedItems,
+		Root:                  s.Root,
+		KnownFormats:          s.KnownFormats,
+		Options:               s.Options,
+	}
+}
+
+func (s *SchemaValidator) contentValidator() valueValidator {
+	return &contentValidator{
+		Path:             s.Path,
+		In:               s.in,
+		ContentEncoding:  s.Schema.ContentEncoding,
+		ContentMediaType: s.Schema.ContentMediaType,
+		ContentSchema:    s.Schema.ContentSchema,
+		Root:             s.Root,
+		KnownFormats:     s.KnownFormats,
+		Options:          s.Options,
 	}
 }
@@ -44,6 +44,12 @@ const (
 
 	// MustNotValidateSchemaError indicates that in a Not construct, the schema constraint specified was verified
 	MustNotValidateSchemaError = "%q must not validate the schema (not)"
+
+	// MustValidateThenSchemaError indicates that in an If construct, the if schema was verified, but not the then schema
+	MustValidateThenSchemaError = "%q must validate the then schema, as it validates the if schema"
+
+	// MustValidateElseSchemaError indicates that in an If construct, neither the if schema nor the else schema were verified
+	MustValidateElseSchemaError = "%q must validate the else schema, as it does not validate the if schema"
 )
 
 // Warning messages related to schema validation and returned as results
@@ -64,6 +70,12 @@ func mustValidateAllSchemasMsg(path, additionalMsg string) errors.Error {
 func mustNotValidatechemaMsg(path string) errors.Error {
 	return errors.New(errors.CompositeErrorCode, MustNotValidateSchemaError, path)
 }
+func mustValidateThenSchemaMsg(path string) errors.Error {
+	return errors.New(errors.CompositeErrorCode, MustValidateThenSchemaError, path)
+}
+func mustValidateElseSchemaMsg(path string) errors.Error {
+	return errors.New(errors.CompositeErrorCode, MustValidateElseSchemaError, path)
+}
 func hasADependencyMsg(path, depkey string) errors.Error {
 	return errors.New(errors.CompositeErrorCode, HasDependencyError, path, depkey)
 }
@@ -14,15 +14,274 @@
 
 package validate
 
+import (
+	"context"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+)
+
 // SchemaValidatorOptions defines optional rules for schema validation
 type SchemaValidatorOptions struct {
 	validationRulesEnabled bool
+	listMapKeyPaths        bool
+	propertySuggestions    bool
+	jsonPointers           bool
+	warnings               bool
+	structural             bool
+	unknownFields          UnknownFieldStrictness
+	unknownFieldsSet       bool
+	direction              Direction
+	stripByDirection       bool
+	maxErrors              int
+	exactMultipleOf        bool
+	propertyWorkers        int
+	tracer                 Tracer
+	coercion               bool
+	catalog                errors.MessageCatalog
+
+	// ctx is set by the WithContext variants of the validation methods.
+	ctx context.Context
+
+	// pointer is the JSON pointer of the validated value, if jsonPointers
+	// is set.
+	pointer string
+
+	// cache shares compiled schemas between validators, and compiled is
+	// the compiled schema of the validated schema, if cached.
+	cache    *ValidatorCache
+	compiled *compiledSchema
+
+	// nested is set on the options of the validators of sub-schemas, so that
+	// only the validator created by the caller post-processes the result.
+	nested bool
 }
 
 // Option sets optional rules for schema validation
 type Option func(*SchemaValidatorOptions)
 
-// Options returns current options
+// EnableListMapKeyPaths identifies the items of list-type=map arrays by their
+// x-kubernetes-list-map-keys in error paths, e.g. `containers[name="app"].image`
+// instead of "containers.0.image", so that errors are stable across reorderings.
+// Key values are JSON encoded. Items missing a key keep their index.
+func EnableListMapKeyPaths() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.listMapKeyPaths = true
+	}
+}
+
+// EnablePropertySuggestions suggests the closest declared property names when a
+// property is rejected because of additionalProperties: false, to help with typos.
+// It is off by default as computing suggestions is costly for large objects.
+func EnablePropertySuggestions() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.propertySuggestions = true
+	}
+}
+
+// EnableJSONPointers sets the Pointer of the errors.Validation errors of the
+// result to the RFC 6901 JSON pointer of the invalid value, e.g.
+// "/metadata/labels/app.kubernetes.io~1name". Pointers are built token by
+// token while validating, so unlike dotted paths they are unambiguous for
+// property names containing dots or brackets, and items are identified by
+// their index even with EnableListMapKeyPaths. Missing required properties
+// are pointed to as the property of their object.
+func EnableJSONPointers() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.jsonPointers = true
+	}
+}
+
+// EnableWarnings reports soft problems as warnings of the result, which
+// do not fail validation:
+//   - properties of objects declaring properties, but neither additionalProperties,
+//     patternProperties nor x-kubernetes-preserve-unknown-fields, which are
+//     usually misspelled fields that would be pruned;
+//   - strings declared with a format missing from the registry, which is not checked.
+func EnableWarnings() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.warnings = true
+	}
+}
+
+// UnknownFieldStrictness is how unknown fields are reported, see WithUnknownFields.
+type UnknownFieldStrictness int
+
+const (
+	// UnknownFieldsIgnore does not report unknown fields. It is the default,
+	// unless warnings are enabled.
+	UnknownFieldsIgnore UnknownFieldStrictness = iota
+	// UnknownFieldsWarn reports unknown fields as warnings.
+	UnknownFieldsWarn
+	// UnknownFieldsError reports unknown fields as errors.
+	UnknownFieldsError
+)
+
+// WithUnknownFields sets how unknown fields are reported: the properties of
+// objects declaring properties, but neither additionalProperties,
+// patternProperties nor x-kubernetes-preserve-unknown-fields. Their paths
+// are returned by Result.UnknownFields. It overrides EnableWarnings for
+// unknown fields.
+func WithUnknownFields(strictness UnknownFieldStrictness) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.unknownFields = strictness
+		svo.unknownFieldsSet = true
+	}
+}
+
+// Direction is the direction of the validated data, see WithDirection.
+type Direction int
+
+const (
+	// DirectionNone does not enforce readOnly and writeOnly. It is the default.
+	DirectionNone Direction = iota
+	// DirectionRequest rejects the readOnly properties of requests.
+	DirectionRequest
+	// DirectionResponse rejects the writeOnly properties of responses.
+	DirectionResponse
+)
+
+// WithDirection enforces readOnly and writeOnly for data sent in direction:
+// properties whose schema is readOnly are rejected in requests, and those
+// whose schema is writeOnly are rejected in responses.
+func WithDirection(direction Direction) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.direction = direction
+	}
+}
+
+// EnableStripByDirection strips the properties rejected by WithDirection
+// from the validated data, in place, reporting them as warnings instead
+// of errors.
+func EnableStripByDirection() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.stripByDirection = true
+	}
+}
+
+// RequireStructuralSchema reports the violations of the constraints of
+// Kubernetes structural schemas by the validated schema, as
+// ValidateStructuralSchema, along with the errors of the validated data.
+func RequireStructuralSchema() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.structural = true
+	}
+}
+
+// WithMaxErrors stops validating objects and arrays once n errors are found,
+// and limits the errors of the result to n, setting its Truncated field, so
+// that validating wildly malformed data does not pile up errors. Whether the
+// data is valid is not affected. A zero n, the default, is no limit.
+func WithMaxErrors(n int) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.maxErrors = n
+	}
+}
+
+// WithMessageCatalog rewrites the messages of the validation errors and
+// warnings of the result with catalog, e.g. to translate them.
+func WithMessageCatalog(catalog errors.MessageCatalog) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.catalog = catalog
+	}
+}
+
+// EnableExactMultipleOf checks multipleOf with exact decimal arithmetic
+// instead of floats, which reject values like 19.99 as a multiple of 0.01
+// because of rounding errors. Numbers are read as their shortest decimal
+// representation. It is slower, as it allocates.
+func EnableExactMultipleOf() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.exactMultipleOf = true
+	}
+}
+
+// WithParallelProperties validates the properties of the validated object
+// with up to workers goroutines, which speeds up the validation of objects
+// with many properties. Only the top-level properties are validated
+// concurrently. Their errors are in the order of the property names.
+// Validators registered with RegisterExtensionValidator must then be safe
+// for concurrent use. A workers value below 2, the default, validates
+// properties sequentially.
+func WithParallelProperties(workers int) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.propertyWorkers = workers
+	}
+}
+
+// WithTracer notifies tracer of each schema values are validated against.
+// With WithParallelProperties, tracer must be safe for concurrent use, and
+// the calls for different properties are interleaved.
+func WithTracer(tracer Tracer) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.tracer = tracer
+	}
+}
+
+// EnableCoercion converts strings to the integer, number or boolean type of
+// their schema before validation, as OpenAPI parameters, e.g. "5" to 5 for
+// an integer schema and "true" to true for a boolean one. Strings allowed by
+// their schema, or not valid values of the type, are kept. The coerced data
+// is the Value of the result of Validate. Objects and arrays are coerced in place.
+//
+// The schemas of values are found by properties, patternProperties,
+// additionalProperties, items and additionalItems, including in allOf
+// subschemas, but not in anyOf, oneOf and if/then/else subschemas.
+func EnableCoercion() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.coercion = true
+	}
+}
+
+// withContext sets the context checked by validators, which stop once it is done.
+func withContext(ctx context.Context) Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.ctx = ctx
+	}
+}
+
+// withoutValidatorCache disables the validator cache, for schemas which are not long-lived.
+func withoutValidatorCache() Option {
+	return func(svo *SchemaValidatorOptions) {
+		svo.cache = nil
+		svo.compiled = nil
+	}
+}
+
+// Options returns current options, to be passed to the validators of sub-schemas.
 func (svo SchemaValidatorOptions) Options() []Option {
-	return []Option{}
+	return []Option{func(o *SchemaValidatorOptions) {
+		*o = svo
+		o.nested = true
+		// the sub-schema is unknown
+		o.compiled = nil
+	}}
+}
+
+// childValueOptions returns current options, to be passed to the validator of
+// the sub-schema at key validating the property or item token of the value,
+// e.g. "properties/name" for the property "name".
+func (svo SchemaValidatorOptions) childValueOptions(key, token string) []Option {
+	options := svo.childOptions(key)
+	if !svo.jsonPointers {
+		return options
+	}
+	pointer := childPointer(svo.pointer, token)
+	return append(options, func(o *SchemaValidatorOptions) {
+		o.pointer = pointer
+	})
+}
+
+// childOptions returns current options, to be passed to the validator of the
+// sub-schema at key, e.g. "properties/name", so that it shares the compiled
+// sub-schema if cached. An empty key is the validated schema itself.
+func (svo SchemaValidatorOptions) childOptions(key string) []Option {
+	compiled := svo.compiled
+	if key != "" {
+		compiled = compiled.child(key)
+	}
+	return []Option{func(o *SchemaValidatorOptions) {
+		*o = svo
+		o.nested = true
+		o.compiled = compiled
+	}}
 }
@@ -17,6 +17,7 @@ package validate
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"k8s.io/kube-openapi/pkg/validation/strfmt"
@@ -29,45 +30,96 @@ type schemaPropsValidator struct {
 	OneOf           []spec.Schema
 	AnyOf           []spec.Schema
 	Not             *spec.Schema
+	If              *spec.Schema
+	Then            *spec.Schema
+	Else            *spec.Schema
 	Dependencies    spec.Dependencies
 	anyOfValidators []SchemaValidator
 	allOfValidators []SchemaValidator
 	oneOfValidators []SchemaValidator
 	notValidator    *SchemaValidator
+	ifValidator     *SchemaValidator
+	thenValidator   *SchemaValidator
+	elseValidator   *SchemaValidator
 	Root            interface{}
 	KnownFormats    strfmt.Registry
 	Options         SchemaValidatorOptions
+
+	// Discriminator, when set, is the property whose value selects the
+	// oneOf, or else anyOf, schema objects are validated against.
+	Discriminator        string
+	DiscriminatorMapping map[string]string
 }
 
 func (s *schemaPropsValidator) SetPath(path string) {
 	s.Path = path
+	for i := range s.anyOfValidators {
+		s.anyOfValidators[i].SetPath(path)
+	}
+	for i := range s.allOfValidators {
+		s.allOfValidators[i].SetPath(path)
+	}
+	for i := range s.oneOfValidators {
+		s.oneOfValidators[i].SetPath(path)
+	}
+	for _, v := range []*SchemaValidator{s.notValidator, s.ifValidator, s.thenValidator, s.elseValidator} {
+		if v != nil {
+			v.SetPath(path)
+		}
+	}
 }
 
-func newSchemaPropsValidator(path string, in string, allOf, oneOf, anyOf []spec.Schema, not *spec.Schema, deps spec.Dependencies, root interface{}, formats strfmt.Registry, options ...Option) *schemaPropsValidator {
+func (s *schemaPropsValidator) setPointer(pointer string) {
+	s.Options.pointer = pointer
+	for i := range s.anyOfValidators {
+		s.anyOfValidators[i].setPointer(pointer)
+	}
+	for i := range s.allOfValidators {
+		s.allOfValidators[i].setPointer(pointer)
+	}
+	for i := range s.oneOfValidators {
+		s.oneOfValidators[i].setPointer(pointer)
+	}
+	for _, v := range []*SchemaValidator{s.notValidator, s.ifValidator, s.thenValidator, s.elseValidator} {
+		if v != nil {
+			v.setPointer(pointer)
+		}
+	}
+}
+
+func newSchemaPropsValidator(path string, in string, allOf, oneOf, anyOf []spec.Schema, not, ifSchema, thenSchema, elseSchema *spec.Schema, deps spec.Dependencies, root interface{}, formats strfmt.Registry, options ...Option) *schemaPropsValidator {
+	schOptions := &SchemaValidatorOptions{}
+	for _, o := range options {
+		o(schOptions)
+	}
+
 	var anyValidators []SchemaValidator
-	for _, v := range anyOf {
+	for i, v := range anyOf {
 		v := v
-		anyValidators = append(anyValidators, *NewSchemaValidator(&v, root, path, formats, options...))
+		anyValidators = append(anyValidators, *NewSchemaValidator(&v, root, path, formats, schOptions.childOptions("anyOf/"+strconv.Itoa(i))...))
 	}
 	var allValidators []SchemaValidator
-	for _, v := range allOf {
+	for i, v := range allOf {
 		v := v
-		allValidators = append(allValidators, *NewSchemaValidator(&v, root, path, formats, options...))
+		allValidators = append(allValidators, *NewSchemaValidator(&v, root, path, formats, schOptions.childOptions("allOf/"+strconv.Itoa(i))...))
 	}
 	var oneValidators []SchemaValidator
-	for _, v := range oneOf {
+	for i, v := range oneOf {
 		v := v
-		oneValidators = append(oneValidators, *NewSchemaValidator(&v, root, path, formats, options...))
+		oneValidators = append(oneValidators, *NewSchemaValidator(&v, root, path, formats, schOptions.childOptions("oneOf/"+strconv.Itoa(i))...))
 	}
 
 	var notValidator *SchemaValidator
 	if not != nil {
-		notValidator = NewSchemaValidator(not, root, path, formats, options...)
+		notValidator = NewSchemaValidator(not, root, path, formats, schOptions.childOptions("not")...)
 	}
 
-	schOptions := &SchemaValidatorOptions{}
-	for _, o := range options {
-		o(schOptions)
+	// then and else only apply along with if
+	var ifValidator, thenValidator, elseValidator *SchemaValidator
+	if ifSchema != nil {
+		ifValidator = NewSchemaValidator(ifSchema, root, path, formats, schOptions.childOptions("if")...)
+		thenValidator = NewSchemaValidator(thenSchema, root, path, formats, schOptions.childOptions("then")...)
+		elseValidator = NewSchemaValidator(elseSchema, root, path, formats, schOptions.childOptions("else")...)
 	}
 	return &schemaPropsValidator{
 		Path:            path,
@@ -76,11 +128,17 @@ func newSchemaPropsValidator(path string, in string, allOf, oneOf, anyOf []spec.
 		OneOf:           oneOf,
 		AnyOf:           anyOf,
 		Not:             not,
+		If:              ifSchema,
+		Then:            thenSchema,
+		Else:            elseSchema,
 		Dependencies:    deps,
 		anyOfValidators: anyValidators,
 		allOfValidators: allValidators,
 		oneOfValidators: oneValidators,
 		notValidator:    notValidator,
+		ifValidator:     ifValidator,
+		thenValidator:   thenValidator,
+		elseValidator:   elseValidator,
 		Root:            root,
 		KnownFormats:    formats,
 		Options:         *schOptions,
@@ -103,9 +161,22 @@ func (s *schemaPropsValidator) Validate(data interface{}) *Result {
 	keepResultOneOf := new(Result)
 	keepResultAllOf := new(Result)
 
+	// a discriminator replaces the validation against every oneOf, or anyOf, schema
+	discriminatedOneOf, discriminatedAnyOf := false, false
+	if obj, ok := data.(map[string]interface{}); ok && s.Discriminator != "" {
+		switch {
+		case len(s.oneOfValidators) > 0:
+			mainResult.Merge(s.validateDiscriminated(obj, s.OneOf, s.oneOfValidators))
+			discriminatedOneOf = true
+		case len(s.anyOfValidators) > 0:
+			mainResult.Merge(s.validateDiscriminated(obj, s.AnyOf, s.anyOfValidators))
+			discriminatedAnyOf = true
+		}
+	}
+
 	// Validates at least one in anyOf schemas
 	var firstSuccess *Result
-	if len(s.anyOfValidators) > 0 {
+	if len(s.anyOfValidators) > 0 && !discriminatedAnyOf {
 		var bestFailures *Result
 		succeededOnce := false
 		for _, anyOfSchema := range s.anyOfValidators {
@@ -138,7 +209,7 @@ func (s *schemaPropsValidator) Validate(data interface{}) *Result {
 	}
 
 	// Validates exactly one in oneOf schemas
-	if len(s.oneOfValidators) > 0 {
+	if len(s.oneOfValidators) > 0 && !discriminatedOneOf {
 		var bestFailures *Result
 		var firstSuccess *Result
 		validated := 0
@@ -212,13 +283,30 @@ func (s *schemaPropsValidator) Validate(data interface{}) *Result {
 		}
 	}
 
+	if s.ifValidator != nil {
+		// the result of if only selects then or else
+		if s.ifValidator.Validate(data).IsValid() {
+			if s.thenValidator != nil {
+				if result := s.thenValidator.Validate(data); result.HasErrors() {
+					mainResult.AddErrors(mustValidateThenSchemaMsg(s.Path))
+					mainResult.Merge(result)
+				}
+			}
+		} else if s.elseValidator != nil {
+			if result := s.elseValidator.Validate(data); result.HasErrors() {
+				mainResult.AddErrors(mustValidateElseSchemaMsg(s.Path))
+				mainResult.Merge(result)
+			}
+		}
+	}
+
 	if s.Dependencies != nil && len(s.Dependencies) > 0 && reflect.TypeOf(data).Kind() == reflect.Map {
 		val := data.(map[string]interface{})
 		for key := range val {
 			if dep, ok := s.Dependencies[key]; ok {
 
 				if dep.Schema != nil {
-					mainResult.Merge(NewSchemaValidator(dep.Schema, s.Root, s.Path+"."+key, s.KnownFormats, s.Options.Options()...).Validate(data))
+					mainResult.Merge(NewSchemaValidator(dep.Schema, s.Root, s.Path+"."+key, s.KnownFormats, s.Options.childOptions("dependencies/"+key)...).Validate(data))
 					continue
 				}
 
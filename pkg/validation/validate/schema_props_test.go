@@ -15,9 +15,14 @@
 package validate
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
 
 // Test edge cases in schema_props_validator which are difficult
@@ -28,3 +33,42 @@ func TestSchemaPropsValidator_EdgeCases(t *testing.T) {
 	s.SetPath("path")
 	assert.Equal(t, "path", s.Path)
 }
+
+func TestSchemaPropsValidator_IfThenElse(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {"kind": {"type": "string"}, "port": {"type": "integer"}, "path": {"type": "string"}},
+		"if": {"properties": {"kind": {"enum": ["tcp"]}}},
+		"then": {"required": ["port"]},
+		"else": {"required": ["path"]}
+	}`), schema))
+	assert.NotNil(t, schema.If)
+	assert.NotNil(t, schema.Then)
+	assert.NotNil(t, schema.Else)
+	assert.Empty(t, schema.ExtraProps)
+
+	validate := func(input string) []string {
+		var data interface{}
+		require.NoError(t, json.Unmarshal([]byte(input), &data))
+		return errorStrings(NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(data))
+	}
+	assert.Empty(t, validate(`{"kind": "tcp", "port": 80}`))
+	assert.Empty(t, validate(`{"kind": "http", "path": "/"}`))
+	assert.ElementsMatch(t, []string{
+		`"spec" must validate the then schema, as it validates the if schema`,
+		"spec.port in body is required",
+	}, validate(`{"kind": "tcp", "path": "/"}`))
+	assert.ElementsMatch(t, []string{
+		`"spec" must validate the else schema, as it does not validate the if schema`,
+		"spec.path in body is required",
+	}, validate(`{"kind": "http", "port": 80}`))
+
+	// then and else are ignored without if
+	schema.If = nil
+	assert.Empty(t, validate(`{"kind": "tcp"}`))
+
+	b, err := json.Marshal(&spec.Schema{SchemaProps: spec.SchemaProps{If: spec.StringProperty(), Then: spec.StringProperty().WithMinLength(1)}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"if": {"type": "string"}, "then": {"type": "string", "minLength": 1}}`, string(b))
+}
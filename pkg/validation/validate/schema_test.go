@@ -15,6 +15,7 @@
 package validate
 
 import (
+	"context"
 	"encoding/json"
 	"math"
 	"reflect"
@@ -24,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-openapi/swag"
+	"k8s.io/kube-openapi/pkg/validation/errors"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
@@ -183,3 +185,365 @@ func TestSchemaValidator_EdgeCases(t *testing.T) {
 	r = s.Validate(j)
 	assert.False(t, r.IsValid())
 }
+
+// Items are validated by a single validator whose path is set for each item,
+// including the paths of the validators of its properties and subschemas.
+func TestSchemaValidator_ItemPaths(t *testing.T) {
+	var schemaJSON = `
+{
+    "properties": {
+        "c": {
+            "type": "array",
+            "items": {
+                "type": "object",
+                "properties": {
+                    "i": {"type": "integer"},
+                    "l": {"type": "array", "items": {"type": "string"}}
+                },
+                "allOf": [{"properties": {"j": {"minimum": 0}}}]
+            }
+        }
+    }
+}`
+
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), schema))
+
+	var input map[string]interface{}
+	var inputJSON = `{"c": [{"i": "a", "j": -1}, {"i": "b", "j": -2, "l": ["x", 1]}]}`
+	require.NoError(t, json.Unmarshal([]byte(inputJSON), &input))
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input)
+	var paths []string
+	for _, err := range res.Errors {
+		if e, ok := err.(*errors.Validation); ok {
+			paths = append(paths, e.Name)
+		}
+	}
+	assert.ElementsMatch(t, []string{"c.0.i", "c.1.i", "c.0.j", "c.1.j", "c.1.l.1"}, paths)
+}
+
+func TestSchemaValidator_ListMapKeyPaths(t *testing.T) {
+	var schemaJSON = `
+{
+    "properties": {
+        "containers": {
+            "type": "array",
+            "x-kubernetes-list-type": "map",
+            "x-kubernetes-list-map-keys": ["name", "port"],
+            "items": {
+                "type": "object",
+                "properties": {
+                    "name": {"type": "string"},
+                    "port": {"type": "integer"},
+                    "image": {"type": "string"}
+                }
+            }
+        }
+    }
+}`
+
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), schema))
+
+	var input map[string]interface{}
+	var inputJSON = `{"containers": [{"name": "app", "port": 80, "image": 1}, {"name": "sidecar", "image": 2}, {"name": "a]b=c,d.e\\\"", "port": 81, "image": 3}]}`
+	require.NoError(t, json.Unmarshal([]byte(inputJSON), &input))
+
+	paths := func(res *Result) []string {
+		var ret []string
+		for _, err := range res.Errors {
+			ret = append(ret, err.(*errors.Validation).Name)
+		}
+		return ret
+	}
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input)
+	assert.ElementsMatch(t, []string{"containers.0.image", "containers.1.image", "containers.2.image"}, paths(res))
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, EnableListMapKeyPaths()).Validate(input)
+	assert.ElementsMatch(t, []string{`containers[name="app",port=80].image`, "containers.1.image", `containers[name="a]b=c,d.e\\\"",port=81].image`}, paths(res))
+}
+
+func TestSchemaValidator_Warnings(t *testing.T) {
+	var schemaJSON = `
+{
+    "properties": {
+        "spec": {
+            "type": "object",
+            "properties": {
+                "replicas": {"type": "integer"},
+                "id": {"type": "string", "format": "ulid"},
+                "uid": {"type": "string", "format": "uuid"},
+                "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+                "raw": {"type": "object", "properties": {"a": {"type": "string"}}, "x-kubernetes-preserve-unknown-fields": true},
+                "template": {"type": "object", "properties": {"spec": {"type": "object"}}, "x-kubernetes-embedded-resource": true}
+            }
+        }
+    }
+}`
+
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), schema))
+
+	var input map[string]interface{}
+	var inputJSON = `{"spec": {"replics": 1, "id": "01F8", "uid": "not-a-uuid", "labels": {"a": "b"}, "raw": {"b": 1}, "template": {"kind": "Pod", "spce": {}}}}`
+	require.NoError(t, json.Unmarshal([]byte(inputJSON), &input))
+
+	messages := func(errs []error) []string {
+		var ret []string
+		for _, err := range errs {
+			ret = append(ret, err.Error())
+		}
+		return ret
+	}
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input)
+	assert.Len(t, res.Errors, 1)
+	assert.Empty(t, res.Warnings)
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, EnableWarnings()).Validate(input)
+	assert.Equal(t, []string{`spec.uid in body must be of type uuid: "not-a-uuid"`}, messages(res.Errors))
+	assert.ElementsMatch(t, []string{
+		"spec.replics in body is an unknown field",
+		`spec.id in body has unknown format "ulid"`,
+		"spec.template.spce in body is an unknown field",
+	}, messages(res.Warnings))
+
+	// warnings alone do not fail validation
+	delete(input["spec"].(map[string]interface{}), "uid")
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, EnableWarnings()).Validate(input)
+	assert.True(t, res.IsValid())
+	assert.Len(t, res.Warnings, 3)
+}
+
+func TestSchemaValidator_ValidateWithContext(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {"items": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}}}
+	}`), schema))
+
+	var input interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"items": ["a", "B", "c"]}`), &input))
+
+	v := NewSchemaValidator(schema, nil, "", strfmt.Default, EnableJSONPointers())
+	res := v.ValidateWithContext(context.Background(), input)
+	require.Len(t, res.Errors, 1)
+	assert.Equal(t, "/items/1", res.Errors[0].(*errors.Validation).Pointer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = v.ValidateWithContext(ctx, input)
+	assert.Equal(t, []error{context.Canceled}, res.Errors)
+	assert.Equal(t, context.Canceled, AgainstSchemaWithContext(ctx, schema, input, strfmt.Default).(*errors.CompositeError).Errors[0])
+
+	// the validator is not changed
+	assert.Len(t, v.Validate(input).Errors, 1)
+	var nilValidator *SchemaValidator
+	assert.True(t, nilValidator.ValidateWithContext(ctx, input).IsValid())
+}
+
+func TestSchemaValidator_UnknownFields(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"properties": {
+			"spec": {"type": "object", "properties": {"replicas": {"type": "integer"}}},
+			"status": {"type": "object", "properties": {"ready": {"type": "boolean"}}, "x-kubernetes-preserve-unknown-fields": true}
+		}
+	}`), schema))
+	input := map[string]interface{}{
+		"spec":   map[string]interface{}{"replics": 1},
+		"status": map[string]interface{}{"phase": "Running"},
+		"foo":    "bar",
+	}
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input)
+	assert.True(t, res.IsValid())
+	assert.Empty(t, res.Warnings)
+	assert.Empty(t, res.UnknownFields())
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, WithUnknownFields(UnknownFieldsWarn)).Validate(input)
+	assert.True(t, res.IsValid())
+	assert.Len(t, res.Warnings, 2)
+	assert.Equal(t, []string{"foo", "spec.replics"}, res.UnknownFields())
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, WithUnknownFields(UnknownFieldsError)).Validate(input)
+	assert.ElementsMatch(t, []string{".foo in body is an unknown field", "spec.replics in body is an unknown field"}, errorStrings(res))
+	assert.Equal(t, []string{"foo", "spec.replics"}, res.UnknownFields())
+
+	// the strictness overrides warnings
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, EnableWarnings(), WithUnknownFields(UnknownFieldsIgnore)).Validate(input)
+	assert.Empty(t, res.Warnings)
+}
+
+func TestSchemaValidator_Direction(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"minProperties": 2,
+		"properties": {
+			"id": {"type": "string", "readOnly": true},
+			"name": {"type": "string"},
+			"password": {"type": "string", "writeOnly": true}
+		}
+	}`), schema))
+	input := func() map[string]interface{} {
+		return map[string]interface{}{"id": "1", "name": "a", "password": "secret"}
+	}
+
+	assert.True(t, NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input()).IsValid())
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default, WithDirection(DirectionRequest)).Validate(input())
+	assert.Equal(t, []string{".id in body is read-only and must not be set in requests"}, errorStrings(res))
+	assert.EqualValues(t, errors.ReadOnlyFieldCode, res.Errors[0].(*errors.Validation).Code())
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, WithDirection(DirectionResponse)).Validate(input())
+	assert.Equal(t, []string{".password in body is write-only and must not be set in responses"}, errorStrings(res))
+
+	data := input()
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, WithDirection(DirectionResponse), EnableStripByDirection()).Validate(data)
+	assert.True(t, res.IsValid())
+	assert.Len(t, res.Warnings, 1)
+	assert.Equal(t, map[string]interface{}{"id": "1", "name": "a"}, data)
+
+	// stripped properties do not count
+	data = map[string]interface{}{"id": "1", "name": "a"}
+	res = NewSchemaValidator(schema, nil, "spec", strfmt.Default, WithDirection(DirectionRequest), EnableStripByDirection()).Validate(data)
+	assert.Equal(t, []string{"spec in body should have at least 2 properties"}, errorStrings(res))
+	assert.Equal(t, "spec.id in body is read-only and must not be set in requests", res.Warnings[0].Error())
+}
+
+func TestSchemaValidator_MaxErrors(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type: spec.StringOrArray{"object"},
+		Properties: map[string]spec.Schema{
+			"items": *spec.ArrayProperty(spec.Int64Property()),
+			"name":  *spec.StringProperty(),
+		},
+	}}
+	items := make([]interface{}, 1000)
+	for i := range items {
+		items[i] = "x"
+	}
+	input := map[string]interface{}{"items": items, "name": 1}
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input)
+	assert.Len(t, res.Errors, 1001)
+	assert.False(t, res.Truncated)
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, WithMaxErrors(10)).Validate(input)
+	assert.Len(t, res.Errors, 10)
+	assert.True(t, res.Truncated)
+
+	input = map[string]interface{}{"items": []interface{}{int64(1), "x"}}
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, WithMaxErrors(10)).Validate(input)
+	assert.Equal(t, []string{`items.1 in body must be of type integer: "string"`}, errorStrings(res))
+	assert.False(t, res.Truncated)
+}
+
+func TestSchemaValidator_MessageCatalog(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:     spec.StringOrArray{"object"},
+		Required: []string{"name"},
+		Properties: map[string]spec.Schema{
+			"replicas": *spec.Int64Property().WithMaximum(10, false),
+		},
+	}}
+	catalog := errors.TemplateCatalog{
+		"{name} in {in} is required":                           "{name} ({in}) est obligatoire",
+		"{name} in {in} should be less than or equal to {max}": "{name} ({in}) doit être inférieur ou égal à {max}",
+	}
+	input := map[string]interface{}{"replicas": 20}
+
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(input)
+	assert.ElementsMatch(t, []string{
+		"spec.name in body is required",
+		"spec.replicas in body should be less than or equal to 10",
+	}, errorStrings(res))
+
+	res = NewSchemaValidator(schema, nil, "spec", strfmt.Default, WithMessageCatalog(catalog)).Validate(input)
+	assert.ElementsMatch(t, []string{
+		"spec.name (body) est obligatoire",
+		"spec.replicas (body) doit être inférieur ou égal à 10",
+	}, errorStrings(res))
+	for _, err := range res.Errors {
+		e := err.(*errors.Validation)
+		if e.Code() == errors.MaxFailCode {
+			assert.Equal(t, errors.Params{"name": "spec.replicas", "in": "body", "max": int64(10)}, e.Params)
+		}
+	}
+}
+
+func TestSchemaValidator_ExactMultipleOf(t *testing.T) {
+	schema := spec.Float64Property()
+	schema.MultipleOf = swag.Float64(0.01)
+
+	assert.Equal(t, []string{"price in body should be a multiple of 0.01"}, errorStrings(NewSchemaValidator(schema, nil, "price", strfmt.Default).Validate(19.99)))
+	assert.Empty(t, errorStrings(NewSchemaValidator(schema, nil, "price", strfmt.Default, EnableExactMultipleOf()).Validate(19.99)))
+	assert.Equal(t, []string{"price in body should be a multiple of 0.01"}, errorStrings(NewSchemaValidator(schema, nil, "price", strfmt.Default, EnableExactMultipleOf()).Validate(19.995)))
+}
+
+func TestSchemaValidator_JSONSchema2020Keywords(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"kind": {"const": "Pet"},
+			"age": {"type": "integer", "exclusiveMinimum": 0, "exclusiveMaximum": 30},
+			"position": {
+				"type": "array",
+				"prefixItems": [{"type": "number"}, {"type": "string"}],
+				"items": {"type": "boolean"},
+				"unevaluatedItems": false
+			}
+		}
+	}`), schema))
+
+	var valid, invalid interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"kind": "Pet", "age": 29, "position": [1.5, "north", true]}`), &valid))
+	require.NoError(t, json.Unmarshal([]byte(`{"kind": "Cat", "age": 0, "position": ["north", 1.5, "true"]}`), &invalid))
+
+	assert.Empty(t, errorStrings(NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(valid)))
+	assert.ElementsMatch(t, []string{
+		"kind in body should be equal to Pet",
+		"age in body should be greater than 0",
+		`position.0 in body must be of type number: "string"`,
+		`position.1 in body must be of type string: "number"`,
+		`position.2 in body must be of type boolean: "string"`,
+	}, errorStrings(NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(invalid)))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"age": 30}`), &invalid))
+	assert.Equal(t, []string{"age in body should be less than 30"}, errorStrings(NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(invalid)))
+}
+
+func TestSchemaValidator_InferredSchemaRoundTrip(t *testing.T) {
+	type meta struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+	type sample struct {
+		meta `json:",inline"`
+
+		Replicas *int32            `json:"replicas"`
+		Tags     []string          `json:"tags"`
+		Notes    map[string]string `json:"notes"`
+		Data     []byte            `json:"data"`
+		Items    []meta            `json:"items"`
+		Any      interface{}       `json:"any"`
+		Enabled  bool              `json:"enabled"`
+	}
+
+	for _, v := range []sample{
+		{},
+		{Replicas: swag.Int32(1), Tags: []string{}, Notes: map[string]string{"a": "b"}, Data: []byte("data"), Items: []meta{{Name: "x"}}, Any: 1},
+	} {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		var decoded interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		res := NewSchemaValidator(spec.InferSchema(v), nil, "", strfmt.Default).Validate(decoded)
+		assert.Empty(t, res.Errors, "%s", data)
+	}
+}
@@ -15,9 +15,13 @@
 package validate
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
+	"k8s.io/kube-openapi/pkg/validation/errors"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
@@ -30,6 +34,9 @@ type schemaSliceValidator struct {
 	UniqueItems     bool
 	AdditionalItems *spec.SchemaOrBool
 	Items           *spec.SchemaOrArray
+	PrefixItems     []spec.Schema
+	ListMapKeys     []string
+	UniqueFields    []string
 	Root            interface{}
 	KnownFormats    strfmt.Registry
 	Options         SchemaValidatorOptions
@@ -39,6 +46,10 @@ func (s *schemaSliceValidator) SetPath(path string) {
 	s.Path = path
 }
 
+func (s *schemaSliceValidator) setPointer(pointer string) {
+	s.Options.pointer = pointer
+}
+
 func (s *schemaSliceValidator) Applies(source interface{}, kind reflect.Kind) bool {
 	_, ok := source.(*spec.Schema)
 	r := ok && kind == reflect.Slice
@@ -53,11 +64,27 @@ func (s *schemaSliceValidator) Validate(data interface{}) *Result {
 	val := reflect.ValueOf(data)
 	size := val.Len()
 
+	for i := 0; i < len(s.PrefixItems) && i < size; i++ {
+		if result.reachedMaxErrors(s.Options.maxErrors) {
+			return result
+		}
+		value := val.Index(i)
+		validator := NewSchemaValidator(&s.PrefixItems[i], s.Root, s.itemPath(i, value.Interface()), s.KnownFormats, s.Options.childValueOptions("prefixItems/"+strconv.Itoa(i), strconv.Itoa(i))...)
+		result.Merge(validator.Validate(value.Interface()))
+	}
+
 	if s.Items != nil && s.Items.Schema != nil {
-		validator := NewSchemaValidator(s.Items.Schema, s.Root, s.Path, s.KnownFormats, s.Options.Options()...)
-		for i := 0; i < size; i++ {
-			validator.SetPath(fmt.Sprintf("%s.%d", s.Path, i))
+		// items applies to the items following prefixItems
+		validator := NewSchemaValidator(s.Items.Schema, s.Root, s.Path, s.KnownFormats, s.Options.childOptions("items")...)
+		for i := len(s.PrefixItems); i < size; i++ {
+			if result.reachedMaxErrors(s.Options.maxErrors) {
+				return result
+			}
 			value := val.Index(i)
+			validator.SetPath(s.itemPath(i, value.Interface()))
+			if s.Options.jsonPointers {
+				validator.setPointer(childPointer(s.Options.pointer, strconv.Itoa(i)))
+			}
 			result.Merge(validator.Validate(value.Interface()))
 		}
 	}
@@ -66,8 +93,8 @@ func (s *schemaSliceValidator) Validate(data interface{}) *Result {
 	if s.Items != nil && len(s.Items.Schemas) > 0 {
 		itemsSize = len(s.Items.Schemas)
 		for i := 0; i < itemsSize; i++ {
-			validator := NewSchemaValidator(&s.Items.Schemas[i], s.Root, fmt.Sprintf("%s.%d", s.Path, i), s.KnownFormats, s.Options.Options()...)
-			if val.Len() <= i {
+			validator := NewSchemaValidator(&s.Items.Schemas[i], s.Root, fmt.Sprintf("%s.%d", s.Path, i), s.KnownFormats, s.Options.childValueOptions("items/"+strconv.Itoa(i), strconv.Itoa(i))...)
+			if val.Len() <= i || result.reachedMaxErrors(s.Options.maxErrors) {
 				break
 			}
 			result.Merge(validator.Validate(val.Index(i).Interface()))
@@ -79,7 +106,10 @@ func (s *schemaSliceValidator) Validate(data interface{}) *Result {
 		}
 		if s.AdditionalItems.Schema != nil {
 			for i := itemsSize; i < size-itemsSize+1; i++ {
-				validator := NewSchemaValidator(s.AdditionalItems.Schema, s.Root, fmt.Sprintf("%s.%d", s.Path, i), s.KnownFormats, s.Options.Options()...)
+				if result.reachedMaxErrors(s.Options.maxErrors) {
+					break
+				}
+				validator := NewSchemaValidator(s.AdditionalItems.Schema, s.Root, fmt.Sprintf("%s.%d", s.Path, i), s.KnownFormats, s.Options.childValueOptions("additionalItems", strconv.Itoa(i))...)
 				result.Merge(validator.Validate(val.Index(i).Interface()))
 			}
 		}
@@ -100,6 +130,92 @@ func (s *schemaSliceValidator) Validate(data interface{}) *Result {
 			result.AddErrors(err)
 		}
 	}
+	for _, field := range s.UniqueFields {
+		result.AddErrors(s.validateUniqueField(val, field)...)
+	}
 	result.Inc()
 	return result
 }
+
+// validateUniqueField checks that the values at the dot-separated path field are
+// unique across the items of val. Items without a value at field are ignored.
+// Values are compared by their JSON encoding, in linear time.
+func (s *schemaSliceValidator) validateUniqueField(val reflect.Value, field string) []error {
+	var errs []error
+	path := strings.Split(strings.TrimPrefix(field, "."), ".")
+	seen := make(map[string]int, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		item := val.Index(i).Interface()
+		v, ok := fieldValue(item, path)
+		if !ok {
+			continue
+		}
+		key, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		if first, found := seen[string(key)]; found {
+			previous := s.itemPath(first, val.Index(first).Interface()) + "." + strings.Join(path, ".")
+			err := errors.DuplicateField(s.itemPath(i, item)+"."+strings.Join(path, "."), s.In, previous, v)
+			err.Pointer = s.fieldPointer(i, path)
+			errs = append(errs, err)
+			continue
+		}
+		seen[string(key)] = i
+	}
+	return errs
+}
+
+func fieldValue(item interface{}, path []string) (interface{}, bool) {
+	for _, name := range path {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if item, ok = obj[name]; !ok {
+			return nil, false
+		}
+	}
+	return item, true
+}
+
+// fieldPointer returns the JSON pointer of the field at path of the i-th item,
+// if JSON pointers are enabled.
+func (s *schemaSliceValidator) fieldPointer(i int, path []string) string {
+	if !s.Options.jsonPointers {
+		return ""
+	}
+	pointer := childPointer(s.Options.pointer, strconv.Itoa(i))
+	for _, name := range path {
+		pointer = childPointer(pointer, name)
+	}
+	return pointer
+}
+
+// itemPath returns the path of the i-th item. Items of a list-type=map array are
+// identified by their keys when enabled, e.g. `containers[name="app",port=80]`,
+// so that paths are stable across reorderings. Key values are JSON encoded, so
+// that values containing "]", "=", "," or "." are unambiguous. Otherwise the
+// index is used, e.g. "containers.0".
+func (s *schemaSliceValidator) itemPath(i int, item interface{}) string {
+	if len(s.ListMapKeys) == 0 {
+		return fmt.Sprintf("%s.%d", s.Path, i)
+	}
+	obj, ok := item.(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("%s.%d", s.Path, i)
+	}
+	keys := make([]string, 0, len(s.ListMapKeys))
+	for _, k := range s.ListMapKeys {
+		v, ok := obj[k]
+		if !ok {
+			return fmt.Sprintf("%s.%d", s.Path, i)
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%s.%d", s.Path, i)
+		}
+		keys = append(keys, k+"="+string(value))
+	}
+	return fmt.Sprintf("%s[%s]", s.Path, strings.Join(keys, ","))
+}
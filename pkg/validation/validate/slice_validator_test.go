@@ -15,9 +15,14 @@
 package validate
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
 )
 
 // Test edge cases in slice_validator which are difficult
@@ -32,3 +37,36 @@ func TestSliceValidator_EdgeCases(t *testing.T) {
 	assert.NotNil(t, r)
 	assert.True(t, r.IsValid())
 }
+
+func TestSliceValidator_UniqueFields(t *testing.T) {
+	var schemaJSON = `
+{
+    "type": "array",
+    "x-kubernetes-unique-fields": ["name", "port.number"],
+    "items": {"type": "object"}
+}`
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), schema))
+
+	var input []interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"name": "a", "port": {"number": 80}},
+		{"name": "b", "port": {"number": 443}},
+		{"name": "a"},
+		{"port": {"number": 80}},
+		{"name": "c", "port": "invalid"}
+	]`), &input))
+
+	res := NewSchemaValidator(schema, nil, "ports", strfmt.Default).Validate(input)
+	var msgs []string
+	for _, err := range res.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		"ports.2.name in body must be unique, it duplicates ports.0.name",
+		"ports.3.port.number in body must be unique, it duplicates ports.0.port.number",
+	}, msgs)
+
+	require.NoError(t, json.Unmarshal([]byte(`[{"name": "a"}, {"name": "b"}, {}]`), &input))
+	assert.True(t, NewSchemaValidator(schema, nil, "ports", strfmt.Default).Validate(input).IsValid())
+}
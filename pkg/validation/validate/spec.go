@@ -0,0 +1,523 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// Spec validates doc, a Swagger 2.0 document, against the rules of the
+// specification its types cannot express:
+//
+//   - operation IDs are unique,
+//   - local references resolve, other references are not checked,
+//   - parameters have a valid location, path parameters are required and
+//     match the variables of their path template, and operations have at
+//     most one body parameter, and not both body and formData parameters,
+//   - defaults are valid against their schema.
+//
+// Errors are named after the location of the invalid part, e.g.
+// "paths[/pets].get.operationId".
+func Spec(doc *spec.Swagger, formats strfmt.Registry) *Result {
+	v := newSpecValidator(doc, formats)
+	if v.doc == nil {
+		return v.result
+	}
+	if doc.Swagger != "2.0" {
+		v.invalid("swagger", fmt.Sprintf("unsupported version %q, must be \"2.0\"", doc.Swagger))
+	}
+	for _, name := range sortedKeys(doc.Definitions) {
+		s := doc.Definitions[name]
+		v.checkSchema("definitions["+name+"]", &s)
+	}
+	for _, name := range sortedKeys(doc.Parameters) {
+		p := doc.Parameters[name]
+		v.checkParameter("parameters["+name+"]", &p)
+	}
+	for _, name := range sortedKeys(doc.Responses) {
+		r := doc.Responses[name]
+		v.checkResponse("responses["+name+"]", &r)
+	}
+	if doc.Paths == nil {
+		return v.result
+	}
+	for _, path := range sortedKeys(doc.Paths.Paths) {
+		item := doc.Paths.Paths[path]
+		name := "paths[" + path + "]"
+		v.checkRef(name, item.Ref)
+		for i := range item.Parameters {
+			v.checkParameter(name+".parameters["+strconv.Itoa(i)+"]", &item.Parameters[i])
+		}
+		for _, op := range []struct {
+			method string
+			op     *spec.Operation
+		}{
+			{"get", item.Get}, {"put", item.Put}, {"post", item.Post}, {"delete", item.Delete},
+			{"options", item.Options}, {"head", item.Head}, {"patch", item.Patch},
+		} {
+			if op.op != nil {
+				v.checkOperation(name+"."+op.method, path, doc, item.Parameters, op.op)
+			}
+		}
+	}
+	return v.result
+}
+
+// SpecV3 validates doc, an OpenAPI v3 document, as Spec, the parameter
+// locations being those of OpenAPI v3.
+func SpecV3(doc *spec3.OpenAPI, formats strfmt.Registry) *Result {
+	v := newSpecValidator(doc, formats)
+	if v.doc == nil {
+		return v.result
+	}
+	if !strings.HasPrefix(doc.Version, "3.") {
+		v.invalid("openapi", fmt.Sprintf("unsupported version %q, must be 3.x", doc.Version))
+	}
+	if c := doc.Components; c != nil {
+		for _, name := range sortedKeys(c.Schemas) {
+			v.checkSchema("components.schemas["+name+"]", c.Schemas[name])
+		}
+		for _, name := range sortedKeys(c.Parameters) {
+			v.checkParameterV3("components.parameters["+name+"]", c.Parameters[name])
+		}
+		for _, name := range sortedKeys(c.Responses) {
+			v.checkResponseV3("components.responses["+name+"]", c.Responses[name])
+		}
+		for _, name := range sortedKeys(c.RequestBodies) {
+			v.checkRequestBodyV3("components.requestBodies["+name+"]", c.RequestBodies[name])
+		}
+	}
+	if doc.Paths == nil {
+		return v.result
+	}
+	for _, path := range sortedKeys(doc.Paths.Paths) {
+		item := doc.Paths.Paths[path]
+		name := "paths[" + path + "]"
+		if item == nil {
+			continue
+		}
+		v.checkRef(name, item.Ref)
+		for i, p := range item.Parameters {
+			v.checkParameterV3(name+".parameters["+strconv.Itoa(i)+"]", p)
+		}
+		for _, op := range []struct {
+			method string
+			op     *spec3.Operation
+		}{
+			{"get", item.Get}, {"put", item.Put}, {"post", item.Post}, {"delete", item.Delete},
+			{"options", item.Options}, {"head", item.Head}, {"patch", item.Patch}, {"trace", item.Trace},
+		} {
+			if op.op != nil {
+				v.checkOperationV3(name+"."+op.method, path, doc, item.Parameters, op.op)
+			}
+		}
+	}
+	return v.result
+}
+
+type specValidator struct {
+	result  *Result
+	formats strfmt.Registry
+	// doc is the JSON form of the document, to resolve references.
+	doc interface{}
+	// operationIDs maps the operation IDs to the name of the first operation
+	// using them.
+	operationIDs map[string]string
+}
+
+func newSpecValidator(doc interface{}, formats strfmt.Registry) *specValidator {
+	v := &specValidator{result: new(Result), formats: formats, operationIDs: map[string]string{}}
+	data, err := json.Marshal(doc)
+	if err == nil {
+		err = json.Unmarshal(data, &v.doc)
+	}
+	if err != nil {
+		v.invalid("document", err.Error())
+		v.doc = nil
+	} else if v.doc == nil {
+		v.invalid("document", "must not be empty")
+	}
+	return v
+}
+
+func (v *specValidator) invalid(name, reason string) {
+	v.result.AddErrors(errors.InvalidSpec(name, "", reason))
+}
+
+// checkRef checks that ref, if local, resolves in the document.
+func (v *specValidator) checkRef(name string, ref spec.Ref) {
+	s := ref.String()
+	if !strings.HasPrefix(s, "#") {
+		return
+	}
+	if !v.resolves(s[1:]) {
+		v.invalid(name+".$ref", fmt.Sprintf("unresolved reference %q", s))
+	}
+}
+
+// resolves returns true if pointer, a JSON pointer, points to a value of the
+// document.
+func (v *specValidator) resolves(pointer string) bool {
+	if p, err := url.PathUnescape(pointer); err == nil {
+		pointer = p
+	}
+	if pointer == "" {
+		return true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return false
+	}
+	cur := v.doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = jsonpointer.Unescape(token)
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			next, ok := c[token]
+			if !ok {
+				return false
+			}
+			cur = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(c) {
+				return false
+			}
+			cur = c[i]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (v *specValidator) checkOperationID(name, id string) {
+	if id == "" {
+		return
+	}
+	if first, ok := v.operationIDs[id]; ok {
+		v.invalid(name+".operationId", fmt.Sprintf("duplicate operation ID %q, also used by %s", id, first))
+		return
+	}
+	v.operationIDs[id] = name
+}
+
+// checkSchema checks the references of schema and its subschemas, and that
+// their defaults are valid.
+func (v *specValidator) checkSchema(name string, s *spec.Schema) {
+	if s == nil {
+		return
+	}
+	v.checkRef(name, s.Ref)
+	if s.Default != nil && s.Ref.String() == "" {
+		v.checkDefault(name, s, s.Default)
+	}
+
+	for _, k := range sortedKeys(s.Properties) {
+		p := s.Properties[k]
+		v.checkSchema(name+".properties["+k+"]", &p)
+	}
+	for _, k := range sortedKeys(s.PatternProperties) {
+		p := s.PatternProperties[k]
+		v.checkSchema(name+".patternProperties["+k+"]", &p)
+	}
+	for _, k := range sortedKeys(s.DependentSchemas) {
+		d := s.DependentSchemas[k]
+		v.checkSchema(name+".dependentSchemas["+k+"]", &d)
+	}
+	for _, sb := range []struct {
+		keyword string
+		schema  *spec.SchemaOrBool
+	}{
+		{"additionalProperties", s.AdditionalProperties},
+		{"additionalItems", s.AdditionalItems},
+		{"unevaluatedProperties", s.UnevaluatedProperties},
+		{"unevaluatedItems", s.UnevaluatedItems},
+	} {
+		if sb.schema != nil {
+			v.checkSchema(name+"."+sb.keyword, sb.schema.Schema)
+		}
+	}
+	if s.Items != nil {
+		v.checkSchema(name+".items", s.Items.Schema)
+		for i := range s.Items.Schemas {
+			v.checkSchema(name+".items["+strconv.Itoa(i)+"]", &s.Items.Schemas[i])
+		}
+	}
+	for _, list := range []struct {
+		keyword string
+		schemas []spec.Schema
+	}{{"allOf", s.AllOf}, {"anyOf", s.AnyOf}, {"oneOf", s.OneOf}, {"prefixItems", s.PrefixItems}} {
+		for i := range list.schemas {
+			v.checkSchema(name+"."+list.keyword+"["+strconv.Itoa(i)+"]", &list.schemas[i])
+		}
+	}
+	v.checkSchema(name+".not", s.Not)
+	v.checkSchema(name+".if", s.If)
+	v.checkSchema(name+".then", s.Then)
+	v.checkSchema(name+".else", s.Else)
+	v.checkSchema(name+".contentSchema", s.ContentSchema)
+	for _, k := range sortedKeys(s.Definitions) {
+		d := s.Definitions[k]
+		v.checkSchema(name+".definitions["+k+"]", &d)
+	}
+}
+
+func (v *specValidator) checkDefault(name string, s *spec.Schema, value interface{}) {
+	res := NewSchemaValidator(s, nil, name+".default", v.formats).Validate(value)
+	v.result.AddErrors(res.Errors...)
+}
+
+func (v *specValidator) checkParameter(name string, p *spec.Parameter) {
+	if p.Ref.String() != "" {
+		v.checkRef(name, p.Ref)
+		return
+	}
+	switch p.In {
+	case "body":
+		if p.Schema == nil {
+			v.invalid(name+".schema", "must be set for body parameters")
+		}
+		v.checkSchema(name+".schema", p.Schema)
+		return
+	case "path":
+		if !p.Required {
+			v.invalid(name+".required", "must be true for path parameters")
+		}
+	case "query", "header", "formData":
+	default:
+		v.invalid(name+".in", fmt.Sprintf("unsupported location %q", p.In))
+		return
+	}
+	if p.Type == "" {
+		v.invalid(name+".type", fmt.Sprintf("must be set for %s parameters", p.In))
+		return
+	}
+	if p.Default != nil && p.Type != "file" {
+		v.checkDefault(name, &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:             spec.StringOrArray{p.Type},
+			Format:           p.Format,
+			Maximum:          p.Maximum,
+			ExclusiveMaximum: p.ExclusiveMaximum,
+			Minimum:          p.Minimum,
+			ExclusiveMinimum: p.ExclusiveMinimum,
+			MaxLength:        p.MaxLength,
+			MinLength:        p.MinLength,
+			Pattern:          p.Pattern,
+			MaxItems:         p.MaxItems,
+			MinItems:         p.MinItems,
+			UniqueItems:      p.UniqueItems,
+			MultipleOf:       p.MultipleOf,
+			Enum:             p.Enum,
+		}}, p.Default)
+	}
+}
+
+func (v *specValidator) checkResponse(name string, r *spec.Response) {
+	if r.Ref.String() != "" {
+		v.checkRef(name, r.Ref)
+		return
+	}
+	v.checkSchema(name+".schema", r.Schema)
+}
+
+func (v *specValidator) checkOperation(name, path string, doc *spec.Swagger, pathParams []spec.Parameter, op *spec.Operation) {
+	v.checkOperationID(name, op.ID)
+	for i := range op.Parameters {
+		v.checkParameter(name+".parameters["+strconv.Itoa(i)+"]", &op.Parameters[i])
+	}
+	if op.Responses != nil {
+		if op.Responses.Default != nil {
+			v.checkResponse(name+".responses[default]", op.Responses.Default)
+		}
+		for _, code := range sortedCodes(op.Responses.StatusCodeResponses) {
+			r := op.Responses.StatusCodeResponses[code]
+			v.checkResponse(name+".responses["+strconv.Itoa(code)+"]", &r)
+		}
+	}
+
+	// the parameters of the operation override those of the path
+	params := map[string]*spec.Parameter{}
+	for _, list := range [][]spec.Parameter{pathParams, op.Parameters} {
+		for i := range list {
+			p := &list[i]
+			if n, ok := p.Ref.LocalName("#/parameters/"); ok {
+				if shared, found := doc.Parameters[n]; found {
+					p = &shared
+				}
+			}
+			params[p.In+"/"+p.Name] = p
+		}
+	}
+	var body, formData bool
+	var declared []string
+	for _, p := range params {
+		switch p.In {
+		case "body":
+			if body {
+				v.invalid(name+".parameters", "must have at most one body parameter")
+			}
+			body = true
+		case "formData":
+			formData = true
+		case "path":
+			declared = append(declared, p.Name)
+		}
+	}
+	if body && formData {
+		v.invalid(name+".parameters", "must not have both body and formData parameters")
+	}
+	v.checkPathParameters(name, path, declared)
+}
+
+func (v *specValidator) checkParameterV3(name string, p *spec3.Parameter) {
+	if p == nil {
+		return
+	}
+	if p.Ref.String() != "" {
+		v.checkRef(name, p.Ref)
+		return
+	}
+	switch p.In {
+	case "path":
+		if !p.Required {
+			v.invalid(name+".required", "must be true for path parameters")
+		}
+	case "query", "header", "cookie":
+	default:
+		v.invalid(name+".in", fmt.Sprintf("unsupported location %q", p.In))
+	}
+	v.checkSchema(name+".schema", p.Schema)
+	v.checkContentV3(name, p.Content)
+}
+
+func (v *specValidator) checkContentV3(name string, content map[string]*spec3.MediaType) {
+	for _, mediaType := range sortedKeys(content) {
+		if m := content[mediaType]; m != nil {
+			v.checkSchema(name+".content["+mediaType+"].schema", m.Schema)
+		}
+	}
+}
+
+func (v *specValidator) checkResponseV3(name string, r *spec3.Response) {
+	if r == nil {
+		return
+	}
+	if r.Ref.String() != "" {
+		v.checkRef(name, r.Ref)
+		return
+	}
+	v.checkContentV3(name, r.Content)
+}
+
+func (v *specValidator) checkRequestBodyV3(name string, b *spec3.RequestBody) {
+	if b == nil {
+		return
+	}
+	if b.Ref.String() != "" {
+		v.checkRef(name, b.Ref)
+		return
+	}
+	v.checkContentV3(name, b.Content)
+}
+
+func (v *specValidator) checkOperationV3(name, path string, doc *spec3.OpenAPI, pathParams []*spec3.Parameter, op *spec3.Operation) {
+	v.checkOperationID(name, op.OperationId)
+	for i, p := range op.Parameters {
+		v.checkParameterV3(name+".parameters["+strconv.Itoa(i)+"]", p)
+	}
+	v.checkRequestBodyV3(name+".requestBody", op.RequestBody)
+	if op.Responses != nil {
+		v.checkResponseV3(name+".responses[default]", op.Responses.Default)
+		for _, code := range sortedCodes(op.Responses.StatusCodeResponses) {
+			v.checkResponseV3(name+".responses["+strconv.Itoa(code)+"]", op.Responses.StatusCodeResponses[code])
+		}
+	}
+
+	declared := map[string]bool{}
+	for _, list := range [][]*spec3.Parameter{pathParams, op.Parameters} {
+		for _, p := range list {
+			if p == nil {
+				continue
+			}
+			if n, ok := p.Ref.LocalName("#/components/parameters/"); ok && doc.Components != nil && doc.Components.Parameters[n] != nil {
+				p = doc.Components.Parameters[n]
+			}
+			if p.In == "path" {
+				declared[p.Name] = true
+			}
+		}
+	}
+	v.checkPathParameters(name, path, sortedKeys(declared))
+}
+
+var pathTemplateVariable = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// checkPathParameters checks that the path parameters declared for the
+// operation name match the variables of path.
+func (v *specValidator) checkPathParameters(name, path string, declared []string) {
+	variables := map[string]bool{}
+	for _, m := range pathTemplateVariable.FindAllStringSubmatch(path, -1) {
+		variables[m[1]] = true
+	}
+	isDeclared := map[string]bool{}
+	for _, p := range declared {
+		isDeclared[p] = true
+		if !variables[p] {
+			v.invalid(name+".parameters", fmt.Sprintf("path parameter %q is not in the path", p))
+		}
+	}
+	for _, variable := range sortedKeys(variables) {
+		if !isDeclared[variable] {
+			v.invalid(name+".parameters", fmt.Sprintf("path parameter %q is not declared", variable))
+		}
+	}
+}
+
+// sortedKeys returns the sorted keys of m, a map with string keys.
+func sortedKeys(m interface{}) []string {
+	value := reflect.ValueOf(m)
+	keys := make([]string, 0, value.Len())
+	for _, k := range value.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedCodes returns the sorted keys of m, a map with status code keys.
+func sortedCodes(m interface{}) []int {
+	value := reflect.ValueOf(m)
+	codes := make([]int, 0, value.Len())
+	for _, k := range value.MapKeys() {
+		codes = append(codes, int(k.Int()))
+	}
+	sort.Ints(codes)
+	return codes
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		expected []string
+	}{
+		{
+			name: "valid",
+			doc: `{
+				"swagger": "2.0",
+				"info": {"title": "pets", "version": "1.0"},
+				"definitions": {
+					"Pet": {"type": "object", "properties": {"name": {"type": "string", "default": "rex"}}},
+					"a/b": {"type": "string"}
+				},
+				"parameters": {"id": {"name": "id", "in": "path", "required": true, "type": "string"}},
+				"paths": {
+					"/pets/{id}": {
+						"parameters": [{"$ref": "#/parameters/id"}],
+						"get": {
+							"operationId": "getPet",
+							"parameters": [{"name": "limit", "in": "query", "type": "integer", "default": 10, "maximum": 100}],
+							"responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Pet"}}}
+						},
+						"put": {
+							"operationId": "putPet",
+							"parameters": [{"name": "pet", "in": "body", "schema": {"$ref": "#/definitions/a~1b"}}],
+							"responses": {"default": {"description": "ok"}}
+						}
+					}
+				}
+			}`,
+		},
+		{
+			name: "invalid",
+			doc: `{
+				"swagger": "3.0",
+				"info": {"title": "pets", "version": "1.0"},
+				"definitions": {
+					"Pet": {"type": "object", "properties": {
+						"age": {"type": "integer", "default": "old"},
+						"owner": {"$ref": "#/definitions/Owner"}
+					}}
+				},
+				"paths": {
+					"/pets/{id}": {
+						"get": {
+							"operationId": "getPet",
+							"parameters": [
+								{"name": "name", "in": "path", "type": "string"},
+								{"name": "limit", "in": "query", "type": "integer", "default": 1000, "maximum": 100},
+								{"name": "x", "in": "cookie", "type": "string"},
+								{"name": "tag", "in": "query"}
+							],
+							"responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Missing"}}}
+						},
+						"post": {
+							"operationId": "getPet",
+							"parameters": [
+								{"name": "id", "in": "path", "required": true, "type": "string"},
+								{"name": "a", "in": "body"},
+								{"name": "b", "in": "formData", "type": "string"}
+							],
+							"responses": {"default": {"$ref": "#/responses/Missing"}}
+						}
+					}
+				}
+			}`,
+			expected: []string{
+				`swagger is invalid: unsupported version "3.0", must be "2.0"`,
+				"definitions[Pet].properties[age].default in body must be of type integer: \"string\"",
+				`definitions[Pet].properties[owner].$ref is invalid: unresolved reference "#/definitions/Owner"`,
+				"paths[/pets/{id}].get.parameters[0].required is invalid: must be true for path parameters",
+				"paths[/pets/{id}].get.parameters[1].default in body should be less than or equal to 100",
+				`paths[/pets/{id}].get.parameters[2].in is invalid: unsupported location "cookie"`,
+				"paths[/pets/{id}].get.parameters[3].type is invalid: must be set for query parameters",
+				`paths[/pets/{id}].get.responses[200].schema.$ref is invalid: unresolved reference "#/definitions/Missing"`,
+				`paths[/pets/{id}].get.parameters is invalid: path parameter "name" is not in the path`,
+				`paths[/pets/{id}].get.parameters is invalid: path parameter "id" is not declared`,
+				`paths[/pets/{id}].post.operationId is invalid: duplicate operation ID "getPet", also used by paths[/pets/{id}].get`,
+				"paths[/pets/{id}].post.parameters[1].schema is invalid: must be set for body parameters",
+				`paths[/pets/{id}].post.responses[default].$ref is invalid: unresolved reference "#/responses/Missing"`,
+				"paths[/pets/{id}].post.parameters is invalid: must not have both body and formData parameters",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := new(spec.Swagger)
+			require.NoError(t, json.Unmarshal([]byte(tt.doc), doc))
+			res := Spec(doc, strfmt.Default)
+			assert.ElementsMatch(t, tt.expected, errorStrings(res))
+		})
+	}
+}
+
+func TestSpecV3(t *testing.T) {
+	doc := new(spec3.OpenAPI)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "pets", "version": "1.0"},
+		"components": {
+			"schemas": {"Pet": {"type": "object", "properties": {"age": {"type": "integer", "minimum": 0, "default": -1}}}},
+			"parameters": {"id": {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}}
+		},
+		"paths": {
+			"/pets/{id}": {
+				"parameters": [{"$ref": "#/components/parameters/id"}],
+				"get": {
+					"operationId": "getPet",
+					"parameters": [{"name": "session", "in": "cookie", "schema": {"type": "string"}}],
+					"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}
+				},
+				"put": {
+					"operationId": "getPet",
+					"parameters": [{"name": "x", "in": "body"}],
+					"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Missing"}}}},
+					"responses": {"200": {"description": "ok"}}
+				}
+			},
+			"/owners/{owner}": {
+				"get": {"responses": {"200": {"$ref": "#/components/responses/Missing"}}}
+			}
+		}
+	}`), doc))
+
+	res := SpecV3(doc, strfmt.Default)
+	assert.ElementsMatch(t, []string{
+		"components.schemas[Pet].properties[age].default in body should be greater than or equal to 0",
+		`paths[/owners/{owner}].get.responses[200].$ref is invalid: unresolved reference "#/components/responses/Missing"`,
+		`paths[/owners/{owner}].get.parameters is invalid: path parameter "owner" is not declared`,
+		`paths[/pets/{id}].put.operationId is invalid: duplicate operation ID "getPet", also used by paths[/pets/{id}].get`,
+		`paths[/pets/{id}].put.parameters[0].in is invalid: unsupported location "body"`,
+		`paths[/pets/{id}].put.requestBody.content[application/json].schema.$ref is invalid: unresolved reference "#/components/schemas/Missing"`,
+	}, errorStrings(res))
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+)
+
+// ItemIterator yields the items of an array one at a time.
+type ItemIterator interface {
+	// Next returns the next item, or false once there are no more items.
+	Next() (item interface{}, ok bool, err error)
+}
+
+// ItemIteratorFunc is a function implementing ItemIterator.
+type ItemIteratorFunc func() (interface{}, bool, error)
+
+// Next implements ItemIterator.
+func (f ItemIteratorFunc) Next() (interface{}, bool, error) {
+	return f()
+}
+
+// jsonItemIterator decodes the items of a JSON array one at a time.
+type jsonItemIterator struct {
+	dec  *json.Decoder
+	done bool
+}
+
+// NewJSONItemIterator returns an iterator over the items of the JSON array
+// read next by dec, decoding a single item at a time. Configure dec, e.g.
+// with UseNumber, before creating the iterator.
+func NewJSONItemIterator(dec *json.Decoder) (ItemIterator, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		return nil, fmt.Errorf("expected a JSON array, got %v", t)
+	}
+	return &jsonItemIterator{dec: dec}, nil
+}
+
+func (it *jsonItemIterator) Next() (interface{}, bool, error) {
+	if it.done {
+		return nil, false, nil
+	}
+	if !it.dec.More() {
+		it.done = true
+		// consume the closing bracket
+		if _, err := it.dec.Token(); err != nil {
+			return nil, false, err
+		}
+		return nil, false, nil
+	}
+	var item interface{}
+	if err := it.dec.Decode(&item); err != nil {
+		it.done = true
+		return nil, false, err
+	}
+	return item, true, nil
+}
+
+// ValidateItems validates the array whose items are yielded by items
+// against the schema, as Validate, without holding all the items in memory.
+// It is meant for very large arrays, e.g. decoded by NewJSONItemIterator.
+//
+// Only the keywords applying to arrays are checked: type, prefixItems,
+// items, additionalItems, minItems, maxItems, uniqueItems and
+// x-kubernetes-unique-fields. The keywords needing the whole array, e.g.
+// enum, allOf, anyOf, oneOf, not, unevaluatedItems and vendor extension
+// validators, are not.
+// uniqueItems keeps the items in memory, and x-kubernetes-unique-fields the
+// JSON encoding of the compared values.
+//
+// An error of the iterator is added to the result, and stops validation.
+func (s *SchemaValidator) ValidateItems(items ItemIterator) *Result {
+	result := new(Result)
+	if s == nil {
+		return result
+	}
+	if s.Options.tracer != nil {
+		s.Options.tracer.EnterSchema(s.Path, s.Schema)
+	}
+	// the type validator only depends on the kind of empty arrays
+	result.Merge(s.validators[0].Validate([]interface{}{}))
+
+	sv := s.validators[5].(*schemaSliceValidator)
+	stream := newStreamValidator(sv)
+	for i := 0; ; i++ {
+		if s.Options.ctx != nil {
+			if err := s.Options.ctx.Err(); err != nil {
+				result.AddErrors(err)
+				break
+			}
+		}
+		if result.reachedMaxErrors(s.Options.maxErrors) {
+			break
+		}
+		item, ok, err := items.Next()
+		if err != nil {
+			result.AddErrors(err)
+			break
+		}
+		if !ok {
+			break
+		}
+		result.Merge(stream.validateItem(i, item))
+	}
+	result.Merge(stream.finish())
+	if s.Options.jsonPointers {
+		setJSONPointers(result, s.Path, s.Options.pointer)
+	}
+	result.AddErrors(s.structuralErrors...)
+	if !s.Options.nested {
+		result.truncate(s.Options.maxErrors)
+		result.localize(s.Options.catalog)
+	}
+	result.Inc()
+	if s.Options.tracer != nil {
+		s.Options.tracer.ExitSchema(s.Path, result)
+	}
+	return result
+}
+
+// streamValidator validates the items of an array one at a time, for the
+// keywords of a schemaSliceValidator.
+type streamValidator struct {
+	s          *schemaSliceValidator
+	items      *SchemaValidator
+	additional *SchemaValidator
+	size       int
+
+	// uniqueItems groups the items by JSON encoding, which is the same for
+	// equal items.
+	uniqueItems map[string][]interface{}
+	duplicate   bool
+
+	// uniqueFields maps the encoded values of each unique field to the
+	// path of the first item with this value.
+	uniqueFields []map[string]string
+}
+
+func newStreamValidator(s *schemaSliceValidator) *streamValidator {
+	v := &streamValidator{s: s}
+	if s.Items != nil && s.Items.Schema != nil {
+		v.items = NewSchemaValidator(s.Items.Schema, s.Root, s.Path, s.KnownFormats, s.Options.childOptions("items")...)
+	}
+	if s.AdditionalItems != nil && s.AdditionalItems.Schema != nil {
+		v.additional = NewSchemaValidator(s.AdditionalItems.Schema, s.Root, s.Path, s.KnownFormats, s.Options.childOptions("additionalItems")...)
+	}
+	if s.UniqueItems {
+		v.uniqueItems = map[string][]interface{}{}
+	}
+	for range s.UniqueFields {
+		v.uniqueFields = append(v.uniqueFields, map[string]string{})
+	}
+	return v
+}
+
+func (v *streamValidator) validateItem(i int, item interface{}) *Result {
+	s := v.s
+	result := new(Result)
+	v.size++
+
+	tupleSize := 0
+	if s.Items != nil {
+		tupleSize = len(s.Items.Schemas)
+	}
+	path := s.itemPath(i, item)
+	if i < len(s.PrefixItems) {
+		validator := NewSchemaValidator(&s.PrefixItems[i], s.Root, path, s.KnownFormats, s.Options.childValueOptions("prefixItems/"+strconv.Itoa(i), strconv.Itoa(i))...)
+		result.Merge(validator.Validate(item))
+	}
+	switch {
+	case v.items != nil:
+		// items applies to the items following prefixItems
+		if i >= len(s.PrefixItems) {
+			v.items.SetPath(path)
+			if s.Options.jsonPointers {
+				v.items.setPointer(childPointer(s.Options.pointer, strconv.Itoa(i)))
+			}
+			result.Merge(v.items.Validate(item))
+		}
+	case i < tupleSize:
+		validator := NewSchemaValidator(&s.Items.Schemas[i], s.Root, path, s.KnownFormats, s.Options.childValueOptions("items/"+strconv.Itoa(i), strconv.Itoa(i))...)
+		result.Merge(validator.Validate(item))
+	case s.AdditionalItems != nil:
+		if tupleSize > 0 && !s.AdditionalItems.Allows && i == tupleSize {
+			result.AddErrors(arrayDoesNotAllowAdditionalItemsMsg())
+		}
+		if v.additional != nil {
+			v.additional.SetPath(path)
+			if s.Options.jsonPointers {
+				v.additional.setPointer(childPointer(s.Options.pointer, strconv.Itoa(i)))
+			}
+			result.Merge(v.additional.Validate(item))
+		}
+	}
+
+	if v.uniqueItems != nil && !v.duplicate {
+		// items failing to encode are all compared with each other
+		key, _ := json.Marshal(item)
+		for _, u := range v.uniqueItems[string(key)] {
+			if equalItems(item, u) {
+				v.duplicate = true
+				break
+			}
+		}
+		v.uniqueItems[string(key)] = append(v.uniqueItems[string(key)], item)
+	}
+	for j, field := range s.UniqueFields {
+		fieldPath := strings.Split(strings.TrimPrefix(field, "."), ".")
+		fv, ok := fieldValue(item, fieldPath)
+		if !ok {
+			continue
+		}
+		key, err := json.Marshal(fv)
+		if err != nil {
+			continue
+		}
+		itemPath := path + "." + strings.Join(fieldPath, ".")
+		if previous, found := v.uniqueFields[j][string(key)]; found {
+			err := errors.DuplicateField(itemPath, s.In, previous, fv)
+			err.Pointer = s.fieldPointer(i, fieldPath)
+			result.AddErrors(err)
+			continue
+		}
+		v.uniqueFields[j][string(key)] = itemPath
+	}
+	return result
+}
+
+// finish validates the keywords depending on the number of items.
+func (v *streamValidator) finish() *Result {
+	s := v.s
+	result := new(Result)
+	if s.MinItems != nil {
+		if err := MinItems(s.Path, s.In, int64(v.size), *s.MinItems); err != nil {
+			result.AddErrors(err)
+		}
+	}
+	if s.MaxItems != nil {
+		if err := MaxItems(s.Path, s.In, int64(v.size), *s.MaxItems); err != nil {
+			result.AddErrors(err)
+		}
+	}
+	if v.duplicate {
+		result.AddErrors(errors.DuplicateItems(s.Path, s.In))
+	}
+	return result
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestValidateItems(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "array",
+		"maxItems": 3,
+		"uniqueItems": true,
+		"x-kubernetes-unique-fields": ["name"],
+		"items": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {"name": {"type": "string"}, "port": {"type": "integer", "maximum": 100}}
+		}
+	}`), schema))
+
+	for _, input := range []string{
+		`[]`,
+		`[{"name": "a", "port": 80}, {"name": "b"}]`,
+		`[{"name": "a", "port": 800}, {"port": 1}, {"name": "a"}, {"name": "a"}]`,
+	} {
+		var data []interface{}
+		require.NoError(t, json.Unmarshal([]byte(input), &data))
+		expected := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(data)
+
+		items, err := NewJSONItemIterator(json.NewDecoder(strings.NewReader(input)))
+		require.NoError(t, err)
+		actual := NewSchemaValidator(schema, nil, "spec", strfmt.Default).ValidateItems(items)
+		assert.ElementsMatch(t, errorStrings(expected), errorStrings(actual), input)
+	}
+}
+
+func TestValidateItemsTuple(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:            spec.StringOrArray{"array"},
+		Items:           &spec.SchemaOrArray{Schemas: []spec.Schema{*spec.StringProperty(), *spec.Int64Property()}},
+		AdditionalItems: &spec.SchemaOrBool{Allows: true, Schema: spec.BoolProperty()},
+	}}
+	i := 0
+	values := []interface{}{"a", "b", true, 1}
+	items := ItemIteratorFunc(func() (interface{}, bool, error) {
+		if i == len(values) {
+			return nil, false, nil
+		}
+		i++
+		return values[i-1], true, nil
+	})
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default).ValidateItems(items)
+	assert.Equal(t, []string{
+		`spec.1 in body must be of type integer: "string"`,
+		`spec.3 in body must be of type boolean: "integer"`,
+	}, errorStrings(res))
+}
+
+func TestValidateItemsErrors(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:  spec.StringOrArray{"array"},
+		Items: &spec.SchemaOrArray{Schema: spec.StringProperty()},
+	}}
+
+	_, err := NewJSONItemIterator(json.NewDecoder(strings.NewReader(`{"a": 1}`)))
+	assert.Error(t, err)
+
+	items, err := NewJSONItemIterator(json.NewDecoder(strings.NewReader(`["a", 1, `)))
+	require.NoError(t, err)
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default).ValidateItems(items)
+	require.Len(t, res.Errors, 2)
+	assert.Equal(t, `spec.1 in body must be of type string: "number"`, res.Errors[0].Error())
+	assert.Contains(t, res.Errors[1].Error(), "unexpected end of JSON input")
+
+	object := &spec.Schema{SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"object"}}}
+	items, err = NewJSONItemIterator(json.NewDecoder(strings.NewReader(`[]`)))
+	require.NoError(t, err)
+	res = NewSchemaValidator(object, nil, "spec", strfmt.Default).ValidateItems(items)
+	assert.Equal(t, []string{`spec in body must be of type object: "array"`}, errorStrings(res))
+}
+
+func TestValidateItemsJSONPointers(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:     spec.StringOrArray{"array"},
+		MinItems: swag.Int64(3),
+		Items: &spec.SchemaOrArray{Schema: &spec.Schema{SchemaProps: spec.SchemaProps{
+			Type:       spec.StringOrArray{"object"},
+			Properties: map[string]spec.Schema{"a.b": *spec.StringProperty()},
+		}}},
+	}}
+	items, err := NewJSONItemIterator(json.NewDecoder(strings.NewReader(`[{"a.b": "x"}, {"a.b": 1}]`)))
+	require.NoError(t, err)
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default, EnableJSONPointers()).ValidateItems(items)
+	var pointers []string
+	for _, err := range res.Errors {
+		pointers = append(pointers, err.(*errors.Validation).Pointer)
+	}
+	assert.Equal(t, []string{"/1/a.b", ""}, pointers)
+}
+
+func TestValidateItemsMaxErrors(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:  spec.StringOrArray{"array"},
+		Items: &spec.SchemaOrArray{Schema: spec.StringProperty()},
+	}}
+	next := 0
+	items := ItemIteratorFunc(func() (interface{}, bool, error) {
+		next++
+		return next, true, nil
+	})
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default, WithMaxErrors(5)).ValidateItems(items)
+	assert.Len(t, res.Errors, 5)
+	assert.True(t, res.Truncated)
+	assert.Equal(t, 5, next)
+}
+
+func sliceItems(values []interface{}) ItemIterator {
+	i := 0
+	return ItemIteratorFunc(func() (interface{}, bool, error) {
+		if i == len(values) {
+			return nil, false, nil
+		}
+		i++
+		return values[i-1], true, nil
+	})
+}
+
+func TestValidateItemsAgreesWithValidate(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:        spec.StringOrArray{"array"},
+		UniqueItems: true,
+		PrefixItems: []spec.Schema{*spec.StringProperty(), *spec.BoolProperty()},
+		Items:       &spec.SchemaOrArray{Schema: spec.Int64Property()},
+	}}
+
+	for _, values := range [][]interface{}{
+		{},
+		{"a"},
+		{"a", true, int64(1), int64(2)},
+		{1, "b", "c"},
+		{"a", false, int64(1), int64(1)},
+		// equal for JSON, but not for uniqueItems
+		{"a", true, int64(1), float64(1)},
+		{"a", true, []interface{}{int64(1)}, []interface{}{float64(1)}},
+		{"a", true, map[string]interface{}{"x": int64(1), "y": "z"}, map[string]interface{}{"y": "z", "x": int64(1)}},
+	} {
+		expected := NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(values)
+		actual := NewSchemaValidator(schema, nil, "spec", strfmt.Default).ValidateItems(sliceItems(values))
+		assert.ElementsMatch(t, errorStrings(expected), errorStrings(actual), "%#v", values)
+	}
+}
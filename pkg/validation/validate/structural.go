@@ -0,0 +1,244 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"sort"
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/internal"
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const junctorsMessage = "allOf, anyOf, oneOf and not"
+
+// structuralContext is the position of a schema being checked by ValidateStructuralSchema.
+type structuralContext struct {
+	// root is set for the root schema.
+	root bool
+	// junctor is set for schemas under allOf, anyOf, oneOf or not.
+	junctor bool
+	// intOrStringBranch is set for the direct junctor schemas of an
+	// x-kubernetes-int-or-string schema, which may set type integer or string.
+	intOrStringBranch bool
+}
+
+// ValidateStructuralSchema checks that schema is a Kubernetes structural
+// schema, as required for the schemas of CustomResourceDefinitions:
+//   - the root is of type object, and every schema outside of allOf, anyOf,
+//     oneOf and not has a type, unless it is x-kubernetes-int-or-string or
+//     x-kubernetes-preserve-unknown-fields;
+//   - schemas under allOf, anyOf, oneOf and not only validate values: they do not
+//     set type, description, default, nullable or additionalProperties, and every
+//     property, items and additionalProperties is also declared outside of them;
+//   - $ref, $schema, id, definitions, patternProperties, additionalItems,
+//     uniqueItems: true and lists of items schemas are not used, and neither is
+//     additionalProperties along with properties or set to false;
+//   - metadata only restricts metadata.name and metadata.generateName.
+//
+// Errors are named after the path of the offending keyword from root, e.g.
+// "properties[spec].items.type".
+func ValidateStructuralSchema(schema *spec.Schema, root string) *Result {
+	res := new(Result)
+	if schema == nil {
+		return res
+	}
+	if len(schema.Type) != 1 || schema.Type[0] != objectType {
+		res.AddErrors(errors.NotStructural(internal.JoinPath(root, "type"), "", `must be "object" at the root`))
+	}
+	checkStructural(res, schema, root, structuralContext{root: true})
+	return res
+}
+
+func checkStructural(res *Result, s *spec.Schema, path string, ctx structuralContext) {
+	forbid := func(keyword, reason string) {
+		res.AddErrors(errors.NotStructural(internal.JoinPath(path, keyword), "", reason))
+	}
+
+	if s.Ref.String() != "" {
+		forbid("$ref", "must not be used, references must be expanded")
+	}
+	if s.Schema != "" {
+		forbid("$schema", "must not be used")
+	}
+	if s.ID != "" {
+		forbid("id", "must not be used")
+	}
+	if len(s.Definitions) > 0 {
+		forbid("definitions", "must not be used")
+	}
+	if len(s.PatternProperties) > 0 {
+		forbid("patternProperties", "must not be used")
+	}
+	if s.AdditionalItems != nil {
+		forbid("additionalItems", "must not be used")
+	}
+	if s.UniqueItems {
+		forbid("uniqueItems", "must not be true")
+	}
+	if s.Items != nil && len(s.Items.Schemas) > 0 {
+		forbid("items", "must be a schema, not a list of schemas")
+	}
+	if s.AdditionalProperties != nil {
+		if len(s.Properties) > 0 {
+			forbid("additionalProperties", "must not be used together with properties")
+		}
+		if !s.AdditionalProperties.Allows {
+			forbid("additionalProperties", "must not be false")
+		}
+	}
+
+	preserve, preserveSet := s.Extensions.GetBool("x-kubernetes-preserve-unknown-fields")
+	if preserveSet && !preserve {
+		forbid("x-kubernetes-preserve-unknown-fields", "must be true or undefined")
+	}
+	intOrString, _ := s.Extensions.GetBool("x-kubernetes-int-or-string")
+	embedded, _ := s.Extensions.GetBool("x-kubernetes-embedded-resource")
+
+	if ctx.junctor {
+		intOrStringType := ctx.intOrStringBranch && len(s.Type) == 1 && (s.Type[0] == integerType || s.Type[0] == stringType)
+		if len(s.Type) > 0 && !intOrStringType {
+			forbid("type", "must not be specified inside "+junctorsMessage)
+		}
+		if s.Description != "" {
+			forbid("description", "must not be specified inside "+junctorsMessage)
+		}
+		if s.Default != nil {
+			forbid("default", "must not be specified inside "+junctorsMessage)
+		}
+		if s.Nullable {
+			forbid("nullable", "must not be specified inside "+junctorsMessage)
+		}
+		if s.AdditionalProperties != nil {
+			forbid("additionalProperties", "must not be specified inside "+junctorsMessage)
+		}
+	} else {
+		switch {
+		case intOrString && len(s.Type) > 0:
+			forbid("type", "must be empty for x-kubernetes-int-or-string")
+		case len(s.Type) == 0 && !intOrString && !preserve:
+			forbid("type", "must not be empty")
+		case embedded && (len(s.Type) != 1 || s.Type[0] != objectType):
+			forbid("type", `must be "object" for x-kubernetes-embedded-resource`)
+		}
+		if metadata, ok := s.Properties["metadata"]; ok && (ctx.root || embedded) {
+			for _, name := range sortedSchemaNames(metadata.Properties) {
+				if name != "name" && name != "generateName" {
+					res.AddErrors(errors.NotStructural(internal.JoinPath(path, "properties[metadata].properties["+name+"]"), "",
+						"must not be specified, only metadata.name and metadata.generateName may be restricted"))
+				}
+			}
+		}
+	}
+
+	for _, junctors := range junctorsOf(s) {
+		keyword := junctors.keyword
+		for i := range junctors.schemas {
+			junctor := &junctors.schemas[i]
+			jPath := internal.JoinPath(path, keyword+"["+strconv.Itoa(i)+"]")
+			if !ctx.junctor {
+				// nested junctors are checked against the schema outside of all junctors
+				checkJunctorSkeleton(res, junctor, s, jPath)
+			}
+			checkStructural(res, junctor, jPath, structuralContext{junctor: true, intOrStringBranch: intOrString})
+		}
+	}
+	if s.Not != nil {
+		jPath := internal.JoinPath(path, "not")
+		if !ctx.junctor {
+			checkJunctorSkeleton(res, s.Not, s, jPath)
+		}
+		checkStructural(res, s.Not, jPath, structuralContext{junctor: true})
+	}
+
+	child := structuralContext{junctor: ctx.junctor}
+	for _, name := range sortedSchemaNames(s.Properties) {
+		p := s.Properties[name]
+		checkStructural(res, &p, internal.JoinPath(path, "properties["+name+"]"), child)
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		checkStructural(res, s.Items.Schema, internal.JoinPath(path, "items"), child)
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		checkStructural(res, s.AdditionalProperties.Schema, internal.JoinPath(path, "additionalProperties"), child)
+	}
+}
+
+// checkJunctorSkeleton checks that the properties, items and additionalProperties
+// of the junctor schema j are declared in outside, the schema j is a junctor of.
+func checkJunctorSkeleton(res *Result, j, outside *spec.Schema, path string) {
+	missing := func(keyword string) {
+		res.AddErrors(errors.NotStructural(internal.JoinPath(path, keyword), "", "must be specified outside of "+junctorsMessage+" too"))
+	}
+
+	for _, name := range sortedSchemaNames(j.Properties) {
+		var o *spec.Schema
+		if outside != nil {
+			if p, ok := outside.Properties[name]; ok {
+				o = &p
+			}
+		}
+		if o == nil {
+			missing("properties[" + name + "]")
+			continue
+		}
+		p := j.Properties[name]
+		checkJunctorSkeleton(res, &p, o, internal.JoinPath(path, "properties["+name+"]"))
+	}
+	if j.Items != nil && j.Items.Schema != nil {
+		if outside == nil || outside.Items == nil || outside.Items.Schema == nil {
+			missing("items")
+		} else {
+			checkJunctorSkeleton(res, j.Items.Schema, outside.Items.Schema, internal.JoinPath(path, "items"))
+		}
+	}
+	if j.AdditionalProperties != nil && j.AdditionalProperties.Schema != nil {
+		if outside == nil || outside.AdditionalProperties == nil || outside.AdditionalProperties.Schema == nil {
+			missing("additionalProperties")
+		} else {
+			checkJunctorSkeleton(res, j.AdditionalProperties.Schema, outside.AdditionalProperties.Schema, internal.JoinPath(path, "additionalProperties"))
+		}
+	}
+	// nested junctors must match the same outside schema
+	for _, junctors := range junctorsOf(j) {
+		for i := range junctors.schemas {
+			checkJunctorSkeleton(res, &junctors.schemas[i], outside, internal.JoinPath(path, junctors.keyword+"["+strconv.Itoa(i)+"]"))
+		}
+	}
+	if j.Not != nil {
+		checkJunctorSkeleton(res, j.Not, outside, internal.JoinPath(path, "not"))
+	}
+}
+
+type junctorList struct {
+	keyword string
+	schemas []spec.Schema
+}
+
+func junctorsOf(s *spec.Schema) []junctorList {
+	return []junctorList{{"allOf", s.AllOf}, {"anyOf", s.AnyOf}, {"oneOf", s.OneOf}}
+}
+
+func sortedSchemaNames(m map[string]spec.Schema) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
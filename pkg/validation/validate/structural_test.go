@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestValidateStructuralSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected []string
+	}{
+		{
+			name: "structural",
+			schema: `{
+				"type": "object",
+				"properties": {
+					"metadata": {"type": "object", "properties": {"name": {"type": "string", "maxLength": 10}}},
+					"spec": {
+						"type": "object",
+						"properties": {
+							"port": {"x-kubernetes-int-or-string": true, "anyOf": [{"type": "integer"}, {"type": "string"}]},
+							"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+							"raw": {"x-kubernetes-preserve-unknown-fields": true},
+							"replicas": {"type": "integer"}
+						},
+						"oneOf": [{"required": ["replicas"]}, {"properties": {"replicas": {"minimum": 1}}}]
+					}
+				}
+			}`,
+		},
+		{
+			name: "missing types",
+			schema: `{
+				"properties": {"spec": {"properties": {"items": {"type": "array", "items": {}}}}}
+			}`,
+			expected: []string{
+				`type is not structural: must be "object" at the root`,
+				"type is not structural: must not be empty",
+				"properties[spec].type is not structural: must not be empty",
+				"properties[spec].properties[items].items.type is not structural: must not be empty",
+			},
+		},
+		{
+			name: "value validations only in junctors",
+			schema: `{
+				"type": "object",
+				"properties": {"a": {"type": "string"}},
+				"allOf": [{"type": "object", "description": "d", "properties": {"a": {"default": "x"}, "b": {"minLength": 1}}}],
+				"not": {"items": {"nullable": true}}
+			}`,
+			expected: []string{
+				"allOf[0].type is not structural: must not be specified inside allOf, anyOf, oneOf and not",
+				"allOf[0].description is not structural: must not be specified inside allOf, anyOf, oneOf and not",
+				"allOf[0].properties[b] is not structural: must be specified outside of allOf, anyOf, oneOf and not too",
+				"allOf[0].properties[a].default is not structural: must not be specified inside allOf, anyOf, oneOf and not",
+				"not.items is not structural: must be specified outside of allOf, anyOf, oneOf and not too",
+				"not.items.nullable is not structural: must not be specified inside allOf, anyOf, oneOf and not",
+			},
+		},
+		{
+			name: "nested junctors",
+			schema: `{
+				"type": "object",
+				"properties": {"a": {"type": "string"}},
+				"anyOf": [{"allOf": [{"properties": {"a": {"minLength": 1}, "b": {}}}]}]
+			}`,
+			expected: []string{
+				"anyOf[0].allOf[0].properties[b] is not structural: must be specified outside of allOf, anyOf, oneOf and not too",
+			},
+		},
+		{
+			name: "forbidden keywords",
+			schema: `{
+				"type": "object",
+				"id": "x",
+				"definitions": {"a": {"type": "string"}},
+				"patternProperties": {"^a": {"type": "string"}},
+				"properties": {
+					"list": {"type": "array", "uniqueItems": true, "items": [{"type": "string"}], "additionalItems": false},
+					"ref": {"$ref": "#/definitions/a"},
+					"closed": {"type": "object", "additionalProperties": false},
+					"both": {"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": {"type": "string"}},
+					"pruned": {"type": "object", "x-kubernetes-preserve-unknown-fields": false}
+				}
+			}`,
+			expected: []string{
+				"id is not structural: must not be used",
+				"definitions is not structural: must not be used",
+				"patternProperties is not structural: must not be used",
+				"properties[both].additionalProperties is not structural: must not be used together with properties",
+				"properties[closed].additionalProperties is not structural: must not be false",
+				"properties[list].additionalItems is not structural: must not be used",
+				"properties[list].uniqueItems is not structural: must not be true",
+				"properties[list].items is not structural: must be a schema, not a list of schemas",
+				"properties[pruned].x-kubernetes-preserve-unknown-fields is not structural: must be true or undefined",
+				"properties[ref].$ref is not structural: must not be used, references must be expanded",
+				"properties[ref].type is not structural: must not be empty",
+			},
+		},
+		{
+			name: "kubernetes extensions",
+			schema: `{
+				"type": "object",
+				"properties": {
+					"metadata": {"type": "object", "properties": {"labels": {"type": "object"}}},
+					"port": {"type": "string", "x-kubernetes-int-or-string": true},
+					"template": {
+						"type": "string",
+						"x-kubernetes-embedded-resource": true,
+						"properties": {"metadata": {"type": "object", "properties": {"namespace": {"type": "string"}}}}
+					}
+				}
+			}`,
+			expected: []string{
+				"properties[metadata].properties[labels] is not structural: must not be specified, only metadata.name and metadata.generateName may be restricted",
+				"properties[port].type is not structural: must be empty for x-kubernetes-int-or-string",
+				`properties[template].type is not structural: must be "object" for x-kubernetes-embedded-resource`,
+				"properties[template].properties[metadata].properties[namespace] is not structural: must not be specified, only metadata.name and metadata.generateName may be restricted",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := new(spec.Schema)
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), schema))
+			res := ValidateStructuralSchema(schema, "")
+			assert.ElementsMatch(t, tt.expected, errorStrings(res))
+		})
+	}
+}
+
+func TestRequireStructuralSchema(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "object", "properties": {"a": {}, "b": {"type": "integer"}}}`), schema))
+	input := map[string]interface{}{"b": "x"}
+
+	res := NewSchemaValidator(schema, nil, "", strfmt.Default).Validate(input)
+	assert.Equal(t, []string{`b in body must be of type integer: "string"`}, errorStrings(res))
+
+	res = NewSchemaValidator(schema, nil, "", strfmt.Default, RequireStructuralSchema()).Validate(input)
+	assert.ElementsMatch(t, []string{
+		`b in body must be of type integer: "string"`,
+		"properties[a].type is not structural: must not be empty",
+	}, errorStrings(res))
+
+	assert.Equal(t, []string{"spec.properties[a].type is not structural: must not be empty"}, errorStrings(ValidateStructuralSchema(schema, "spec")))
+}
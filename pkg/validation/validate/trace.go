@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// Tracer is notified of each schema a value is validated against, including
+// sub-schemas, e.g. to explain which sub-schema rejected a value. See WithTracer.
+//
+// Calls are nested: the schemas entered between EnterSchema and ExitSchema
+// for a path are sub-schemas of the schema entered for this path. Schemas may
+// be entered several times for the same value, e.g. for anyOf or if, and
+// are then traced each time.
+type Tracer interface {
+	// EnterSchema is called before validating the value at path against schema.
+	EnterSchema(path string, schema *spec.Schema)
+	// ExitSchema is called after validating the value at path, with the
+	// result, which must not be modified.
+	ExitSchema(path string, result *Result)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+type recordingTracer struct {
+	depth int
+	lines []string
+}
+
+func (r *recordingTracer) EnterSchema(path string, schema *spec.Schema) {
+	r.lines = append(r.lines, fmt.Sprintf("%senter %s %v", strings.Repeat("  ", r.depth), path, schema.Type))
+	r.depth++
+}
+
+func (r *recordingTracer) ExitSchema(path string, result *Result) {
+	r.depth--
+	r.lines = append(r.lines, fmt.Sprintf("%sexit %s %d", strings.Repeat("  ", r.depth), path, len(result.Errors)))
+}
+
+func TestTracer(t *testing.T) {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type: spec.StringOrArray{"object"},
+		Properties: map[string]spec.Schema{
+			"port": {SchemaProps: spec.SchemaProps{AnyOf: []spec.Schema{*spec.Int64Property(), *spec.StringProperty().WithPattern("^[a-z]+$")}}},
+		},
+	}}
+	tracer := &recordingTracer{}
+	res := NewSchemaValidator(schema, nil, "spec", strfmt.Default, WithTracer(tracer)).Validate(map[string]interface{}{"port": "80"})
+	assert.Len(t, res.Errors, 2)
+	assert.Equal(t, []string{
+		"enter spec [object]",
+		"  enter spec.port []",
+		"    enter spec.port [integer]",
+		"    exit spec.port 1",
+		"    enter spec.port [string]",
+		"    exit spec.port 1",
+		"  exit spec.port 2",
+		"exit spec 2",
+	}, tracer.lines)
+
+	tracer = &recordingTracer{}
+	NewSchemaValidator(spec.ArrayProperty(nil), nil, "spec", strfmt.Default, WithTracer(tracer)).ValidateItems(ItemIteratorFunc(func() (interface{}, bool, error) {
+		return nil, false, nil
+	}))
+	assert.Equal(t, []string{"enter spec [array]", "exit spec 0"}, tracer.lines)
+}
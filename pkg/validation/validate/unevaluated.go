@@ -0,0 +1,232 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+// unevaluatedValidator validates unevaluatedProperties and unevaluatedItems.
+//
+// A property, or item, is evaluated when a keyword of the schema applies to
+// it: properties, patternProperties and additionalProperties for objects,
+// items and additionalItems for arrays, and unevaluatedProperties or
+// unevaluatedItems of a subschema. The keywords of allOf subschemas, of the
+// dependentSchemas of present properties, and of the anyOf, oneOf, if, then
+// and else subschemas the data validates against are included.
+type unevaluatedValidator struct {
+	Path                  string
+	In                    string
+	Schema                *spec.Schema
+	UnevaluatedProperties *spec.SchemaOrBool
+	UnevaluatedItems      *spec.SchemaOrBool
+	Root                  interface{}
+	KnownFormats          strfmt.Registry
+	Options               SchemaValidatorOptions
+}
+
+func (u *unevaluatedValidator) SetPath(path string) {
+	u.Path = path
+}
+
+func (u *unevaluatedValidator) setPointer(pointer string) {
+	u.Options.pointer = pointer
+}
+
+func (u *unevaluatedValidator) Applies(source interface{}, kind reflect.Kind) bool {
+	if _, ok := source.(*spec.Schema); !ok {
+		return false
+	}
+	return (kind == reflect.Map && u.UnevaluatedProperties != nil) || (kind == reflect.Slice && u.UnevaluatedItems != nil)
+}
+
+func (u *unevaluatedValidator) Validate(data interface{}) *Result {
+	result := new(Result)
+	switch val := data.(type) {
+	case map[string]interface{}:
+		u.validateProperties(val, result)
+	case nil:
+	default:
+		if v := reflect.ValueOf(data); v.Kind() == reflect.Slice {
+			u.validateItems(v, result)
+		}
+	}
+	result.Inc()
+	return result
+}
+
+func (u *unevaluatedValidator) validateProperties(val map[string]interface{}, result *Result) {
+	evaluated := map[string]struct{}{}
+	if u.evaluatedProperties(u.Schema, val, evaluated) {
+		return
+	}
+	var unevaluated []string
+	for k := range val {
+		if _, ok := evaluated[k]; !ok {
+			unevaluated = append(unevaluated, k)
+		}
+	}
+	sort.Strings(unevaluated)
+
+	for _, k := range unevaluated {
+		if result.reachedMaxErrors(u.Options.maxErrors) {
+			return
+		}
+		if u.UnevaluatedProperties.Schema != nil {
+			validator := NewSchemaValidator(u.UnevaluatedProperties.Schema, u.Root, u.Path+"."+k, u.KnownFormats, u.Options.childValueOptions("unevaluatedProperties", k)...)
+			result.Merge(validator.Validate(val[k]))
+		} else if !u.UnevaluatedProperties.Allows {
+			result.AddErrors(errors.UnevaluatedPropertyNotAllowed(u.Path, u.In, k))
+		}
+	}
+}
+
+func (u *unevaluatedValidator) validateItems(val reflect.Value, result *Result) {
+	size := val.Len()
+	evaluated := u.evaluatedItems(u.Schema, val)
+	if evaluated >= size {
+		return
+	}
+	if u.UnevaluatedItems.Schema == nil {
+		if !u.UnevaluatedItems.Allows {
+			result.AddErrors(errors.UnevaluatedItemsNotAllowed(u.Path, u.In))
+		}
+		return
+	}
+	for i := evaluated; i < size; i++ {
+		if result.reachedMaxErrors(u.Options.maxErrors) {
+			return
+		}
+		validator := NewSchemaValidator(u.UnevaluatedItems.Schema, u.Root, fmt.Sprintf("%s.%d", u.Path, i), u.KnownFormats, u.Options.childValueOptions("unevaluatedItems", strconv.Itoa(i))...)
+		result.Merge(validator.Validate(val.Index(i).Interface()))
+	}
+}
+
+// evaluatedProperties adds the properties of val evaluated by the keywords
+// of schema, apart from its own unevaluatedProperties, to evaluated. It
+// returns true when all the properties are evaluated.
+func (u *unevaluatedValidator) evaluatedProperties(schema *spec.Schema, val map[string]interface{}, evaluated map[string]struct{}) bool {
+	if schema.AdditionalProperties != nil {
+		return true
+	}
+	for k := range val {
+		if _, ok := schema.Properties[k]; ok {
+			evaluated[k] = struct{}{}
+			continue
+		}
+		for pattern := range schema.PatternProperties {
+			if r, err := compileRegexp(pattern); err == nil && r.MatchString(k) {
+				evaluated[k] = struct{}{}
+				break
+			}
+		}
+	}
+
+	subschema := func(s *spec.Schema) bool {
+		return s.UnevaluatedProperties != nil || u.evaluatedProperties(s, val, evaluated)
+	}
+	for _, s := range u.appliedSubschemas(schema, val) {
+		if subschema(s) {
+			return true
+		}
+	}
+	for k, dep := range schema.DependentSchemas {
+		if _, ok := val[k]; ok {
+			dep := dep
+			if subschema(&dep) {
+				return true
+			}
+		}
+	}
+	for k, dep := range schema.Dependencies {
+		if _, ok := val[k]; ok && dep.Schema != nil && subschema(dep.Schema) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluatedItems returns the number of leading items of val evaluated by
+// the keywords of schema, apart from its own unevaluatedItems.
+func (u *unevaluatedValidator) evaluatedItems(schema *spec.Schema, val reflect.Value) int {
+	size := val.Len()
+	if schema.AdditionalItems != nil {
+		return size
+	}
+	evaluated := len(schema.PrefixItems)
+	if schema.Items != nil {
+		if schema.Items.Schema != nil {
+			return size
+		}
+		if len(schema.Items.Schemas) > evaluated {
+			evaluated = len(schema.Items.Schemas)
+		}
+	}
+	for _, s := range u.appliedSubschemas(schema, val.Interface()) {
+		if evaluated >= size {
+			break
+		}
+		if s.UnevaluatedItems != nil {
+			return size
+		}
+		if n := u.evaluatedItems(s, val); n > evaluated {
+			evaluated = n
+		}
+	}
+	return evaluated
+}
+
+// appliedSubschemas returns the allOf subschemas of schema, and the anyOf,
+// oneOf, if, then and else subschemas whose annotations apply to data.
+func (u *unevaluatedValidator) appliedSubschemas(schema *spec.Schema, data interface{}) []*spec.Schema {
+	var applied []*spec.Schema
+	for i := range schema.AllOf {
+		applied = append(applied, &schema.AllOf[i])
+	}
+	for i := range schema.AnyOf {
+		if u.isValid(&schema.AnyOf[i], data) {
+			applied = append(applied, &schema.AnyOf[i])
+		}
+	}
+	for i := range schema.OneOf {
+		if u.isValid(&schema.OneOf[i], data) {
+			applied = append(applied, &schema.OneOf[i])
+		}
+	}
+	if schema.If != nil {
+		if u.isValid(schema.If, data) {
+			applied = append(applied, schema.If)
+			if schema.Then != nil {
+				applied = append(applied, schema.Then)
+			}
+		} else if schema.Else != nil {
+			applied = append(applied, schema.Else)
+		}
+	}
+	return applied
+}
+
+func (u *unevaluatedValidator) isValid(schema *spec.Schema, data interface{}) bool {
+	return NewSchemaValidator(schema, u.Root, u.Path, u.KnownFormats, u.Options.Options()...).Validate(data).IsValid()
+}
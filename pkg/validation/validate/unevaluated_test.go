@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+)
+
+func TestUnevaluatedProperties(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {"kind": {"type": "string"}},
+		"allOf": [{"properties": {"name": {"type": "string"}}}],
+		"anyOf": [
+			{"required": ["port"], "properties": {"port": {"type": "integer"}}},
+			{"required": ["path"], "properties": {"path": {"type": "string"}}}
+		],
+		"if": {"properties": {"kind": {"enum": ["tls"]}}},
+		"then": {"properties": {"cert": {"type": "string"}}},
+		"dependentSchemas": {"name": {"patternProperties": {"^x-": {}}}},
+		"unevaluatedProperties": false
+	}`), schema))
+	require.NotNil(t, schema.UnevaluatedProperties)
+	assert.False(t, schema.UnevaluatedProperties.Allows)
+	assert.Empty(t, schema.ExtraProps)
+
+	validate := func(input string) []string {
+		var data interface{}
+		require.NoError(t, json.Unmarshal([]byte(input), &data))
+		return errorStrings(NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(data))
+	}
+	assert.Empty(t, validate(`{"kind": "tls", "name": "a", "port": 1, "cert": "c", "x-a": 1}`))
+	// only the valid anyOf branches, and then for data validating if, evaluate properties
+	assert.Equal(t, []string{"spec.path in body is an unevaluated property"}, validate(`{"port": 1, "path": 2}`))
+	assert.Equal(t, []string{"spec.cert in body is an unevaluated property"}, validate(`{"kind": "tcp", "port": 1, "cert": "c"}`))
+	// dependentSchemas only apply with their property
+	assert.Equal(t, []string{"spec.x-a in body is an unevaluated property"}, validate(`{"port": 1, "x-a": 1}`))
+
+	// a schema validates the unevaluated properties
+	schema.UnevaluatedProperties = &spec.SchemaOrBool{Allows: true, Schema: spec.Int64Property()}
+	assert.Equal(t, []string{`spec.other in body must be of type integer: "string"`}, validate(`{"port": 1, "other": "a", "more": 2}`))
+
+	// additionalProperties evaluates all properties
+	schema.AdditionalProperties = &spec.SchemaOrBool{Allows: true}
+	assert.Empty(t, validate(`{"port": 1, "other": "a"}`))
+
+	b, err := json.Marshal(&spec.Schema{SchemaProps: spec.SchemaProps{UnevaluatedProperties: &spec.SchemaOrBool{Allows: false}}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"unevaluatedProperties": false}`, string(b))
+}
+
+func TestUnevaluatedItems(t *testing.T) {
+	schema := new(spec.Schema)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "array",
+		"items": [{"type": "string"}],
+		"allOf": [{"items": [{}, {"type": "integer"}]}],
+		"anyOf": [{"items": [{}, {}, {"type": "boolean"}]}, {"maxItems": 2}],
+		"unevaluatedItems": {"type": "string"}
+	}`), schema))
+	require.NotNil(t, schema.UnevaluatedItems)
+	assert.Empty(t, schema.ExtraProps)
+
+	validate := func(input string) []string {
+		var data interface{}
+		require.NoError(t, json.Unmarshal([]byte(input), &data))
+		return errorStrings(NewSchemaValidator(schema, nil, "spec", strfmt.Default).Validate(data))
+	}
+	assert.Empty(t, validate(`["a", 1]`))
+	assert.Empty(t, validate(`["a", 1, true, "b"]`))
+	assert.Equal(t, []string{`spec.4 in body must be of type string: "number"`}, validate(`["a", 1, true, "b", 2]`))
+
+	schema.UnevaluatedItems = &spec.SchemaOrBool{Allows: false}
+	assert.Equal(t, []string{"spec in body can't have unevaluated items"}, validate(`["a", 1, true, "b"]`))
+
+	// items of a nested unevaluatedItems are evaluated
+	schema.AllOf[0].UnevaluatedItems = &spec.SchemaOrBool{Allows: true}
+	assert.Empty(t, validate(`["a", 1, true, "b"]`))
+}
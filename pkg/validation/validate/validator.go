@@ -39,6 +39,10 @@ type basicCommonValidator struct {
 	In      string
 	Default interface{}
 	Enum    []interface{}
+	Const   interface{}
+
+	// enumSet is the set of the values of Enum, if all strings and cached.
+	enumSet map[string]struct{}
 }
 
 func (b *basicCommonValidator) SetPath(path string) {
@@ -54,16 +58,19 @@ func (b *basicCommonValidator) Applies(source interface{}, kind reflect.Kind) bo
 }
 
 func (b *basicCommonValidator) Validate(data interface{}) (res *Result) {
+	if b.Const != nil && !equalValues(data, b.Const) {
+		return errorHelp.sErr(errors.ConstFail(b.Path, b.In, data, b.Const))
+	}
 	if len(b.Enum) > 0 {
+		if s, ok := data.(string); ok && b.enumSet != nil {
+			if _, found := b.enumSet[s]; found {
+				return nil
+			}
+			return errorHelp.sErr(errors.EnumFail(b.Path, b.In, data, b.Enum))
+		}
 		for _, enumValue := range b.Enum {
-			actualType := reflect.TypeOf(enumValue)
-			if actualType != nil { // Safeguard
-				expectedValue := reflect.ValueOf(data)
-				if expectedValue.IsValid() && expectedValue.Type().ConvertibleTo(actualType) {
-					if reflect.DeepEqual(expectedValue.Convert(actualType).Interface(), enumValue) {
-						return nil
-					}
-				}
+			if equalValues(data, enumValue) {
+				return nil
 			}
 		}
 		return errorHelp.sErr(errors.EnumFail(b.Path, b.In, data, b.Enum))
@@ -71,15 +78,33 @@ func (b *basicCommonValidator) Validate(data interface{}) (res *Result) {
 	return nil
 }
 
+// equalValues returns true if data, converted to the type of value, is equal
+// to value.
+func equalValues(data, value interface{}) bool {
+	actualType := reflect.TypeOf(value)
+	if actualType == nil { // Safeguard
+		return false
+	}
+	expectedValue := reflect.ValueOf(data)
+	return expectedValue.IsValid() && expectedValue.Type().ConvertibleTo(actualType) &&
+		reflect.DeepEqual(expectedValue.Convert(actualType).Interface(), value)
+}
+
 type numberValidator struct {
 	Path             string
 	In               string
 	Default          interface{}
 	MultipleOf       *float64
+	ExactMultipleOf  bool
 	Maximum          *float64
 	ExclusiveMaximum bool
 	Minimum          *float64
 	ExclusiveMinimum bool
+	// ExclusiveMaximumValue and ExclusiveMinimumValue are the numeric
+	// exclusive bounds of JSON Schema 2020-12, applying with Maximum and
+	// Minimum.
+	ExclusiveMaximumValue *float64
+	ExclusiveMinimumValue *float64
 	// Allows for more accurate behavior regarding integers
 	Type   string
 	Format string
@@ -141,7 +166,12 @@ func (n *numberValidator) Validate(val interface{}) *Result {
 	if n.MultipleOf != nil {
 		// Is the constraint specifier within the range of the specific numeric type and format?
 		resMultiple.AddErrors(IsValueValidAgainstRange(*n.MultipleOf, n.Type, n.Format, "MultipleOf", n.Path))
-		if resMultiple.IsValid() {
+		if n.ExactMultipleOf {
+			// Constraint validated with exact arithmetic, whatever the types
+			if err := MultipleOfExact(n.Path, n.In, val, *n.MultipleOf); err != nil {
+				resMultiple.Merge(errorHelp.sErr(err))
+			}
+		} else if resMultiple.IsValid() {
 			// Constraint validated with compatible types
 			if err := MultipleOfNativeType(n.Path, n.In, val, *n.MultipleOf); err != nil {
 				resMultiple.Merge(errorHelp.sErr(err))
@@ -154,44 +184,61 @@ func (n *numberValidator) Validate(val interface{}) *Result {
 		}
 	}
 
-	// nolint: dupl
 	if n.Maximum != nil {
-		// Is the constraint specifier within the range of the specific numeric type and format?
-		resMaximum.AddErrors(IsValueValidAgainstRange(*n.Maximum, n.Type, n.Format, "Maximum boundary", n.Path))
-		if resMaximum.IsValid() {
-			// Constraint validated with compatible types
-			if err := MaximumNativeType(n.Path, n.In, val, *n.Maximum, n.ExclusiveMaximum); err != nil {
-				resMaximum.Merge(errorHelp.sErr(err))
-			}
-		} else {
-			// Constraint nevertheless validated, converted as general number
-			if err := Maximum(n.Path, n.In, data, *n.Maximum, n.ExclusiveMaximum); err != nil {
-				resMaximum.Merge(errorHelp.sErr(err))
-			}
-		}
+		resMaximum.Merge(n.validateMaximum(val, data, *n.Maximum, n.ExclusiveMaximum))
+	}
+	if n.ExclusiveMaximumValue != nil {
+		resMaximum.Merge(n.validateMaximum(val, data, *n.ExclusiveMaximumValue, true))
 	}
-
-	// nolint: dupl
 	if n.Minimum != nil {
-		// Is the constraint specifier within the range of the specific numeric type and format?
-		resMinimum.AddErrors(IsValueValidAgainstRange(*n.Minimum, n.Type, n.Format, "Minimum boundary", n.Path))
-		if resMinimum.IsValid() {
-			// Constraint validated with compatible types
-			if err := MinimumNativeType(n.Path, n.In, val, *n.Minimum, n.ExclusiveMinimum); err != nil {
-				resMinimum.Merge(errorHelp.sErr(err))
-			}
-		} else {
-			// Constraint nevertheless validated, converted as general number
-			if err := Minimum(n.Path, n.In, data, *n.Minimum, n.ExclusiveMinimum); err != nil {
-				resMinimum.Merge(errorHelp.sErr(err))
-			}
-		}
+		resMinimum.Merge(n.validateMinimum(val, data, *n.Minimum, n.ExclusiveMinimum))
+	}
+	if n.ExclusiveMinimumValue != nil {
+		resMinimum.Merge(n.validateMinimum(val, data, *n.ExclusiveMinimumValue, true))
 	}
 	res.Merge(resMultiple, resMinimum, resMaximum)
 	res.Inc()
 	return res
 }
 
+// nolint: dupl
+func (n *numberValidator) validateMaximum(val interface{}, data, max float64, exclusive bool) *Result {
+	res := new(Result)
+	// Is the constraint specifier within the range of the specific numeric type and format?
+	res.AddErrors(IsValueValidAgainstRange(max, n.Type, n.Format, "Maximum boundary", n.Path))
+	if res.IsValid() {
+		// Constraint validated with compatible types
+		if err := MaximumNativeType(n.Path, n.In, val, max, exclusive); err != nil {
+			res.Merge(errorHelp.sErr(err))
+		}
+	} else {
+		// Constraint nevertheless validated, converted as general number
+		if err := Maximum(n.Path, n.In, data, max, exclusive); err != nil {
+			res.Merge(errorHelp.sErr(err))
+		}
+	}
+	return res
+}
+
+// nolint: dupl
+func (n *numberValidator) validateMinimum(val interface{}, data, min float64, exclusive bool) *Result {
+	res := new(Result)
+	// Is the constraint specifier within the range of the specific numeric type and format?
+	res.AddErrors(IsValueValidAgainstRange(min, n.Type, n.Format, "Minimum boundary", n.Path))
+	if res.IsValid() {
+		// Constraint validated with compatible types
+		if err := MinimumNativeType(n.Path, n.In, val, min, exclusive); err != nil {
+			res.Merge(errorHelp.sErr(err))
+		}
+	} else {
+		// Constraint nevertheless validated, converted as general number
+		if err := Minimum(n.Path, n.In, data, min, exclusive); err != nil {
+			res.Merge(errorHelp.sErr(err))
+		}
+	}
+	return res
+}
+
 type stringValidator struct {
 	MaxLength *int64
 	MinLength *int64
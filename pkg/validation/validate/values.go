@@ -15,8 +15,11 @@
 package validate
 
 import (
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
+	"strconv"
 	"unicode/utf8"
 
 	"github.com/go-openapi/swag"
@@ -82,7 +85,7 @@ func UniqueItems(path, in string, data interface{}) *errors.Validation {
 	for i := 0; i < val.Len(); i++ {
 		v := val.Index(i).Interface()
 		for _, u := range unique {
-			if reflect.DeepEqual(v, u) {
+			if equalItems(v, u) {
 				return errors.DuplicateItems(path, in)
 			}
 		}
@@ -91,6 +94,11 @@ func UniqueItems(path, in string, data interface{}) *errors.Validation {
 	return nil
 }
 
+// equalItems returns whether two items are equal for uniqueItems.
+func equalItems(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
 // MinLength validates a string for minimum length
 func MinLength(path, in, data string, minLength int64) *errors.Validation {
 	strLen := int64(utf8.RuneCount([]byte(data)))
@@ -221,6 +229,47 @@ func MultipleOfUint(path, in string, data, factor uint64) *errors.Validation {
 	return nil
 }
 
+// MultipleOfExact validates if the provided number is a multiple of the factor,
+// as MultipleOf, but with exact arithmetic instead of floats. Numbers are read
+// as their shortest decimal representation, e.g. 0.01 as 1/100, so that 19.99
+// is a multiple of 0.01, and json.Number as written.
+func MultipleOfExact(path, in string, data interface{}, factor float64) *errors.Validation {
+	// multipleOf factor must be positive
+	if factor <= 0 {
+		return errors.MultipleOfMustBePositive(path, in, factor)
+	}
+	value, ok := exactNumber(data)
+	f, okFactor := exactNumber(factor)
+	if !ok || !okFactor {
+		return MultipleOf(path, in, valueHelp.asFloat64(data), factor)
+	}
+	if !value.Quo(value, f).IsInt() {
+		return errors.NotMultipleOf(path, in, factor, data)
+	}
+	return nil
+}
+
+// exactNumber returns the numeric value as a rational number, or false if it
+// is not a finite number.
+func exactNumber(value interface{}) (*big.Rat, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(string(v))
+	case float32:
+		return new(big.Rat).SetString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(v.Uint())), true
+	}
+	return nil, false
+}
+
 // FormatOf validates if a string matches a format in the format registry
 func FormatOf(path, in, format, data string, registry strfmt.Registry) *errors.Validation {
 	if registry == nil {
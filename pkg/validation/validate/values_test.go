@@ -15,6 +15,7 @@
 package validate
 
 import (
+	"encoding/json"
 	"math"
 	"testing"
 
@@ -170,6 +171,30 @@ func TestValuMultipleOf(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestValuMultipleOfExact(t *testing.T) {
+	// rejected with floats
+	assert.Error(t, MultipleOf("test", "body", 19.99, 0.01))
+	assert.Nil(t, MultipleOfExact("test", "body", 19.99, 0.01))
+	assert.Nil(t, MultipleOfExact("test", "body", 4.35, 0.01))
+	assert.Nil(t, MultipleOfExact("test", "body", 0.3, 0.1))
+	assert.Nil(t, MultipleOfExact("test", "body", json.Number("12345678901234567.89"), 0.01))
+	assert.Nil(t, MultipleOfExact("test", "body", int64(9), 3))
+	assert.Nil(t, MultipleOfExact("test", "body", uint32(10), 2.5))
+
+	err := MultipleOfExact("test", "body", 9.34, 0.1)
+	if assert.NotNil(t, err) {
+		assert.EqualValues(t, errors.MultipleOfFailCode, err.Code())
+	}
+	assert.NotNil(t, MultipleOfExact("test", "body", json.Number("1.001"), 0.01))
+	assert.NotNil(t, MultipleOfExact("test", "body", int64(10), 3))
+
+	// error on non-positive factor
+	err = MultipleOfExact("test", "body", 9.34, 0)
+	if assert.NotNil(t, err) {
+		assert.EqualValues(t, errors.MultipleOfMustBePositiveCode, err.Code())
+	}
+}
+
 // Test edge case for Pattern (in regular spec, no invalid regexp should reach there)
 func TestValues_Pattern_Edgecases(t *testing.T) {
 	var err *errors.Validation
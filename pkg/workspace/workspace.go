@@ -0,0 +1,370 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workspace loads OpenAPI v2 documents that are split across several
+// files referencing each other through relative $refs, and bundles them into
+// a single self-contained document.
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/kube-openapi/pkg/schemamutation"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const definitionPrefix = "#/definitions/"
+
+// Definition is a schema definition of the workspace along with the file it
+// was loaded from.
+type Definition struct {
+	// Name is the name of the definition in the bundled document.
+	Name string
+	// Source is the absolute path of the file declaring the definition.
+	Source string
+	// Schema is the definition as written in Source, with refs untouched.
+	Schema spec.Schema
+}
+
+// Workspace is the in-memory representation of a set of spec files and of
+// every file they transitively reference.
+type Workspace struct {
+	// roots are the files passed to Load, in order.
+	roots []string
+	// documents are the files holding a Swagger document, keyed by absolute path.
+	documents map[string]*spec.Swagger
+	// schemas are the files holding a single standalone schema, keyed by absolute path.
+	schemas map[string]*spec.Schema
+}
+
+// Load reads the given spec files, JSON or YAML, and every file they reference
+// through relative $refs. A referenced file is either a Swagger document, in
+// which case refs point into its definitions ("common.json#/definitions/Foo"),
+// or a standalone schema referenced as a whole ("pet.yaml").
+//
+// Remote (http) references are not supported.
+func Load(paths ...string) (*Workspace, error) {
+	w := &Workspace{
+		documents: map[string]*spec.Swagger{},
+		schemas:   map[string]*spec.Schema{},
+	}
+	var queue []string
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		w.roots = append(w.roots, abs)
+		queue = append(queue, abs)
+	}
+
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		if w.has(file) {
+			continue
+		}
+		refs, err := w.loadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			target, _, err := resolveRef(file, ref)
+			if err != nil {
+				return nil, err
+			}
+			if target != "" && !w.has(target) {
+				queue = append(queue, target)
+			}
+		}
+	}
+
+	return w, nil
+}
+
+func (w *Workspace) has(file string) bool {
+	_, isDoc := w.documents[file]
+	_, isSchema := w.schemas[file]
+	return isDoc || isSchema
+}
+
+// loadFile parses file, stores it in the workspace and returns all refs found in it.
+func (w *Workspace) loadFile(file string) ([]spec.Ref, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(file)); ext == ".yaml" || ext == ".yml" {
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to convert %s to JSON: %v", file, err)
+		}
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+	}
+
+	var refs []spec.Ref
+	collector := &schemamutation.Walker{
+		SchemaCallback: schemamutation.SchemaCallBackNoop,
+		RefCallback: func(ref *spec.Ref) *spec.Ref {
+			if ref.String() != "" {
+				refs = append(refs, *ref)
+			}
+			return ref
+		},
+	}
+
+	if isDocument(keys) {
+		doc := &spec.Swagger{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+		w.documents[file] = doc
+		collector.WalkRoot(doc)
+		return refs, nil
+	}
+
+	s := &spec.Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+	}
+	w.schemas[file] = s
+	collector.WalkSchema(s)
+	return refs, nil
+}
+
+// isDocument tells a Swagger document apart from a standalone schema.
+func isDocument(keys map[string]json.RawMessage) bool {
+	if _, ok := keys["swagger"]; ok {
+		return true
+	}
+	if _, ok := keys["paths"]; ok {
+		return true
+	}
+	_, hasDefinitions := keys["definitions"]
+	_, hasType := keys["type"]
+	_, hasProperties := keys["properties"]
+	return hasDefinitions && !hasType && !hasProperties
+}
+
+// resolveRef returns the absolute file ref points to, relative to from, and the
+// definition name within that file. An empty file means that ref is local to from.
+// An empty name means that ref points to the whole file.
+func resolveRef(from string, ref spec.Ref) (string, string, error) {
+	if ref.HasFullURL {
+		return "", "", fmt.Errorf("remote reference %q in %s is not supported", ref.String(), from)
+	}
+	u := ref.GetURL()
+	if u == nil {
+		return "", "", fmt.Errorf("invalid reference %q in %s", ref.String(), from)
+	}
+
+	name := ""
+	if u.Fragment != "" {
+		if !strings.HasPrefix("#"+u.Fragment, definitionPrefix) {
+			return "", "", fmt.Errorf("unsupported reference %q in %s: only references to definitions are supported", ref.String(), from)
+		}
+		name = jsonpointer.Unescape(u.Fragment[len(definitionPrefix)-1:])
+	}
+
+	if u.Path == "" {
+		return "", name, nil
+	}
+	target := filepath.FromSlash(u.Path)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(from), target)
+	}
+	return filepath.Clean(target), name, nil
+}
+
+// schemaFileDefinitionName is the definition name given to a standalone schema file.
+func schemaFileDefinitionName(file string) string {
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Document returns the Swagger document loaded from path, if any.
+func (w *Workspace) Document(path string) (*spec.Swagger, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, false
+	}
+	doc, ok := w.documents[abs]
+	return doc, ok
+}
+
+// Files returns the absolute paths of all files in the workspace, sorted.
+func (w *Workspace) Files() []string {
+	files := make([]string, 0, len(w.documents)+len(w.schemas))
+	for f := range w.documents {
+		files = append(files, f)
+	}
+	for f := range w.schemas {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Definitions returns every definition of the workspace with its provenance,
+// sorted by name and source.
+func (w *Workspace) Definitions() []Definition {
+	var defs []Definition
+	for file, doc := range w.documents {
+		for name, s := range doc.Definitions {
+			defs = append(defs, Definition{Name: name, Source: file, Schema: s})
+		}
+	}
+	for file, s := range w.schemas {
+		defs = append(defs, Definition{Name: schemaFileDefinitionName(file), Source: file, Schema: *s})
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Name != defs[j].Name {
+			return defs[i].Name < defs[j].Name
+		}
+		return defs[i].Source < defs[j].Source
+	})
+	return defs
+}
+
+// Bundle merges the workspace into a single document whose refs are all local.
+//
+// Paths, parameters and responses are taken from the files passed to Load,
+// definitions from every file of the workspace. The info, host and other
+// top-level properties are those of the first file passed to Load. Bundle fails
+// if two different definitions or two paths end up with the same name.
+func (w *Workspace) Bundle() (*spec.Swagger, error) {
+	ret := &spec.Swagger{}
+	if len(w.roots) > 0 {
+		if root, ok := w.documents[w.roots[0]]; ok {
+			ret.SwaggerProps = root.SwaggerProps
+			ret.VendorExtensible = root.VendorExtensible
+		}
+	}
+	ret.Paths = nil
+	ret.Parameters = nil
+	ret.Responses = nil
+	ret.Definitions = spec.Definitions{}
+
+	sources := map[string]string{}
+	var err error
+	for _, def := range w.Definitions() {
+		s := w.localizeSchema(def.Source, &def.Schema, &err)
+		if err != nil {
+			return nil, err
+		}
+		if existing, found := ret.Definitions[def.Name]; found {
+			if !reflect.DeepEqual(existing, *s) {
+				return nil, fmt.Errorf("definition %q is declared differently in %s and %s", def.Name, sources[def.Name], def.Source)
+			}
+			continue
+		}
+		ret.Definitions[def.Name] = *s
+		sources[def.Name] = def.Source
+	}
+
+	for _, file := range w.roots {
+		doc, ok := w.documents[file]
+		if !ok {
+			continue
+		}
+		localized := w.localizeDocument(file, doc, &err)
+		if err != nil {
+			return nil, err
+		}
+		if localized.Paths != nil {
+			if ret.Paths == nil {
+				ret.Paths = &spec.Paths{Paths: map[string]spec.PathItem{}}
+			}
+			for path, item := range localized.Paths.Paths {
+				if _, found := ret.Paths.Paths[path]; found {
+					return nil, fmt.Errorf("path %q is declared more than once, last in %s", path, file)
+				}
+				ret.Paths.Paths[path] = item
+			}
+		}
+		for name, p := range localized.Parameters {
+			if ret.Parameters == nil {
+				ret.Parameters = map[string]spec.Parameter{}
+			}
+			if existing, found := ret.Parameters[name]; found && !reflect.DeepEqual(existing, p) {
+				return nil, fmt.Errorf("parameter %q is declared differently in several files, last in %s", name, file)
+			}
+			ret.Parameters[name] = p
+		}
+		for name, r := range localized.Responses {
+			if ret.Responses == nil {
+				ret.Responses = map[string]spec.Response{}
+			}
+			if existing, found := ret.Responses[name]; found && !reflect.DeepEqual(existing, r) {
+				return nil, fmt.Errorf("response %q is declared differently in several files, last in %s", name, file)
+			}
+			ret.Responses[name] = r
+		}
+	}
+	return ret, nil
+}
+
+// localizer returns a walker rewriting refs found in file to local definition refs.
+func (w *Workspace) localizer(file string, errp *error) *schemamutation.Walker {
+	return &schemamutation.Walker{
+		SchemaCallback: schemamutation.SchemaCallBackNoop,
+		RefCallback: func(ref *spec.Ref) *spec.Ref {
+			if ref.String() == "" || *errp != nil {
+				return ref
+			}
+			target, name, err := resolveRef(file, *ref)
+			if err != nil {
+				*errp = err
+				return ref
+			}
+			if target == "" {
+				return ref
+			}
+			if _, isSchema := w.schemas[target]; isSchema && name == "" {
+				name = schemaFileDefinitionName(target)
+			} else if name == "" {
+				*errp = fmt.Errorf("reference %q in %s points to a whole document", ref.String(), file)
+				return ref
+			}
+			newRef, err := spec.NewRef(definitionPrefix + jsonpointer.Escape(name))
+			if err != nil {
+				*errp = err
+				return ref
+			}
+			return &newRef
+		},
+	}
+}
+
+func (w *Workspace) localizeSchema(file string, s *spec.Schema, errp *error) *spec.Schema {
+	return w.localizer(file, errp).WalkSchema(s)
+}
+
+func (w *Workspace) localizeDocument(file string, doc *spec.Swagger, errp *error) *spec.Swagger {
+	return w.localizer(file, errp).WalkRoot(doc)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "workspace")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoadAndBundle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"api.json": `{
+			"swagger": "2.0",
+			"info": {"title": "pets", "version": "v1"},
+			"paths": {
+				"/pets": {
+					"get": {
+						"responses": {
+							"200": {"description": "OK", "schema": {"$ref": "models/common.json#/definitions/PetList"}}
+						}
+					}
+				}
+			}
+		}`,
+		"models/common.json": `{
+			"definitions": {
+				"PetList": {
+					"type": "object",
+					"properties": {
+						"items": {"type": "array", "items": {"$ref": "pet.yaml"}},
+						"meta": {"$ref": "#/definitions/Meta"}
+					}
+				},
+				"Meta": {"type": "object", "properties": {"continue": {"type": "string"}}}
+			}
+		}`,
+		"models/pet.yaml": `
+type: object
+properties:
+  name:
+    type: string
+`,
+	})
+
+	w, err := Load(filepath.Join(dir, "api.json"))
+	require.NoError(t, err)
+	assert.Len(t, w.Files(), 3)
+
+	var provenance []string
+	for _, def := range w.Definitions() {
+		rel, err := filepath.Rel(dir, def.Source)
+		require.NoError(t, err)
+		provenance = append(provenance, def.Name+"@"+filepath.ToSlash(rel))
+	}
+	assert.Equal(t, []string{
+		"Meta@models/common.json",
+		"PetList@models/common.json",
+		"pet@models/pet.yaml",
+	}, provenance)
+
+	bundle, err := w.Bundle()
+	require.NoError(t, err)
+	assert.Equal(t, "pets", bundle.Info.Title)
+	assert.Len(t, bundle.Definitions, 3)
+
+	resp := bundle.Paths.Paths["/pets"].Get.Responses.StatusCodeResponses[200]
+	assert.Equal(t, "#/definitions/PetList", resp.Schema.Ref.String())
+	list := bundle.Definitions["PetList"]
+	assert.Equal(t, "#/definitions/pet", list.Properties["items"].Items.Schema.Ref.String())
+	meta := list.Properties["meta"]
+	assert.Equal(t, "#/definitions/Meta", meta.Ref.String())
+
+	// the loaded documents are left untouched
+	doc, ok := w.Document(filepath.Join(dir, "api.json"))
+	require.True(t, ok)
+	resp = doc.Paths.Paths["/pets"].Get.Responses.StatusCodeResponses[200]
+	assert.Equal(t, "models/common.json#/definitions/PetList", resp.Schema.Ref.String())
+}
+
+func TestBundleConflicts(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.json": `{"swagger": "2.0", "paths": {}, "definitions": {"Foo": {"type": "string"}}}`,
+		"b.json": `{"swagger": "2.0", "paths": {}, "definitions": {"Foo": {"type": "integer"}}}`,
+		"c.json": `{"swagger": "2.0", "paths": {}, "definitions": {"Foo": {"type": "string"}}}`,
+	})
+
+	w, err := Load(filepath.Join(dir, "a.json"), filepath.Join(dir, "c.json"))
+	require.NoError(t, err)
+	bundle, err := w.Bundle()
+	require.NoError(t, err)
+	assert.Len(t, bundle.Definitions, 1)
+
+	w, err = Load(filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json"))
+	require.NoError(t, err)
+	_, err = w.Bundle()
+	assert.Error(t, err)
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"missing.json": `{"swagger": "2.0", "definitions": {"Foo": {"$ref": "nope.json#/definitions/Bar"}}}`,
+		"remote.json":  `{"swagger": "2.0", "definitions": {"Foo": {"$ref": "http://example.com/x.json#/definitions/Bar"}}}`,
+	})
+
+	_, err := Load(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+	_, err = Load(filepath.Join(dir, "remote.json"))
+	assert.Error(t, err)
+}